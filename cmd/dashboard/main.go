@@ -9,13 +9,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/clustercost/clustercost-dashboard/internal/alerts"
 	"github.com/clustercost/clustercost-dashboard/internal/api"
 	"github.com/clustercost/clustercost-dashboard/internal/auth"
 	"github.com/clustercost/clustercost-dashboard/internal/config"
 	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/egress"
 	"github.com/clustercost/clustercost-dashboard/internal/finops"
 	ccgrpc "github.com/clustercost/clustercost-dashboard/internal/grpc"
 	"github.com/clustercost/clustercost-dashboard/internal/logging"
+	"github.com/clustercost/clustercost-dashboard/internal/reports"
 	"github.com/clustercost/clustercost-dashboard/internal/store"
 	"github.com/clustercost/clustercost-dashboard/internal/vm"
 )
@@ -56,11 +59,36 @@ func main() {
 	// Initialize FinOps Engine
 	finopsEngine := finops.NewEngine(vmClient, st.PricingCatalog())
 
+	// Initialize Alert Evaluator
+	alertEngine := alerts.NewEngine(sqlite, vmClient, alerts.NewWebhookNotifier(10*time.Second), cfg.AlertEvaluationInterval, logging.New("alerts"))
+	go alertEngine.Run(ctx)
+
+	// Initialize Cost Anomaly Detector
+	anomalyDetector := finops.NewAnomalyDetector(sqlite, vmClient, cfg.AnomalyDetectionInterval, logging.New("anomalies"))
+	go anomalyDetector.Run(ctx)
+
+	// Initialize Report Scheduler
+	var mailer reports.Mailer
+	if smtpMailer := reports.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom); smtpMailer != nil {
+		mailer = smtpMailer
+	}
+	reportScheduler := reports.NewScheduler(sqlite, vmClient, mailer, logging.New("reports"))
+	go reportScheduler.Run(ctx)
+
+	// Initialize External Destination Classifier
+	egressClassifier, err := egress.Load(cfg.EgressRulesFile, cfg.AWSIPRangesFile)
+	if err != nil {
+		logger.Fatalf("egress rules setup error: %v", err)
+	}
+	if cfg.AWSIPRangesFile == "" {
+		logger.Printf("AWS_IP_RANGES_FILE not set: AWS destinations are matched by DNS name only")
+	}
+
 	auth.SetSecret(cfg.JWTSecret)
 
 	srv := &http.Server{
 		Addr:              cfg.ListenAddr,
-		Handler:           api.NewRouter(vmClient, sqlite, st, finopsEngine),
+		Handler:           api.NewRouter(vmClient, sqlite, st, finopsEngine, alertEngine, reportScheduler, egressClassifier),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
@@ -0,0 +1,254 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// anomalyBaseline is the history used to score cost anomalies.
+const anomalyBaseline = 24 * time.Hour
+
+// Source provides the signals alert rules are evaluated against.
+type Source interface {
+	Overview(ctx context.Context, limit int, mode store.AllocationMode) (store.OverviewPayload, error)
+	NamespaceDetail(ctx context.Context, name string) (store.NamespaceSummary, error)
+	AgentStatus(ctx context.Context) (store.AgentStatusPayload, error)
+	CostSince(ctx context.Context, since time.Time, scope map[string]string) (float64, error)
+	CostAnomalyScore(ctx context.Context, scope map[string]string, baseline time.Duration) (float64, error)
+	ValidateGroupLabel(key string) error
+}
+
+// Engine periodically evaluates stored alert rules and sends notifications on state changes.
+type Engine struct {
+	db       *db.Store
+	source   Source
+	notifier Notifier
+	interval time.Duration
+	logger   *log.Logger
+	now      func() time.Time
+}
+
+// NewEngine creates an alert evaluator. A zero interval disables the periodic loop.
+func NewEngine(database *db.Store, source Source, notifier Notifier, interval time.Duration, logger *log.Logger) *Engine {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &Engine{
+		db:       database,
+		source:   source,
+		notifier: notifier,
+		interval: interval,
+		logger:   logger,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run evaluates rules every interval until ctx is cancelled.
+func (e *Engine) Run(ctx context.Context) {
+	if e.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.EvaluateOnce(ctx); err != nil {
+				e.logger.Printf("alert evaluation failed: %v", err)
+			}
+		}
+	}
+}
+
+// EvaluateOnce evaluates every enabled rule. Rule level errors are logged and do not stop the pass.
+func (e *Engine) EvaluateOnce(ctx context.Context) error {
+	rules, err := e.db.ListAlertRules()
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if err := e.evaluateRule(ctx, rule); err != nil {
+			e.logger.Printf("alert rule %d (%s): %v", rule.ID, rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// SendTest delivers a test notification for a rule without touching its state.
+func (e *Engine) SendTest(ctx context.Context, rule db.AlertRule) error {
+	return e.notifier.Notify(ctx, rule.WebhookURL, buildNotification(rule, "test", 0, e.now()))
+}
+
+// evaluateRule advances the rule state machine:
+// inactive -> pending when breached, pending -> firing once breached for ForSeconds,
+// firing -> inactive when it clears. Only firing and resolve transitions notify, so a
+// rule that stays breached is reported once.
+func (e *Engine) evaluateRule(ctx context.Context, rule db.AlertRule) error {
+	value, breached, err := e.measure(ctx, rule)
+	if err == vm.ErrNoData {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := e.now()
+	state, err := e.db.GetAlertState(rule.ID)
+	if err != nil {
+		return err
+	}
+	state.Value = value
+	state.EvaluatedAt = now
+
+	var notify string
+	switch {
+	case breached && state.State == db.AlertFiring:
+		// Already reported
+	case breached:
+		if state.State != db.AlertPending {
+			state.State = db.AlertPending
+			state.ActiveSince = now
+		}
+		if now.Sub(state.ActiveSince) >= time.Duration(rule.ForSeconds)*time.Second {
+			state.State = db.AlertFiring
+			state.FiredAt = now
+			notify = "firing"
+		}
+	case state.State == db.AlertFiring:
+		state.State = db.AlertInactive
+		state.ResolvedAt = now
+		notify = "resolved"
+	default:
+		state.State = db.AlertInactive
+		state.ActiveSince = time.Time{}
+	}
+
+	if notify != "" {
+		if err := e.notifier.Notify(ctx, rule.WebhookURL, buildNotification(rule, notify, value, now)); err != nil {
+			// Keep the previous state so the transition is retried on the next pass
+			return fmt.Errorf("notify %s: %w", notify, err)
+		}
+	}
+	return e.db.SaveAlertState(state)
+}
+
+// measure returns the current value of the rule signal and whether it breaches the threshold.
+func (e *Engine) measure(ctx context.Context, rule db.AlertRule) (float64, bool, error) {
+	ctx = vm.WithClusterID(ctx, rule.ClusterID)
+	switch rule.Kind {
+	case db.AlertKindCost:
+		var cost float64
+		if rule.Namespace != "" {
+			ns, err := e.source.NamespaceDetail(ctx, rule.Namespace)
+			if err != nil {
+				return 0, false, err
+			}
+			cost = ns.HourlyCost
+		} else {
+			overview, err := e.source.Overview(ctx, 1, store.AllocationUsage)
+			if err != nil {
+				return 0, false, err
+			}
+			cost = overview.TotalHourlyCost
+		}
+		return cost, cost > rule.Threshold, nil
+	case db.AlertKindAnomaly:
+		var scope map[string]string
+		if rule.Namespace != "" {
+			scope = map[string]string{"namespace": rule.Namespace}
+		}
+		score, err := e.source.CostAnomalyScore(ctx, scope, anomalyBaseline)
+		if err != nil {
+			return 0, false, err
+		}
+		return score, score > rule.Threshold, nil
+	case db.AlertKindAgentOffline:
+		status, err := e.source.AgentStatus(ctx)
+		if err == vm.ErrNoData {
+			// No heartbeat inside the lookback window at all
+			return 1, true, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		if status.Status == "offline" {
+			return 1, true, nil
+		}
+		return 0, false, nil
+	case db.AlertKindBudget:
+		budget, err := e.db.GetBudget(rule.BudgetID)
+		if errors.Is(err, db.ErrNotFound) {
+			// The budget was deleted; nothing left to breach
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		eval, err := finops.EvaluateBudgetSpend(ctx, e.source, budget, e.now())
+		if err != nil {
+			return 0, false, err
+		}
+		return eval.UsedPercent, eval.UsedPercent >= rule.Threshold, nil
+	default:
+		return 0, false, fmt.Errorf("unknown alert kind %q", rule.Kind)
+	}
+}
+
+func buildNotification(rule db.AlertRule, status string, value float64, now time.Time) Notification {
+	return Notification{
+		Text:      notificationText(rule, status, value),
+		Status:    status,
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		Kind:      string(rule.Kind),
+		ClusterID: rule.ClusterID,
+		Namespace: rule.Namespace,
+		Value:     value,
+		Threshold: rule.Threshold,
+		Timestamp: now,
+	}
+}
+
+func notificationText(rule db.AlertRule, status string, value float64) string {
+	scope := "cluster"
+	if rule.ClusterID != "" {
+		scope = "cluster " + rule.ClusterID
+	}
+	if rule.Namespace != "" {
+		scope = "namespace " + rule.Namespace
+	}
+
+	var detail string
+	switch rule.Kind {
+	case db.AlertKindCost:
+		detail = fmt.Sprintf("%s hourly cost $%.2f (threshold $%.2f)", scope, value, rule.Threshold)
+	case db.AlertKindAnomaly:
+		detail = fmt.Sprintf("%s cost anomaly score %.1f (threshold %.1f)", scope, value, rule.Threshold)
+	case db.AlertKindAgentOffline:
+		detail = scope + " agent offline"
+	case db.AlertKindBudget:
+		detail = fmt.Sprintf("budget %d at %.0f%% of limit (threshold %.0f%%)", rule.BudgetID, value, rule.Threshold)
+	}
+
+	switch status {
+	case "resolved":
+		return fmt.Sprintf("[RESOLVED] %s: %s", rule.Name, detail)
+	case "test":
+		return fmt.Sprintf("[TEST] %s: test notification from ClusterCost", rule.Name)
+	default:
+		return fmt.Sprintf("[FIRING] %s: %s", rule.Name, detail)
+	}
+}
@@ -0,0 +1,221 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+type fakeSource struct {
+	hourlyCost  float64
+	agentStatus string
+	monthToDate float64
+}
+
+func (f *fakeSource) Overview(context.Context, int, store.AllocationMode) (store.OverviewPayload, error) {
+	return store.OverviewPayload{TotalHourlyCost: f.hourlyCost}, nil
+}
+func (f *fakeSource) NamespaceDetail(_ context.Context, name string) (store.NamespaceSummary, error) {
+	return store.NamespaceSummary{Namespace: name, HourlyCost: f.hourlyCost}, nil
+}
+func (f *fakeSource) AgentStatus(context.Context) (store.AgentStatusPayload, error) {
+	if f.agentStatus == "" {
+		return store.AgentStatusPayload{}, vm.ErrNoData
+	}
+	return store.AgentStatusPayload{Status: f.agentStatus}, nil
+}
+func (f *fakeSource) CostSince(context.Context, time.Time, map[string]string) (float64, error) {
+	return f.monthToDate, nil
+}
+func (f *fakeSource) CostAnomalyScore(context.Context, map[string]string, time.Duration) (float64, error) {
+	return 0, vm.ErrNoData
+}
+func (f *fakeSource) ValidateGroupLabel(string) error {
+	return nil
+}
+
+// sink is a local webhook receiver recording notifications.
+type sink struct {
+	mu       sync.Mutex
+	received []Notification
+}
+
+func (s *sink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var n Notification
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.received = append(s.received, n)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *sink) statuses() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.received))
+	for _, n := range s.received {
+		out = append(out, n.Status)
+	}
+	return out
+}
+
+func newTestEngine(t *testing.T, src Source) (*Engine, *db.Store, *sink, string) {
+	t.Helper()
+	sqlite, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlite.Close() })
+
+	recv := &sink{}
+	srv := httptest.NewServer(recv)
+	t.Cleanup(srv.Close)
+
+	return NewEngine(sqlite, src, NewWebhookNotifier(time.Second), 0, nil), sqlite, recv, srv.URL
+}
+
+func TestEngineFiresAfterForDurationAndResolvesOnce(t *testing.T) {
+	src := &fakeSource{hourlyCost: 12}
+	engine, sqlite, recv, url := newTestEngine(t, src)
+
+	now := time.Date(2024, time.June, 10, 12, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	rule, err := sqlite.CreateAlertRule(db.AlertRule{Name: "cost spike", Kind: db.AlertKindCost, Threshold: 10, ForSeconds: 300, WebhookURL: url, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+
+	step := func(d time.Duration) db.AlertState {
+		t.Helper()
+		now = now.Add(d)
+		if err := engine.EvaluateOnce(context.Background()); err != nil {
+			t.Fatalf("EvaluateOnce: %v", err)
+		}
+		st, err := sqlite.GetAlertState(rule.ID)
+		if err != nil {
+			t.Fatalf("GetAlertState: %v", err)
+		}
+		return st
+	}
+
+	if st := step(0); st.State != db.AlertPending {
+		t.Fatalf("expected pending, got %s", st.State)
+	}
+	if st := step(4 * time.Minute); st.State != db.AlertPending {
+		t.Fatalf("expected still pending before for-duration, got %s", st.State)
+	}
+	if st := step(time.Minute); st.State != db.AlertFiring || st.Value != 12 {
+		t.Fatalf("expected firing with value 12, got %+v", st)
+	}
+	// Still breached: deduplicated
+	step(time.Minute)
+
+	src.hourlyCost = 5
+	if st := step(time.Minute); st.State != db.AlertInactive || st.ResolvedAt.IsZero() {
+		t.Fatalf("expected resolved, got %+v", st)
+	}
+	step(time.Minute)
+
+	got := recv.statuses()
+	if len(got) != 2 || got[0] != "firing" || got[1] != "resolved" {
+		t.Fatalf("expected [firing resolved] notifications, got %v", got)
+	}
+	if recv.received[0].Text == "" {
+		t.Fatal("expected slack compatible text field")
+	}
+}
+
+func TestEnginePendingClearsWithoutNotification(t *testing.T) {
+	src := &fakeSource{hourlyCost: 12}
+	engine, sqlite, recv, url := newTestEngine(t, src)
+
+	rule, err := sqlite.CreateAlertRule(db.AlertRule{Name: "slow", Kind: db.AlertKindCost, Threshold: 10, ForSeconds: 3600, WebhookURL: url, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	_ = engine.EvaluateOnce(context.Background())
+	src.hourlyCost = 1
+	_ = engine.EvaluateOnce(context.Background())
+
+	st, _ := sqlite.GetAlertState(rule.ID)
+	if st.State != db.AlertInactive {
+		t.Fatalf("expected inactive, got %s", st.State)
+	}
+	if len(recv.statuses()) != 0 {
+		t.Fatalf("expected no notifications, got %v", recv.statuses())
+	}
+}
+
+func TestEngineAgentOfflineAndBudgetRules(t *testing.T) {
+	src := &fakeSource{agentStatus: "offline", monthToDate: 950}
+	engine, sqlite, recv, url := newTestEngine(t, src)
+
+	budget, err := sqlite.CreateBudget(db.Budget{Name: "team", Scope: db.BudgetScopeCluster, MonthlyLimit: 1000, AlertThresholdPercent: 80})
+	if err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+	if _, err := sqlite.CreateAlertRule(db.AlertRule{Name: "agent", Kind: db.AlertKindAgentOffline, WebhookURL: url, Enabled: true}); err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	if _, err := sqlite.CreateAlertRule(db.AlertRule{Name: "budget", Kind: db.AlertKindBudget, BudgetID: budget.ID, Threshold: 90, WebhookURL: url, Enabled: true}); err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+
+	if err := engine.EvaluateOnce(context.Background()); err != nil {
+		t.Fatalf("EvaluateOnce: %v", err)
+	}
+	if got := recv.statuses(); len(got) != 2 {
+		t.Fatalf("expected 2 firing notifications, got %v", got)
+	}
+}
+
+func TestSendTestDoesNotChangeState(t *testing.T) {
+	engine, sqlite, recv, url := newTestEngine(t, &fakeSource{})
+	rule, err := sqlite.CreateAlertRule(db.AlertRule{Name: "t", Kind: db.AlertKindAgentOffline, WebhookURL: url, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	if err := engine.SendTest(context.Background(), rule); err != nil {
+		t.Fatalf("SendTest: %v", err)
+	}
+	if got := recv.statuses(); len(got) != 1 || got[0] != "test" {
+		t.Fatalf("expected one test notification, got %v", got)
+	}
+	states, _ := sqlite.ListAlertStates()
+	if len(states) != 0 {
+		t.Fatalf("expected no stored state, got %+v", states)
+	}
+}
+
+func TestEngineBudgetRuleWithoutBudgetIsInactive(t *testing.T) {
+	engine, sqlite, recv, url := newTestEngine(t, &fakeSource{monthToDate: 950})
+
+	// A rule left behind by a budget deleted before rules were disabled with it
+	rule, err := sqlite.CreateAlertRule(db.AlertRule{Name: "budget", Kind: db.AlertKindBudget, BudgetID: 42, Threshold: 90, WebhookURL: url, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	if err := engine.evaluateRule(context.Background(), rule); err != nil {
+		t.Fatalf("expected a missing budget to evaluate as inactive, got %v", err)
+	}
+	state, err := sqlite.GetAlertState(rule.ID)
+	if err != nil || state.State != db.AlertInactive {
+		t.Fatalf("expected inactive state, got %+v (%v)", state, err)
+	}
+	if got := recv.statuses(); len(got) != 0 {
+		t.Fatalf("expected no notifications, got %v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Notification is the webhook payload. The top level "text" field makes it a valid
+// Slack incoming-webhook message; generic receivers can read the structured fields.
+type Notification struct {
+	Text      string    `json:"text"`
+	Status    string    `json:"status"` // firing, resolved or test
+	RuleID    int64     `json:"ruleId"`
+	RuleName  string    `json:"ruleName"`
+	Kind      string    `json:"kind"`
+	ClusterID string    `json:"clusterId,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notifier delivers alert notifications.
+type Notifier interface {
+	Notify(ctx context.Context, url string, n Notification) error
+}
+
+// WebhookNotifier posts notifications as JSON.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier with the given request timeout.
+func NewWebhookNotifier(timeout time.Duration) *WebhookNotifier {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookNotifier{client: &http.Client{Timeout: timeout}}
+}
+
+// Notify posts the notification to url and fails on non-2xx responses.
+func (w *WebhookNotifier) Notify(ctx context.Context, url string, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats for list endpoints. JSON keeps the regular response envelope.
+const (
+	formatJSON    = "json"
+	formatCSV     = "csv"
+	formatNDJSON  = "ndjson"
+	formatParquet = "parquet"
+)
+
+var exportContentTypes = map[string]string{
+	formatCSV:     "text/csv",
+	formatNDJSON:  "application/x-ndjson",
+	formatParquet: "application/vnd.apache.parquet",
+}
+
+// exportFlushRows is how many rows are written between flushes when streaming.
+const exportFlushRows = 1000
+
+type columnKind int
+
+const (
+	columnString columnKind = iota
+	columnInt
+	columnFloat
+	columnBool
+	columnTime
+)
+
+// exportColumn is one scalar field of a row type, named after its JSON key. Maps and string
+// slices are flattened into strings; other nested values are not exported.
+type exportColumn struct {
+	name  string
+	kind  columnKind
+	index []int
+}
+
+// exportOptions is the format and the columns of an export request.
+type exportOptions struct {
+	format  string
+	columns []exportColumn
+}
+
+// export reports whether the rows are written as a file rather than the JSON response.
+func (o exportOptions) export() bool {
+	return o.format != formatJSON
+}
+
+// limit returns the page size default: exports return everything up to max unless asked
+// otherwise. Larger exports are paged with offset; X-Total-Count gives the full count.
+func (o exportOptions) limit(raw string, fallback, max int) int {
+	if o.export() && raw == "" {
+		fallback = max
+	}
+	return parseLimit(raw, fallback, max)
+}
+
+// parseExport reads the format from the format parameter, else the Accept header, and the
+// comma-separated columns parameter, validated against the fields of T.
+func parseExport[T any](r *http.Request) (exportOptions, error) {
+	opts := exportOptions{format: formatJSON}
+	q := r.URL.Query()
+	if raw := strings.ToLower(q.Get("format")); raw != "" {
+		if _, ok := exportContentTypes[raw]; !ok && raw != formatJSON {
+			return opts, fmt.Errorf("format must be one of json, csv, ndjson, parquet")
+		}
+		opts.format = raw
+	} else {
+		opts.format = formatFromAccept(r.Header.Get("Accept"))
+	}
+
+	all := exportColumns(reflect.TypeOf((*T)(nil)).Elem(), nil)
+	raw := q.Get("columns")
+	if raw == "" {
+		opts.columns = all
+		return opts, nil
+	}
+	byName := make(map[string]exportColumn, len(all))
+	for _, col := range all {
+		byName[strings.ToLower(col.name)] = col
+	}
+	seen := make(map[string]bool, len(all))
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		col, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return opts, fmt.Errorf("unknown column %q", name)
+		}
+		if seen[col.name] {
+			return opts, fmt.Errorf("duplicate column %q", name)
+		}
+		seen[col.name] = true
+		opts.columns = append(opts.columns, col)
+	}
+	if len(opts.columns) == 0 {
+		return opts, fmt.Errorf("columns must name at least one column")
+	}
+	return opts, nil
+}
+
+func formatFromAccept(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return formatCSV
+		case "application/x-ndjson", "application/ndjson":
+			return formatNDJSON
+		case "application/vnd.apache.parquet", "application/parquet":
+			return formatParquet
+		}
+	}
+	return formatJSON
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// exportColumns lists the exportable fields of t in declaration order, descending into
+// embedded structs.
+func exportColumns(t reflect.Type, prefix []int) []exportColumn {
+	var out []exportColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int(nil), prefix...), i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			out = append(out, exportColumns(f.Type, index)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		kind, ok := columnKindOf(f.Type)
+		if !ok {
+			continue
+		}
+		out = append(out, exportColumn{name: name, kind: kind, index: index})
+	}
+	return out
+}
+
+func columnKindOf(t reflect.Type) (columnKind, bool) {
+	if t == timeType {
+		return columnTime, true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return columnString, true
+	case reflect.Bool:
+		return columnBool, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return columnInt, true
+	case reflect.Float32, reflect.Float64:
+		return columnFloat, true
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+			return columnString, true
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return columnString, true
+		}
+	}
+	return 0, false
+}
+
+// value returns the column of row as a string, int64, float64, bool or time.Time.
+func (c exportColumn) value(row reflect.Value) any {
+	v := row.FieldByIndex(c.index)
+	switch c.kind {
+	case columnTime:
+		return v.Interface().(time.Time)
+	case columnBool:
+		return v.Bool()
+	case columnInt:
+		if v.CanInt() {
+			return v.Int()
+		}
+		return int64(v.Uint())
+	case columnFloat:
+		return v.Float()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, iter.Key().String()+"="+iter.Value().String())
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ";")
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).String()
+		}
+		return strings.Join(items, ";")
+	}
+	return v.String()
+}
+
+func formatCell(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+// csvCell formats a cell for CSV. Text starting with a character spreadsheets read as a
+// formula (=, +, -, @, tab or carriage return) is prefixed with a quote so opening the export
+// cannot run it. Numbers are left as they are: they cannot hold a formula.
+func csvCell(v any) string {
+	cell := formatCell(v)
+	if _, text := v.(string); text && cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// writeExport writes rows as an attachment named after name. total is the number of matching
+// rows before the limit, sent as X-Total-Count so clients can tell a capped export from a full
+// one.
+func writeExport[T any](w http.ResponseWriter, opts exportOptions, name string, rows []T, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	out := newExportWriter(w, opts, name)
+	for _, row := range rows {
+		if err := out.write(reflect.ValueOf(row)); err != nil {
+			return
+		}
+	}
+	_ = out.close()
+}
+
+// exportWriter encodes an export one row at a time. CSV and NDJSON are flushed to the client
+// every exportFlushRows rows and Parquet writes a row group as often, so an export never holds
+// more rows than that. The status and headers go out with the first row or on close, so a
+// caller streaming rows from a query can still send an error until then.
+type exportWriter struct {
+	w       http.ResponseWriter
+	opts    exportOptions
+	name    string
+	started bool
+	rows    int
+
+	csv     *csv.Writer
+	record  []string
+	parquet *parquetWriter
+	values  [][]any
+	pending int
+}
+
+func newExportWriter(w http.ResponseWriter, opts exportOptions, name string) *exportWriter {
+	return &exportWriter{w: w, opts: opts, name: name}
+}
+
+func (e *exportWriter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	e.w.Header().Set("Content-Type", exportContentTypes[e.opts.format])
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.name+"."+e.opts.format))
+	e.w.WriteHeader(http.StatusOK)
+
+	switch e.opts.format {
+	case formatCSV:
+		e.csv = csv.NewWriter(e.w)
+		header := make([]string, len(e.opts.columns))
+		for i, col := range e.opts.columns {
+			header[i] = col.name
+		}
+		e.record = make([]string, len(e.opts.columns))
+		return e.csv.Write(header)
+	case formatParquet:
+		e.values = make([][]any, len(e.opts.columns))
+		var err error
+		e.parquet, err = newParquetWriter(e.w, e.opts.columns)
+		return err
+	}
+	return nil
+}
+
+// write encodes one row, a struct of the type the columns were parsed from.
+func (e *exportWriter) write(rv reflect.Value) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	switch e.opts.format {
+	case formatCSV:
+		for i, col := range e.opts.columns {
+			e.record[i] = csvCell(col.value(rv))
+		}
+		if err := e.csv.Write(e.record); err != nil {
+			return err
+		}
+	case formatParquet:
+		for i, col := range e.opts.columns {
+			e.values[i] = append(e.values[i], col.value(rv))
+		}
+		e.pending++
+	default:
+		if err := writeNDJSONRow(e.w, e.opts.columns, rv); err != nil {
+			return err
+		}
+	}
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// flush sends what has been encoded so far, writing the pending Parquet rows as a row group.
+func (e *exportWriter) flush() error {
+	switch e.opts.format {
+	case formatCSV:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	case formatParquet:
+		if e.pending > 0 {
+			if err := e.parquet.writeRowGroup(e.values, e.pending); err != nil {
+				return err
+			}
+			for i := range e.values {
+				e.values[i] = e.values[i][:0]
+			}
+			e.pending = 0
+		}
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// close writes the remaining rows and, for Parquet, the footer.
+func (e *exportWriter) close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if err := e.flush(); err != nil {
+		return err
+	}
+	if e.parquet != nil {
+		return e.parquet.close()
+	}
+	return nil
+}
+
+// writeNDJSONRow writes one object with the columns in order. Zero times and non-finite
+// floats are written as null.
+func writeNDJSONRow(w io.Writer, columns []exportColumn, rv reflect.Value) error {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, col := range columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(col.name)
+		v := col.value(rv)
+		if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			v = nil
+		}
+		if ts, ok := v.(time.Time); ok && ts.IsZero() {
+			v = nil
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"flag"
+	"math"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+var updateFixtures = flag.Bool("update", false, "rewrite testdata fixtures")
+
+func TestParseExportFormat(t *testing.T) {
+	cases := []struct {
+		url, accept, want string
+	}{
+		{"/api/cost/namespaces", "", formatJSON},
+		{"/api/cost/namespaces", "text/csv", formatCSV},
+		{"/api/cost/namespaces", "application/json, application/x-ndjson;q=0.9", formatNDJSON},
+		{"/api/cost/namespaces?format=parquet", "text/csv", formatParquet},
+		{"/api/cost/namespaces?format=JSON", "text/csv", formatJSON},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.url, nil)
+		req.Header.Set("Accept", tc.accept)
+		opts, err := parseExport[store.NamespaceSummary](req)
+		if err != nil || opts.format != tc.want {
+			t.Errorf("%s (Accept %q): expected %s, got %s (%v)", tc.url, tc.accept, tc.want, opts.format, err)
+		}
+	}
+
+	if _, err := parseExport[store.NamespaceSummary](httptest.NewRequest("GET", "/?format=xlsx", nil)); err == nil {
+		t.Fatalf("expected unknown format to be rejected")
+	}
+	if _, err := parseExport[store.NamespaceSummary](httptest.NewRequest("GET", "/?columns=namespace,owner", nil)); err == nil {
+		t.Fatalf("expected unknown column to be rejected")
+	}
+	if _, err := parseExport[store.NamespaceSummary](httptest.NewRequest("GET", "/?columns=hourlyCost,namespace,HOURLYCOST", nil)); err == nil {
+		t.Fatalf("expected duplicate column to be rejected")
+	}
+}
+
+func exportFixture() []store.NamespaceSummary {
+	return []store.NamespaceSummary{
+		{Namespace: "payments", HourlyCost: 1.25, PodCount: 3, Labels: map[string]string{"team": "core", "env": "prod"}},
+		{Namespace: "web, \"edge\"", HourlyCost: 0.5, PodCount: 1},
+	}
+}
+
+func TestWriteExportCSVSelectsColumns(t *testing.T) {
+	opts, err := parseExport[store.NamespaceSummary](httptest.NewRequest("GET", "/?format=csv&columns=namespace,hourlyCost,labels", nil))
+	if err != nil {
+		t.Fatalf("parseExport returned error: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	writeExport(rec, opts, "namespaces", exportFixture(), 5)
+
+	if rec.Header().Get("Content-Type") != "text/csv" || !strings.Contains(rec.Header().Get("Content-Disposition"), "namespaces.csv") {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+	if rec.Header().Get("X-Total-Count") != "5" {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0], "|") != "namespace|hourlyCost|labels" {
+		t.Fatalf("unexpected csv: %v", records)
+	}
+	if strings.Join(records[1], "|") != "payments|1.25|env=prod;team=core" || records[2][0] != "web, \"edge\"" {
+		t.Fatalf("unexpected rows: %v", records[1:])
+	}
+}
+
+func TestWriteExportCSVEscapesFormulas(t *testing.T) {
+	rows := []parquetRow{{Name: "=HYPERLINK(\"http://evil\")", Count: -2}, {Name: "+1"}, {Name: "-1"}, {Name: "@SUM(A1)"}, {Name: "\tx"}, {Name: "\rx"}, {Name: "a=b"}}
+	opts, _ := parseExport[parquetRow](httptest.NewRequest("GET", "/?format=csv&columns=name,count", nil))
+	rec := httptest.NewRecorder()
+	writeExport(rec, opts, "rows", rows, len(rows))
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	var names []string
+	for _, r := range records[1:] {
+		names = append(names, r[0])
+	}
+	want := []string{"'=HYPERLINK(\"http://evil\")", "'+1", "'-1", "'@SUM(A1)", "'\tx", "'\rx", "a=b"}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected formula cells to be quoted, got %q", names)
+	}
+	if records[1][1] != "-2" {
+		t.Fatalf("expected negative numbers to stay numeric, got %q", records[1][1])
+	}
+
+	// Only the CSV path escapes
+	opts, _ = parseExport[parquetRow](httptest.NewRequest("GET", "/?format=ndjson&columns=name", nil))
+	rec = httptest.NewRecorder()
+	writeExport(rec, opts, "rows", rows[:1], 1)
+	if line := strings.TrimSpace(rec.Body.String()); line != `{"name":"=HYPERLINK(\"http://evil\")"}` {
+		t.Fatalf("expected ndjson to keep the value, got %s", line)
+	}
+}
+
+func TestWriteExportNDJSON(t *testing.T) {
+	opts, _ := parseExport[store.NamespaceSummary](httptest.NewRequest("GET", "/?format=ndjson&columns=podCount,namespace", nil))
+	rec := httptest.NewRecorder()
+	writeExport(rec, opts, "namespaces", exportFixture(), 2)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 || lines[0] != `{"podCount":3,"namespace":"payments"}` {
+		t.Fatalf("unexpected ndjson: %q", lines)
+	}
+}
+
+type parquetRow struct {
+	Name  string    `json:"name"`
+	Count int64     `json:"count"`
+	Cost  float64   `json:"cost"`
+	Flag  bool      `json:"flag"`
+	Seen  time.Time `json:"seen"`
+	Skip  []int     `json:"skip"`
+}
+
+var parquetSeen = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func parquetRows() []parquetRow {
+	return []parquetRow{{"a", 1, 0.5, true, parquetSeen, nil}, {"bb", -2, 1.5, false, time.Time{}, nil}, {"", 3, 2, true, parquetSeen, nil}}
+}
+
+func writeParquetRows(t *testing.T) []byte {
+	t.Helper()
+	rows := parquetRows()
+	opts, _ := parseExport[parquetRow](httptest.NewRequest("GET", "/?format=parquet", nil))
+	rec := httptest.NewRecorder()
+	writeExport(rec, opts, "rows", rows, len(rows))
+	return rec.Body.Bytes()
+}
+
+func TestWriteExportNDJSONZeroTime(t *testing.T) {
+	opts, _ := parseExport[parquetRow](httptest.NewRequest("GET", "/?format=ndjson&columns=name,seen", nil))
+	rec := httptest.NewRecorder()
+	writeExport(rec, opts, "rows", parquetRows(), 3)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 || lines[0] != `{"name":"a","seen":"2026-01-02T03:04:05Z"}` || lines[1] != `{"name":"bb","seen":null}` {
+		t.Fatalf("unexpected ndjson: %q", lines)
+	}
+}
+
+func TestWriteExportParquet(t *testing.T) {
+	data := writeParquetRows(t)
+	if !bytes.HasPrefix(data, parquetMagic) || !bytes.HasSuffix(data, parquetMagic) {
+		t.Fatalf("missing parquet magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := readThriftStruct(t, bytes.NewReader(data[len(data)-8-footerLen:len(data)-8]))
+
+	if meta[3].(int64) != 3 {
+		t.Fatalf("expected 3 rows, got %v", meta[3])
+	}
+	schema := meta[2].([]any)
+	var names []string
+	for _, el := range schema[1:] {
+		names = append(names, string(el.(map[int16]any)[4].([]byte)))
+	}
+	if strings.Join(names, ",") != "name,count,cost,flag,seen" {
+		t.Fatalf("unexpected schema: %v", names)
+	}
+	if rep := schema[5].(map[int16]any)[3].(int64); rep != parquetOptional {
+		t.Fatalf("expected the time column to be optional, got repetition %d", rep)
+	}
+
+	chunks := meta[4].([]any)[0].(map[int16]any)[1].([]any)
+	page := func(col int) []byte {
+		offset := chunks[col].(map[int16]any)[2].(int64)
+		r := bytes.NewReader(data[offset:])
+		header := readThriftStruct(t, r)
+		size := header[3].(int64)
+		start := int(offset) + len(data[offset:]) - r.Len()
+		return data[start : start+int(size)]
+	}
+
+	names = nil
+	for r := bytes.NewReader(page(0)); r.Len() > 0; {
+		var n uint32
+		_ = binary.Read(r, binary.LittleEndian, &n)
+		s := make([]byte, n)
+		_, _ = r.Read(s)
+		names = append(names, string(s))
+	}
+	if strings.Join(names, "|") != "a|bb|" {
+		t.Fatalf("unexpected strings: %q", names)
+	}
+	if v := int64(binary.LittleEndian.Uint64(page(1)[8:])); v != -2 {
+		t.Fatalf("expected -2, got %d", v)
+	}
+	if v := math.Float64frombits(binary.LittleEndian.Uint64(page(2)[16:])); v != 2 {
+		t.Fatalf("expected 2, got %v", v)
+	}
+	if flags := page(3); len(flags) != 1 || flags[0] != 0b101 {
+		t.Fatalf("unexpected booleans: %b", flags)
+	}
+	// Definition levels 1,0,1 as three RLE runs, then the two times that are set
+	times := page(4)
+	if n := binary.LittleEndian.Uint32(times); n != 6 || !bytes.Equal(times[4:10], []byte{2, 1, 2, 0, 2, 1}) {
+		t.Fatalf("unexpected definition levels: %v", times[:10])
+	}
+	if len(times) != 10+16 || int64(binary.LittleEndian.Uint64(times[18:])) != parquetSeen.UnixMilli() {
+		t.Fatalf("expected two times of %d, got %v", parquetSeen.UnixMilli(), times[10:])
+	}
+}
+
+func TestWriteExportParquetRowGroups(t *testing.T) {
+	rows := make([]parquetRow, 2500)
+	for i := range rows {
+		rows[i] = parquetRow{Name: "row", Count: int64(i)}
+	}
+	opts, _ := parseExport[parquetRow](httptest.NewRequest("GET", "/?format=parquet", nil))
+	rec := httptest.NewRecorder()
+	writeExport(rec, opts, "rows", rows, len(rows))
+
+	data := rec.Body.Bytes()
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := readThriftStruct(t, bytes.NewReader(data[len(data)-8-footerLen:len(data)-8]))
+	if meta[3].(int64) != 2500 {
+		t.Fatalf("expected 2500 rows, got %v", meta[3])
+	}
+	var groups []int64
+	for _, group := range meta[4].([]any) {
+		groups = append(groups, group.(map[int16]any)[3].(int64))
+	}
+	if len(groups) != 3 || groups[0] != exportFlushRows || groups[2] != 500 {
+		t.Fatalf("expected row groups of %d, %d and 500 rows, got %v", exportFlushRows, exportFlushRows, groups)
+	}
+}
+
+// TestWriteExportParquetFixture pins the encoder output to testdata/rows.parquet, which is
+// meant to be checked with a real Parquet reader; see testdata/README.md. Run with -update
+// after an intended change to the encoding.
+func TestWriteExportParquetFixture(t *testing.T) {
+	data := writeParquetRows(t)
+	const fixture = "testdata/rows.parquet"
+	if *updateFixtures {
+		if err := os.WriteFile(fixture, data, 0o644); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+	}
+	want, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("parquet output differs from %s; re-check it with a reader and run with -update", fixture)
+	}
+}
+
+// readThriftStruct decodes a Thrift compact struct into field id -> value, enough to check the
+// Parquet metadata: integers as int64, binaries as []byte, lists as []any, structs as maps.
+func readThriftStruct(t *testing.T, r *bytes.Reader) map[int16]any {
+	t.Helper()
+	out := make(map[int16]any)
+	var last int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			t.Fatalf("truncated thrift struct: %v", err)
+		}
+		if b == 0 {
+			return out
+		}
+		typ := b & 0x0f
+		if delta := int16(b >> 4); delta != 0 {
+			last += delta
+		} else {
+			id, _ := binary.ReadUvarint(r)
+			last = int16(id>>1) ^ -int16(id&1)
+		}
+		out[last] = readThriftValue(t, r, typ)
+	}
+}
+
+func readThriftValue(t *testing.T, r *bytes.Reader, typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		v, _ := binary.ReadUvarint(r)
+		return int64(v>>1) ^ -int64(v&1)
+	case thriftBinary:
+		n, _ := binary.ReadUvarint(r)
+		b := make([]byte, n)
+		_, _ = r.Read(b)
+		return b
+	case thriftList:
+		header, _ := r.ReadByte()
+		size := uint64(header >> 4)
+		if size == 15 {
+			size, _ = binary.ReadUvarint(r)
+		}
+		items := make([]any, size)
+		for i := range items {
+			items[i] = readThriftValue(t, r, header&0x0f)
+		}
+		return items
+	case thriftStruct:
+		return readThriftStruct(t, r)
+	}
+	t.Fatalf("unexpected thrift type %d", typ)
+	return nil
+}
@@ -18,5 +18,14 @@ func (h *Handler) Agents(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	// Counter resets are detected by the collector in this process, not stored in VictoriaMetrics.
+	if h.store != nil {
+		resets := h.store.CounterResets()
+		for i := range agents {
+			if stats, ok := resets[agents[i].Name]; ok {
+				agents[i].CounterResets, agents[i].Restarts = stats.CounterResets, stats.Restarts
+			}
+		}
+	}
 	writeJSON(w, http.StatusOK, agents)
 }
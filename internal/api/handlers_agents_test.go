@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+func TestAgentsIncludesCounterResets(t *testing.T) {
+	st := store.New(nil, "")
+	st.RecordCounterResets("agent-1", 3, true)
+	st.RecordCounterResets("agent-1", 2, false)
+	h := &Handler{
+		vm:    &fakeMetricsProvider{agents: []store.AgentInfo{{Name: "agent-1", Status: "connected"}, {Name: "agent-2", Status: "connected"}}},
+		store: st,
+	}
+
+	rec := httptest.NewRecorder()
+	h.Agents(rec, httptest.NewRequest(http.MethodGet, "/api/agents", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var agents []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &agents); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(agents) != 2 {
+		t.Fatalf("expected 2 agents, got %v", agents)
+	}
+	if agents[0]["counterResets"] != float64(5) || agents[0]["restarts"] != float64(1) {
+		t.Fatalf("expected agent-1 to report 5 counter resets and 1 restart, got %v", agents[0])
+	}
+	if agents[1]["counterResets"] != float64(0) || agents[1]["restarts"] != float64(0) {
+		t.Fatalf("expected agent-2 to report no resets, got %v", agents[1])
+	}
+}
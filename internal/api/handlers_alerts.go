@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+)
+
+type alertRuleRequest struct {
+	db.AlertRule
+	// Enabled defaults to true when omitted
+	Enabled *bool `json:"enabled"`
+}
+
+type alertEntry struct {
+	Rule  db.AlertRule  `json:"rule"`
+	State db.AlertState `json:"state"`
+}
+
+// Alerts lists every rule with its current evaluation state, firing rules first.
+func (h *Handler) Alerts(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert storage unavailable")
+		return
+	}
+	rules, err := h.db.ListAlertRules()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	states, err := h.db.ListAlertStates()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	byRule := make(map[int64]db.AlertState, len(states))
+	for _, st := range states {
+		byRule[st.RuleID] = st
+	}
+
+	firing := make([]alertEntry, 0, len(rules))
+	others := make([]alertEntry, 0, len(rules))
+	for _, rule := range rules {
+		st, ok := byRule[rule.ID]
+		if !ok {
+			st = db.AlertState{RuleID: rule.ID, State: db.AlertInactive}
+		}
+		if st.State == db.AlertFiring {
+			firing = append(firing, alertEntry{Rule: rule, State: st})
+		} else {
+			others = append(others, alertEntry{Rule: rule, State: st})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items":       append(firing, others...),
+		"count":       len(rules),
+		"firingCount": len(firing),
+	})
+}
+
+// AlertRules lists stored alert rules.
+func (h *Handler) AlertRules(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert storage unavailable")
+		return
+	}
+	rules, err := h.db.ListAlertRules()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": rules,
+		"count": len(rules),
+	})
+}
+
+// AlertRuleDetail returns a single alert rule.
+func (h *Handler) AlertRuleDetail(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert storage unavailable")
+		return
+	}
+	id, ok := alertRuleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	rule, err := h.db.GetAlertRule(id)
+	if err != nil {
+		writeAlertStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// CreateAlertRule stores a new alert rule.
+func (h *Handler) CreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert storage unavailable")
+		return
+	}
+	rule, ok := decodeAlertRule(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.db.CreateAlertRule(rule)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// UpdateAlertRule replaces an alert rule and resets its state.
+func (h *Handler) UpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert storage unavailable")
+		return
+	}
+	id, ok := alertRuleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	rule, ok := decodeAlertRule(w, r)
+	if !ok {
+		return
+	}
+	rule.ID = id
+	updated, err := h.db.UpdateAlertRule(rule)
+	if err != nil {
+		writeAlertStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteAlertRule removes an alert rule.
+func (h *Handler) DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "alert storage unavailable")
+		return
+	}
+	id, ok := alertRuleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteAlertRule(id); err != nil {
+		writeAlertStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestAlertRule sends a test notification to the rule webhook.
+func (h *Handler) TestAlertRule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil || h.alerts == nil {
+		writeError(w, http.StatusServiceUnavailable, "alerting unavailable")
+		return
+	}
+	id, ok := alertRuleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	rule, err := h.db.GetAlertRule(id)
+	if err != nil {
+		writeAlertStoreError(w, err)
+		return
+	}
+	if err := h.alerts.SendTest(r.Context(), rule); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "sent"})
+}
+
+func decodeAlertRule(w http.ResponseWriter, r *http.Request) (db.AlertRule, bool) {
+	var req alertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return db.AlertRule{}, false
+	}
+	rule := req.AlertRule
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.Normalize()
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return db.AlertRule{}, false
+	}
+	return rule, true
+}
+
+func alertRuleIDFromRequest(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid alert rule id")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeAlertStoreError(w http.ResponseWriter, err error) {
+	if err == db.ErrNotFound {
+		writeError(w, http.StatusNotFound, "alert rule not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
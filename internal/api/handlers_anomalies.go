@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+// overviewAnomalyWindow is how far back the overview looks for recent anomalies.
+const overviewAnomalyWindow = 24 * time.Hour
+
+// Anomalies lists detected namespace cost anomalies, newest first.
+func (h *Handler) Anomalies(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "anomaly storage unavailable")
+		return
+	}
+	start, _, err := parseTimeRange(r, 7*24*time.Hour)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid time range")
+		return
+	}
+
+	items, err := h.db.ListAnomalies(store.AnomalyFilter{
+		ClusterID: clusterIDFromRequest(r),
+		Namespace: r.URL.Query().Get("namespace"),
+		Since:     start,
+		Limit:     parseLimit(r.URL.Query().Get("limit"), 100, 1000),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+func (h *Handler) recentAnomalies(clusterID string, limit int) []store.Anomaly {
+	if h.db == nil {
+		return nil
+	}
+	items, err := h.db.ListAnomalies(store.AnomalyFilter{
+		ClusterID: clusterID,
+		Since:     time.Now().UTC().Add(-overviewAnomalyWindow),
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil
+	}
+	return items
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
+)
+
+// Budgets lists all budgets with their month-to-date spend and status. A budget that fails to
+// evaluate is listed with status unknown and its error rather than failing the list.
+func (h *Handler) Budgets(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "budget storage unavailable")
+		return
+	}
+	budgets, err := h.db.ListBudgets()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	items := make([]finops.BudgetEvaluation, 0, len(budgets))
+	for _, b := range budgets {
+		eval, err := finops.EvaluateBudgetSpend(r.Context(), h.vm, b, now)
+		if err != nil {
+			eval = finops.FailedBudgetEvaluation(b, now, err)
+		}
+		items = append(items, eval)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+// BudgetDetail returns a single evaluated budget.
+func (h *Handler) BudgetDetail(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "budget storage unavailable")
+		return
+	}
+	id, ok := budgetIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	b, err := h.db.GetBudget(id)
+	if err != nil {
+		writeBudgetStoreError(w, err)
+		return
+	}
+	eval, err := finops.EvaluateBudgetSpend(r.Context(), h.vm, b, time.Now().UTC())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, eval)
+}
+
+// CreateBudget stores a new budget definition.
+func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "budget storage unavailable")
+		return
+	}
+	b, ok := h.decodeBudget(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.db.CreateBudget(b)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// UpdateBudget replaces an existing budget definition.
+func (h *Handler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "budget storage unavailable")
+		return
+	}
+	id, ok := budgetIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	b, ok := h.decodeBudget(w, r)
+	if !ok {
+		return
+	}
+	b.ID = id
+	updated, err := h.db.UpdateBudget(b)
+	if err != nil {
+		writeBudgetStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteBudget removes a budget.
+func (h *Handler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "budget storage unavailable")
+		return
+	}
+	id, ok := budgetIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteBudget(id); err != nil {
+		writeBudgetStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) decodeBudget(w http.ResponseWriter, r *http.Request) (db.Budget, bool) {
+	var b db.Budget
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return db.Budget{}, false
+	}
+	b.Normalize()
+	if err := b.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return db.Budget{}, false
+	}
+	if b.Scope == db.BudgetScopeLabel {
+		if err := h.vm.ValidateGroupLabel(b.LabelKey); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return db.Budget{}, false
+		}
+	}
+	return b, true
+}
+
+func budgetIDFromRequest(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid budget id")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeBudgetStoreError(w http.ResponseWriter, err error) {
+	if err == db.ErrNotFound {
+		writeError(w, http.StatusNotFound, "budget not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
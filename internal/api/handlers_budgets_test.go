@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+func newBudgetTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	r, _ := newBudgetTestRouterWithDB(t)
+	return r
+}
+
+func newBudgetTestRouterWithDB(t *testing.T) (http.Handler, *db.Store) {
+	t.Helper()
+	sqlite, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlite.Close() })
+
+	h := newTestHandler(store.ClusterMetadata{}, store.AgentStatusPayload{})
+	h.db = sqlite
+
+	r := chi.NewRouter()
+	r.Get("/api/budgets", h.Budgets)
+	r.Post("/api/budgets", h.CreateBudget)
+	r.Get("/api/budgets/{id}", h.BudgetDetail)
+	r.Delete("/api/budgets/{id}", h.DeleteBudget)
+	return r, sqlite
+}
+
+func TestBudgetHandlersCreateAndList(t *testing.T) {
+	r := newBudgetTestRouter(t)
+
+	body := `{"name":"payments","scope":"namespace","namespace":"payments","monthlyLimit":500}`
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/budgets", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/budgets", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp struct {
+		Items []finops.BudgetEvaluation `json:"items"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Items) != 1 {
+		t.Fatalf("expected 1 budget, got %d", len(resp.Items))
+	}
+	item := resp.Items[0]
+	if item.Namespace != "payments" || item.Status != finops.BudgetOK || item.MonthToDateCost != 0 {
+		t.Fatalf("unexpected budget evaluation: %+v", item)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/budgets/1", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/budgets/1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestCreateBudgetRejectsInvalidScope(t *testing.T) {
+	r := newBudgetTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/budgets", strings.NewReader(`{"name":"x","scope":"namespace","monthlyLimit":10}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	for _, key := range []string{"owner", "cluster_id", `team\"}`} {
+		body := `{"name":"x","scope":"label","labelKey":"` + key + `","labelValue":"a","monthlyLimit":10}`
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/budgets", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for labelKey %q, got %d", key, rec.Code)
+		}
+	}
+}
+
+func TestBudgetsListReportsFailedEvaluations(t *testing.T) {
+	r, sqlite := newBudgetTestRouterWithDB(t)
+
+	body := `{"name":"core","scope":"label","labelKey":"team","labelValue":"core","monthlyLimit":500}`
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/budgets", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected configured team label to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// A label that is no longer configured, e.g. after namespaceLabels changed
+	if _, err := sqlite.CreateBudget(db.Budget{Name: "stale", Scope: db.BudgetScopeLabel, LabelKey: "owner", LabelValue: "a", MonthlyLimit: 10, AlertThresholdPercent: 80}); err != nil {
+		t.Fatalf("create budget: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/budgets", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 despite one failing budget, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Items []finops.BudgetEvaluation `json:"items"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("expected 2 budgets, got %+v", resp.Items)
+	}
+	for _, item := range resp.Items {
+		switch item.Name {
+		case "core":
+			if item.Status != finops.BudgetOK || item.Error != "" {
+				t.Fatalf("unexpected team budget %+v", item)
+			}
+		case "stale":
+			if item.Status != finops.BudgetUnknown || !strings.Contains(item.Error, "invalid label key") {
+				t.Fatalf("expected the stale budget to carry its error, got %+v", item)
+			}
+		}
+	}
+}
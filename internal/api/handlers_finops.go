@@ -2,37 +2,141 @@ package api
 
 import (
 	"net/http"
-	"sort"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
 )
 
-// EfficiencyReport generates the FinOps efficiency analysis.
-func (h *Handler) EfficiencyReport(w http.ResponseWriter, r *http.Request) {
-	pods := h.store.GetAllPods()
+const (
+	defaultEfficiencyLimit = 100
+	maxEfficiencyLimit     = 1000
+)
 
-	var reports []finops.EfficiencyReport
-	ctx := r.Context()
+// EfficiencyReport generates the FinOps efficiency analysis per workload, or per namespace with
+// ?groupBy=namespace. Rows can be narrowed by clusterId, namespace and minSavings, then sorted
+// and paginated; totals cover the whole filtered set and skipped lists pods without data.
+func (h *Handler) EfficiencyReport(w http.ResponseWriter, r *http.Request) {
+	if h.finops == nil || h.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "efficiency analysis unavailable")
+		return
+	}
+	q := r.URL.Query()
+	lookback := finops.DefaultEfficiencyLookback
+	if raw := q.Get("lookback"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "lookback must be a positive duration")
+			return
+		}
+		lookback = d
+	}
+	groupBy := q.Get("groupBy")
+	if groupBy != "" && groupBy != "workload" && groupBy != "namespace" {
+		writeError(w, http.StatusBadRequest, "groupBy must be workload or namespace")
+		return
+	}
+	filter := finops.EfficiencyFilter{
+		MinSavings: parseFloat(q.Get("minSavings"), 0),
+		Sort:       q.Get("sort"),
+		Order:      q.Get("order"),
+		Limit:      parseLimit(q.Get("limit"), defaultEfficiencyLimit, maxEfficiencyLimit),
+		Offset:     parseOffset(q.Get("offset")),
+	}
+	if filter.Sort != "" && !slices.Contains(finops.EfficiencySortFields, filter.Sort) {
+		writeError(w, http.StatusBadRequest, "sort must be one of "+strings.Join(finops.EfficiencySortFields, ", "))
+		return
+	}
+	if filter.Order != "" && filter.Order != "asc" && filter.Order != "desc" {
+		writeError(w, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
 
+	clusterID := clusterIDFromRequest(r)
+	namespace := q.Get("namespace")
+	pods := h.store.GetAllPods()
+	scoped := pods[:0]
 	for _, pc := range pods {
-		report, err := h.finops.CalculatePodEfficiency(ctx, pc.Pod, pc.ClusterID, pc.Region, pc.AZ, pc.InstanceType)
-		if err != nil {
-			// Log error but continue? Or skip?
-			// For now continue and maybe return partial results?
+		if pc.Pod == nil || (clusterID != "" && pc.ClusterID != clusterID) || (namespace != "" && pc.Pod.Namespace != namespace) {
 			continue
 		}
-		if report != nil {
-			reports = append(reports, *report)
-		}
+		scoped = append(scoped, pc)
 	}
 
-	// Sort by Potential Savings Descending (highest waste first)
-	sort.Slice(reports, func(i, j int) bool {
-		return reports[i].PotentialSavingsMo > reports[j].PotentialSavingsMo
-	})
-
+	analysis, err := h.finops.AnalyzeEfficiency(r.Context(), scoped, lookback)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rows := analysis.Workloads
+	if groupBy == "namespace" {
+		rows = analysis.Namespaces
+	}
+	items, total, totals := finops.FilterEfficiency(rows, filter)
+	skipped := analysis.Skipped
+	if skipped == nil {
+		skipped = []finops.SkippedPod{}
+	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"items": reports,
-		"count": len(reports),
+		"items":        items,
+		"count":        len(items),
+		"totalCount":   total,
+		"totals":       totals,
+		"skipped":      skipped,
+		"skippedCount": len(skipped),
+		"lookback":     analysis.Lookback.String(),
 	})
 }
+
+// RightSizing recommends per-container requests and limits with a risk tier and a
+// strategic-merge patch. Percentiles and headroom can be tuned per request.
+func (h *Handler) RightSizing(w http.ResponseWriter, r *http.Request) {
+	if h.finops == nil {
+		writeError(w, http.StatusServiceUnavailable, "right-sizing unavailable")
+		return
+	}
+	q := r.URL.Query()
+	opts := finops.DefaultRightSizingOptions()
+	if raw := q.Get("lookback"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "lookback must be a duration")
+			return
+		}
+		opts.Lookback = d
+	}
+	opts.CPUPercentile = parseFloat(q.Get("cpuPercentile"), opts.CPUPercentile)
+	opts.MemoryPercentile = parseFloat(q.Get("memoryPercentile"), opts.MemoryPercentile)
+	opts.CPUHeadroomPercent = parseFloat(q.Get("cpuHeadroom"), opts.CPUHeadroomPercent)
+	opts.MemoryHeadroomPercent = parseFloat(q.Get("memoryHeadroom"), opts.MemoryHeadroomPercent)
+	if err := opts.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
+	report, err := h.finops.RightSizing(ctx, q.Get("namespace"), opts)
+	if err != nil {
+		if err == vm.ErrNoData {
+			writeError(w, http.StatusServiceUnavailable, "data not yet available")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if risk := finops.RiskTier(q.Get("risk")); risk != "" {
+		filtered := report.Items[:0]
+		report.TotalMonthlySavings = 0
+		for _, item := range report.Items {
+			if item.Risk == risk {
+				filtered = append(filtered, item)
+				report.TotalMonthlySavings += item.MonthlySavings
+			}
+		}
+		report.Items = filtered
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// CostForecast projects month-end spend for the cluster and each namespace with confidence bands.
+// Stored budgets for the cluster get their projected crossing date; ?budget= adds an ad-hoc cluster limit.
+func (h *Handler) CostForecast(w http.ResponseWriter, r *http.Request) {
+	history := finops.DefaultForecastHistory
+	if raw := r.URL.Query().Get("history"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "history must be a positive duration")
+			return
+		}
+		history = d
+	}
+
+	clusterID := clusterIDFromRequest(r)
+	ctx := vm.WithClusterID(r.Context(), clusterID)
+	report, err := finops.ForecastCosts(ctx, h.vm, time.Now().UTC(), history)
+	if err != nil {
+		if err == vm.ErrNoData {
+			writeError(w, http.StatusServiceUnavailable, "data not yet available")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if h.db != nil {
+		budgets, err := h.db.ListBudgets()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		matching := budgets[:0]
+		for _, b := range budgets {
+			if clusterID == "" || b.ClusterID == "" || b.ClusterID == clusterID {
+				matching = append(matching, b)
+			}
+		}
+		finops.ApplyBudgets(&report, matching)
+	}
+	if limit := parseFloat(r.URL.Query().Get("budget"), 0); limit > 0 {
+		finops.ApplyBudgets(&report, []db.Budget{{Name: "ad-hoc", Scope: db.BudgetScopeCluster, MonthlyLimit: limit}})
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+func TestCostForecastHandler(t *testing.T) {
+	h := newTestHandler(store.ClusterMetadata{}, store.AgentStatusPayload{})
+
+	rec := httptest.NewRecorder()
+	h.CostForecast(rec, httptest.NewRequest(http.MethodGet, "/api/cost/forecast", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without history, got %d", rec.Code)
+	}
+
+	end := time.Now().UTC().Truncate(time.Hour)
+	var points []vm.CostPoint
+	for ts := end.Add(-47 * time.Hour); !ts.After(end); ts = ts.Add(time.Hour) {
+		points = append(points, vm.CostPoint{Timestamp: ts, Cost: 2})
+	}
+	h.vm.(*fakeMetricsProvider).history = []vm.NamespaceCostSeries{{Namespace: "web", Points: points}}
+
+	rec = httptest.NewRecorder()
+	h.CostForecast(rec, httptest.NewRequest(http.MethodGet, "/api/cost/forecast?history=bad", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid history, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.CostForecast(rec, httptest.NewRequest(http.MethodGet, "/api/cost/forecast?budget=1000000", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var report finops.CostForecastReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(report.Namespaces) != 1 || report.Cluster.ProjectedMonthEndCost < report.Cluster.MonthToDateCost {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Cluster.Budgets) != 1 || report.Cluster.Budgets[0].CrossesAt != nil {
+		t.Fatalf("expected uncrossed ad-hoc budget, got %+v", report.Cluster.Budgets)
+	}
+}
@@ -13,11 +13,15 @@ import (
 )
 
 type fakeMetricsProvider struct {
-	meta   store.ClusterMetadata
-	status store.AgentStatusPayload
+	meta       store.ClusterMetadata
+	status     store.AgentStatusPayload
+	history    []vm.NamespaceCostSeries
+	chargeback *store.ChargebackReport
+	agents     []store.AgentInfo
+	edges      []store.NetworkEdge
 }
 
-func (f *fakeMetricsProvider) Overview(context.Context, int) (store.OverviewPayload, error) {
+func (f *fakeMetricsProvider) Overview(context.Context, int, store.AllocationMode) (store.OverviewPayload, error) {
 	return store.OverviewPayload{}, vm.ErrNoData
 }
 func (f *fakeMetricsProvider) NamespaceList(context.Context, store.NamespaceFilter) (store.NamespaceListResponse, error) {
@@ -26,12 +30,24 @@ func (f *fakeMetricsProvider) NamespaceList(context.Context, store.NamespaceFilt
 func (f *fakeMetricsProvider) NamespaceDetail(context.Context, string) (store.NamespaceSummary, error) {
 	return store.NamespaceSummary{}, vm.ErrNoData
 }
+func (f *fakeMetricsProvider) NamespaceBreakdown(context.Context, string) (store.NamespaceDetail, error) {
+	return store.NamespaceDetail{}, vm.ErrNoData
+}
 func (f *fakeMetricsProvider) NodeList(context.Context, store.NodeFilter) (store.NodeListResponse, error) {
 	return store.NodeListResponse{}, vm.ErrNoData
 }
 func (f *fakeMetricsProvider) NodeDetail(context.Context, string) (store.NodeSummary, error) {
 	return store.NodeSummary{}, vm.ErrNoData
 }
+func (f *fakeMetricsProvider) NodeBreakdown(context.Context, string) (store.NodeDetail, error) {
+	return store.NodeDetail{}, vm.ErrNoData
+}
+func (f *fakeMetricsProvider) PodList(context.Context, store.PodFilter) (store.PodListResponse, error) {
+	return store.PodListResponse{}, vm.ErrNoData
+}
+func (f *fakeMetricsProvider) PodDetail(context.Context, string, string) (store.PodDetail, error) {
+	return store.PodDetail{}, vm.ErrNoData
+}
 func (f *fakeMetricsProvider) Resources(context.Context) (store.ResourcesPayload, error) {
 	return store.ResourcesPayload{}, vm.ErrNoData
 }
@@ -39,7 +55,10 @@ func (f *fakeMetricsProvider) AgentStatus(context.Context) (store.AgentStatusPay
 	return f.status, nil
 }
 func (f *fakeMetricsProvider) Agents(context.Context) ([]store.AgentInfo, error) {
-	return nil, vm.ErrNoData
+	if len(f.agents) == 0 {
+		return nil, vm.ErrNoData
+	}
+	return append([]store.AgentInfo(nil), f.agents...), nil
 }
 func (f *fakeMetricsProvider) ClusterMetadata(context.Context) (store.ClusterMetadata, error) {
 	return f.meta, nil
@@ -48,6 +67,53 @@ func (f *fakeMetricsProvider) NetworkTopology(context.Context, store.NetworkTopo
 	return nil, vm.ErrNoData
 }
 
+func (f *fakeMetricsProvider) StreamNetworkEdges(_ context.Context, _ store.NetworkTopologyOptions, fn func(store.NetworkEdge) error) error {
+	if len(f.edges) == 0 {
+		return vm.ErrNoData
+	}
+	for _, edge := range f.edges {
+		if err := fn(edge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeMetricsProvider) ZoneFlows(context.Context, store.NetworkTopologyOptions) ([]vm.ZoneFlow, error) {
+	return nil, vm.ErrNoData
+}
+
+func (f *fakeMetricsProvider) CostSince(context.Context, time.Time, map[string]string) (float64, error) {
+	return 0, vm.ErrNoData
+}
+
+// ValidateGroupLabel accepts environment and team, as if team were a configured namespace label.
+func (f *fakeMetricsProvider) ValidateGroupLabel(key string) error {
+	if key == "environment" || key == "team" {
+		return nil
+	}
+	return vm.ErrInvalidLabelKey
+}
+
+func (f *fakeMetricsProvider) NamespaceCostHistory(context.Context, time.Time, time.Time, time.Duration) ([]vm.NamespaceCostSeries, error) {
+	if len(f.history) == 0 {
+		return nil, vm.ErrNoData
+	}
+	return f.history, nil
+}
+
+func (f *fakeMetricsProvider) Chargeback(_ context.Context, start, end time.Time, labelKey string) (store.ChargebackReport, error) {
+	if f.chargeback == nil {
+		return store.ChargebackReport{}, vm.ErrNoData
+	}
+	report := *f.chargeback
+	report.PeriodStart, report.PeriodEnd = start, end
+	if labelKey != "" {
+		report.GroupBy, report.LabelKey = store.ChargebackByLabel, labelKey
+	}
+	return report, nil
+}
+
 func newTestHandler(meta store.ClusterMetadata, status store.AgentStatusPayload) *Handler {
 	return &Handler{vm: &fakeMetricsProvider{meta: meta, status: status}}
 }
@@ -19,11 +19,30 @@ func (h *Handler) Namespaces(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
 
+	mode, ok := store.ParseAllocationMode(q.Get("allocation"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "allocation must be one of usage, request, max")
+		return
+	}
+
+	listQuery, err := parseListQuery(q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	exp, err := parseExport[store.NamespaceSummary](r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	filter := store.NamespaceFilter{
 		Environment: q.Get("environment"),
 		Search:      q.Get("search"),
-		Limit:       parseLimit(q.Get("limit"), defaultNamespaceLimit, maxNamespaceLimit),
+		Limit:       exp.limit(q.Get("limit"), defaultNamespaceLimit, maxNamespaceLimit),
 		Offset:      parseOffset(q.Get("offset")),
+		Allocation:  mode,
+		ListQuery:   listQuery,
 	}
 
 	resp, err := h.vm.NamespaceList(ctx, filter)
@@ -36,10 +55,14 @@ func (h *Handler) Namespaces(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if exp.export() {
+		writeExport(w, exp, "namespaces", resp.Items, resp.TotalCount)
+		return
+	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
-// NamespaceDetail returns a single namespace entry.
+// NamespaceDetail returns a single namespace entry with its top pods, nodes, egress and cost sparkline.
 func (h *Handler) NamespaceDetail(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if name == "" {
@@ -48,7 +71,7 @@ func (h *Handler) NamespaceDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
-	ns, err := h.vm.NamespaceDetail(ctx, name)
+	ns, err := h.vm.NamespaceBreakdown(ctx, name)
 	if err != nil {
 		if err == vm.ErrNoData {
 			writeError(w, http.StatusNotFound, "namespace not found")
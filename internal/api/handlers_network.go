@@ -3,30 +3,64 @@ package api
 import (
 	"errors"
 	"net/http"
+	"reflect"
+	"strconv"
 	"time"
 
+	"github.com/clustercost/clustercost-dashboard/internal/egress"
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
 	"github.com/clustercost/clustercost-dashboard/internal/store"
 	"github.com/clustercost/clustercost-dashboard/internal/vm"
 )
 
+// topologyEdgeFetchLimit caps the pod-level edges read per request, before paging or
+// collapsing to a coarser granularity. Edges come back largest first, so hitting the cap
+// drops the smallest flows; the response reports it as edgeCapReached.
+const topologyEdgeFetchLimit = 50000
+
 type NetworkTopologyResponse struct {
-	ClusterID      string              `json:"clusterId"`
-	Namespace      string              `json:"namespace,omitempty"`
-	Start          time.Time           `json:"start"`
-	End            time.Time           `json:"end"`
-	Edges          []store.NetworkEdge `json:"edges"`
-	TotalEdges     int                 `json:"totalEdges"`
-	RequestedLimit int                 `json:"requestedLimit"`
-	Timestamp      time.Time           `json:"timestamp"`
+	ClusterID      string                   `json:"clusterId"`
+	Namespace      string                   `json:"namespace,omitempty"`
+	Start          time.Time                `json:"start"`
+	End            time.Time                `json:"end"`
+	Granularity    store.NetworkGranularity `json:"granularity"`
+	Edges          []store.NetworkEdge      `json:"edges"`
+	Nodes          []store.NetworkGraphNode `json:"nodes"`
+	Links          []store.NetworkLink      `json:"links"`
+	TotalEdges     int                      `json:"totalEdges"`
+	RequestedLimit int                      `json:"requestedLimit"`
+	EdgeCapReached bool                     `json:"edgeCapReached,omitempty"`
+	Timestamp      time.Time                `json:"timestamp"`
 }
 
+// NetworkTopology returns the connection graph. ?granularity=workload|service|namespace
+// collapses pod edges into links before the min* filters and limit apply; raw edges are only
+// returned at pod granularity. limit and offset page through the edges or links. Pod edge
+// exports are streamed uncapped, see streamTopologyExport.
 func (h *Handler) NetworkTopology(w http.ResponseWriter, r *http.Request) {
 	clusterID := clusterIDFromRequest(r)
 	namespaces := parseNamespaceList(r.URL.Query()["namespace"])
-	limit := parseLimit(r.URL.Query().Get("limit"), 2000, 10000)
 	minCostUSD := parseFloat(r.URL.Query().Get("minCost"), 0)
 	minBytes := parseInt64(r.URL.Query().Get("minBytes"), 0)
 	minConnections := parseInt64(r.URL.Query().Get("minConnections"), 0)
+	granularity, err := store.ParseNetworkGranularity(r.URL.Query().Get("granularity"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Exports hold the edges at pod granularity, else the collapsed links
+	var exp exportOptions
+	if granularity == store.GranularityPod {
+		exp, err = parseExport[store.NetworkEdge](r)
+	} else {
+		exp, err = parseExport[store.NetworkLink](r)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := exp.limit(r.URL.Query().Get("limit"), 2000, 10000)
+	offset := parseOffset(r.URL.Query().Get("offset"))
 
 	start, end, err := parseTimeRange(r, 1*time.Hour)
 	if err != nil {
@@ -38,50 +72,250 @@ func (h *Handler) NetworkTopology(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	edges, err := h.vm.NetworkTopology(r.Context(), store.NetworkTopologyOptions{
+	opts := store.NetworkTopologyOptions{
 		ClusterID:      clusterID,
 		Namespaces:     namespaces,
 		Start:          start,
 		End:            end,
-		Limit:          limit,
+		Limit:          topologyEdgeFetchLimit,
 		MinCostUSD:     minCostUSD,
 		MinBytes:       minBytes,
 		MinConnections: minConnections,
-	})
-	if err != nil {
-		if errors.Is(err, vm.ErrNoData) {
-			namespace := ""
-			if len(namespaces) == 1 {
-				namespace = namespaces[0]
-			}
-			writeJSON(w, http.StatusOK, NetworkTopologyResponse{
-				ClusterID:      clusterID,
-				Namespace:      namespace,
-				Start:          start,
-				End:            end,
-				Edges:          []store.NetworkEdge{},
-				TotalEdges:     0,
-				RequestedLimit: limit,
-				Timestamp:      time.Now().UTC(),
-			})
-			return
-		}
-		writeError(w, http.StatusInternalServerError, "failed to query network topology")
+	}
+	if granularity != store.GranularityPod {
+		// Filters apply to the collapsed links instead
+		opts.MinCostUSD, opts.MinBytes, opts.MinConnections = 0, 0, 0
+	} else if exp.export() {
+		h.streamTopologyExport(w, r, exp, opts, offset)
 		return
 	}
-
 	namespace := ""
 	if len(namespaces) == 1 {
 		namespace = namespaces[0]
 	}
-	writeJSON(w, http.StatusOK, NetworkTopologyResponse{
+	resp := NetworkTopologyResponse{
 		ClusterID:      clusterID,
 		Namespace:      namespace,
 		Start:          start,
 		End:            end,
-		Edges:          edges,
-		TotalEdges:     len(edges),
+		Granularity:    granularity,
+		Edges:          []store.NetworkEdge{},
+		Nodes:          []store.NetworkGraphNode{},
+		Links:          []store.NetworkLink{},
 		RequestedLimit: limit,
 		Timestamp:      time.Now().UTC(),
+	}
+
+	edges, err := h.vm.NetworkTopology(r.Context(), opts)
+	if err != nil {
+		if errors.Is(err, vm.ErrNoData) {
+			writeTopology(w, exp, resp)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to query network topology")
+		return
+	}
+
+	h.egress.Annotate(edges)
+	resp.EdgeCapReached = len(edges) >= topologyEdgeFetchLimit
+	if granularity == store.GranularityPod {
+		resp.TotalEdges = len(edges)
+		resp.Edges = page(edges, offset, limit)
+		resp.Nodes, resp.Links = store.AggregateNetworkEdges(resp.Edges, granularity)
+		writeTopology(w, exp, resp)
+		return
+	}
+
+	nodes, links := store.AggregateNetworkEdges(edges, granularity)
+	filtered := links[:0]
+	for _, link := range links {
+		if minCostUSD > 0 && link.EgressCostUSD <= minCostUSD {
+			continue
+		}
+		if minBytes > 0 && link.BytesSent+link.BytesReceived < minBytes {
+			continue
+		}
+		if minConnections > 0 && link.ConnectionCount < minConnections {
+			continue
+		}
+		filtered = append(filtered, link)
+	}
+	resp.TotalEdges = len(filtered)
+	resp.Links = page(filtered, offset, limit)
+	resp.Nodes = linkedNodes(nodes, resp.Links)
+	writeTopology(w, exp, resp)
+}
+
+// streamTopologyExport writes the pod edges as VictoriaMetrics streams them, so the export
+// holds every matching edge unless limit is given, in label order rather than by cost. The
+// count is only known at the end and is sent as the X-Total-Count trailer. Hairpin detection
+// needs the cluster addresses seen on all edges, so with a classifier the edges are read twice.
+func (h *Handler) streamTopologyExport(w http.ResponseWriter, r *http.Request, exp exportOptions, opts store.NetworkTopologyOptions, offset int) {
+	limit := parseLimit(r.URL.Query().Get("limit"), 0, 0)
+	var clusterIPs egress.ClusterIPs
+	if h.egress != nil {
+		clusterIPs = egress.ClusterIPs{}
+		err := h.vm.StreamNetworkEdges(r.Context(), opts, func(edge store.NetworkEdge) error {
+			clusterIPs.Observe(edge)
+			return nil
+		})
+		if err != nil && !errors.Is(err, vm.ErrNoData) {
+			writeError(w, http.StatusInternalServerError, "failed to query network topology")
+			return
+		}
+	}
+
+	w.Header().Set("Trailer", "X-Total-Count")
+	out := newExportWriter(w, exp, "topology")
+	total := 0
+	err := h.vm.StreamNetworkEdges(r.Context(), opts, func(edge store.NetworkEdge) error {
+		total++
+		if total <= offset || (limit > 0 && total > offset+limit) {
+			return nil
+		}
+		h.egress.AnnotateEdge(&edge, clusterIPs)
+		return out.write(reflect.ValueOf(edge))
+	})
+	if err != nil && !errors.Is(err, vm.ErrNoData) {
+		if !out.started {
+			w.Header().Del("Trailer")
+			writeError(w, http.StatusInternalServerError, "failed to query network topology")
+			return
+		}
+		// Abort the connection so the client sees a failed download, not a short file
+		panic(http.ErrAbortHandler)
+	}
+	if err := out.close(); err != nil {
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+}
+
+// writeTopology writes the topology response, or its edges or links when exporting.
+func writeTopology(w http.ResponseWriter, exp exportOptions, resp NetworkTopologyResponse) {
+	switch {
+	case !exp.export():
+		writeJSON(w, http.StatusOK, resp)
+	case resp.Granularity == store.GranularityPod:
+		writeExport(w, exp, "topology", resp.Edges, resp.TotalEdges)
+	default:
+		writeExport(w, exp, "topology-"+string(resp.Granularity), resp.Links, resp.TotalEdges)
+	}
+}
+
+// page returns the limit items starting at offset.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// linkedNodes keeps the graph nodes that remain on at least one link.
+func linkedNodes(nodes []store.NetworkGraphNode, links []store.NetworkLink) []store.NetworkGraphNode {
+	used := make(map[string]bool, len(links)*2)
+	for _, l := range links {
+		used[l.Source] = true
+		used[l.Target] = true
+	}
+	out := make([]store.NetworkGraphNode, 0, len(used))
+	for _, n := range nodes {
+		if used[n.ID] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// NetworkCrossAZ ranks service pairs by cross-AZ cost and flags services whose clients are
+// spread across AZs while their backends run in one.
+func (h *Handler) NetworkCrossAZ(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := finops.DefaultCrossAZOptions()
+	opts.Limit = parseLimit(q.Get("limit"), opts.Limit, 1000)
+	opts.ConcentrationPercent = parseFloat(q.Get("concentration"), opts.ConcentrationPercent)
+	if opts.ConcentrationPercent <= 0 || opts.ConcentrationPercent > 100 {
+		writeError(w, http.StatusBadRequest, "concentration must be between 0 and 100")
+		return
+	}
+
+	start, end, err := parseTimeRange(r, 24*time.Hour)
+	if err != nil || !start.Before(end) {
+		writeError(w, http.StatusBadRequest, "invalid time range")
+		return
+	}
+
+	flows, err := h.vm.ZoneFlows(r.Context(), store.NetworkTopologyOptions{
+		ClusterID:  clusterIDFromRequest(r),
+		Namespaces: parseNamespaceList(q["namespace"]),
+		Start:      start,
+		End:        end,
+	})
+	if err != nil && !errors.Is(err, vm.ErrNoData) {
+		writeError(w, http.StatusInternalServerError, "failed to query cross-AZ traffic")
+		return
+	}
+	writeJSON(w, http.StatusOK, finops.AnalyzeCrossAZ(flows, start, end, opts))
+}
+
+// NetworkExternalResponse groups external traffic by destination provider and service.
+type NetworkExternalResponse struct {
+	ClusterID    string                 `json:"clusterId"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	Groups       []egress.ProviderGroup `json:"groups"`
+	EgressCost   float64                `json:"egressCostUsd"`
+	HairpinBytes int64                  `json:"hairpinBytes"`
+	Hairpins     []store.NetworkEdge    `json:"hairpins"`
+	Timestamp    time.Time              `json:"timestamp"`
+}
+
+// NetworkExternal classifies external destinations (S3, Google APIs, Auth0, ...) and lists
+// NAT hairpins, i.e. traffic leaving through a public address back into the cluster.
+func (h *Handler) NetworkExternal(w http.ResponseWriter, r *http.Request) {
+	if h.egress == nil {
+		writeError(w, http.StatusServiceUnavailable, "egress classification unavailable")
+		return
+	}
+	clusterID := clusterIDFromRequest(r)
+	start, end, err := parseTimeRange(r, 1*time.Hour)
+	if err != nil || !start.Before(end) {
+		writeError(w, http.StatusBadRequest, "invalid time range")
+		return
+	}
+
+	edges, err := h.vm.NetworkTopology(r.Context(), store.NetworkTopologyOptions{
+		ClusterID:  clusterID,
+		Namespaces: parseNamespaceList(r.URL.Query()["namespace"]),
+		Start:      start,
+		End:        end,
 	})
+	if err != nil && !errors.Is(err, vm.ErrNoData) {
+		writeError(w, http.StatusInternalServerError, "failed to query network topology")
+		return
+	}
+	h.egress.Annotate(edges)
+
+	resp := NetworkExternalResponse{
+		ClusterID: clusterID,
+		Start:     start,
+		End:       end,
+		Groups:    egress.GroupByProvider(edges),
+		Hairpins:  []store.NetworkEdge{},
+		Timestamp: time.Now().UTC(),
+	}
+	for _, g := range resp.Groups {
+		resp.EgressCost += g.EgressCost
+		resp.HairpinBytes += g.HairpinBytes
+	}
+	for _, e := range edges {
+		if e.Hairpin {
+			resp.Hairpins = append(resp.Hairpins, e)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
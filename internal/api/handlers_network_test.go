@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/clustercost/clustercost-dashboard/internal/egress"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+func TestNetworkTopologyStreamsEdgeExports(t *testing.T) {
+	edges := make([]store.NetworkEdge, 12000)
+	for i := range edges {
+		edges[i] = store.NetworkEdge{SrcNamespace: "api", DstNamespace: "db", BytesSent: int64(i)}
+	}
+	edges[0].DstIP, edges[0].DstKind = "52.0.0.1", "external"
+	classifier, err := egress.New([]egress.Rule{{Provider: "AWS", CIDRs: []string{"52.0.0.0/8"}}})
+	if err != nil {
+		t.Fatalf("classifier: %v", err)
+	}
+	h := &Handler{vm: &fakeMetricsProvider{edges: edges}, egress: classifier}
+
+	rec := httptest.NewRecorder()
+	h.NetworkTopology(rec, httptest.NewRequest(http.MethodGet, "/api/network/topology?format=csv&columns=srcNamespace,bytesSent,dstProvider", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != len(edges)+1 {
+		t.Fatalf("expected every edge beyond the old 10000 row cap, got %d lines", len(lines)-1)
+	}
+	if lines[1] != "api,0,AWS" {
+		t.Fatalf("expected streamed edges to be annotated, got %q", lines[1])
+	}
+	if total := rec.Result().Trailer.Get("X-Total-Count"); total != "12000" {
+		t.Fatalf("expected X-Total-Count trailer 12000, got %q", total)
+	}
+
+	rec = httptest.NewRecorder()
+	h.NetworkTopology(rec, httptest.NewRequest(http.MethodGet, "/api/network/topology?format=csv&columns=bytesSent&offset=10&limit=5", nil))
+	lines = strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if strings.Join(lines, ",") != "bytesSent,10,11,12,13,14" {
+		t.Fatalf("expected edges 10 to 14, got %q", lines)
+	}
+	if total := rec.Result().Trailer.Get("X-Total-Count"); total != "12000" {
+		t.Fatalf("expected the trailer to count all edges, got %q", total)
+	}
+}
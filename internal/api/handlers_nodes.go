@@ -18,10 +18,37 @@ const (
 func (h *Handler) Nodes(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
+
+	mode, ok := store.ParseAllocationMode(q.Get("allocation"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "allocation must be one of usage, request, max")
+		return
+	}
+
+	status, ok := store.ParseNodeStatus(q.Get("status"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "status must be one of ready, notready, unknown")
+		return
+	}
+
+	listQuery, err := parseListQuery(q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	exp, err := parseExport[store.NodeSummary](r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	filter := store.NodeFilter{
-		Search: q.Get("search"),
-		Limit:  parseLimit(q.Get("limit"), defaultNodeLimit, maxNodeLimit),
-		Offset: parseOffset(q.Get("offset")),
+		Search:     q.Get("search"),
+		Status:     status,
+		Limit:      exp.limit(q.Get("limit"), defaultNodeLimit, maxNodeLimit),
+		Offset:     parseOffset(q.Get("offset")),
+		Allocation: mode,
+		ListQuery:  listQuery,
 	}
 
 	resp, err := h.vm.NodeList(ctx, filter)
@@ -34,10 +61,14 @@ func (h *Handler) Nodes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if exp.export() {
+		writeExport(w, exp, "nodes", resp.Items, resp.TotalCount)
+		return
+	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
-// NodeDetail returns a single node entry.
+// NodeDetail returns a single node entry with its tenants, idle cost and pressure history.
 func (h *Handler) NodeDetail(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if name == "" {
@@ -46,7 +77,7 @@ func (h *Handler) NodeDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
-	node, err := h.vm.NodeDetail(ctx, name)
+	node, err := h.vm.NodeBreakdown(ctx, name)
 	if err != nil {
 		if err == vm.ErrNoData {
 			writeError(w, http.StatusNotFound, "node not found")
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// Consolidation simulates draining under-used nodes (first-fit decreasing on requests) and
+// reports which nodes could go, the resulting density and the monthly savings.
+// ?source=store|vm picks the input; by default live agent reports are used when present.
+func (h *Handler) Consolidation(w http.ResponseWriter, r *http.Request) {
+	if h.finops == nil {
+		writeError(w, http.StatusServiceUnavailable, "consolidation unavailable")
+		return
+	}
+	q := r.URL.Query()
+	opts := finops.DefaultConsolidationOptions()
+	opts.MaxUtilizationPercent = parseFloat(q.Get("maxUtilization"), opts.MaxUtilizationPercent)
+	if opts.MaxUtilizationPercent <= 0 || opts.MaxUtilizationPercent > 100 {
+		writeError(w, http.StatusBadRequest, "maxUtilization must be between 0 and 100")
+		return
+	}
+	if raw := q.Get("minNodes"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "minNodes must be a positive integer")
+			return
+		}
+		opts.MinNodes = n
+	}
+
+	source := q.Get("source")
+	if source != "" && source != "store" && source != "vm" {
+		writeError(w, http.StatusBadRequest, "source must be store or vm")
+		return
+	}
+	var (
+		nodes []finops.ConsolidationNode
+		err   error
+	)
+	if source != "vm" && h.store != nil {
+		nodes, err = h.finops.ConsolidationNodesFromStore(r.Context(), h.store, clusterIDFromRequest(r))
+		if err == nil {
+			source = "store"
+		} else if source == "store" || err != store.ErrNoData {
+			writeOptimizeError(w, err)
+			return
+		}
+	}
+	if source != "store" {
+		ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
+		nodes, err = h.finops.ConsolidationNodesFromVM(ctx)
+		if err != nil {
+			writeOptimizeError(w, err)
+			return
+		}
+		source = "vm"
+	}
+
+	plan := finops.SimulateConsolidation(nodes, opts)
+	plan.Source = source
+	writeJSON(w, http.StatusOK, plan)
+}
+
+func writeOptimizeError(w http.ResponseWriter, err error) {
+	if err == vm.ErrNoData || err == store.ErrNoData {
+		writeError(w, http.StatusServiceUnavailable, "data not yet available")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// InstanceTypes proposes cheaper instance types for each node group from the regional price table.
+func (h *Handler) InstanceTypes(w http.ResponseWriter, r *http.Request) {
+	if h.finops == nil {
+		writeError(w, http.StatusServiceUnavailable, "instance type recommendations unavailable")
+		return
+	}
+	q := r.URL.Query()
+	opts := finops.DefaultInstanceTypeOptions()
+	if raw := q.Get("lookback"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "lookback must be a duration")
+			return
+		}
+		opts.Lookback = d
+	}
+	opts.UsagePercentile = parseFloat(q.Get("percentile"), opts.UsagePercentile)
+	opts.HeadroomPercent = parseFloat(q.Get("headroom"), opts.HeadroomPercent)
+	opts.MaxUtilizationPercent = parseFloat(q.Get("maxUtilization"), opts.MaxUtilizationPercent)
+	opts.MinRatioFit = parseFloat(q.Get("minRatioFit"), opts.MinRatioFit)
+	if raw := q.Get("minNodes"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "minNodes must be a positive integer")
+			return
+		}
+		opts.MinNodes = n
+	}
+	if raw := q.Get("allowArchitectureChange"); raw != "" {
+		allow, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "allowArchitectureChange must be a boolean")
+			return
+		}
+		opts.AllowArchitectureChange = allow
+	}
+	if err := opts.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
+	report, err := h.finops.RecommendInstanceTypes(ctx, q.Get("region"), opts)
+	if err != nil {
+		writeOptimizeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -3,15 +3,21 @@ package api
 import (
 	"net/http"
 
+	"github.com/clustercost/clustercost-dashboard/internal/store"
 	"github.com/clustercost/clustercost-dashboard/internal/vm"
 )
 
 // Overview serves the aggregated overview payload.
 func (h *Handler) Overview(w http.ResponseWriter, r *http.Request) {
 	limit := parseLimit(r.URL.Query().Get("limitTopNamespaces"), 5, 20)
+	mode, ok := store.ParseAllocationMode(r.URL.Query().Get("allocation"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "allocation must be one of usage, request, max")
+		return
+	}
 
 	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
-	overview, err := h.vm.Overview(ctx, limit)
+	overview, err := h.vm.Overview(ctx, limit, mode)
 	if err != nil {
 		if err == vm.ErrNoData {
 			writeError(w, http.StatusServiceUnavailable, "data not yet available")
@@ -20,5 +26,8 @@ func (h *Handler) Overview(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if recent := h.recentAnomalies(clusterIDFromRequest(r), 5); len(recent) > 0 {
+		overview.Anomalies = recent
+	}
 	writeJSON(w, http.StatusOK, overview)
 }
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+const (
+	defaultPodLimit = 100
+	maxPodLimit     = 1000
+)
+
+// Pods returns the running pods with their cost, filtered by namespace, node, search and the list query.
+func (h *Handler) Pods(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
+
+	listQuery, err := parseListQuery(q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	exp, err := parseExport[store.PodSummary](r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := store.PodFilter{
+		Namespace: q.Get("namespace"),
+		Node:      q.Get("node"),
+		Search:    q.Get("search"),
+		Limit:     exp.limit(q.Get("limit"), defaultPodLimit, maxPodLimit),
+		Offset:    parseOffset(q.Get("offset")),
+		ListQuery: listQuery,
+	}
+
+	resp, err := h.vm.PodList(ctx, filter)
+	if err != nil {
+		if err == vm.ErrNoData {
+			writeError(w, http.StatusServiceUnavailable, "data not yet available")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if exp.export() {
+		writeExport(w, exp, "pods", resp.Items, resp.TotalCount)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PodDetail returns requests vs usage, the cost breakdown and network egress for a pod.
+func (h *Handler) PodDetail(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	if namespace == "" || name == "" {
+		writeError(w, http.StatusBadRequest, "namespace and pod name are required")
+		return
+	}
+
+	ctx := vm.WithClusterID(r.Context(), clusterIDFromRequest(r))
+	pod, err := h.vm.PodDetail(ctx, namespace, name)
+	if err != nil {
+		if err == vm.ErrNoData {
+			writeError(w, http.StatusNotFound, "pod not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pod)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/reports"
+)
+
+type reportScheduleRequest struct {
+	db.ReportSchedule
+	// Enabled defaults to true when omitted
+	Enabled *bool `json:"enabled"`
+}
+
+// ReportSchedules lists stored report schedules.
+func (h *Handler) ReportSchedules(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	schedules, err := h.db.ListReportSchedules()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": schedules,
+		"count": len(schedules),
+	})
+}
+
+// ReportScheduleDetail returns a single report schedule.
+func (h *Handler) ReportScheduleDetail(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	id, ok := reportScheduleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	schedule, err := h.db.GetReportSchedule(id)
+	if err != nil {
+		writeReportScheduleStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// CreateReportSchedule stores a new report schedule.
+func (h *Handler) CreateReportSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	schedule, ok := decodeReportSchedule(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.db.CreateReportSchedule(schedule)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// UpdateReportSchedule replaces a report schedule and recomputes its next run.
+func (h *Handler) UpdateReportSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	id, ok := reportScheduleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	schedule, ok := decodeReportSchedule(w, r)
+	if !ok {
+		return
+	}
+	schedule.ID = id
+	updated, err := h.db.UpdateReportSchedule(schedule)
+	if err != nil {
+		writeReportScheduleStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteReportSchedule removes a report schedule and its run history.
+func (h *Handler) DeleteReportSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	id, ok := reportScheduleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteReportSchedule(id); err != nil {
+		writeReportScheduleStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunReportSchedule delivers a report immediately without moving the schedule.
+func (h *Handler) RunReportSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil || h.reports == nil {
+		writeError(w, http.StatusServiceUnavailable, "report scheduling unavailable")
+		return
+	}
+	id, ok := reportScheduleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	schedule, err := h.db.GetReportSchedule(id)
+	if err != nil {
+		writeReportScheduleStoreError(w, err)
+		return
+	}
+	run, err := h.reports.RunSchedule(r.Context(), schedule, reports.TriggerManual)
+	if err != nil && run.ID == 0 {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	status := http.StatusOK
+	if run.Status == db.ReportRunFailed {
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, run)
+}
+
+// ReportScheduleRuns lists the delivery history of a schedule, newest first.
+func (h *Handler) ReportScheduleRuns(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	id, ok := reportScheduleIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if _, err := h.db.GetReportSchedule(id); err != nil {
+		writeReportScheduleStoreError(w, err)
+		return
+	}
+	runs, err := h.db.ListReportRuns(id, parseLimit(r.URL.Query().Get("limit"), 50, 500))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": runs,
+		"count": len(runs),
+	})
+}
+
+func decodeReportSchedule(w http.ResponseWriter, r *http.Request) (db.ReportSchedule, bool) {
+	var req reportScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return db.ReportSchedule{}, false
+	}
+	schedule := req.ReportSchedule
+	schedule.Enabled = req.Enabled == nil || *req.Enabled
+	schedule.Normalize()
+	if err := schedule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return db.ReportSchedule{}, false
+	}
+	return schedule, true
+}
+
+func reportScheduleIDFromRequest(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid report schedule id")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeReportScheduleStoreError(w http.ResponseWriter, err error) {
+	if err == db.ErrNotFound {
+		writeError(w, http.StatusNotFound, "report schedule not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// ChargebackReport returns the chargeback report for a closed period, generating and storing it
+// on first request. Stored reports are returned as-is so past invoices do not drift.
+// Query: period=YYYY-MM (default: last month) or start/end, groupBy=namespace|label, labelKey (environment
+// or a configured namespace label), format=json|csv.
+func (h *Handler) ChargebackReport(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	q := r.URL.Query()
+	format, ok := parseReportFormat(q.Get("format"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "format must be one of json, csv")
+		return
+	}
+	start, end, err := parseReportPeriod(r, time.Now().UTC())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	groupBy := q.Get("groupBy")
+	labelKey := q.Get("labelKey")
+	switch groupBy {
+	case "", store.ChargebackByNamespace:
+		groupBy, labelKey = store.ChargebackByNamespace, ""
+	case store.ChargebackByLabel:
+		if labelKey == "" {
+			writeError(w, http.StatusBadRequest, "labelKey is required when grouping by label")
+			return
+		}
+		if err := h.vm.ValidateGroupLabel(labelKey); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "groupBy must be one of namespace, label")
+		return
+	}
+
+	// Without a cluster, reports are generated for the latest reporting cluster and stored
+	// under its ID; resolve it the same way so the stored report is found again.
+	clusterID := clusterIDFromRequest(r)
+	if clusterID == "" {
+		if meta, err := h.vm.ClusterMetadata(r.Context()); err == nil {
+			clusterID = meta.ID
+		}
+	}
+	if stored, err := h.db.FindChargebackReport(clusterID, groupBy, labelKey, start, end); err == nil {
+		writeChargebackReport(w, stored, format)
+		return
+	} else if err != db.ErrNotFound {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	report, err := h.vm.Chargeback(vm.WithClusterID(r.Context(), clusterID), start, end, labelKey)
+	if err != nil {
+		switch {
+		case err == vm.ErrNoData:
+			writeError(w, http.StatusServiceUnavailable, "no cost data for period")
+		case errors.Is(err, vm.ErrInvalidLabelKey):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	stored, err := h.db.SaveChargebackReport(report)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeChargebackReport(w, stored, format)
+}
+
+// ChargebackReports lists stored chargeback reports without their lines.
+func (h *Handler) ChargebackReports(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	items, err := h.db.ListChargebackReports()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+// ChargebackReportDetail downloads a stored chargeback report by ID.
+func (h *Handler) ChargebackReportDetail(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "report storage unavailable")
+		return
+	}
+	format, ok := parseReportFormat(r.URL.Query().Get("format"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "format must be one of json, csv")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid report id")
+		return
+	}
+	report, err := h.db.GetChargebackReport(id)
+	if err != nil {
+		if err == db.ErrNotFound {
+			writeError(w, http.StatusNotFound, "report not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeChargebackReport(w, report, format)
+}
+
+func parseReportFormat(raw string) (string, bool) {
+	switch raw {
+	case "", "json":
+		return "json", true
+	case "csv":
+		return "csv", true
+	default:
+		return "", false
+	}
+}
+
+// parseReportPeriod resolves period=YYYY-MM or start/end into a closed UTC period.
+// Without either, the previous calendar month is used.
+func parseReportPeriod(r *http.Request, now time.Time) (time.Time, time.Time, error) {
+	q := r.URL.Query()
+	var start, end time.Time
+	switch {
+	case q.Get("period") != "":
+		month, err := time.Parse("2006-01", q.Get("period"))
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("period must be formatted as YYYY-MM")
+		}
+		start, end = finops.MonthBounds(month)
+	case q.Get("start") != "" || q.Get("end") != "":
+		var err error
+		if start, err = parseTimestamp(q.Get("start")); err != nil || start.IsZero() {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start")
+		}
+		if end, err = parseTimestamp(q.Get("end")); err != nil || end.IsZero() {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end")
+		}
+	default:
+		current, _ := finops.MonthBounds(now)
+		start, end = finops.MonthBounds(current.AddDate(0, -1, 0))
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must be after start")
+	}
+	if end.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("period has not closed yet")
+	}
+	return start, end, nil
+}
+
+func writeChargebackReport(w http.ResponseWriter, report store.ChargebackReport, format string) {
+	if format != "csv" {
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+	filename := fmt.Sprintf("chargeback-%s-%s.csv", report.PeriodStart.Format("2006-01-02"), report.PeriodEnd.Format("2006-01-02"))
+	if report.ClusterID != "" {
+		filename = fmt.Sprintf("chargeback-%s-%s-%s.csv", report.ClusterID, report.PeriodStart.Format("2006-01-02"), report.PeriodEnd.Format("2006-01-02"))
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	group := report.GroupBy
+	if report.LabelKey != "" {
+		group = report.LabelKey
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{group, "cpu_core_hours", "memory_gb_hours", "egress_public_gb", "egress_cross_az_gb",
+		"compute_cost", "memory_cost", "gpu_cost", "storage_cost", "network_egress_cost", "idle_share_cost", "total_cost"})
+	var total store.ChargebackLine
+	for _, l := range report.Lines {
+		_ = cw.Write(chargebackCSVRow(l))
+		total.CPUCoreHours += l.CPUCoreHours
+		total.MemoryGBHours += l.MemoryGBHours
+		total.EgressPublicGB += l.EgressPublicGB
+		total.EgressCrossAZGB += l.EgressCrossAZGB
+		total.ComputeCost += l.ComputeCost
+		total.MemoryCost += l.MemoryCost
+		total.GPUCost += l.GPUCost
+		total.StorageCost += l.StorageCost
+		total.NetworkEgressCost += l.NetworkEgressCost
+		total.IdleShareCost += l.IdleShareCost
+		total.TotalCost += l.TotalCost
+	}
+	total.Group = "TOTAL"
+	_ = cw.Write(chargebackCSVRow(total))
+	cw.Flush()
+}
+
+func chargebackCSVRow(l store.ChargebackLine) []string {
+	quantity := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	return []string{
+		l.Group,
+		quantity(l.CPUCoreHours),
+		quantity(l.MemoryGBHours),
+		quantity(l.EgressPublicGB),
+		quantity(l.EgressCrossAZGB),
+		money(l.ComputeCost),
+		money(l.MemoryCost),
+		money(l.GPUCost),
+		money(l.StorageCost),
+		money(l.NetworkEgressCost),
+		money(l.IdleShareCost),
+		money(l.TotalCost),
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+func newReportTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	sqlite, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlite.Close() })
+
+	h := newTestHandler(store.ClusterMetadata{}, store.AgentStatusPayload{})
+	h.db = sqlite
+	h.vm.(*fakeMetricsProvider).chargeback = &store.ChargebackReport{
+		ClusterID: "c1",
+		GroupBy:   store.ChargebackByNamespace,
+		TotalCost: 30,
+		Lines: []store.ChargebackLine{
+			{Group: "web", ComputeCost: 10, MemoryCost: 5, IdleShareCost: 5, TotalCost: 20},
+			{Group: "batch", ComputeCost: 5, MemoryCost: 5, TotalCost: 10},
+		},
+	}
+	return h
+}
+
+func TestChargebackReportPersistsAndDownloadsCSV(t *testing.T) {
+	h := newReportTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?period=2024-05&clusterId=c1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var report store.ChargebackReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.ID == 0 || !report.PeriodStart.Equal(time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// Later pricing changes must not alter the stored invoice
+	h.vm.(*fakeMetricsProvider).chargeback.TotalCost = 999
+	rec = httptest.NewRecorder()
+	h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?period=2024-05&clusterId=c1&format=csv", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("expected csv, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 4 || rows[0][0] != "namespace" || rows[1][0] != "web" || rows[3][0] != "TOTAL" || rows[3][11] != "30.00" {
+		t.Fatalf("unexpected csv: %v", rows)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ChargebackReports(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback/history", nil))
+	var list struct {
+		Count int `json:"count"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || list.Count != 1 {
+		t.Fatalf("expected one stored report, got %d (%v)", list.Count, err)
+	}
+}
+
+func TestChargebackReportRejectsOpenPeriod(t *testing.T) {
+	h := newReportTestHandler(t)
+	period := time.Now().UTC().Format("2006-01")
+
+	rec := httptest.NewRecorder()
+	h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?period="+period, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for current month, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?groupBy=label", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without labelKey, got %d", rec.Code)
+	}
+}
+
+func TestChargebackReportReusesStoredReportWithoutCluster(t *testing.T) {
+	h := newReportTestHandler(t)
+	h.vm.(*fakeMetricsProvider).meta = store.ClusterMetadata{ID: "c1"}
+
+	rec := httptest.NewRecorder()
+	h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?period=2024-05", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	h.vm.(*fakeMetricsProvider).chargeback.TotalCost = 999
+	rec = httptest.NewRecorder()
+	h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?period=2024-05", nil))
+	var report store.ChargebackReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.TotalCost != 30 {
+		t.Fatalf("expected the stored report, got total %v", report.TotalCost)
+	}
+}
+
+func TestChargebackReportRejectsUningestedLabel(t *testing.T) {
+	h := newReportTestHandler(t)
+	for _, key := range []string{"owner", "cluster_id", "bad-key"} {
+		rec := httptest.NewRecorder()
+		h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?period=2024-05&groupBy=label&labelKey="+key, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for labelKey %q, got %d", key, rec.Code)
+		}
+	}
+	// team stands in for a label configured through namespaceLabels
+	for _, key := range []string{"environment", "team"} {
+		rec := httptest.NewRecorder()
+		h.ChargebackReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/chargeback?period=2024-05&groupBy=label&labelKey="+key, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d: %s", key, rec.Code, rec.Body.String())
+		}
+	}
+}
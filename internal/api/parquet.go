@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Parquet constants from parquet.thrift, limited to what encodeParquet writes.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+	parquetDataPage      = 0
+	parquetUncompressed  = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// parquetWriter streams a Parquet file: every writeRowGroup call writes one row group with
+// one uncompressed, PLAIN encoded data page per column, and close writes the footer, so only
+// the rows of the current group are held in memory. Times are optional UTC milliseconds, null
+// where CSV leaves the cell empty; every other column is required.
+type parquetWriter struct {
+	w       io.Writer
+	columns []exportColumn
+	offset  int64
+	rows    int64
+	groups  []parquetRowGroup
+}
+
+type parquetRowGroup struct {
+	rows   int64
+	chunks []parquetChunk
+}
+
+type parquetChunk struct {
+	offset, size int64
+}
+
+// newParquetWriter writes the leading magic bytes to w.
+func newParquetWriter(w io.Writer, columns []exportColumn) (*parquetWriter, error) {
+	p := &parquetWriter{w: w, columns: columns}
+	return p, p.write(parquetMagic)
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// writeRowGroup writes rows rows; values holds the values of each column in order.
+func (p *parquetWriter) writeRowGroup(values [][]any, rows int) error {
+	group := parquetRowGroup{rows: int64(rows), chunks: make([]parquetChunk, len(p.columns))}
+	for i, col := range p.columns {
+		data := encodeColumn(col.kind, values[i])
+
+		var header bytes.Buffer
+		t := newThriftWriter(&header)
+		t.i32(1, parquetDataPage)
+		t.i32(2, int32(len(data)))
+		t.i32(3, int32(len(data)))
+		t.beginStruct(5)
+		t.i32(1, int32(rows))
+		t.i32(2, parquetEncodingPlain)
+		t.i32(3, parquetEncodingRLE)
+		t.i32(4, parquetEncodingRLE)
+		t.endStruct()
+		t.stop()
+
+		group.chunks[i] = parquetChunk{offset: p.offset, size: int64(header.Len() + len(data))}
+		if err := p.write(header.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+	}
+	p.groups = append(p.groups, group)
+	p.rows += int64(rows)
+	return nil
+}
+
+// close writes the footer. A file without rows still gets one empty row group.
+func (p *parquetWriter) close() error {
+	if len(p.groups) == 0 {
+		if err := p.writeRowGroup(make([][]any, len(p.columns)), 0); err != nil {
+			return err
+		}
+	}
+
+	var footer bytes.Buffer
+	t := newThriftWriter(&footer)
+	t.i32(1, 1)
+	t.listBegin(2, thriftStruct, len(p.columns)+1)
+	t.elemBegin()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(p.columns)))
+	t.elemEnd()
+	for _, col := range p.columns {
+		physical, converted := parquetTypes(col.kind)
+		repetition := int32(parquetRequired)
+		if col.kind == columnTime {
+			repetition = parquetOptional
+		}
+		t.elemBegin()
+		t.i32(1, physical)
+		t.i32(3, repetition)
+		t.binary(4, col.name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.elemEnd()
+	}
+	t.i64(3, p.rows)
+
+	t.listBegin(4, thriftStruct, len(p.groups))
+	for _, group := range p.groups {
+		var total int64
+		for _, c := range group.chunks {
+			total += c.size
+		}
+		t.elemBegin()
+		t.listBegin(1, thriftStruct, len(p.columns))
+		for i, col := range p.columns {
+			physical, _ := parquetTypes(col.kind)
+			chunk := group.chunks[i]
+			t.elemBegin()
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, physical)
+			if col.kind == columnTime {
+				t.listBegin(2, thriftI32, 2)
+				t.listI32(parquetEncodingPlain)
+				t.listI32(parquetEncodingRLE)
+			} else {
+				t.listBegin(2, thriftI32, 1)
+				t.listI32(parquetEncodingPlain)
+			}
+			t.listBegin(3, thriftBinary, 1)
+			t.listBinary(col.name)
+			t.i32(4, parquetUncompressed)
+			t.i64(5, group.rows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.elemEnd()
+		}
+		t.i64(2, total)
+		t.i64(3, group.rows)
+		t.elemEnd()
+	}
+	t.binary(6, "clustercost-dashboard")
+	t.stop()
+
+	if err := p.write(footer.Bytes()); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(footer.Len()))
+	if err := p.write(length[:]); err != nil {
+		return err
+	}
+	return p.write(parquetMagic)
+}
+
+// parquetTypes maps a column kind to its physical and converted type; -1 means none.
+func parquetTypes(kind columnKind) (physical, converted int32) {
+	switch kind {
+	case columnInt:
+		return parquetInt64, -1
+	case columnFloat:
+		return parquetDouble, -1
+	case columnBool:
+		return parquetBoolean, -1
+	case columnTime:
+		return parquetInt64, parquetConvertedTimestampMillis
+	default:
+		return parquetByteArray, parquetConvertedUTF8
+	}
+}
+
+// encodeColumn returns the page data of a column. Time columns start with their definition
+// levels, 0 for a zero time and 1 otherwise, and hold only the times that are set.
+func encodeColumn(kind columnKind, values []any) []byte {
+	if kind != columnTime {
+		return encodePlain(kind, values)
+	}
+	levels := make([]byte, len(values))
+	set := make([]any, 0, len(values))
+	for i, v := range values {
+		if !v.(time.Time).IsZero() {
+			levels[i] = 1
+			set = append(set, v)
+		}
+	}
+	runs := encodeLevelRuns(levels)
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(runs)))
+	buf.Write(runs)
+	buf.Write(encodePlain(kind, set))
+	return buf.Bytes()
+}
+
+// encodeLevelRuns writes 1-bit levels in the RLE/bit-packed hybrid encoding as RLE runs only:
+// a varint of the run length shifted left by one, then the level in one byte.
+func encodeLevelRuns(levels []byte) []byte {
+	var buf bytes.Buffer
+	var scratch [binary.MaxVarintLen64]byte
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		n := binary.PutUvarint(scratch[:], uint64(end-start)<<1)
+		buf.Write(scratch[:n])
+		buf.WriteByte(levels[start])
+		start = end
+	}
+	return buf.Bytes()
+}
+
+func encodePlain(kind columnKind, values []any) []byte {
+	var buf bytes.Buffer
+	var scratch [8]byte
+	switch kind {
+	case columnBool:
+		packed := make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v.(bool) {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf.Write(packed)
+	case columnInt, columnTime, columnFloat:
+		for _, v := range values {
+			var bits uint64
+			switch x := v.(type) {
+			case int64:
+				bits = uint64(x)
+			case float64:
+				bits = math.Float64bits(x)
+			case time.Time:
+				bits = uint64(x.UnixMilli())
+			}
+			binary.LittleEndian.PutUint64(scratch[:], bits)
+			buf.Write(scratch[:])
+		}
+	default:
+		for _, v := range values {
+			s := v.(string)
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s)))
+			buf.Write(scratch[:4])
+			buf.WriteString(s)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Thrift compact protocol types used by the Parquet metadata.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes Thrift compact protocol structs. It starts inside the top-level struct.
+type thriftWriter struct {
+	buf  *bytes.Buffer
+	last []int16
+}
+
+func newThriftWriter(buf *bytes.Buffer) *thriftWriter {
+	return &thriftWriter{buf: buf, last: []int16{0}}
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(uint64((id << 1) ^ (id >> 15)))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], v)
+	t.buf.Write(scratch[:n])
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.listBinary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.elemBegin()
+}
+
+func (t *thriftWriter) endStruct() {
+	t.elemEnd()
+}
+
+// stop ends the top-level struct.
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+func (t *thriftWriter) listBegin(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elem)
+	t.varint(uint64(size))
+}
+
+// elemBegin and elemEnd wrap a struct element of a list.
+func (t *thriftWriter) elemBegin() {
+	t.last = append(t.last, 0)
+}
+
+func (t *thriftWriter) elemEnd() {
+	t.buf.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) listBinary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
@@ -1,10 +1,14 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
 )
 
 func parseLimit(raw string, fallback, max int) int {
@@ -132,3 +136,66 @@ func parseTimestamp(raw string) (time.Time, error) {
 	}
 	return parsed.UTC(), nil
 }
+
+// rangeParams names the min/max query parameters of each sortable list field, e.g. minCost.
+var rangeParams = map[string]string{
+	store.SortCost:          "Cost",
+	store.SortCPUEfficiency: "Efficiency",
+	store.SortMemoryUsage:   "Memory",
+	store.SortEgress:        "Egress",
+	store.SortPodCount:      "Pods",
+}
+
+// parseListQuery reads sort, order, the min/max range filters and the label selector.
+func parseListQuery(q url.Values) (store.ListQuery, error) {
+	var lq store.ListQuery
+	field, ok := store.ParseSortField(q.Get("sort"))
+	if !ok {
+		return lq, fmt.Errorf("sort must be one of %s, %s", strings.Join(store.SortFields, ", "), store.SortName)
+	}
+	lq.Sort = field
+
+	switch strings.ToLower(q.Get("order")) {
+	case "":
+		lq.Ascending = field == store.SortName
+	case "asc":
+		lq.Ascending = true
+	case "desc":
+	default:
+		return lq, fmt.Errorf("order must be asc or desc")
+	}
+
+	for _, field := range store.SortFields {
+		var r store.Range
+		for _, bound := range []struct {
+			param string
+			dst   **float64
+		}{
+			{"min" + rangeParams[field], &r.Min},
+			{"max" + rangeParams[field], &r.Max},
+		} {
+			raw := q.Get(bound.param)
+			if raw == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return lq, fmt.Errorf("%s must be a number", bound.param)
+			}
+			*bound.dst = &v
+		}
+		if r.Min != nil || r.Max != nil {
+			if lq.Ranges == nil {
+				lq.Ranges = make(map[string]store.Range)
+			}
+			lq.Ranges[field] = r
+		}
+	}
+
+	selector, err := store.ParseLabelSelector(q.Get("selector"))
+	if err != nil {
+		return lq, err
+	}
+	lq.Selector = selector
+	return lq, nil
+}
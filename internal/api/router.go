@@ -4,47 +4,68 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
+	"github.com/clustercost/clustercost-dashboard/internal/alerts"
 	"github.com/clustercost/clustercost-dashboard/internal/auth"
 	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/egress"
 	"github.com/clustercost/clustercost-dashboard/internal/finops"
+	"github.com/clustercost/clustercost-dashboard/internal/reports"
 	"github.com/clustercost/clustercost-dashboard/internal/static"
 	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
 )
 
 // MetricsProvider defines the data backend used by API handlers.
 type MetricsProvider interface {
-	Overview(ctx context.Context, limit int) (store.OverviewPayload, error)
+	Overview(ctx context.Context, limit int, mode store.AllocationMode) (store.OverviewPayload, error)
 	NamespaceList(ctx context.Context, filter store.NamespaceFilter) (store.NamespaceListResponse, error)
 	NamespaceDetail(ctx context.Context, name string) (store.NamespaceSummary, error)
+	NamespaceBreakdown(ctx context.Context, name string) (store.NamespaceDetail, error)
 	NodeList(ctx context.Context, filter store.NodeFilter) (store.NodeListResponse, error)
 	NodeDetail(ctx context.Context, name string) (store.NodeSummary, error)
+	NodeBreakdown(ctx context.Context, name string) (store.NodeDetail, error)
+	PodList(ctx context.Context, filter store.PodFilter) (store.PodListResponse, error)
+	PodDetail(ctx context.Context, namespace, name string) (store.PodDetail, error)
 	Resources(ctx context.Context) (store.ResourcesPayload, error)
 	AgentStatus(ctx context.Context) (store.AgentStatusPayload, error)
 	Agents(ctx context.Context) ([]store.AgentInfo, error)
 	ClusterMetadata(ctx context.Context) (store.ClusterMetadata, error)
 	NetworkTopology(ctx context.Context, opts store.NetworkTopologyOptions) ([]store.NetworkEdge, error)
+	StreamNetworkEdges(ctx context.Context, opts store.NetworkTopologyOptions, fn func(store.NetworkEdge) error) error
+	ZoneFlows(ctx context.Context, opts store.NetworkTopologyOptions) ([]vm.ZoneFlow, error)
+	CostSince(ctx context.Context, since time.Time, scope map[string]string) (float64, error)
+	NamespaceCostHistory(ctx context.Context, start, end time.Time, step time.Duration) ([]vm.NamespaceCostSeries, error)
+	Chargeback(ctx context.Context, start, end time.Time, labelKey string) (store.ChargebackReport, error)
+	ValidateGroupLabel(key string) error
 }
 
 // Handler wires HTTP requests to the VictoriaMetrics client.
 type Handler struct {
-	vm     MetricsProvider
-	db     *db.Store
-	store  *store.Store
-	finops *finops.Engine
+	vm      MetricsProvider
+	db      *db.Store
+	store   *store.Store
+	finops  *finops.Engine
+	alerts  *alerts.Engine
+	reports *reports.Scheduler
+	egress  *egress.Classifier
 }
 
 // NewRouter builds the HTTP router serving both JSON APIs and static assets.
-func NewRouter(vmClient MetricsProvider, db *db.Store, st *store.Store, finopsEngine *finops.Engine) http.Handler {
+func NewRouter(vmClient MetricsProvider, db *db.Store, st *store.Store, finopsEngine *finops.Engine, alertEngine *alerts.Engine, reportScheduler *reports.Scheduler, egressClassifier *egress.Classifier) http.Handler {
 	h := &Handler{
-		vm:     vmClient,
-		db:     db,
-		store:  st,
-		finops: finopsEngine,
+		vm:      vmClient,
+		db:      db,
+		store:   st,
+		finops:  finopsEngine,
+		alerts:  alertEngine,
+		reports: reportScheduler,
+		egress:  egressClassifier,
 	}
 
 	r := chi.NewRouter()
@@ -53,8 +74,9 @@ func NewRouter(vmClient MetricsProvider, db *db.Store, st *store.Store, finopsEn
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodOptions}, // Added POST for Login
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}, // POST/PUT/DELETE for login, budgets, alerts and report schedules
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Requested-With"},
+		ExposedHeaders:   []string{"X-Total-Count"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -73,17 +95,61 @@ func NewRouter(vmClient MetricsProvider, db *db.Store, st *store.Store, finopsEn
 				cost.Get("/namespaces/{name}", h.NamespaceDetail)
 				cost.Get("/nodes", h.Nodes)
 				cost.Get("/nodes/{name}", h.NodeDetail)
+				cost.Get("/pods", h.Pods)
+				cost.Get("/pods/{namespace}/{name}", h.PodDetail)
 				cost.Get("/resources", h.Resources)
+				cost.Get("/forecast", h.CostForecast)
 			})
 			protected.Get("/agent", h.AgentStatus)
 			protected.Get("/agents", h.Agents)
 
 			protected.Route("/finops", func(finops chi.Router) {
 				finops.Get("/efficiency", h.EfficiencyReport)
+				finops.Get("/rightsizing", h.RightSizing)
+			})
+
+			protected.Route("/optimize", func(optimize chi.Router) {
+				optimize.Get("/consolidation", h.Consolidation)
+				optimize.Get("/instance-types", h.InstanceTypes)
 			})
 
 			protected.Route("/network", func(network chi.Router) {
 				network.Get("/topology", h.NetworkTopology)
+				network.Get("/cross-az", h.NetworkCrossAZ)
+				network.Get("/external", h.NetworkExternal)
+			})
+
+			protected.Route("/budgets", func(budgets chi.Router) {
+				budgets.Get("/", h.Budgets)
+				budgets.Post("/", h.CreateBudget)
+				budgets.Get("/{id}", h.BudgetDetail)
+				budgets.Put("/{id}", h.UpdateBudget)
+				budgets.Delete("/{id}", h.DeleteBudget)
+			})
+
+			protected.Get("/anomalies", h.Anomalies)
+
+			protected.Route("/reports", func(reports chi.Router) {
+				reports.Get("/chargeback", h.ChargebackReport)
+				reports.Get("/chargeback/history", h.ChargebackReports)
+				reports.Get("/chargeback/{id}", h.ChargebackReportDetail)
+				reports.Get("/schedules", h.ReportSchedules)
+				reports.Post("/schedules", h.CreateReportSchedule)
+				reports.Get("/schedules/{id}", h.ReportScheduleDetail)
+				reports.Put("/schedules/{id}", h.UpdateReportSchedule)
+				reports.Delete("/schedules/{id}", h.DeleteReportSchedule)
+				reports.Post("/schedules/{id}/run", h.RunReportSchedule)
+				reports.Get("/schedules/{id}/runs", h.ReportScheduleRuns)
+			})
+
+			protected.Route("/alerts", func(alerts chi.Router) {
+				alerts.Get("/", h.Alerts)
+				alerts.Get("/rules", h.AlertRules)
+				alerts.Post("/rules", h.CreateAlertRule)
+				alerts.Get("/rules/{id}", h.AlertRuleDetail)
+				alerts.Put("/rules/{id}", h.UpdateAlertRule)
+				alerts.Delete("/rules/{id}", h.DeleteAlertRule)
+				alerts.Post("/rules/{id}/test", h.TestAlertRule)
 			})
 		})
 	})
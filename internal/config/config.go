@@ -44,6 +44,26 @@ type Config struct {
 	StoragePath                  string        `yaml:"storagePath"`
 	JWTSecret                    string        `yaml:"jwtSecret"`
 	LogLevel                     string        `yaml:"logLevel"`
+	AlertEvaluationInterval      time.Duration `yaml:"alertEvaluationInterval"`
+	AnomalyDetectionInterval     time.Duration `yaml:"anomalyDetectionInterval"`
+	SMTPHost                     string        `yaml:"smtpHost"`
+	SMTPPort                     int           `yaml:"smtpPort"`
+	SMTPUsername                 string        `yaml:"smtpUsername"`
+	SMTPPassword                 string        `yaml:"smtpPassword"`
+	SMTPFrom                     string        `yaml:"smtpFrom"`
+	EgressRulesFile              string        `yaml:"egressRulesFile"`
+	AWSIPRangesFile              string        `yaml:"awsIpRangesFile"`
+	// Connection metric cardinality controls, applied at ingest (see vm.newCardinalityPolicy)
+	NetworkEphemeralIPs          string   `yaml:"networkEphemeralIps"`
+	NetworkExternalIPs           string   `yaml:"networkExternalIps"`
+	NetworkTopConnectionsPerNode int      `yaml:"networkTopConnectionsPerNode"`
+	NetworkLabelAllow            []string `yaml:"networkLabelAllow"`
+	NetworkLabelDeny             []string `yaml:"networkLabelDeny"`
+	// NetworkCounterMode is how agents report connection bytes: cumulative or delta
+	NetworkCounterMode string `yaml:"networkCounterMode"`
+	// NamespaceLabels adds labels such as team to the series of each namespace, keyed by
+	// namespace, so budgets and chargeback reports can group by them
+	NamespaceLabels map[string]map[string]string `yaml:"namespaceLabels"`
 }
 
 // Default returns the default configuration used when no other information is provided.
@@ -63,6 +83,9 @@ func Default() Config {
 		VictoriaMetricsLookback:      24 * time.Hour,
 		StoragePath:                  "data/clustercost.db",
 		JWTSecret:                    "clustercost-secret",
+		AlertEvaluationInterval:      time.Minute,
+		AnomalyDetectionInterval:     15 * time.Minute,
+		SMTPPort:                     587,
 	}
 }
 
@@ -213,6 +236,77 @@ func Load() (Config, error) {
 		cfg.JWTSecret = secret
 	}
 
+	if raw := os.Getenv("ALERT_EVALUATION_INTERVAL"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid ALERT_EVALUATION_INTERVAL: %w", err)
+		}
+		cfg.AlertEvaluationInterval = d
+	}
+
+	if raw := os.Getenv("ANOMALY_DETECTION_INTERVAL"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid ANOMALY_DETECTION_INTERVAL: %w", err)
+		}
+		cfg.AnomalyDetectionInterval = d
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		cfg.SMTPHost = host
+	}
+	if raw := os.Getenv("SMTP_PORT"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		cfg.SMTPPort = parsed
+	}
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		cfg.SMTPUsername = username
+	}
+	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
+		cfg.SMTPPassword = password
+	}
+	if from := os.Getenv("SMTP_FROM"); from != "" {
+		cfg.SMTPFrom = from
+	}
+
+	if file := os.Getenv("EGRESS_RULES_FILE"); file != "" {
+		cfg.EgressRulesFile = file
+	}
+	if file := os.Getenv("AWS_IP_RANGES_FILE"); file != "" {
+		cfg.AWSIPRangesFile = file
+	}
+
+	if mode := os.Getenv("NETWORK_EPHEMERAL_IPS"); mode != "" {
+		cfg.NetworkEphemeralIPs = strings.ToLower(mode)
+	}
+	if mode := os.Getenv("NETWORK_EXTERNAL_IPS"); mode != "" {
+		cfg.NetworkExternalIPs = strings.ToLower(mode)
+	}
+	if raw := os.Getenv("NETWORK_TOP_CONNECTIONS_PER_NODE"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid NETWORK_TOP_CONNECTIONS_PER_NODE: %w", err)
+		}
+		cfg.NetworkTopConnectionsPerNode = parsed
+	}
+	if raw := os.Getenv("NETWORK_LABEL_ALLOW"); raw != "" {
+		cfg.NetworkLabelAllow = splitList(raw)
+	}
+	if raw := os.Getenv("NETWORK_LABEL_DENY"); raw != "" {
+		cfg.NetworkLabelDeny = splitList(raw)
+	}
+	if mode := os.Getenv("NETWORK_COUNTER_MODE"); mode != "" {
+		cfg.NetworkCounterMode = strings.ToLower(mode)
+	}
+	switch cfg.NetworkCounterMode {
+	case "", "cumulative", "delta":
+	default:
+		return Config{}, fmt.Errorf("invalid NETWORK_COUNTER_MODE %q: must be cumulative or delta", cfg.NetworkCounterMode)
+	}
+
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		cfg.LogLevel = strings.ToLower(logLevel)
 	}
@@ -311,4 +405,63 @@ func merge(dst *Config, src Config) {
 	if src.JWTSecret != "" {
 		dst.JWTSecret = src.JWTSecret
 	}
+	if src.AlertEvaluationInterval != 0 {
+		dst.AlertEvaluationInterval = src.AlertEvaluationInterval
+	}
+	if src.AnomalyDetectionInterval != 0 {
+		dst.AnomalyDetectionInterval = src.AnomalyDetectionInterval
+	}
+	if src.SMTPHost != "" {
+		dst.SMTPHost = src.SMTPHost
+	}
+	if src.SMTPPort != 0 {
+		dst.SMTPPort = src.SMTPPort
+	}
+	if src.SMTPUsername != "" {
+		dst.SMTPUsername = src.SMTPUsername
+	}
+	if src.SMTPPassword != "" {
+		dst.SMTPPassword = src.SMTPPassword
+	}
+	if src.SMTPFrom != "" {
+		dst.SMTPFrom = src.SMTPFrom
+	}
+	if src.EgressRulesFile != "" {
+		dst.EgressRulesFile = src.EgressRulesFile
+	}
+	if src.AWSIPRangesFile != "" {
+		dst.AWSIPRangesFile = src.AWSIPRangesFile
+	}
+	if src.NetworkEphemeralIPs != "" {
+		dst.NetworkEphemeralIPs = src.NetworkEphemeralIPs
+	}
+	if src.NetworkExternalIPs != "" {
+		dst.NetworkExternalIPs = src.NetworkExternalIPs
+	}
+	if src.NetworkTopConnectionsPerNode != 0 {
+		dst.NetworkTopConnectionsPerNode = src.NetworkTopConnectionsPerNode
+	}
+	if len(src.NetworkLabelAllow) > 0 {
+		dst.NetworkLabelAllow = src.NetworkLabelAllow
+	}
+	if len(src.NetworkLabelDeny) > 0 {
+		dst.NetworkLabelDeny = src.NetworkLabelDeny
+	}
+	if src.NetworkCounterMode != "" {
+		dst.NetworkCounterMode = src.NetworkCounterMode
+	}
+	if len(src.NamespaceLabels) > 0 {
+		dst.NamespaceLabels = src.NamespaceLabels
+	}
+}
+
+// splitList parses a comma-separated environment value, skipping blanks.
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
 }
@@ -0,0 +1,252 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AlertKind selects the signal an alert rule watches.
+type AlertKind string
+
+const (
+	// AlertKindCost fires when the current hourly cost (cluster or namespace) exceeds the threshold.
+	AlertKindCost AlertKind = "cost"
+	// AlertKindAnomaly fires when the hourly cost anomaly score (z-score) exceeds the threshold.
+	AlertKindAnomaly AlertKind = "anomaly"
+	// AlertKindAgentOffline fires while the agent status is offline.
+	AlertKindAgentOffline AlertKind = "agent_offline"
+	// AlertKindBudget fires when a budget's month-to-date spend exceeds the threshold (percent of limit).
+	AlertKindBudget AlertKind = "budget"
+)
+
+// AlertStateValue is the lifecycle position of a rule.
+type AlertStateValue string
+
+const (
+	AlertInactive AlertStateValue = "inactive"
+	AlertPending  AlertStateValue = "pending"
+	AlertFiring   AlertStateValue = "firing"
+)
+
+// AlertRule is a stored alert definition.
+type AlertRule struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Kind       AlertKind `json:"kind"`
+	ClusterID  string    `json:"clusterId"`
+	Namespace  string    `json:"namespace,omitempty"`
+	BudgetID   int64     `json:"budgetId,omitempty"`
+	Threshold  float64   `json:"threshold"`
+	ForSeconds int64     `json:"forSeconds"`
+	WebhookURL string    `json:"webhookUrl"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Normalize trims input fields.
+func (r *AlertRule) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Kind = AlertKind(strings.ToLower(strings.TrimSpace(string(r.Kind))))
+	r.ClusterID = strings.TrimSpace(r.ClusterID)
+	r.Namespace = strings.TrimSpace(r.Namespace)
+	r.WebhookURL = strings.TrimSpace(r.WebhookURL)
+}
+
+// Validate reports the first problem with a rule definition.
+func (r AlertRule) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	switch r.Kind {
+	case AlertKindCost, AlertKindAnomaly:
+		if r.Threshold <= 0 {
+			return errors.New("threshold must be greater than zero")
+		}
+	case AlertKindBudget:
+		if r.BudgetID <= 0 {
+			return errors.New("budgetId is required for budget alerts")
+		}
+		if r.Threshold <= 0 {
+			return errors.New("threshold must be greater than zero")
+		}
+	case AlertKindAgentOffline:
+	default:
+		return errors.New("kind must be one of cost, anomaly, agent_offline, budget")
+	}
+	if r.ForSeconds < 0 {
+		return errors.New("forSeconds must not be negative")
+	}
+	parsed, err := url.Parse(r.WebhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("webhookUrl must be an http(s) url")
+	}
+	return nil
+}
+
+// AlertState tracks the evaluation history of a rule for dedup and resolve notifications.
+type AlertState struct {
+	RuleID      int64           `json:"ruleId"`
+	State       AlertStateValue `json:"state"`
+	Value       float64         `json:"value"`
+	ActiveSince time.Time       `json:"activeSince"`
+	FiredAt     time.Time       `json:"firedAt"`
+	ResolvedAt  time.Time       `json:"resolvedAt"`
+	EvaluatedAt time.Time       `json:"evaluatedAt"`
+}
+
+const alertRuleColumns = "id, name, kind, cluster_id, namespace, budget_id, threshold, for_seconds, webhook_url, enabled, created_at, updated_at"
+
+func scanAlertRule(row interface{ Scan(...any) error }) (AlertRule, error) {
+	var r AlertRule
+	var kind string
+	err := row.Scan(&r.ID, &r.Name, &kind, &r.ClusterID, &r.Namespace, &r.BudgetID, &r.Threshold,
+		&r.ForSeconds, &r.WebhookURL, &r.Enabled, &r.CreatedAt, &r.UpdatedAt)
+	r.Kind = AlertKind(kind)
+	return r, err
+}
+
+// ListAlertRules returns all alert rules ordered by id.
+func (s *Store) ListAlertRules() ([]AlertRule, error) {
+	rows, err := s.db.Query("SELECT " + alertRuleColumns + " FROM alert_rules ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("list alert rules: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rules := []AlertRule{}
+	for rows.Next() {
+		r, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert rule: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// GetAlertRule returns a single rule or ErrNotFound.
+func (s *Store) GetAlertRule(id int64) (AlertRule, error) {
+	r, err := scanAlertRule(s.db.QueryRow("SELECT "+alertRuleColumns+" FROM alert_rules WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return AlertRule{}, ErrNotFound
+	}
+	if err != nil {
+		return AlertRule{}, fmt.Errorf("get alert rule: %w", err)
+	}
+	return r, nil
+}
+
+// CreateAlertRule inserts a rule and returns the stored record.
+func (s *Store) CreateAlertRule(r AlertRule) (AlertRule, error) {
+	now := time.Now().UTC()
+	res, err := s.db.Exec(`INSERT INTO alert_rules (name, kind, cluster_id, namespace, budget_id, threshold, for_seconds, webhook_url, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Name, string(r.Kind), r.ClusterID, r.Namespace, r.BudgetID, r.Threshold, r.ForSeconds, r.WebhookURL, r.Enabled, now, now)
+	if err != nil {
+		return AlertRule{}, fmt.Errorf("create alert rule: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return AlertRule{}, fmt.Errorf("create alert rule: %w", err)
+	}
+	return s.GetAlertRule(id)
+}
+
+// UpdateAlertRule replaces a rule definition. Evaluation state is reset so the new rule starts clean.
+func (s *Store) UpdateAlertRule(r AlertRule) (AlertRule, error) {
+	res, err := s.db.Exec(`UPDATE alert_rules SET name = ?, kind = ?, cluster_id = ?, namespace = ?, budget_id = ?, threshold = ?,
+		for_seconds = ?, webhook_url = ?, enabled = ?, updated_at = ? WHERE id = ?`,
+		r.Name, string(r.Kind), r.ClusterID, r.Namespace, r.BudgetID, r.Threshold, r.ForSeconds, r.WebhookURL, r.Enabled, time.Now().UTC(), r.ID)
+	if err != nil {
+		return AlertRule{}, fmt.Errorf("update alert rule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return AlertRule{}, ErrNotFound
+	}
+	if _, err := s.db.Exec("DELETE FROM alert_states WHERE rule_id = ?", r.ID); err != nil {
+		return AlertRule{}, fmt.Errorf("reset alert state: %w", err)
+	}
+	return s.GetAlertRule(r.ID)
+}
+
+// DeleteAlertRule removes a rule and its state or returns ErrNotFound.
+func (s *Store) DeleteAlertRule(id int64) error {
+	res, err := s.db.Exec("DELETE FROM alert_rules WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete alert rule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	if _, err := s.db.Exec("DELETE FROM alert_states WHERE rule_id = ?", id); err != nil {
+		return fmt.Errorf("delete alert state: %w", err)
+	}
+	return nil
+}
+
+const alertStateColumns = "rule_id, state, value, active_since, fired_at, resolved_at, evaluated_at"
+
+func scanAlertState(row interface{ Scan(...any) error }) (AlertState, error) {
+	var st AlertState
+	var state string
+	var activeSince, firedAt, resolvedAt, evaluatedAt sql.NullTime
+	err := row.Scan(&st.RuleID, &state, &st.Value, &activeSince, &firedAt, &resolvedAt, &evaluatedAt)
+	st.State = AlertStateValue(state)
+	st.ActiveSince = activeSince.Time
+	st.FiredAt = firedAt.Time
+	st.ResolvedAt = resolvedAt.Time
+	st.EvaluatedAt = evaluatedAt.Time
+	return st, err
+}
+
+// GetAlertState returns the state of a rule. Rules that were never evaluated are inactive.
+func (s *Store) GetAlertState(ruleID int64) (AlertState, error) {
+	st, err := scanAlertState(s.db.QueryRow("SELECT "+alertStateColumns+" FROM alert_states WHERE rule_id = ?", ruleID))
+	if err == sql.ErrNoRows {
+		return AlertState{RuleID: ruleID, State: AlertInactive}, nil
+	}
+	if err != nil {
+		return AlertState{}, fmt.Errorf("get alert state: %w", err)
+	}
+	return st, nil
+}
+
+// ListAlertStates returns the state of every evaluated rule.
+func (s *Store) ListAlertStates() ([]AlertState, error) {
+	rows, err := s.db.Query("SELECT " + alertStateColumns + " FROM alert_states ORDER BY rule_id")
+	if err != nil {
+		return nil, fmt.Errorf("list alert states: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	states := []AlertState{}
+	for rows.Next() {
+		st, err := scanAlertState(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert state: %w", err)
+		}
+		states = append(states, st)
+	}
+	return states, rows.Err()
+}
+
+// SaveAlertState upserts the state of a rule.
+func (s *Store) SaveAlertState(st AlertState) error {
+	_, err := s.db.Exec(`INSERT INTO alert_states (`+alertStateColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(rule_id) DO UPDATE SET state = excluded.state, value = excluded.value, active_since = excluded.active_since,
+		fired_at = excluded.fired_at, resolved_at = excluded.resolved_at, evaluated_at = excluded.evaluated_at`,
+		st.RuleID, string(st.State), st.Value, nullTime(st.ActiveSince), nullTime(st.FiredAt), nullTime(st.ResolvedAt), nullTime(st.EvaluatedAt))
+	if err != nil {
+		return fmt.Errorf("save alert state: %w", err)
+	}
+	return nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+// SaveAnomaly stores an anomaly. Re-detections for the same namespace and window update the existing row.
+func (s *Store) SaveAnomaly(a store.Anomaly) error {
+	_, err := s.db.Exec(`INSERT INTO anomalies (cluster_id, namespace, window_start, detected_at, expected_hourly_cost, actual_hourly_cost, deviation_percent, score, direction)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(cluster_id, namespace, window_start) DO UPDATE SET detected_at = excluded.detected_at,
+		expected_hourly_cost = excluded.expected_hourly_cost, actual_hourly_cost = excluded.actual_hourly_cost,
+		deviation_percent = excluded.deviation_percent, score = excluded.score, direction = excluded.direction`,
+		a.ClusterID, a.Namespace, a.WindowStart.UTC(), a.DetectedAt.UTC(), a.ExpectedHourlyCost, a.ActualHourlyCost,
+		a.DeviationPercent, a.Score, string(a.Direction))
+	if err != nil {
+		return fmt.Errorf("save anomaly: %w", err)
+	}
+	return nil
+}
+
+// ListAnomalies returns stored anomalies, newest window first.
+func (s *Store) ListAnomalies(filter store.AnomalyFilter) ([]store.Anomaly, error) {
+	var where []string
+	var args []any
+	if filter.ClusterID != "" {
+		where = append(where, "cluster_id = ?")
+		args = append(args, filter.ClusterID)
+	}
+	if filter.Namespace != "" {
+		where = append(where, "namespace = ?")
+		args = append(args, filter.Namespace)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "window_start >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+
+	query := `SELECT id, cluster_id, namespace, window_start, detected_at, expected_hourly_cost, actual_hourly_cost, deviation_percent, score, direction FROM anomalies`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY window_start DESC, score DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list anomalies: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []store.Anomaly{}
+	for rows.Next() {
+		var a store.Anomaly
+		var direction string
+		if err := rows.Scan(&a.ID, &a.ClusterID, &a.Namespace, &a.WindowStart, &a.DetectedAt, &a.ExpectedHourlyCost,
+			&a.ActualHourlyCost, &a.DeviationPercent, &a.Score, &direction); err != nil {
+			return nil, fmt.Errorf("scan anomaly: %w", err)
+		}
+		a.Direction = store.AnomalyDirection(direction)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
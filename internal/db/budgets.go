@@ -0,0 +1,174 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// BudgetScope selects which costs count against a budget.
+type BudgetScope string
+
+const (
+	// BudgetScopeCluster tracks the whole cluster.
+	BudgetScopeCluster BudgetScope = "cluster"
+	// BudgetScopeNamespace tracks a single namespace.
+	BudgetScopeNamespace BudgetScope = "namespace"
+	// BudgetScopeLabel tracks every namespace series carrying a label value (e.g. environment=production).
+	BudgetScopeLabel BudgetScope = "label"
+)
+
+// DefaultBudgetAlertThreshold is the month-to-date spend (percent of limit) that marks a budget at risk.
+const DefaultBudgetAlertThreshold = 80
+
+// Budget is a monthly spend limit for a cluster, namespace or label value.
+type Budget struct {
+	ID                    int64       `json:"id"`
+	Name                  string      `json:"name"`
+	ClusterID             string      `json:"clusterId"`
+	Scope                 BudgetScope `json:"scope"`
+	Namespace             string      `json:"namespace,omitempty"`
+	LabelKey              string      `json:"labelKey,omitempty"`
+	LabelValue            string      `json:"labelValue,omitempty"`
+	MonthlyLimit          float64     `json:"monthlyLimit"`
+	AlertThresholdPercent float64     `json:"alertThresholdPercent"`
+	CreatedAt             time.Time   `json:"createdAt"`
+	UpdatedAt             time.Time   `json:"updatedAt"`
+}
+
+// Normalize trims input and applies defaults.
+func (b *Budget) Normalize() {
+	b.Name = strings.TrimSpace(b.Name)
+	b.ClusterID = strings.TrimSpace(b.ClusterID)
+	b.Scope = BudgetScope(strings.ToLower(strings.TrimSpace(string(b.Scope))))
+	b.Namespace = strings.TrimSpace(b.Namespace)
+	b.LabelKey = strings.TrimSpace(b.LabelKey)
+	b.LabelValue = strings.TrimSpace(b.LabelValue)
+	if b.Scope == "" {
+		b.Scope = BudgetScopeCluster
+	}
+	if b.AlertThresholdPercent == 0 {
+		b.AlertThresholdPercent = DefaultBudgetAlertThreshold
+	}
+}
+
+// Validate reports the first problem with a budget definition.
+func (b Budget) Validate() error {
+	if b.Name == "" {
+		return errors.New("name is required")
+	}
+	if b.MonthlyLimit <= 0 {
+		return errors.New("monthlyLimit must be greater than zero")
+	}
+	if b.AlertThresholdPercent <= 0 || b.AlertThresholdPercent > 100 {
+		return errors.New("alertThresholdPercent must be between 0 and 100")
+	}
+	switch b.Scope {
+	case BudgetScopeCluster:
+	case BudgetScopeNamespace:
+		if b.Namespace == "" {
+			return errors.New("namespace is required for namespace budgets")
+		}
+	case BudgetScopeLabel:
+		if b.LabelKey == "" || b.LabelValue == "" {
+			return errors.New("labelKey and labelValue are required for label budgets")
+		}
+	default:
+		return errors.New("scope must be one of cluster, namespace, label")
+	}
+	return nil
+}
+
+const budgetColumns = "id, name, cluster_id, scope, namespace, label_key, label_value, monthly_limit, alert_threshold_percent, created_at, updated_at"
+
+func scanBudget(row interface{ Scan(...any) error }) (Budget, error) {
+	var b Budget
+	var scope string
+	err := row.Scan(&b.ID, &b.Name, &b.ClusterID, &scope, &b.Namespace, &b.LabelKey, &b.LabelValue,
+		&b.MonthlyLimit, &b.AlertThresholdPercent, &b.CreatedAt, &b.UpdatedAt)
+	b.Scope = BudgetScope(scope)
+	return b, err
+}
+
+// ListBudgets returns all budgets ordered by id.
+func (s *Store) ListBudgets() ([]Budget, error) {
+	rows, err := s.db.Query("SELECT " + budgetColumns + " FROM budgets ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("list budgets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	budgets := []Budget{}
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// GetBudget returns a single budget or ErrNotFound.
+func (s *Store) GetBudget(id int64) (Budget, error) {
+	b, err := scanBudget(s.db.QueryRow("SELECT "+budgetColumns+" FROM budgets WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return Budget{}, ErrNotFound
+	}
+	if err != nil {
+		return Budget{}, fmt.Errorf("get budget: %w", err)
+	}
+	return b, nil
+}
+
+// CreateBudget inserts a budget and returns the stored record.
+func (s *Store) CreateBudget(b Budget) (Budget, error) {
+	now := time.Now().UTC()
+	res, err := s.db.Exec(`INSERT INTO budgets (name, cluster_id, scope, namespace, label_key, label_value, monthly_limit, alert_threshold_percent, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		b.Name, b.ClusterID, string(b.Scope), b.Namespace, b.LabelKey, b.LabelValue, b.MonthlyLimit, b.AlertThresholdPercent, now, now)
+	if err != nil {
+		return Budget{}, fmt.Errorf("create budget: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Budget{}, fmt.Errorf("create budget: %w", err)
+	}
+	return s.GetBudget(id)
+}
+
+// UpdateBudget replaces the definition of an existing budget.
+func (s *Store) UpdateBudget(b Budget) (Budget, error) {
+	res, err := s.db.Exec(`UPDATE budgets SET name = ?, cluster_id = ?, scope = ?, namespace = ?, label_key = ?, label_value = ?,
+		monthly_limit = ?, alert_threshold_percent = ?, updated_at = ? WHERE id = ?`,
+		b.Name, b.ClusterID, string(b.Scope), b.Namespace, b.LabelKey, b.LabelValue, b.MonthlyLimit, b.AlertThresholdPercent, time.Now().UTC(), b.ID)
+	if err != nil {
+		return Budget{}, fmt.Errorf("update budget: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return Budget{}, ErrNotFound
+	}
+	return s.GetBudget(b.ID)
+}
+
+// DeleteBudget removes a budget or returns ErrNotFound. Budget alert rules that watched it
+// are disabled rather than deleted, so their name and webhook survive for reuse.
+func (s *Store) DeleteBudget(id int64) error {
+	res, err := s.db.Exec("DELETE FROM budgets WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete budget: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	if _, err := s.db.Exec("UPDATE alert_rules SET enabled = ?, updated_at = ? WHERE kind = ? AND budget_id = ?",
+		false, time.Now().UTC(), string(AlertKindBudget), id); err != nil {
+		return fmt.Errorf("disable budget alert rules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestDB(t *testing.T) *Store {
+	t.Helper()
+	s, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestBudgetCRUD(t *testing.T) {
+	s := newTestDB(t)
+
+	b := Budget{Name: " ml team ", Scope: "Namespace", Namespace: "ml", MonthlyLimit: 1000}
+	b.Normalize()
+	if err := b.Validate(); err != nil {
+		t.Fatalf("expected valid budget, got %v", err)
+	}
+	created, err := s.CreateBudget(b)
+	if err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+	if created.ID == 0 || created.Name != "ml team" || created.Scope != BudgetScopeNamespace {
+		t.Fatalf("unexpected created budget: %+v", created)
+	}
+	if created.AlertThresholdPercent != DefaultBudgetAlertThreshold {
+		t.Fatalf("expected default threshold, got %f", created.AlertThresholdPercent)
+	}
+	if created.CreatedAt.IsZero() {
+		t.Fatal("expected created_at to be set")
+	}
+
+	created.MonthlyLimit = 1500
+	updated, err := s.UpdateBudget(created)
+	if err != nil {
+		t.Fatalf("UpdateBudget: %v", err)
+	}
+	if updated.MonthlyLimit != 1500 {
+		t.Fatalf("expected limit 1500, got %f", updated.MonthlyLimit)
+	}
+
+	list, err := s.ListBudgets()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListBudgets: %v %+v", err, list)
+	}
+
+	if err := s.DeleteBudget(created.ID); err != nil {
+		t.Fatalf("DeleteBudget: %v", err)
+	}
+	if _, err := s.GetBudget(created.ID); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.DeleteBudget(created.ID); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound on second delete, got %v", err)
+	}
+}
+
+func TestBudgetValidate(t *testing.T) {
+	cases := []Budget{
+		{Scope: BudgetScopeCluster, MonthlyLimit: 10, AlertThresholdPercent: 80},
+		{Name: "x", Scope: BudgetScopeCluster, AlertThresholdPercent: 80},
+		{Name: "x", Scope: BudgetScopeNamespace, MonthlyLimit: 10, AlertThresholdPercent: 80},
+		{Name: "x", Scope: BudgetScopeLabel, LabelKey: "environment", MonthlyLimit: 10, AlertThresholdPercent: 80},
+		{Name: "x", Scope: "team", MonthlyLimit: 10, AlertThresholdPercent: 80},
+		{Name: "x", Scope: BudgetScopeCluster, MonthlyLimit: 10, AlertThresholdPercent: 120},
+	}
+	for _, b := range cases {
+		if err := b.Validate(); err == nil {
+			t.Errorf("expected validation error for %+v", b)
+		}
+	}
+}
+
+func TestDeleteBudgetDisablesItsAlertRules(t *testing.T) {
+	s := newTestDB(t)
+
+	budget, err := s.CreateBudget(Budget{Name: "ml", Scope: BudgetScopeCluster, MonthlyLimit: 1000, AlertThresholdPercent: 80})
+	if err != nil {
+		t.Fatalf("CreateBudget: %v", err)
+	}
+	watching, err := s.CreateAlertRule(AlertRule{Name: "ml budget", Kind: AlertKindBudget, BudgetID: budget.ID, Threshold: 90, WebhookURL: "http://hooks.local", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	other, err := s.CreateAlertRule(AlertRule{Name: "cost", Kind: AlertKindCost, Threshold: 10, WebhookURL: "http://hooks.local", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+
+	if err := s.DeleteBudget(budget.ID); err != nil {
+		t.Fatalf("DeleteBudget: %v", err)
+	}
+	if got, err := s.GetAlertRule(watching.ID); err != nil || got.Enabled {
+		t.Fatalf("expected the budget rule to be kept but disabled, got %+v (%v)", got, err)
+	}
+	if got, err := s.GetAlertRule(other.ID); err != nil || !got.Enabled {
+		t.Fatalf("expected unrelated rules to stay enabled, got %+v (%v)", got, err)
+	}
+}
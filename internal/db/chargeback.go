@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+// SaveChargebackReport stores a generated report and returns the persisted copy.
+// Reports are immutable: when one already exists for the same cluster, grouping and period,
+// the stored report is returned unchanged.
+func (s *Store) SaveChargebackReport(r store.ChargebackReport) (store.ChargebackReport, error) {
+	r.ID = 0
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return store.ChargebackReport{}, fmt.Errorf("encode chargeback report: %w", err)
+	}
+	_, err = s.db.Exec(`INSERT INTO chargeback_reports (cluster_id, group_by, label_key, period_start, period_end, generated_at, total_cost, report)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(cluster_id, group_by, label_key, period_start, period_end) DO NOTHING`,
+		r.ClusterID, r.GroupBy, r.LabelKey, r.PeriodStart.UTC(), r.PeriodEnd.UTC(), r.GeneratedAt.UTC(), r.TotalCost, string(payload))
+	if err != nil {
+		return store.ChargebackReport{}, fmt.Errorf("save chargeback report: %w", err)
+	}
+	return s.FindChargebackReport(r.ClusterID, r.GroupBy, r.LabelKey, r.PeriodStart, r.PeriodEnd)
+}
+
+// FindChargebackReport returns the stored report for a cluster, grouping and period.
+func (s *Store) FindChargebackReport(clusterID, groupBy, labelKey string, start, end time.Time) (store.ChargebackReport, error) {
+	row := s.db.QueryRow(`SELECT id, report FROM chargeback_reports
+		WHERE cluster_id = ? AND group_by = ? AND label_key = ? AND period_start = ? AND period_end = ?`,
+		clusterID, groupBy, labelKey, start.UTC(), end.UTC())
+	return scanChargebackReport(row)
+}
+
+// GetChargebackReport returns a stored report by ID.
+func (s *Store) GetChargebackReport(id int64) (store.ChargebackReport, error) {
+	row := s.db.QueryRow(`SELECT id, report FROM chargeback_reports WHERE id = ?`, id)
+	return scanChargebackReport(row)
+}
+
+// ListChargebackReports returns stored reports without their lines, newest period first.
+func (s *Store) ListChargebackReports() ([]store.ChargebackReport, error) {
+	rows, err := s.db.Query(`SELECT id, report FROM chargeback_reports ORDER BY period_start DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list chargeback reports: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []store.ChargebackReport{}
+	for rows.Next() {
+		r, err := scanChargebackReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		r.Lines = nil
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func scanChargebackReport(row interface{ Scan(...any) error }) (store.ChargebackReport, error) {
+	var id int64
+	var payload string
+	if err := row.Scan(&id, &payload); err != nil {
+		if err == sql.ErrNoRows {
+			return store.ChargebackReport{}, ErrNotFound
+		}
+		return store.ChargebackReport{}, fmt.Errorf("scan chargeback report: %w", err)
+	}
+	var r store.ChargebackReport
+	if err := json.Unmarshal([]byte(payload), &r); err != nil {
+		return store.ChargebackReport{}, fmt.Errorf("decode chargeback report: %w", err)
+	}
+	r.ID = id
+	return r, nil
+}
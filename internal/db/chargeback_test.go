@@ -0,0 +1,64 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+func TestChargebackReportsAreImmutable(t *testing.T) {
+	s := newTestDB(t)
+	start := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	report := store.ChargebackReport{
+		ClusterID:           "c1",
+		GroupBy:             store.ChargebackByNamespace,
+		PeriodStart:         start,
+		PeriodEnd:           end,
+		GeneratedAt:         end.Add(time.Hour),
+		CPUPricePerCoreHour: 0.02,
+		TotalCost:           120,
+		Lines:               []store.ChargebackLine{{Group: "web", ComputeCost: 100, TotalCost: 120}},
+	}
+	saved, err := s.SaveChargebackReport(report)
+	if err != nil {
+		t.Fatalf("SaveChargebackReport: %v", err)
+	}
+	if saved.ID == 0 || len(saved.Lines) != 1 || saved.Lines[0].Group != "web" {
+		t.Fatalf("unexpected saved report: %+v", saved)
+	}
+
+	// Regenerating with new prices keeps the original invoice
+	report.CPUPricePerCoreHour = 0.05
+	report.TotalCost = 300
+	again, err := s.SaveChargebackReport(report)
+	if err != nil {
+		t.Fatalf("SaveChargebackReport: %v", err)
+	}
+	if again.ID != saved.ID || again.TotalCost != 120 || again.CPUPricePerCoreHour != 0.02 {
+		t.Fatalf("expected stored report to be unchanged, got %+v", again)
+	}
+
+	byLabel := report
+	byLabel.GroupBy, byLabel.LabelKey = store.ChargebackByLabel, "team"
+	if _, err := s.SaveChargebackReport(byLabel); err != nil {
+		t.Fatalf("SaveChargebackReport: %v", err)
+	}
+
+	list, err := s.ListChargebackReports()
+	if err != nil {
+		t.Fatalf("ListChargebackReports: %v", err)
+	}
+	if len(list) != 2 || list[0].Lines != nil {
+		t.Fatalf("expected 2 reports without lines, got %+v", list)
+	}
+	if _, err := s.GetChargebackReport(999); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	found, err := s.FindChargebackReport("c1", store.ChargebackByNamespace, "", start, end)
+	if err != nil || found.ID != saved.ID {
+		t.Fatalf("FindChargebackReport: %+v %v", found, err)
+	}
+}
@@ -50,6 +50,98 @@ func (s *Store) migrate() error {
 		password_hash TEXT NOT NULL,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
+	CREATE TABLE IF NOT EXISTS budgets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		cluster_id TEXT NOT NULL DEFAULT '',
+		scope TEXT NOT NULL,
+		namespace TEXT NOT NULL DEFAULT '',
+		label_key TEXT NOT NULL DEFAULT '',
+		label_value TEXT NOT NULL DEFAULT '',
+		monthly_limit REAL NOT NULL,
+		alert_threshold_percent REAL NOT NULL DEFAULT 80,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS alert_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		cluster_id TEXT NOT NULL DEFAULT '',
+		namespace TEXT NOT NULL DEFAULT '',
+		budget_id INTEGER NOT NULL DEFAULT 0,
+		threshold REAL NOT NULL DEFAULT 0,
+		for_seconds INTEGER NOT NULL DEFAULT 0,
+		webhook_url TEXT NOT NULL,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS alert_states (
+		rule_id INTEGER PRIMARY KEY REFERENCES alert_rules(id) ON DELETE CASCADE,
+		state TEXT NOT NULL,
+		value REAL NOT NULL DEFAULT 0,
+		active_since DATETIME,
+		fired_at DATETIME,
+		resolved_at DATETIME,
+		evaluated_at DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS anomalies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cluster_id TEXT NOT NULL DEFAULT '',
+		namespace TEXT NOT NULL,
+		window_start DATETIME NOT NULL,
+		detected_at DATETIME NOT NULL,
+		expected_hourly_cost REAL NOT NULL,
+		actual_hourly_cost REAL NOT NULL,
+		deviation_percent REAL NOT NULL,
+		score REAL NOT NULL,
+		direction TEXT NOT NULL,
+		UNIQUE (cluster_id, namespace, window_start)
+	);
+	CREATE INDEX IF NOT EXISTS anomalies_window_start ON anomalies (window_start);
+
+	CREATE TABLE IF NOT EXISTS chargeback_reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cluster_id TEXT NOT NULL DEFAULT '',
+		group_by TEXT NOT NULL,
+		label_key TEXT NOT NULL DEFAULT '',
+		period_start DATETIME NOT NULL,
+		period_end DATETIME NOT NULL,
+		generated_at DATETIME NOT NULL,
+		total_cost REAL NOT NULL,
+		report TEXT NOT NULL,
+		UNIQUE (cluster_id, group_by, label_key, period_start, period_end)
+	);
+
+	CREATE TABLE IF NOT EXISTS report_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		frequency TEXT NOT NULL,
+		hour INTEGER NOT NULL DEFAULT 0,
+		weekday INTEGER NOT NULL DEFAULT 0,
+		day_of_month INTEGER NOT NULL DEFAULT 1,
+		cluster_id TEXT NOT NULL DEFAULT '',
+		namespaces TEXT NOT NULL DEFAULT '',
+		channel TEXT NOT NULL,
+		recipients TEXT NOT NULL DEFAULT '',
+		webhook_url TEXT NOT NULL DEFAULT '',
+		enabled INTEGER NOT NULL DEFAULT 1,
+		next_run_at DATETIME,
+		last_run_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS report_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		schedule_id INTEGER NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+		triggered_by TEXT NOT NULL,
+		status TEXT NOT NULL,
+		error TEXT NOT NULL DEFAULT '',
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS report_runs_schedule ON report_runs (schedule_id, started_at);
 	`
 	_, err := s.db.Exec(query)
 	return err
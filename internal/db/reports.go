@@ -0,0 +1,330 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ReportFrequency is how often a scheduled report is delivered.
+type ReportFrequency string
+
+const (
+	ReportDaily   ReportFrequency = "daily"
+	ReportWeekly  ReportFrequency = "weekly"
+	ReportMonthly ReportFrequency = "monthly"
+)
+
+// Period is the spend window a report with this frequency covers.
+func (f ReportFrequency) Period(now time.Time) time.Duration {
+	switch f {
+	case ReportWeekly:
+		return 7 * 24 * time.Hour
+	case ReportMonthly:
+		return now.Sub(now.AddDate(0, -1, 0))
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// ReportChannel is how a scheduled report is delivered.
+type ReportChannel string
+
+const (
+	ReportChannelEmail   ReportChannel = "email"
+	ReportChannelWebhook ReportChannel = "webhook"
+)
+
+// ReportRunStatus is the outcome of a report delivery.
+type ReportRunStatus string
+
+const (
+	ReportRunSuccess ReportRunStatus = "success"
+	ReportRunFailed  ReportRunStatus = "failed"
+)
+
+// ReportSchedule is a stored report delivery definition. Times are UTC: daily reports go out at
+// Hour, weekly ones on Weekday (0 = Sunday) at Hour, monthly ones on DayOfMonth at Hour.
+type ReportSchedule struct {
+	ID         int64           `json:"id"`
+	Name       string          `json:"name"`
+	Frequency  ReportFrequency `json:"frequency"`
+	Hour       int             `json:"hour"`
+	Weekday    int             `json:"weekday"`
+	DayOfMonth int             `json:"dayOfMonth"`
+	ClusterID  string          `json:"clusterId"`
+	Namespaces []string        `json:"namespaces"`
+	Channel    ReportChannel   `json:"channel"`
+	Recipients []string        `json:"recipients"`
+	WebhookURL string          `json:"webhookUrl,omitempty"`
+	Enabled    bool            `json:"enabled"`
+	NextRunAt  time.Time       `json:"nextRunAt"`
+	LastRunAt  time.Time       `json:"lastRunAt"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// Normalize trims input fields and fills frequency defaults.
+func (r *ReportSchedule) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Frequency = ReportFrequency(strings.ToLower(strings.TrimSpace(string(r.Frequency))))
+	r.Channel = ReportChannel(strings.ToLower(strings.TrimSpace(string(r.Channel))))
+	r.ClusterID = strings.TrimSpace(r.ClusterID)
+	r.WebhookURL = strings.TrimSpace(r.WebhookURL)
+	r.Namespaces = trimList(r.Namespaces)
+	r.Recipients = trimList(r.Recipients)
+	// Keep only the address: SMTP RCPT takes bare addresses, not "Name <addr>".
+	for i, raw := range r.Recipients {
+		if addr, err := mail.ParseAddress(raw); err == nil {
+			r.Recipients[i] = addr.Address
+		}
+	}
+	if r.Frequency == ReportMonthly && r.DayOfMonth == 0 {
+		r.DayOfMonth = 1
+	}
+}
+
+// Validate reports the first problem with a schedule definition.
+func (r ReportSchedule) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	switch r.Frequency {
+	case ReportDaily:
+	case ReportWeekly:
+		if r.Weekday < 0 || r.Weekday > 6 {
+			return errors.New("weekday must be between 0 (Sunday) and 6")
+		}
+	case ReportMonthly:
+		// Capped at 28 so every month has the day.
+		if r.DayOfMonth < 1 || r.DayOfMonth > 28 {
+			return errors.New("dayOfMonth must be between 1 and 28")
+		}
+	default:
+		return errors.New("frequency must be one of daily, weekly, monthly")
+	}
+	if r.Hour < 0 || r.Hour > 23 {
+		return errors.New("hour must be between 0 and 23")
+	}
+	switch r.Channel {
+	case ReportChannelEmail:
+		if len(r.Recipients) == 0 {
+			return errors.New("recipients are required for email delivery")
+		}
+		for _, raw := range r.Recipients {
+			if addr, err := mail.ParseAddress(raw); err != nil || addr.Address != raw {
+				return fmt.Errorf("invalid recipient %q: must be a bare address like name@example.com", raw)
+			}
+		}
+	case ReportChannelWebhook:
+		parsed, err := url.Parse(r.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("webhookUrl must be an http(s) url")
+		}
+	default:
+		return errors.New("channel must be one of email, webhook")
+	}
+	return nil
+}
+
+// NextRun returns the first delivery time strictly after the given time.
+func (r ReportSchedule) NextRun(after time.Time) time.Time {
+	after = after.UTC()
+	switch r.Frequency {
+	case ReportWeekly:
+		next := time.Date(after.Year(), after.Month(), after.Day(), r.Hour, 0, 0, 0, time.UTC)
+		next = next.AddDate(0, 0, (r.Weekday-int(next.Weekday())+7)%7)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	case ReportMonthly:
+		next := time.Date(after.Year(), after.Month(), r.DayOfMonth, r.Hour, 0, 0, 0, time.UTC)
+		if !next.After(after) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	default:
+		next := time.Date(after.Year(), after.Month(), after.Day(), r.Hour, 0, 0, 0, time.UTC)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+}
+
+// ReportRun records one delivery attempt of a schedule.
+type ReportRun struct {
+	ID         int64           `json:"id"`
+	ScheduleID int64           `json:"scheduleId"`
+	Trigger    string          `json:"trigger"` // schedule or manual
+	Status     ReportRunStatus `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt time.Time       `json:"finishedAt"`
+}
+
+const reportScheduleColumns = "id, name, frequency, hour, weekday, day_of_month, cluster_id, namespaces, channel, recipients, webhook_url, enabled, next_run_at, last_run_at, created_at, updated_at"
+
+func scanReportSchedule(row interface{ Scan(...any) error }) (ReportSchedule, error) {
+	var r ReportSchedule
+	var frequency, channel, namespaces, recipients string
+	var nextRun, lastRun sql.NullTime
+	err := row.Scan(&r.ID, &r.Name, &frequency, &r.Hour, &r.Weekday, &r.DayOfMonth, &r.ClusterID, &namespaces,
+		&channel, &recipients, &r.WebhookURL, &r.Enabled, &nextRun, &lastRun, &r.CreatedAt, &r.UpdatedAt)
+	r.Frequency = ReportFrequency(frequency)
+	r.Channel = ReportChannel(channel)
+	r.Namespaces = splitList(namespaces)
+	r.Recipients = splitList(recipients)
+	r.NextRunAt = nextRun.Time
+	r.LastRunAt = lastRun.Time
+	return r, err
+}
+
+// ListReportSchedules returns all report schedules ordered by id.
+func (s *Store) ListReportSchedules() ([]ReportSchedule, error) {
+	rows, err := s.db.Query("SELECT " + reportScheduleColumns + " FROM report_schedules ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("list report schedules: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	schedules := []ReportSchedule{}
+	for rows.Next() {
+		r, err := scanReportSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan report schedule: %w", err)
+		}
+		schedules = append(schedules, r)
+	}
+	return schedules, rows.Err()
+}
+
+// GetReportSchedule returns a single schedule or ErrNotFound.
+func (s *Store) GetReportSchedule(id int64) (ReportSchedule, error) {
+	r, err := scanReportSchedule(s.db.QueryRow("SELECT "+reportScheduleColumns+" FROM report_schedules WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return ReportSchedule{}, ErrNotFound
+	}
+	if err != nil {
+		return ReportSchedule{}, fmt.Errorf("get report schedule: %w", err)
+	}
+	return r, nil
+}
+
+// CreateReportSchedule inserts a schedule, computes its first run and returns the stored record.
+func (s *Store) CreateReportSchedule(r ReportSchedule) (ReportSchedule, error) {
+	now := time.Now().UTC()
+	res, err := s.db.Exec(`INSERT INTO report_schedules (name, frequency, hour, weekday, day_of_month, cluster_id, namespaces, channel,
+		recipients, webhook_url, enabled, next_run_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Name, string(r.Frequency), r.Hour, r.Weekday, r.DayOfMonth, r.ClusterID, strings.Join(r.Namespaces, ","), string(r.Channel),
+		strings.Join(r.Recipients, ","), r.WebhookURL, r.Enabled, r.NextRun(now), now, now)
+	if err != nil {
+		return ReportSchedule{}, fmt.Errorf("create report schedule: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return ReportSchedule{}, fmt.Errorf("create report schedule: %w", err)
+	}
+	return s.GetReportSchedule(id)
+}
+
+// UpdateReportSchedule replaces a schedule definition and recomputes its next run.
+func (s *Store) UpdateReportSchedule(r ReportSchedule) (ReportSchedule, error) {
+	now := time.Now().UTC()
+	res, err := s.db.Exec(`UPDATE report_schedules SET name = ?, frequency = ?, hour = ?, weekday = ?, day_of_month = ?, cluster_id = ?,
+		namespaces = ?, channel = ?, recipients = ?, webhook_url = ?, enabled = ?, next_run_at = ?, updated_at = ? WHERE id = ?`,
+		r.Name, string(r.Frequency), r.Hour, r.Weekday, r.DayOfMonth, r.ClusterID, strings.Join(r.Namespaces, ","), string(r.Channel),
+		strings.Join(r.Recipients, ","), r.WebhookURL, r.Enabled, r.NextRun(now), now, r.ID)
+	if err != nil {
+		return ReportSchedule{}, fmt.Errorf("update report schedule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ReportSchedule{}, ErrNotFound
+	}
+	return s.GetReportSchedule(r.ID)
+}
+
+// DeleteReportSchedule removes a schedule and its run history or returns ErrNotFound.
+func (s *Store) DeleteReportSchedule(id int64) error {
+	res, err := s.db.Exec("DELETE FROM report_schedules WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete report schedule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	if _, err := s.db.Exec("DELETE FROM report_runs WHERE schedule_id = ?", id); err != nil {
+		return fmt.Errorf("delete report runs: %w", err)
+	}
+	return nil
+}
+
+// MarkReportScheduleRun stores when a schedule last ran and when it is next due.
+func (s *Store) MarkReportScheduleRun(id int64, lastRun, nextRun time.Time) error {
+	_, err := s.db.Exec("UPDATE report_schedules SET last_run_at = ?, next_run_at = ? WHERE id = ?", nullTime(lastRun), nullTime(nextRun), id)
+	if err != nil {
+		return fmt.Errorf("mark report schedule run: %w", err)
+	}
+	return nil
+}
+
+// RecordReportRun appends a delivery attempt to the run history.
+func (s *Store) RecordReportRun(run ReportRun) (ReportRun, error) {
+	res, err := s.db.Exec(`INSERT INTO report_runs (schedule_id, triggered_by, status, error, started_at, finished_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		run.ScheduleID, run.Trigger, string(run.Status), run.Error, run.StartedAt.UTC(), run.FinishedAt.UTC())
+	if err != nil {
+		return ReportRun{}, fmt.Errorf("record report run: %w", err)
+	}
+	run.ID, err = res.LastInsertId()
+	if err != nil {
+		return ReportRun{}, fmt.Errorf("record report run: %w", err)
+	}
+	return run, nil
+}
+
+// ListReportRuns returns the most recent runs of a schedule, newest first.
+func (s *Store) ListReportRuns(scheduleID int64, limit int) ([]ReportRun, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.Query(`SELECT id, schedule_id, triggered_by, status, error, started_at, finished_at FROM report_runs
+		WHERE schedule_id = ? ORDER BY started_at DESC, id DESC LIMIT ?`, scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list report runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	runs := []ReportRun{}
+	for rows.Next() {
+		var run ReportRun
+		var status string
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.Trigger, &status, &run.Error, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scan report run: %w", err)
+		}
+		run.Status = ReportRunStatus(status)
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func trimList(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func splitList(raw string) []string {
+	if raw == "" {
+		return []string{}
+	}
+	return strings.Split(raw, ",")
+}
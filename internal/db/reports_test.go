@@ -0,0 +1,115 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportScheduleNextRun(t *testing.T) {
+	after := time.Date(2024, time.June, 12, 10, 30, 0, 0, time.UTC) // Wednesday
+
+	cases := []struct {
+		name     string
+		schedule ReportSchedule
+		want     time.Time
+	}{
+		{"daily later today", ReportSchedule{Frequency: ReportDaily, Hour: 18}, time.Date(2024, time.June, 12, 18, 0, 0, 0, time.UTC)},
+		{"daily tomorrow", ReportSchedule{Frequency: ReportDaily, Hour: 9}, time.Date(2024, time.June, 13, 9, 0, 0, 0, time.UTC)},
+		{"weekly monday", ReportSchedule{Frequency: ReportWeekly, Weekday: 1, Hour: 8}, time.Date(2024, time.June, 17, 8, 0, 0, 0, time.UTC)},
+		{"weekly same day passed", ReportSchedule{Frequency: ReportWeekly, Weekday: 3, Hour: 8}, time.Date(2024, time.June, 19, 8, 0, 0, 0, time.UTC)},
+		{"monthly next month", ReportSchedule{Frequency: ReportMonthly, DayOfMonth: 1, Hour: 6}, time.Date(2024, time.July, 1, 6, 0, 0, 0, time.UTC)},
+		{"monthly this month", ReportSchedule{Frequency: ReportMonthly, DayOfMonth: 28}, time.Date(2024, time.June, 28, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		if got := tc.schedule.NextRun(after); !got.Equal(tc.want) {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestReportScheduleValidate(t *testing.T) {
+	valid := ReportSchedule{Name: "weekly", Frequency: ReportWeekly, Channel: ReportChannelEmail, Recipients: []string{"finance@example.com"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid schedule, got %v", err)
+	}
+	invalid := []ReportSchedule{
+		{Name: "x", Frequency: "hourly", Channel: ReportChannelEmail, Recipients: []string{"a@example.com"}},
+		{Name: "x", Frequency: ReportDaily, Hour: 24, Channel: ReportChannelEmail, Recipients: []string{"a@example.com"}},
+		{Name: "x", Frequency: ReportMonthly, DayOfMonth: 31, Channel: ReportChannelEmail, Recipients: []string{"a@example.com"}},
+		{Name: "x", Frequency: ReportDaily, Channel: ReportChannelEmail, Recipients: []string{"not-an-address"}},
+		{Name: "x", Frequency: ReportDaily, Channel: ReportChannelEmail, Recipients: []string{"Finance <finance@example.com>"}},
+		{Name: "x", Frequency: ReportDaily, Channel: ReportChannelWebhook, WebhookURL: "ftp://example.com"},
+	}
+	for i, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+
+	named := ReportSchedule{Name: "x", Frequency: ReportDaily, Channel: ReportChannelEmail, Recipients: []string{" Finance <finance@example.com>"}}
+	named.Normalize()
+	if err := named.Validate(); err != nil || named.Recipients[0] != "finance@example.com" {
+		t.Fatalf("expected display name to be stripped, got %v (%v)", named.Recipients, err)
+	}
+}
+
+func TestReportScheduleCRUDAndRuns(t *testing.T) {
+	s := newTestDB(t)
+
+	schedule := ReportSchedule{
+		Name:       " finance ",
+		Frequency:  "Daily",
+		Hour:       7,
+		Namespaces: []string{" web ", "", "ml"},
+		Channel:    ReportChannelEmail,
+		Recipients: []string{"finance@example.com"},
+		Enabled:    true,
+	}
+	schedule.Normalize()
+	created, err := s.CreateReportSchedule(schedule)
+	if err != nil {
+		t.Fatalf("CreateReportSchedule: %v", err)
+	}
+	if created.Name != "finance" || len(created.Namespaces) != 2 || created.Namespaces[0] != "web" {
+		t.Fatalf("unexpected schedule: %+v", created)
+	}
+	if created.NextRunAt.IsZero() || created.NextRunAt.Hour() != 7 || !created.LastRunAt.IsZero() {
+		t.Fatalf("unexpected run times: next %s last %s", created.NextRunAt, created.LastRunAt)
+	}
+
+	ranAt := time.Date(2024, time.June, 12, 7, 0, 5, 0, time.UTC)
+	if err := s.MarkReportScheduleRun(created.ID, ranAt, created.NextRun(ranAt)); err != nil {
+		t.Fatalf("MarkReportScheduleRun: %v", err)
+	}
+	for i, status := range []ReportRunStatus{ReportRunSuccess, ReportRunFailed} {
+		if _, err := s.RecordReportRun(ReportRun{ScheduleID: created.ID, Trigger: "schedule", Status: status,
+			StartedAt: ranAt.Add(time.Duration(i) * time.Hour), FinishedAt: ranAt.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("RecordReportRun: %v", err)
+		}
+	}
+	runs, err := s.ListReportRuns(created.ID, 10)
+	if err != nil {
+		t.Fatalf("ListReportRuns: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Status != ReportRunFailed {
+		t.Fatalf("expected newest run first, got %+v", runs)
+	}
+
+	got, err := s.GetReportSchedule(created.ID)
+	if err != nil {
+		t.Fatalf("GetReportSchedule: %v", err)
+	}
+	if !got.LastRunAt.Equal(ranAt) || !got.NextRunAt.Equal(time.Date(2024, time.June, 13, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected run times: next %s last %s", got.NextRunAt, got.LastRunAt)
+	}
+
+	if err := s.DeleteReportSchedule(created.ID); err != nil {
+		t.Fatalf("DeleteReportSchedule: %v", err)
+	}
+	if _, err := s.GetReportSchedule(created.ID); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if runs, _ := s.ListReportRuns(created.ID, 10); len(runs) != 0 {
+		t.Fatalf("expected run history to be deleted, got %d runs", len(runs))
+	}
+}
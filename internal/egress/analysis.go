@@ -0,0 +1,145 @@
+package egress
+
+import (
+	"sort"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+const gibibyte = 1 << 30
+
+// IsExternal reports whether an edge's destination is outside the cluster's pods.
+func IsExternal(e store.NetworkEdge) bool {
+	if e.DstKind != "" {
+		return e.DstKind == "external"
+	}
+	return e.DstNamespace == "" && e.DstPodName == ""
+}
+
+// Annotate classifies the destination of every external edge and flags NAT hairpins: edges to
+// a public address that is the cluster's own, either by a cluster rule or because the agents
+// know the address as a node or a Service (load balancer) endpoint. Public clients sending
+// into the cluster are not cluster addresses, so they are never inferred from ingress traffic.
+func (c *Classifier) Annotate(edges []store.NetworkEdge) {
+	if c == nil {
+		return
+	}
+	clusterIPs := ClusterIPs{}
+	for _, e := range edges {
+		clusterIPs.Observe(e)
+	}
+	for i := range edges {
+		c.AnnotateEdge(&edges[i], clusterIPs)
+	}
+}
+
+// ClusterIPs are the addresses the agents know as nodes or Service endpoints of the cluster.
+type ClusterIPs map[string]bool
+
+// Observe records the cluster addresses an edge reveals.
+func (ips ClusterIPs) Observe(e store.NetworkEdge) {
+	if e.SrcIP != "" && e.SrcNodeName != "" && e.SrcPodName == "" {
+		ips[e.SrcIP] = true
+	}
+	if e.DstIP != "" && ((e.DstNodeName != "" && e.DstPodName == "") || e.DstServices != "") {
+		ips[e.DstIP] = true
+	}
+}
+
+// AnnotateEdge annotates one edge as Annotate does, with the cluster addresses observed on
+// all edges of the topology. Callers streaming edges collect clusterIPs in a first pass.
+func (c *Classifier) AnnotateEdge(e *store.NetworkEdge, clusterIPs ClusterIPs) {
+	if c == nil || !IsExternal(*e) {
+		return
+	}
+	dst := c.Classify(e.DstIP, e.DstDNSName)
+	e.DstProvider, e.DstProviderService = dst.Provider, dst.Service
+	if IsPublic(e.DstIP) {
+		e.Hairpin = dst.Cluster || clusterIPs[e.DstIP]
+	} else if e.DstIP == "" {
+		e.Hairpin = dst.Cluster
+	}
+}
+
+// ProviderGroup aggregates external edges to one provider service.
+type ProviderGroup struct {
+	Provider     string   `json:"provider"`
+	Service      string   `json:"service"`
+	BytesSent    int64    `json:"bytesSent"`
+	BytesRecv    int64    `json:"bytesReceived"`
+	Connections  int64    `json:"connectionCount"`
+	Edges        int      `json:"edges"`
+	EgressCost   float64  `json:"egressCostUsd"`
+	HairpinBytes int64    `json:"hairpinBytes"`
+	Namespaces   []string `json:"namespaces"`
+	Destinations []string `json:"destinations"`
+}
+
+// maxGroupDestinations bounds the example destinations listed per group.
+const maxGroupDestinations = 10
+
+// GroupByProvider aggregates annotated external edges by provider and service, most egress
+// first. Egress is bytes sent priced at the public rate; private destinations are free.
+func GroupByProvider(edges []store.NetworkEdge) []ProviderGroup {
+	type groupKey struct{ provider, service string }
+	groups := make(map[groupKey]*ProviderGroup)
+	namespaces := make(map[groupKey]map[string]bool)
+	destinations := make(map[groupKey]map[string]bool)
+	for _, e := range edges {
+		if !IsExternal(e) || e.DstProvider == "" {
+			continue
+		}
+		key := groupKey{e.DstProvider, e.DstProviderService}
+		g := groups[key]
+		if g == nil {
+			g = &ProviderGroup{Provider: key.provider, Service: key.service}
+			groups[key] = g
+			namespaces[key] = map[string]bool{}
+			destinations[key] = map[string]bool{}
+		}
+		g.BytesSent += e.BytesSent
+		g.BytesRecv += e.BytesReceived
+		g.Connections += e.ConnectionCount
+		g.Edges++
+		if e.DstProvider != "Private" {
+			g.EgressCost += float64(e.BytesSent) / gibibyte * store.CostEgressPublic
+		}
+		if e.Hairpin {
+			g.HairpinBytes += e.BytesSent + e.BytesReceived
+		}
+		if e.SrcNamespace != "" {
+			namespaces[key][e.SrcNamespace] = true
+		}
+		if dst := e.DstDNSName; dst != "" {
+			destinations[key][dst] = true
+		} else if e.DstIP != "" {
+			destinations[key][e.DstIP] = true
+		}
+	}
+
+	out := make([]ProviderGroup, 0, len(groups))
+	for key, g := range groups {
+		g.Namespaces = sortedKeys(namespaces[key], 0)
+		g.Destinations = sortedKeys(destinations[key], maxGroupDestinations)
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].BytesSent != out[j].BytesSent {
+			return out[i].BytesSent > out[j].BytesSent
+		}
+		return out[i].Provider+out[i].Service < out[j].Provider+out[j].Service
+	})
+	return out
+}
+
+func sortedKeys(set map[string]bool, limit int) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,263 @@
+// Package egress classifies network destinations by provider and service and detects
+// NAT hairpinning: traffic that leaves through a public address only to come back in.
+package egress
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed data/rules.yaml
+var bundled embed.FS
+
+// Rule maps CIDRs and DNS suffixes to a provider and service. Cluster marks destinations that
+// are the cluster itself (ingress load balancers, node Elastic IPs) for hairpin detection.
+type Rule struct {
+	Provider    string   `yaml:"provider"`
+	Service     string   `yaml:"service"`
+	CIDRs       []string `yaml:"cidrs"`
+	DNSSuffixes []string `yaml:"dnsSuffixes"`
+	Cluster     bool     `yaml:"cluster"`
+}
+
+// RuleSet is the YAML layout of a rules file.
+type RuleSet struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Destination is the outcome of classifying an endpoint. MatchedBy is "dns", "cidr" or empty
+// when no rule matched.
+type Destination struct {
+	Provider  string `json:"provider"`
+	Service   string `json:"service"`
+	Cluster   bool   `json:"cluster"`
+	MatchedBy string `json:"matchedBy,omitempty"`
+}
+
+type cidrRule struct {
+	prefix netip.Prefix
+	rule   *Rule
+}
+
+type suffixRule struct {
+	labels []string
+	rule   *Rule
+}
+
+// Classifier matches endpoints against rule sets. It is safe for concurrent use.
+type Classifier struct {
+	cidrs    []cidrRule
+	suffixes []suffixRule
+}
+
+// New builds a classifier. Later rules win over earlier ones on equally specific matches.
+func New(rules []Rule) (*Classifier, error) {
+	c := &Classifier{}
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Provider == "" {
+			return nil, fmt.Errorf("rule %d: provider is required", i)
+		}
+		if rule.Service == "" {
+			rule.Service = rule.Provider
+		}
+		for _, raw := range rule.CIDRs {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("rule %d (%s): %w", i, rule.Provider, err)
+			}
+			c.cidrs = append(c.cidrs, cidrRule{prefix: prefix.Masked(), rule: rule})
+		}
+		for _, raw := range rule.DNSSuffixes {
+			suffix := strings.Trim(strings.ToLower(strings.TrimSpace(raw)), ".")
+			if suffix == "" {
+				continue
+			}
+			c.suffixes = append(c.suffixes, suffixRule{labels: strings.Split(suffix, "."), rule: rule})
+		}
+	}
+	// Most specific first; the stable sort keeps later rules ahead after the reversal
+	slices.Reverse(c.cidrs)
+	sort.SliceStable(c.cidrs, func(i, j int) bool { return c.cidrs[i].prefix.Bits() > c.cidrs[j].prefix.Bits() })
+	slices.Reverse(c.suffixes)
+	sort.SliceStable(c.suffixes, func(i, j int) bool { return len(c.suffixes[i].labels) > len(c.suffixes[j].labels) })
+	return c, nil
+}
+
+// Load builds a classifier from the bundled rules, followed by the optional AWS ip-ranges.json
+// (https://ip-ranges.amazonaws.com/ip-ranges.json) and rules file, which take precedence. AWS
+// address ranges change weekly, so none are bundled: without the file AWS destinations are
+// only recognized by DNS name.
+func Load(rulesFile, awsIPRangesFile string) (*Classifier, error) {
+	raw, err := bundled.ReadFile("data/rules.yaml")
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ParseRules(raw)
+	if err != nil {
+		return nil, fmt.Errorf("bundled rules: %w", err)
+	}
+
+	if awsIPRangesFile != "" {
+		raw, err := os.ReadFile(filepath.Clean(awsIPRangesFile)) // #nosec G304 -- operator supplied path
+		if err != nil {
+			return nil, fmt.Errorf("read aws ip ranges: %w", err)
+		}
+		aws, err := ParseAWSIPRanges(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse aws ip ranges: %w", err)
+		}
+		rules = append(rules, aws...)
+	}
+	if rulesFile != "" {
+		raw, err := os.ReadFile(filepath.Clean(rulesFile)) // #nosec G304 -- operator supplied path
+		if err != nil {
+			return nil, fmt.Errorf("read egress rules: %w", err)
+		}
+		set, err := ParseRules(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse egress rules: %w", err)
+		}
+		rules = append(rules, set...)
+	}
+	return New(rules)
+}
+
+// ParseRules decodes a YAML rules file.
+func ParseRules(raw []byte) ([]Rule, error) {
+	var set RuleSet
+	if err := yaml.Unmarshal(raw, &set); err != nil {
+		return nil, err
+	}
+	return set.Rules, nil
+}
+
+// awsServiceNames maps ip-ranges.json service codes to display names.
+var awsServiceNames = map[string]string{
+	"AMAZON":                   "AWS",
+	"EC2":                      "EC2",
+	"S3":                       "S3",
+	"DYNAMODB":                 "DynamoDB",
+	"CLOUDFRONT":               "CloudFront",
+	"CLOUDFRONT_ORIGIN_FACING": "CloudFront",
+	"API_GATEWAY":              "API Gateway",
+	"ROUTE53":                  "Route 53",
+	"GLOBALACCELERATOR":        "Global Accelerator",
+}
+
+// ParseAWSIPRanges converts AWS ip-ranges.json into rules. The catch-all AMAZON entry of a
+// prefix is dropped when a specific service lists the same prefix.
+func ParseAWSIPRanges(raw []byte) ([]Rule, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPPrefix string `json:"ip_prefix"`
+			Service  string `json:"service"`
+		} `json:"prefixes"`
+		IPv6Prefixes []struct {
+			IPv6Prefix string `json:"ipv6_prefix"`
+			Service    string `json:"service"`
+		} `json:"ipv6_prefixes"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	services := make(map[string]string)
+	add := func(prefix, service string) {
+		if prefix == "" {
+			return
+		}
+		if existing, ok := services[prefix]; ok && service == "AMAZON" && existing != "AMAZON" {
+			return
+		}
+		services[prefix] = service
+	}
+	for _, p := range doc.Prefixes {
+		add(p.IPPrefix, p.Service)
+	}
+	for _, p := range doc.IPv6Prefixes {
+		add(p.IPv6Prefix, p.Service)
+	}
+
+	byService := make(map[string][]string)
+	for prefix, service := range services {
+		byService[service] = append(byService[service], prefix)
+	}
+	codes := make([]string, 0, len(byService))
+	for code := range byService {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	rules := make([]Rule, 0, len(codes))
+	for _, code := range codes {
+		name, ok := awsServiceNames[code]
+		if !ok {
+			name = code
+		}
+		prefixes := byService[code]
+		sort.Strings(prefixes)
+		rules = append(rules, Rule{Provider: "AWS", Service: name, CIDRs: prefixes})
+	}
+	return rules, nil
+}
+
+// Classify matches a DNS name first, then the IP. Unmatched public addresses return
+// provider "Internet"; an empty Destination means neither could be parsed.
+func (c *Classifier) Classify(ip, dnsName string) Destination {
+	if c == nil {
+		return Destination{}
+	}
+	if name := strings.Trim(strings.ToLower(dnsName), "."); name != "" {
+		labels := strings.Split(name, ".")
+		for _, s := range c.suffixes {
+			if matchSuffix(labels, s.labels) {
+				return Destination{Provider: s.rule.Provider, Service: s.rule.Service, Cluster: s.rule.Cluster, MatchedBy: "dns"}
+			}
+		}
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Destination{}
+	}
+	addr = addr.Unmap()
+	for _, r := range c.cidrs {
+		if r.prefix.Contains(addr) {
+			return Destination{Provider: r.rule.Provider, Service: r.rule.Service, Cluster: r.rule.Cluster, MatchedBy: "cidr"}
+		}
+	}
+	return Destination{Provider: "Internet", Service: "Unknown"}
+}
+
+// IsPublic reports whether ip is a globally routable unicast address.
+func IsPublic(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the RFC 6598 carrier-grade NAT range, used by some CNIs for pods.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func matchSuffix(name, suffix []string) bool {
+	if len(suffix) > len(name) {
+		return false
+	}
+	offset := len(name) - len(suffix)
+	for i, label := range suffix {
+		if label != "*" && label != name[offset+i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package egress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+const testAWSIPRanges = "testdata/aws-ip-ranges.json"
+
+func TestClassifyBundledRules(t *testing.T) {
+	c, err := Load("", testAWSIPRanges)
+	if err != nil {
+		t.Fatalf("load bundled rules: %v", err)
+	}
+	cases := []struct {
+		ip, dns           string
+		provider, service string
+	}{
+		{"", "my-bucket.s3.eu-west-1.amazonaws.com", "AWS", "S3"},
+		{"", "ec2.eu-west-1.amazonaws.com", "AWS", "AWS"},
+		{"", "oauth2.googleapis.com", "Google", "Google APIs"},
+		{"", "tenant.eu.auth0.com", "Auth0", "Auth0"},
+		{"52.217.1.10", "", "AWS", "S3"},
+		{"13.33.0.1", "", "AWS", "CloudFront"},
+		{"2600:1f18::1", "", "AWS", "EC2"},
+		{"10.1.2.3", "", "Private", "Private network"},
+		{"203.0.113.9", "", "Internet", "Unknown"},
+	}
+	for _, tc := range cases {
+		got := c.Classify(tc.ip, tc.dns)
+		if got.Provider != tc.provider || got.Service != tc.service {
+			t.Errorf("%s %s: expected %s/%s, got %s/%s", tc.ip, tc.dns, tc.provider, tc.service, got.Provider, got.Service)
+		}
+	}
+}
+
+func TestLoadRulesFileTakesPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rules, []byte(`rules:
+  - provider: Cluster
+    service: Ingress
+    cluster: true
+    cidrs: [52.216.10.0/24]
+    dnsSuffixes: ["elb.*.amazonaws.com"]
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(rules, testAWSIPRanges)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := c.Classify("", "a1b2.elb.us-east-1.amazonaws.com"); got.Provider != "Cluster" || !got.Cluster {
+		t.Fatalf("expected user rule to override the bundled load balancer rule, got %+v", got)
+	}
+	if got := c.Classify("52.216.10.5", ""); got.Provider != "Cluster" {
+		t.Fatalf("expected the more specific user CIDR to win, got %+v", got)
+	}
+	if got := c.Classify("52.216.11.5", ""); got.Service != "S3" {
+		t.Fatalf("expected AWS S3 range outside the user CIDR, got %+v", got)
+	}
+
+	// No AWS ranges are bundled: without the file only DNS names identify AWS
+	c, err = Load("", "")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := c.Classify("52.216.11.5", ""); got.Provider != "Internet" {
+		t.Fatalf("expected no bundled AWS ranges, got %+v", got)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml"), ""); err == nil {
+		t.Fatal("expected error for missing rules file")
+	}
+}
+
+func TestAnnotateFlagsHairpinsAndGroupsProviders(t *testing.T) {
+	c, err := Load("", testAWSIPRanges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges := []store.NetworkEdge{
+		// frontend calls the cluster's own ingress through its public address...
+		{SrcNamespace: "web", SrcPodName: "frontend-1", SrcIP: "10.0.1.5", DstIP: "198.51.100.7", DstKind: "external", BytesSent: 3 << 30, BytesReceived: 1 << 30},
+		// ...which the agents know as the ingress load balancer Service
+		{SrcIP: "203.0.113.50", DstIP: "198.51.100.7", DstServices: "ingress/nginx", DstKind: "service", BytesSent: 1 << 30},
+		{SrcNamespace: "web", SrcPodName: "frontend-1", SrcIP: "10.0.1.5", DstIP: "52.216.4.4", DstDNSName: "assets.s3.amazonaws.com", DstKind: "external", BytesSent: 2 << 30, ConnectionCount: 4},
+		{SrcNamespace: "jobs", SrcPodName: "export-1", SrcIP: "10.0.3.1", DstIP: "52.216.9.9", DstKind: "external", BytesSent: 1 << 30, ConnectionCount: 1},
+	}
+	c.Annotate(edges)
+
+	if !edges[0].Hairpin || edges[0].DstProvider != "Internet" {
+		t.Fatalf("expected hairpin to the ingress address, got %+v", edges[0])
+	}
+	if edges[1].DstProvider != "" || edges[1].Hairpin {
+		t.Fatalf("in-cluster edges must not be classified, got %+v", edges[1])
+	}
+	if edges[2].Hairpin || edges[2].DstProviderService != "S3" || edges[3].DstProviderService != "S3" {
+		t.Fatalf("expected S3 edges, got %+v / %+v", edges[2], edges[3])
+	}
+
+	groups := GroupByProvider(edges)
+	if len(groups) != 2 {
+		t.Fatalf("expected two provider groups, got %+v", groups)
+	}
+	// Equal egress sorts by name
+	s3, internet := groups[0], groups[1]
+	if internet.Provider != "Internet" || internet.HairpinBytes != 4<<30 {
+		t.Fatalf("unexpected hairpin group %+v", internet)
+	}
+	if s3.Service != "S3" || s3.BytesSent != 3<<30 || s3.Edges != 2 || s3.Connections != 5 || len(s3.Namespaces) != 2 {
+		t.Fatalf("unexpected S3 group %+v", s3)
+	}
+	if s3.EgressCost != 3*store.CostEgressPublic {
+		t.Fatalf("expected 3 GiB of public egress, got %.4f", s3.EgressCost)
+	}
+}
+
+func TestAnnotateDoesNotTreatPublicClientsAsCluster(t *testing.T) {
+	c, err := Load("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges := []store.NetworkEdge{
+		// A public client calls into a pod, and the pod later calls that client's address
+		{SrcIP: "203.0.113.50", DstNamespace: "api", DstPodName: "api-1", DstIP: "10.0.2.9", DstKind: "pod", BytesSent: 1 << 30},
+		{SrcNamespace: "api", SrcPodName: "api-1", SrcIP: "10.0.2.9", DstIP: "203.0.113.50", DstKind: "external", BytesSent: 1 << 30},
+		// A node's public address is the cluster's own
+		{SrcNodeName: "node-a", SrcIP: "198.51.100.8", DstIP: "10.0.2.9", DstKind: "pod"},
+		{SrcNamespace: "api", SrcPodName: "api-1", SrcIP: "10.0.2.9", DstIP: "198.51.100.8", DstKind: "external", BytesSent: 1 << 30},
+	}
+	c.Annotate(edges)
+	if edges[1].Hairpin {
+		t.Fatalf("expected traffic to a public client not to be a hairpin, got %+v", edges[1])
+	}
+	if !edges[3].Hairpin {
+		t.Fatalf("expected traffic to a node address to be a hairpin, got %+v", edges[3])
+	}
+}
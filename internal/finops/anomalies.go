@@ -0,0 +1,199 @@
+package finops
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+const (
+	// seasonLength is the seasonal period: the same hour and weekday one week earlier.
+	seasonLength = 7 * 24 * time.Hour
+	// defaultAnomalySeasons is how many previous weeks form the baseline.
+	defaultAnomalySeasons = 4
+)
+
+// AnomalyOptions tunes when a deviation counts as an anomaly.
+type AnomalyOptions struct {
+	// MinScore is the minimum |z-score| against the seasonal spread.
+	MinScore float64
+	// MinDeviationPercent ignores small relative changes on stable namespaces.
+	MinDeviationPercent float64
+	// MinHourlyDelta ignores changes worth less than this many dollars per hour.
+	MinHourlyDelta float64
+	// MinSeasons is the number of baseline weeks required before scoring a namespace.
+	MinSeasons int
+}
+
+// DefaultAnomalyOptions flags 3-sigma deviations of at least 25% and one cent per hour.
+func DefaultAnomalyOptions() AnomalyOptions {
+	return AnomalyOptions{
+		MinScore:            3,
+		MinDeviationPercent: 25,
+		MinHourlyDelta:      0.01,
+		MinSeasons:          2,
+	}
+}
+
+// ScoreAgainstBaseline compares actual spend with the seasonal history for the same hour.
+// The spread is floored at 10% of the expected value so perfectly flat histories do not
+// turn every cent of change into an infinite score.
+func ScoreAgainstBaseline(actual float64, history []float64, opts AnomalyOptions) (expected, score, deviationPercent float64, anomalous bool) {
+	if len(history) == 0 || len(history) < opts.MinSeasons {
+		return 0, 0, 0, false
+	}
+	for _, v := range history {
+		expected += v
+	}
+	expected /= float64(len(history))
+
+	var variance float64
+	for _, v := range history {
+		variance += (v - expected) * (v - expected)
+	}
+	stddev := math.Sqrt(variance / float64(len(history)))
+	spread := math.Max(stddev, math.Max(0.1*expected, 0.001))
+
+	delta := actual - expected
+	score = delta / spread
+	if expected > 0 {
+		deviationPercent = delta / expected * 100
+	} else if actual > 0 {
+		deviationPercent = 100
+	}
+
+	anomalous = math.Abs(score) >= opts.MinScore &&
+		math.Abs(deviationPercent) >= opts.MinDeviationPercent &&
+		math.Abs(delta) >= opts.MinHourlyDelta
+	return expected, score, deviationPercent, anomalous
+}
+
+// AnomalySource provides namespace cost history.
+type AnomalySource interface {
+	NamespaceHourlyCosts(ctx context.Context, window, offset time.Duration) ([]vm.NamespaceCost, error)
+}
+
+// AnomalyDetector periodically scores namespace spend against its seasonal baseline and stores anomalies.
+type AnomalyDetector struct {
+	db       *db.Store
+	source   AnomalySource
+	interval time.Duration
+	seasons  int
+	opts     AnomalyOptions
+	logger   *log.Logger
+	now      func() time.Time
+}
+
+// NewAnomalyDetector creates a detector. A zero interval disables the periodic loop.
+func NewAnomalyDetector(database *db.Store, source AnomalySource, interval time.Duration, logger *log.Logger) *AnomalyDetector {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &AnomalyDetector{
+		db:       database,
+		source:   source,
+		interval: interval,
+		seasons:  defaultAnomalySeasons,
+		opts:     DefaultAnomalyOptions(),
+		logger:   logger,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run detects anomalies every interval until ctx is cancelled.
+func (d *AnomalyDetector) Run(ctx context.Context) {
+	if d.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DetectOnce(ctx); err != nil {
+				d.logger.Printf("anomaly detection failed: %v", err)
+			}
+		}
+	}
+}
+
+// DetectOnce scores the last hour of spend for every namespace and stores anomalies. A
+// namespace with baseline history but no spend in the last hour is scored as zero.
+func (d *AnomalyDetector) DetectOnce(ctx context.Context) ([]store.Anomaly, error) {
+	now := d.now()
+	current, err := d.source.NamespaceHourlyCosts(ctx, time.Hour, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ cluster, namespace string }
+	actual := make(map[key]float64, len(current))
+	for _, entry := range current {
+		actual[key{entry.ClusterID, entry.Namespace}] = entry.HourlyCost
+	}
+	history := make(map[key][]float64, len(current))
+	for season := 1; season <= d.seasons; season++ {
+		past, err := d.source.NamespaceHourlyCosts(ctx, time.Hour, time.Duration(season)*seasonLength)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range past {
+			k := key{entry.ClusterID, entry.Namespace}
+			history[k] = append(history[k], entry.HourlyCost)
+		}
+	}
+
+	// Score every namespace seen now or in the baseline: one that stopped reporting spend
+	// entirely has dropped to zero, which is the largest drop there is.
+	keys := make([]key, 0, len(history))
+	for k := range history {
+		keys = append(keys, k)
+	}
+	for k := range actual {
+		if _, ok := history[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].cluster != keys[j].cluster {
+			return keys[i].cluster < keys[j].cluster
+		}
+		return keys[i].namespace < keys[j].namespace
+	})
+
+	var found []store.Anomaly
+	for _, k := range keys {
+		expected, score, deviation, anomalous := ScoreAgainstBaseline(actual[k], history[k], d.opts)
+		if !anomalous {
+			continue
+		}
+		direction := store.AnomalySpike
+		if score < 0 {
+			direction = store.AnomalyDrop
+		}
+		anomaly := store.Anomaly{
+			ClusterID:          k.cluster,
+			Namespace:          k.namespace,
+			WindowStart:        now.Truncate(time.Hour),
+			DetectedAt:         now,
+			ExpectedHourlyCost: expected,
+			ActualHourlyCost:   actual[k],
+			DeviationPercent:   deviation,
+			Score:              score,
+			Direction:          direction,
+		}
+		if err := d.db.SaveAnomaly(anomaly); err != nil {
+			return found, err
+		}
+		found = append(found, anomaly)
+	}
+	return found, nil
+}
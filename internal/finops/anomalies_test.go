@@ -0,0 +1,110 @@
+package finops
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+func TestScoreAgainstBaseline(t *testing.T) {
+	opts := DefaultAnomalyOptions()
+
+	expected, score, deviation, anomalous := ScoreAgainstBaseline(4, []float64{1, 1.1, 0.9, 1}, opts)
+	if !anomalous || score <= 0 {
+		t.Fatalf("expected spike, got score %f anomalous %v", score, anomalous)
+	}
+	if expected != 1 || deviation != 300 {
+		t.Fatalf("expected baseline 1 and +300%%, got %f and %f", expected, deviation)
+	}
+
+	if _, _, _, anomalous := ScoreAgainstBaseline(1.05, []float64{1, 1.1, 0.9, 1}, opts); anomalous {
+		t.Fatal("expected normal variation not to be flagged")
+	}
+	if _, _, _, anomalous := ScoreAgainstBaseline(4, []float64{1}, opts); anomalous {
+		t.Fatal("expected too little history not to be flagged")
+	}
+	if _, score, _, anomalous := ScoreAgainstBaseline(0.1, []float64{2, 2, 2}, opts); !anomalous || score >= 0 {
+		t.Fatalf("expected drop, got score %f anomalous %v", score, anomalous)
+	}
+}
+
+type fakeCostHistory map[time.Duration][]vm.NamespaceCost
+
+func (f fakeCostHistory) NamespaceHourlyCosts(_ context.Context, _ time.Duration, offset time.Duration) ([]vm.NamespaceCost, error) {
+	return f[offset], nil
+}
+
+func TestAnomalyDetectorStoresAnomalies(t *testing.T) {
+	sqlite, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer func() { _ = sqlite.Close() }()
+
+	week := seasonLength
+	source := fakeCostHistory{
+		0:        {{ClusterID: "c1", Namespace: "ml", HourlyCost: 10}, {ClusterID: "c1", Namespace: "web", HourlyCost: 1}},
+		week:     {{ClusterID: "c1", Namespace: "ml", HourlyCost: 2}, {ClusterID: "c1", Namespace: "web", HourlyCost: 1}},
+		2 * week: {{ClusterID: "c1", Namespace: "ml", HourlyCost: 2.2}, {ClusterID: "c1", Namespace: "web", HourlyCost: 1}},
+		3 * week: {{ClusterID: "c1", Namespace: "ml", HourlyCost: 1.8}},
+	}
+	detector := NewAnomalyDetector(sqlite, source, 0, nil)
+	now := time.Date(2024, time.June, 10, 14, 25, 0, 0, time.UTC)
+	detector.now = func() time.Time { return now }
+
+	found, err := detector.DetectOnce(context.Background())
+	if err != nil {
+		t.Fatalf("DetectOnce: %v", err)
+	}
+	if len(found) != 1 || found[0].Namespace != "ml" || found[0].Direction != store.AnomalySpike {
+		t.Fatalf("expected one ml spike, got %+v", found)
+	}
+
+	// Re-running within the same hour updates instead of duplicating
+	if _, err := detector.DetectOnce(context.Background()); err != nil {
+		t.Fatalf("DetectOnce: %v", err)
+	}
+	stored, err := sqlite.ListAnomalies(store.AnomalyFilter{Since: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("ListAnomalies: %v", err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("expected 1 stored anomaly, got %d", len(stored))
+	}
+	got := stored[0]
+	if got.ExpectedHourlyCost != 2 || got.ActualHourlyCost != 10 || !got.WindowStart.Equal(now.Truncate(time.Hour)) {
+		t.Fatalf("unexpected stored anomaly: %+v", got)
+	}
+	if other, _ := sqlite.ListAnomalies(store.AnomalyFilter{Namespace: "web"}); len(other) != 0 {
+		t.Fatalf("expected no anomalies for web, got %+v", other)
+	}
+}
+
+func TestAnomalyDetectorFlagsNamespacesThatStoppedSpending(t *testing.T) {
+	sqlite, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer func() { _ = sqlite.Close() }()
+
+	week := seasonLength
+	source := fakeCostHistory{
+		0:        {{ClusterID: "c1", Namespace: "web", HourlyCost: 1}},
+		week:     {{ClusterID: "c1", Namespace: "batch", HourlyCost: 3}, {ClusterID: "c1", Namespace: "web", HourlyCost: 1}},
+		2 * week: {{ClusterID: "c1", Namespace: "batch", HourlyCost: 3.2}, {ClusterID: "c1", Namespace: "web", HourlyCost: 1}},
+	}
+	detector := NewAnomalyDetector(sqlite, source, 0, nil)
+
+	found, err := detector.DetectOnce(context.Background())
+	if err != nil {
+		t.Fatalf("DetectOnce: %v", err)
+	}
+	if len(found) != 1 || found[0].Namespace != "batch" || found[0].Direction != store.AnomalyDrop || found[0].ActualHourlyCost != 0 {
+		t.Fatalf("expected a batch drop to zero, got %+v", found)
+	}
+}
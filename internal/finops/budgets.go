@@ -0,0 +1,119 @@
+package finops
+
+import (
+	"context"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// BudgetStatus summarizes how a budget is tracking against its limit.
+type BudgetStatus string
+
+const (
+	BudgetOK     BudgetStatus = "ok"
+	BudgetAtRisk BudgetStatus = "at-risk"
+	BudgetOver   BudgetStatus = "over"
+	// BudgetUnknown marks a budget whose spend could not be evaluated; see BudgetEvaluation.Error
+	BudgetUnknown BudgetStatus = "unknown"
+)
+
+// BudgetEvaluation is a budget plus its month-to-date spend and end-of-month forecast.
+type BudgetEvaluation struct {
+	db.Budget
+	PeriodStart     time.Time    `json:"periodStart"`
+	PeriodEnd       time.Time    `json:"periodEnd"`
+	MonthToDateCost float64      `json:"monthToDateCost"`
+	ForecastCost    float64      `json:"forecastCost"`
+	HourlyBurnRate  float64      `json:"hourlyBurnRate"`
+	UsedPercent     float64      `json:"usedPercent"`
+	ForecastPercent float64      `json:"forecastPercent"`
+	Status          BudgetStatus `json:"status"`
+	Error           string       `json:"error,omitempty"`
+}
+
+// FailedBudgetEvaluation reports a budget whose spend could not be loaded, so a list can
+// still show it alongside the budgets that evaluated.
+func FailedBudgetEvaluation(b db.Budget, now time.Time, err error) BudgetEvaluation {
+	start, end := MonthBounds(now)
+	return BudgetEvaluation{Budget: b, PeriodStart: start, PeriodEnd: end, Status: BudgetUnknown, Error: err.Error()}
+}
+
+// CostSource reports accumulated spend for a set of namespace series labels.
+type CostSource interface {
+	CostSince(ctx context.Context, since time.Time, scope map[string]string) (float64, error)
+	ValidateGroupLabel(key string) error
+}
+
+// EvaluateBudgetSpend loads month-to-date spend for the budget scope and evaluates it.
+// Missing data counts as zero spend so new budgets still render; a label the namespace series
+// do not carry is an error rather than a budget that never spends.
+func EvaluateBudgetSpend(ctx context.Context, src CostSource, b db.Budget, now time.Time) (BudgetEvaluation, error) {
+	if b.Scope == db.BudgetScopeLabel {
+		if err := src.ValidateGroupLabel(b.LabelKey); err != nil {
+			return BudgetEvaluation{}, err
+		}
+	}
+	ctx = vm.WithClusterID(ctx, b.ClusterID)
+	start, _ := MonthBounds(now)
+	spent, err := src.CostSince(ctx, start, BudgetScopeLabels(b))
+	if err != nil && err != vm.ErrNoData {
+		return BudgetEvaluation{}, err
+	}
+	return EvaluateBudget(b, spent, now), nil
+}
+
+// MonthBounds returns the start of the UTC calendar month containing now and the start of the next one.
+func MonthBounds(now time.Time) (start, end time.Time) {
+	now = now.UTC()
+	start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// BudgetScopeLabels maps a budget scope to the namespace series labels it covers. Label
+// budgets must use a key CostSource.ValidateGroupLabel accepts.
+func BudgetScopeLabels(b db.Budget) map[string]string {
+	switch b.Scope {
+	case db.BudgetScopeNamespace:
+		return map[string]string{"namespace": b.Namespace}
+	case db.BudgetScopeLabel:
+		return map[string]string{b.LabelKey: b.LabelValue}
+	default:
+		return nil
+	}
+}
+
+// EvaluateBudget projects month-to-date spend linearly to the end of the month.
+// Status is over once spend reaches the limit, and at-risk when either spend crosses the
+// alert threshold or the current burn rate would exceed the limit before month end.
+func EvaluateBudget(b db.Budget, monthToDate float64, now time.Time) BudgetEvaluation {
+	start, end := MonthBounds(now)
+	elapsed := now.UTC().Sub(start).Hours()
+	total := end.Sub(start).Hours()
+
+	eval := BudgetEvaluation{
+		Budget:          b,
+		PeriodStart:     start,
+		PeriodEnd:       end,
+		MonthToDateCost: monthToDate,
+		ForecastCost:    monthToDate,
+		Status:          BudgetOK,
+	}
+	if elapsed > 0 {
+		eval.HourlyBurnRate = monthToDate / elapsed
+		eval.ForecastCost = eval.HourlyBurnRate * total
+	}
+	if b.MonthlyLimit > 0 {
+		eval.UsedPercent = monthToDate / b.MonthlyLimit * 100
+		eval.ForecastPercent = eval.ForecastCost / b.MonthlyLimit * 100
+	}
+
+	switch {
+	case b.MonthlyLimit > 0 && monthToDate >= b.MonthlyLimit:
+		eval.Status = BudgetOver
+	case eval.UsedPercent >= b.AlertThresholdPercent || eval.ForecastCost > b.MonthlyLimit:
+		eval.Status = BudgetAtRisk
+	}
+	return eval
+}
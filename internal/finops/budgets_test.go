@@ -0,0 +1,55 @@
+package finops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+)
+
+func TestEvaluateBudget(t *testing.T) {
+	// 10 days into a 30 day month
+	now := time.Date(2024, time.June, 11, 0, 0, 0, 0, time.UTC)
+	budget := db.Budget{Name: "team", Scope: db.BudgetScopeCluster, MonthlyLimit: 900, AlertThresholdPercent: 80}
+
+	cases := []struct {
+		name     string
+		spent    float64
+		forecast float64
+		status   BudgetStatus
+	}{
+		{"on track", 200, 600, BudgetOK},
+		{"forecast over limit", 400, 1200, BudgetAtRisk},
+		{"threshold crossed", 750, 2250, BudgetAtRisk},
+		{"limit reached", 900, 2700, BudgetOver},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eval := EvaluateBudget(budget, tc.spent, now)
+			if eval.Status != tc.status {
+				t.Fatalf("expected status %s, got %s", tc.status, eval.Status)
+			}
+			if diff := eval.ForecastCost - tc.forecast; diff < -1e-6 || diff > 1e-6 {
+				t.Fatalf("expected forecast %f, got %f", tc.forecast, eval.ForecastCost)
+			}
+		})
+	}
+
+	eval := EvaluateBudget(budget, 300, now)
+	if !eval.PeriodStart.Equal(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)) || !eval.PeriodEnd.Equal(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected period %s - %s", eval.PeriodStart, eval.PeriodEnd)
+	}
+	if diff := eval.UsedPercent - 100.0/3; diff < -1e-9 || diff > 1e-9 {
+		t.Fatalf("expected used percent 33.3, got %f", eval.UsedPercent)
+	}
+}
+
+func TestBudgetScopeLabels(t *testing.T) {
+	if labels := BudgetScopeLabels(db.Budget{Scope: db.BudgetScopeCluster}); labels != nil {
+		t.Fatalf("expected no labels for cluster scope, got %v", labels)
+	}
+	labels := BudgetScopeLabels(db.Budget{Scope: db.BudgetScopeLabel, LabelKey: "environment", LabelValue: "production"})
+	if labels["environment"] != "production" {
+		t.Fatalf("expected environment label, got %v", labels)
+	}
+}
@@ -0,0 +1,406 @@
+package finops
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// maxConsolidationNodes bounds the node list loaded for a simulation.
+const maxConsolidationNodes = 10000
+
+// unattributedPod names the requests a node reports beyond the pods known on it.
+const unattributedPod = "(unattributed requests)"
+
+// ConsolidationOptions tunes the bin-packing simulation.
+type ConsolidationOptions struct {
+	// MaxUtilizationPercent caps how full (requests / allocatable) a node may be packed
+	MaxUtilizationPercent float64 `json:"maxUtilizationPercent"`
+	// MinNodes is the number of nodes that always stay
+	MinNodes int `json:"minNodes"`
+}
+
+// DefaultConsolidationOptions packs nodes up to 85% of allocatable and keeps at least one node.
+func DefaultConsolidationOptions() ConsolidationOptions {
+	return ConsolidationOptions{MaxUtilizationPercent: 85, MinNodes: 1}
+}
+
+// ConsolidationPod is a pod's footprint for the simulation. DaemonSet pods are not moved:
+// every node already runs its own copy.
+type ConsolidationPod struct {
+	Namespace          string
+	Name               string
+	CPURequestMilli    int64
+	MemoryRequestBytes int64
+	DaemonSet          bool
+}
+
+// ConsolidationNode is a node's capacity, price, taints ("key=value:Effect") and pods.
+// Requested totals above the sum of Pods are treated as one more pod that has to move.
+type ConsolidationNode struct {
+	Name                   string
+	ClusterID              string
+	InstanceType           string
+	HourlyCost             float64
+	AllocatableCPUMilli    int64
+	AllocatableMemoryBytes int64
+	RequestedCPUMilli      int64
+	RequestedMemoryBytes   int64
+	Taints                 []string
+	Pods                   []ConsolidationPod
+}
+
+// PodMove relocates one pod off a drained node.
+type PodMove struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// DrainableNode is a node whose pods all fit elsewhere.
+type DrainableNode struct {
+	Name           string    `json:"name"`
+	ClusterID      string    `json:"clusterId,omitempty"`
+	InstanceType   string    `json:"instanceType,omitempty"`
+	HourlyCost     float64   `json:"hourlyCost"`
+	MonthlySavings float64   `json:"monthlySavings"`
+	Moves          []PodMove `json:"moves"`
+}
+
+// BlockedNode is a node that could not be drained and why.
+type BlockedNode struct {
+	Name      string `json:"name"`
+	ClusterID string `json:"clusterId,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// ConsolidationPlan is the outcome of a first-fit-decreasing consolidation simulation.
+type ConsolidationPlan struct {
+	GeneratedAt    time.Time            `json:"generatedAt"`
+	Source         string               `json:"source"`
+	Options        ConsolidationOptions `json:"options"`
+	NodeCount      int                  `json:"nodeCount"`
+	RemainingNodes int                  `json:"remainingNodes"`
+	// Density is requests / allocatable averaged over CPU and memory, in percent
+	DensityScore          float64         `json:"densityScore"`
+	ProjectedDensityScore float64         `json:"projectedDensityScore"`
+	MonthlySavings        float64         `json:"monthlySavings"`
+	Drainable             []DrainableNode `json:"drainable"`
+	Blocked               []BlockedNode   `json:"blocked"`
+}
+
+// ConsolidationNodesFromStore builds simulation input from the latest agent reports.
+// Nodes are priced from the catalog using the instance type their agent reports. Node names
+// are only unique within a cluster, so pods and prices are matched on cluster and node. A
+// non-empty clusterID limits the nodes to that cluster, as ConsolidationNodesFromVM does.
+func (e *Engine) ConsolidationNodesFromStore(ctx context.Context, st *store.Store, clusterID string) ([]ConsolidationNode, error) {
+	list, err := st.NodeList(store.NodeFilter{Limit: maxConsolidationNodes})
+	if err != nil {
+		return nil, err
+	}
+	pods := groupPodContainers(st.GetAllPods())
+	byNode := make(map[string][]ConsolidationPod)
+	pricing := make(map[string]store.PodContext)
+	for _, pc := range pods {
+		if clusterID != "" && pc.ClusterID != clusterID {
+			continue
+		}
+		kind, _ := WorkloadOf(pc.Pod)
+		key := pc.ClusterID + "/" + pc.NodeName
+		byNode[key] = append(byNode[key], ConsolidationPod{
+			Namespace:          pc.Pod.Namespace,
+			Name:               pc.Pod.PodName,
+			CPURequestMilli:    int64(pc.Pod.Cpu.RequestMillicores),
+			MemoryRequestBytes: int64(pc.Pod.Memory.RequestBytes),
+			DaemonSet:          strings.EqualFold(kind, "DaemonSet"),
+		})
+		if pc.InstanceType != "" {
+			pricing[key] = pc
+		}
+	}
+
+	nodes := make([]ConsolidationNode, 0, len(list.Items))
+	for _, n := range list.Items {
+		if clusterID != "" && n.ClusterID != clusterID {
+			continue
+		}
+		key := n.ClusterID + "/" + n.NodeName
+		node := consolidationNode(n, byNode[key])
+		if pc, ok := pricing[key]; ok {
+			node.InstanceType = pc.InstanceType
+			if price := e.pricing.GetTotalNodePrice(ctx, pc.Region, pc.InstanceType); price > 0 {
+				node.HourlyCost = price
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// ConsolidationNodesFromVM builds simulation input from VictoriaMetrics node and pod series.
+func (e *Engine) ConsolidationNodesFromVM(ctx context.Context) ([]ConsolidationNode, error) {
+	list, err := e.vmClient.NodeList(ctx, store.NodeFilter{Limit: maxConsolidationNodes})
+	if err != nil {
+		return nil, err
+	}
+	placements, err := e.vmClient.PodPlacements(ctx)
+	if err != nil && err != vm.ErrNoData {
+		return nil, err
+	}
+	byNode := make(map[string][]ConsolidationPod)
+	for _, p := range placements {
+		byNode[p.Node] = append(byNode[p.Node], ConsolidationPod{
+			Namespace:          p.Namespace,
+			Name:               p.Pod,
+			CPURequestMilli:    int64(p.CPURequestMilli),
+			MemoryRequestBytes: int64(p.MemoryRequestBytes),
+			DaemonSet:          strings.EqualFold(p.WorkloadKind, "DaemonSet"),
+		})
+	}
+	nodes := make([]ConsolidationNode, 0, len(list.Items))
+	for _, n := range list.Items {
+		nodes = append(nodes, consolidationNode(n, byNode[n.NodeName]))
+	}
+	return nodes, nil
+}
+
+func consolidationNode(n store.NodeSummary, pods []ConsolidationPod) ConsolidationNode {
+	return ConsolidationNode{
+		Name:                   n.NodeName,
+		ClusterID:              n.ClusterID,
+		InstanceType:           n.InstanceType,
+		HourlyCost:             n.HourlyCost,
+		AllocatableCPUMilli:    n.CPUAllocatableMilli,
+		AllocatableMemoryBytes: n.MemoryAllocatableBytes,
+		RequestedCPUMilli:      n.CPURequestMilli,
+		RequestedMemoryBytes:   n.MemoryRequestBytes,
+		Taints:                 n.Taints,
+		Pods:                   pods,
+	}
+}
+
+type simNode struct {
+	ConsolidationNode
+	cpu, mem   int64
+	movable    []ConsolidationPod
+	received   bool
+	drained    bool
+	cpuCap     float64
+	memCap     float64
+	hardTaints map[string]bool
+}
+
+func (n *simNode) utilization() float64 {
+	return math.Max(fraction(n.cpu, n.AllocatableCPUMilli), fraction(n.mem, n.AllocatableMemoryBytes))
+}
+
+// SimulateConsolidation tries to drain the least utilized nodes one at a time, placing their
+// pods largest first onto the fullest remaining node with room (first-fit decreasing). A pod
+// is assumed to tolerate only the taints of the node it runs on, so it never moves to a node
+// with other NoSchedule/NoExecute taints, nor to another cluster. MinNodes applies per cluster.
+// Nodes that received pods are not drained themselves.
+func SimulateConsolidation(nodes []ConsolidationNode, opts ConsolidationOptions) ConsolidationPlan {
+	if opts.MaxUtilizationPercent <= 0 || opts.MaxUtilizationPercent > 100 {
+		opts.MaxUtilizationPercent = DefaultConsolidationOptions().MaxUtilizationPercent
+	}
+	if opts.MinNodes < 1 {
+		opts.MinNodes = 1
+	}
+	plan := ConsolidationPlan{
+		GeneratedAt: time.Now().UTC(),
+		Options:     opts,
+		NodeCount:   len(nodes),
+		Drainable:   []DrainableNode{},
+		Blocked:     []BlockedNode{},
+	}
+
+	sims := make([]*simNode, 0, len(nodes))
+	for _, n := range nodes {
+		s := &simNode{
+			ConsolidationNode: n,
+			cpuCap:            float64(n.AllocatableCPUMilli) * opts.MaxUtilizationPercent / 100,
+			memCap:            float64(n.AllocatableMemoryBytes) * opts.MaxUtilizationPercent / 100,
+			hardTaints:        hardTaints(n.Taints),
+		}
+		var podCPU, podMem int64
+		for _, p := range n.Pods {
+			podCPU += p.CPURequestMilli
+			podMem += p.MemoryRequestBytes
+			if !p.DaemonSet {
+				s.movable = append(s.movable, p)
+			}
+		}
+		s.cpu, s.mem = max(podCPU, n.RequestedCPUMilli), max(podMem, n.RequestedMemoryBytes)
+		if extraCPU, extraMem := s.cpu-podCPU, s.mem-podMem; extraCPU > 0 || extraMem > 0 {
+			s.movable = append(s.movable, ConsolidationPod{Name: unattributedPod, CPURequestMilli: extraCPU, MemoryRequestBytes: extraMem})
+		}
+		sims = append(sims, s)
+	}
+	plan.DensityScore = density(sims)
+
+	candidates := append([]*simNode(nil), sims...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].utilization() < candidates[j].utilization()
+	})
+	remaining := make(map[string]int)
+	for _, s := range sims {
+		remaining[s.ClusterID]++
+	}
+	for _, candidate := range candidates {
+		blocked := func(reason string) {
+			plan.Blocked = append(plan.Blocked, BlockedNode{Name: candidate.Name, ClusterID: candidate.ClusterID, Reason: reason})
+		}
+		if remaining[candidate.ClusterID] <= opts.MinNodes {
+			blocked(fmt.Sprintf("at least %d node(s) must remain", opts.MinNodes))
+			continue
+		}
+		if candidate.received {
+			blocked("receives pods from drained nodes")
+			continue
+		}
+		moves, reason := drain(candidate, sims)
+		if reason != "" {
+			blocked(reason)
+			continue
+		}
+		candidate.drained = true
+		remaining[candidate.ClusterID]--
+		savings := candidate.HourlyCost * store.BillingHoursPerMonth
+		plan.MonthlySavings += savings
+		plan.Drainable = append(plan.Drainable, DrainableNode{
+			Name:           candidate.Name,
+			ClusterID:      candidate.ClusterID,
+			InstanceType:   candidate.InstanceType,
+			HourlyCost:     candidate.HourlyCost,
+			MonthlySavings: math.Round(savings*100) / 100,
+			Moves:          moves,
+		})
+	}
+
+	for _, n := range remaining {
+		plan.RemainingNodes += n
+	}
+	plan.ProjectedDensityScore = density(sims)
+	plan.MonthlySavings = math.Round(plan.MonthlySavings*100) / 100
+	return plan
+}
+
+// drain places the candidate's movable pods on other nodes and commits the placement only
+// if every pod fits. It returns the moves, or why the node cannot be drained.
+func drain(candidate *simNode, sims []*simNode) ([]PodMove, string) {
+	pods := append([]ConsolidationPod(nil), candidate.movable...)
+	sort.SliceStable(pods, func(i, j int) bool {
+		return podSize(pods[i], candidate) > podSize(pods[j], candidate)
+	})
+
+	var targets []*simNode
+	for _, t := range sims {
+		if t == candidate || t.drained || t.ClusterID != candidate.ClusterID || !tolerates(candidate.hardTaints, t.hardTaints) {
+			continue
+		}
+		targets = append(targets, t)
+	}
+	// Fill the fullest nodes first so emptier ones stay drainable
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].utilization() > targets[j].utilization()
+	})
+
+	type placement struct {
+		target *simNode
+		pod    ConsolidationPod
+	}
+	var placed []placement
+	undo := func() {
+		for _, p := range placed {
+			p.target.cpu -= p.pod.CPURequestMilli
+			p.target.mem -= p.pod.MemoryRequestBytes
+		}
+	}
+	for _, pod := range pods {
+		var target *simNode
+		for _, t := range targets {
+			if float64(t.cpu+pod.CPURequestMilli) <= t.cpuCap && float64(t.mem+pod.MemoryRequestBytes) <= t.memCap {
+				target = t
+				break
+			}
+		}
+		if target == nil {
+			undo()
+			if len(targets) == 0 {
+				return nil, "no other node accepts its taints"
+			}
+			return nil, fmt.Sprintf("%s does not fit on the remaining nodes", podLabel(pod))
+		}
+		target.cpu += pod.CPURequestMilli
+		target.mem += pod.MemoryRequestBytes
+		placed = append(placed, placement{target: target, pod: pod})
+	}
+
+	moves := make([]PodMove, 0, len(placed))
+	for _, p := range placed {
+		p.target.received = true
+		moves = append(moves, PodMove{Namespace: p.pod.Namespace, Pod: p.pod.Name, From: candidate.Name, To: p.target.Name})
+	}
+	candidate.cpu, candidate.mem = 0, 0
+	return moves, ""
+}
+
+// hardTaints returns the taints that repel pods without a matching toleration.
+func hardTaints(taints []string) map[string]bool {
+	out := make(map[string]bool)
+	for _, t := range taints {
+		if strings.HasSuffix(t, ":NoSchedule") || strings.HasSuffix(t, ":NoExecute") {
+			out[t] = true
+		}
+	}
+	return out
+}
+
+// tolerates reports whether pods tolerating the source taints may schedule on the target.
+func tolerates(source, target map[string]bool) bool {
+	for t := range target {
+		if !source[t] {
+			return false
+		}
+	}
+	return true
+}
+
+func podSize(p ConsolidationPod, n *simNode) float64 {
+	return math.Max(fraction(p.CPURequestMilli, n.AllocatableCPUMilli), fraction(p.MemoryRequestBytes, n.AllocatableMemoryBytes))
+}
+
+func podLabel(p ConsolidationPod) string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+	return p.Namespace + "/" + p.Name
+}
+
+func density(sims []*simNode) float64 {
+	var cpu, mem, cpuAlloc, memAlloc int64
+	for _, s := range sims {
+		if s.drained {
+			continue
+		}
+		cpu += s.cpu
+		mem += s.mem
+		cpuAlloc += s.AllocatableCPUMilli
+		memAlloc += s.AllocatableMemoryBytes
+	}
+	return roundTo((fraction(cpu, cpuAlloc)+fraction(mem, memAlloc))/2*100, 2)
+}
+
+func fraction(used, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(used) / float64(total)
+}
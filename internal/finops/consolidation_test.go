@@ -0,0 +1,222 @@
+package finops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+func testNode(name string, cpu, mem int64, hourly float64, pods ...ConsolidationPod) ConsolidationNode {
+	return ConsolidationNode{
+		Name:                   name,
+		HourlyCost:             hourly,
+		AllocatableCPUMilli:    4000,
+		AllocatableMemoryBytes: 16 * gibibyte,
+		RequestedCPUMilli:      cpu,
+		RequestedMemoryBytes:   mem,
+		Pods:                   pods,
+	}
+}
+
+func TestSimulateConsolidationDrainsLeastUtilizedNode(t *testing.T) {
+	nodes := []ConsolidationNode{
+		testNode("busy", 2000, 8*gibibyte, 0.2,
+			ConsolidationPod{Namespace: "web", Name: "api-1", CPURequestMilli: 2000, MemoryRequestBytes: 8 * gibibyte}),
+		testNode("idle", 600, 2*gibibyte, 0.2,
+			ConsolidationPod{Namespace: "web", Name: "api-2", CPURequestMilli: 500, MemoryRequestBytes: 2 * gibibyte},
+			ConsolidationPod{Namespace: "kube-system", Name: "agent-x", CPURequestMilli: 100, DaemonSet: true}),
+	}
+	plan := SimulateConsolidation(nodes, DefaultConsolidationOptions())
+
+	if len(plan.Drainable) != 1 || plan.Drainable[0].Name != "idle" {
+		t.Fatalf("expected idle node to be drainable, got %+v", plan.Drainable)
+	}
+	moves := plan.Drainable[0].Moves
+	if len(moves) != 1 || moves[0].Pod != "api-2" || moves[0].To != "busy" {
+		t.Fatalf("expected only the non-DaemonSet pod to move to busy, got %+v", moves)
+	}
+	if plan.RemainingNodes != 1 || len(plan.Blocked) != 1 || !strings.Contains(plan.Blocked[0].Reason, "must remain") {
+		t.Fatalf("expected busy node to stay, got remaining=%d blocked=%+v", plan.RemainingNodes, plan.Blocked)
+	}
+	if plan.MonthlySavings != 146 {
+		t.Fatalf("expected 0.2 * 730 savings, got %.2f", plan.MonthlySavings)
+	}
+	// 2600m / 8000m and 10Gi / 32Gi before; 2500m / 4000m and 10Gi / 16Gi after
+	if plan.DensityScore != 31.87 || plan.ProjectedDensityScore != 62.5 {
+		t.Fatalf("unexpected density %.2f -> %.2f", plan.DensityScore, plan.ProjectedDensityScore)
+	}
+}
+
+func TestSimulateConsolidationRespectsCapacity(t *testing.T) {
+	nodes := []ConsolidationNode{
+		testNode("a", 3000, 4*gibibyte, 0.2, ConsolidationPod{Name: "big", CPURequestMilli: 3000, MemoryRequestBytes: 4 * gibibyte}),
+		testNode("b", 2500, 4*gibibyte, 0.2, ConsolidationPod{Name: "medium", CPURequestMilli: 2500, MemoryRequestBytes: 4 * gibibyte}),
+	}
+	plan := SimulateConsolidation(nodes, DefaultConsolidationOptions())
+
+	if len(plan.Drainable) != 0 || plan.MonthlySavings != 0 {
+		t.Fatalf("expected nothing drainable, got %+v", plan.Drainable)
+	}
+	if plan.Blocked[0].Name != "b" || !strings.Contains(plan.Blocked[0].Reason, "medium does not fit") {
+		t.Fatalf("unexpected blocked reason: %+v", plan.Blocked)
+	}
+}
+
+func TestSimulateConsolidationHonorsTaints(t *testing.T) {
+	gpu := testNode("gpu", 1000, gibibyte, 0.9)
+	gpu.Taints = []string{"nvidia.com/gpu=true:NoSchedule"}
+	nodes := []ConsolidationNode{
+		gpu,
+		testNode("general", 200, gibibyte, 0.2, ConsolidationPod{Name: "web", CPURequestMilli: 200, MemoryRequestBytes: gibibyte}),
+	}
+	plan := SimulateConsolidation(nodes, DefaultConsolidationOptions())
+
+	for _, d := range plan.Drainable {
+		if d.Name == "general" {
+			t.Fatalf("pods must not move onto a tainted node: %+v", d)
+		}
+	}
+	var reason string
+	for _, b := range plan.Blocked {
+		if b.Name == "general" {
+			reason = b.Reason
+		}
+	}
+	if reason != "no other node accepts its taints" {
+		t.Fatalf("expected general node blocked by taints, got %q", reason)
+	}
+}
+
+func TestSimulateConsolidationMovesUnattributedRequests(t *testing.T) {
+	nodes := []ConsolidationNode{
+		testNode("a", 2000, 4*gibibyte, 0.2),
+		testNode("b", 1500, 4*gibibyte, 0.2),
+		testNode("c", 100, gibibyte, 0.2),
+	}
+	opts := DefaultConsolidationOptions()
+	opts.MinNodes = 2
+	plan := SimulateConsolidation(nodes, opts)
+
+	if len(plan.Drainable) != 1 || plan.Drainable[0].Name != "c" {
+		t.Fatalf("expected node c to be drainable, got %+v", plan.Drainable)
+	}
+	if moves := plan.Drainable[0].Moves; len(moves) != 1 || moves[0].Pod != unattributedPod || moves[0].To != "a" {
+		t.Fatalf("expected unattributed requests to move to the fullest node, got %+v", moves)
+	}
+	if plan.RemainingNodes != 2 {
+		t.Fatalf("expected min nodes to be kept, got %d", plan.RemainingNodes)
+	}
+}
+
+func TestConsolidationNodesFromStoreKeepsClustersAndTaints(t *testing.T) {
+	st := store.New(nil, "")
+	report := func(cluster, node string, cpu uint64, taints []*agentv1.NodeTaint, pods ...string) {
+		req := &agentv1.MetricsReportRequest{
+			AgentId:      cluster + "-" + node,
+			ClusterId:    cluster,
+			NodeName:     node,
+			InstanceType: "m5.xlarge",
+			Region:       "us-east-1",
+			Nodes: []*agentv1.NodeMetric{{
+				NodeName:                 node,
+				AllocatableCpuMillicores: 4000,
+				AllocatableMemoryBytes:   16 * gibibyte,
+				RequestedCpuMillicores:   cpu * uint64(len(pods)),
+				Taints:                   taints,
+			}},
+		}
+		for _, pod := range pods {
+			req.Pods = append(req.Pods, &agentv1.PodMetric{
+				Namespace: "web",
+				PodName:   pod,
+				Cpu:       &agentv1.CpuMetrics{RequestMillicores: cpu},
+				Memory:    &agentv1.MemoryMetrics{RequestBytes: gibibyte},
+			})
+		}
+		st.UpdateMetrics(req.AgentId, req)
+	}
+	gpuOnly := []*agentv1.NodeTaint{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}}
+	report("c1", "node-a", 1000, gpuOnly, "trainer")
+	report("c1", "node-b", 500, nil, "api-1")
+	report("c1", "node-c", 500, nil, "api-2", "api-3")
+	report("c2", "node-a", 3000, nil, "batch")
+
+	engine := NewEngine(nil, store.NewPricingCatalog(nil))
+	nodes, err := engine.ConsolidationNodesFromStore(context.Background(), st, "")
+	if err != nil {
+		t.Fatalf("ConsolidationNodesFromStore: %v", err)
+	}
+	if len(nodes) != 4 {
+		t.Fatalf("expected node-a of both clusters to stay apart, got %d nodes", len(nodes))
+	}
+	for _, n := range nodes {
+		if n.Name != "node-a" {
+			continue
+		}
+		if len(n.Pods) != 1 || n.HourlyCost <= 0 || n.InstanceType != "m5.xlarge" {
+			t.Fatalf("expected one priced pod on %s/%s, got %+v", n.ClusterID, n.Name, n)
+		}
+		if wantTainted := n.ClusterID == "c1"; (len(n.Taints) == 1) != wantTainted {
+			t.Fatalf("unexpected taints on %s/%s: %v", n.ClusterID, n.Name, n.Taints)
+		}
+	}
+
+	plan := SimulateConsolidation(nodes, DefaultConsolidationOptions())
+	if len(plan.Drainable) == 0 {
+		t.Fatalf("expected a drainable node, got %+v", plan)
+	}
+	for _, d := range plan.Drainable {
+		if d.ClusterID != "c1" {
+			t.Fatalf("expected only c1 nodes to drain, got %+v", d)
+		}
+		for _, m := range d.Moves {
+			if m.To == "node-a" {
+				t.Fatalf("expected no pod to move onto the tainted node or into c2, got %+v", m)
+			}
+		}
+	}
+	var c2Blocked bool
+	for _, b := range plan.Blocked {
+		c2Blocked = c2Blocked || (b.ClusterID == "c2" && strings.Contains(b.Reason, "must remain"))
+	}
+	if !c2Blocked {
+		t.Fatalf("expected the only c2 node to be kept, got %+v", plan.Blocked)
+	}
+
+	scoped, err := engine.ConsolidationNodesFromStore(context.Background(), st, "c2")
+	if err != nil {
+		t.Fatalf("ConsolidationNodesFromStore: %v", err)
+	}
+	if len(scoped) != 1 || scoped[0].ClusterID != "c2" || len(scoped[0].Pods) != 1 {
+		t.Fatalf("expected only the c2 node, got %+v", scoped)
+	}
+}
+
+func TestConsolidationNodesFromStoreMatchesDaemonSetKindCaseInsensitively(t *testing.T) {
+	st := store.New(nil, "")
+	st.UpdateMetrics("agent", &agentv1.MetricsReportRequest{
+		AgentId:   "agent",
+		ClusterId: "c1",
+		NodeName:  "node-a",
+		Nodes:     []*agentv1.NodeMetric{{NodeName: "node-a", AllocatableCpuMillicores: 4000, AllocatableMemoryBytes: 16 * gibibyte}},
+		Pods: []*agentv1.PodMetric{{
+			Namespace:    "logging",
+			PodName:      "fluentd-x2k4p",
+			WorkloadKind: "daemonset",
+			WorkloadName: "fluentd",
+			Cpu:          &agentv1.CpuMetrics{RequestMillicores: 100},
+			Memory:       &agentv1.MemoryMetrics{RequestBytes: gibibyte},
+		}},
+	})
+
+	nodes, err := NewEngine(nil, store.NewPricingCatalog(nil)).ConsolidationNodesFromStore(context.Background(), st, "")
+	if err != nil {
+		t.Fatalf("ConsolidationNodesFromStore: %v", err)
+	}
+	if len(nodes) != 1 || len(nodes[0].Pods) != 1 || !nodes[0].Pods[0].DaemonSet {
+		t.Fatalf("expected the fluentd pod to be a daemonset pod, got %+v", nodes)
+	}
+}
@@ -0,0 +1,296 @@
+package finops
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// CrossAZOptions tunes hotspot detection.
+type CrossAZOptions struct {
+	// ConcentrationPercent is the share of backend pods (or client bytes) in one AZ above
+	// which backends count as concentrated (or clients as not spread)
+	ConcentrationPercent float64 `json:"concentrationPercent"`
+	// Limit caps the number of service pairs returned
+	Limit int `json:"limit"`
+}
+
+// DefaultCrossAZOptions treats 80% in a single AZ as concentrated.
+func DefaultCrossAZOptions() CrossAZOptions {
+	return CrossAZOptions{ConcentrationPercent: 80, Limit: 100}
+}
+
+// ZoneTraffic is the traffic from one AZ to another.
+type ZoneTraffic struct {
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Bytes float64 `json:"bytes"`
+}
+
+// ZoneShare is the traffic (and for backends, the pods) of a service in one AZ.
+type ZoneShare struct {
+	Zone    string  `json:"zone"`
+	Bytes   float64 `json:"bytes"`
+	Percent float64 `json:"percent"`
+	Pods    int     `json:"pods,omitempty"`
+}
+
+// CrossAZPair is the traffic between a client workload and the service it calls.
+type CrossAZPair struct {
+	Source         string        `json:"source"`
+	Destination    string        `json:"destination"`
+	TotalBytes     float64       `json:"totalBytes"`
+	CrossAZBytes   float64       `json:"crossAzBytes"`
+	CrossAZPercent float64       `json:"crossAzPercent"`
+	Cost           float64       `json:"cost"`
+	MonthlyCost    float64       `json:"monthlyCost"`
+	Zones          []ZoneTraffic `json:"zones"`
+}
+
+// CrossAZService summarizes where a service's clients and backends run.
+// MonthlySavings is the cross-AZ cost topology-aware routing removes by keeping traffic in AZs
+// that already have backends; MonthlySavingsIfSpread also counts AZs that would need one.
+type CrossAZService struct {
+	Service                string      `json:"service"`
+	ClientZones            []ZoneShare `json:"clientZones"`
+	BackendZones           []ZoneShare `json:"backendZones"`
+	ClientsSpread          bool        `json:"clientsSpread"`
+	BackendsConcentrated   bool        `json:"backendsConcentrated"`
+	Hotspot                bool        `json:"hotspot"`
+	CrossAZBytes           float64     `json:"crossAzBytes"`
+	MonthlyCost            float64     `json:"monthlyCost"`
+	MonthlySavings         float64     `json:"monthlySavings"`
+	MonthlySavingsIfSpread float64     `json:"monthlySavingsIfSpread"`
+}
+
+// CrossAZReport ranks service pairs by cross-AZ cost and flags services whose clients are
+// spread across AZs while their backends sit in one. Monthly figures extrapolate the window.
+type CrossAZReport struct {
+	Start                  time.Time        `json:"start"`
+	End                    time.Time        `json:"end"`
+	Options                CrossAZOptions   `json:"options"`
+	CostPerGB              float64          `json:"costPerGb"`
+	TotalBytes             float64          `json:"totalBytes"`
+	CrossAZBytes           float64          `json:"crossAzBytes"`
+	Cost                   float64          `json:"cost"`
+	MonthlyCost            float64          `json:"monthlyCost"`
+	MonthlySavings         float64          `json:"monthlySavings"`
+	MonthlySavingsIfSpread float64          `json:"monthlySavingsIfSpread"`
+	Pairs                  []CrossAZPair    `json:"pairs"`
+	TotalPairs             int              `json:"totalPairs"`
+	Services               []CrossAZService `json:"services"`
+	HotspotCount           int              `json:"hotspotCount"`
+}
+
+type crossAZService struct {
+	clientBytes  map[string]float64
+	backendBytes map[string]float64
+	backendPods  map[string]map[string]bool
+	crossBytes   float64
+	// routable is cross-AZ traffic from AZs where the service already has backends
+	routable float64
+}
+
+// AnalyzeCrossAZ aggregates zone flows by client workload and destination service. Clients are
+// named namespace/workload from their pod; destinations by their first Service, else workload.
+func AnalyzeCrossAZ(flows []vm.ZoneFlow, start, end time.Time, opts CrossAZOptions) CrossAZReport {
+	report := CrossAZReport{
+		Start:     start,
+		End:       end,
+		Options:   opts,
+		CostPerGB: store.CostEgressCrossAZ,
+		Pairs:     []CrossAZPair{},
+		Services:  []CrossAZService{},
+	}
+	monthly := 0.0
+	if hours := end.Sub(start).Hours(); hours > 0 {
+		monthly = store.BillingHoursPerMonth / hours
+	}
+	cost := func(bytes float64) float64 { return bytes / gibibyte * store.CostEgressCrossAZ }
+
+	type pairKey struct{ src, dst string }
+	type zoneKey struct{ from, to string }
+	pairs := make(map[pairKey]*CrossAZPair)
+	pairZones := make(map[pairKey]map[zoneKey]float64)
+	services := make(map[string]*crossAZService)
+	for _, f := range flows {
+		src, dst := flowEndpoint(f.SrcNamespace, f.SrcPod, ""), flowEndpoint(f.DstNamespace, f.DstPod, f.DstServices)
+		if src == "" || dst == "" {
+			continue
+		}
+		cross := f.SrcAZ != f.DstAZ
+		report.TotalBytes += f.Bytes
+
+		key := pairKey{src, dst}
+		p := pairs[key]
+		if p == nil {
+			p = &CrossAZPair{Source: src, Destination: dst}
+			pairs[key] = p
+			pairZones[key] = make(map[zoneKey]float64)
+		}
+		p.TotalBytes += f.Bytes
+
+		svc := services[dst]
+		if svc == nil {
+			svc = &crossAZService{clientBytes: map[string]float64{}, backendBytes: map[string]float64{}, backendPods: map[string]map[string]bool{}}
+			services[dst] = svc
+		}
+		svc.clientBytes[f.SrcAZ] += f.Bytes
+		svc.backendBytes[f.DstAZ] += f.Bytes
+		if f.DstPod != "" {
+			if svc.backendPods[f.DstAZ] == nil {
+				svc.backendPods[f.DstAZ] = map[string]bool{}
+			}
+			svc.backendPods[f.DstAZ][f.DstNamespace+"/"+f.DstPod] = true
+		}
+		if !cross {
+			continue
+		}
+		report.CrossAZBytes += f.Bytes
+		p.CrossAZBytes += f.Bytes
+		pairZones[key][zoneKey{f.SrcAZ, f.DstAZ}] += f.Bytes
+		svc.crossBytes += f.Bytes
+	}
+	// Routable traffic needs the full backend AZ set, so it is a second pass
+	for _, f := range flows {
+		if f.SrcAZ == f.DstAZ {
+			continue
+		}
+		dst := flowEndpoint(f.DstNamespace, f.DstPod, f.DstServices)
+		if svc := services[dst]; svc != nil && flowEndpoint(f.SrcNamespace, f.SrcPod, "") != "" && svc.backendBytes[f.SrcAZ] > 0 {
+			svc.routable += f.Bytes
+		}
+	}
+
+	for key, p := range pairs {
+		if p.CrossAZBytes == 0 {
+			continue
+		}
+		p.CrossAZPercent = roundTo(p.CrossAZBytes/p.TotalBytes*100, 2)
+		p.Cost = roundTo(cost(p.CrossAZBytes), 4)
+		p.MonthlyCost = roundTo(cost(p.CrossAZBytes)*monthly, 2)
+		for z, bytes := range pairZones[key] {
+			p.Zones = append(p.Zones, ZoneTraffic{From: z.from, To: z.to, Bytes: bytes})
+		}
+		sort.Slice(p.Zones, func(i, j int) bool {
+			if p.Zones[i].Bytes != p.Zones[j].Bytes {
+				return p.Zones[i].Bytes > p.Zones[j].Bytes
+			}
+			return p.Zones[i].From+p.Zones[i].To < p.Zones[j].From+p.Zones[j].To
+		})
+		report.Pairs = append(report.Pairs, *p)
+	}
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		a, b := report.Pairs[i], report.Pairs[j]
+		if a.CrossAZBytes != b.CrossAZBytes {
+			return a.CrossAZBytes > b.CrossAZBytes
+		}
+		return a.Source+a.Destination < b.Source+b.Destination
+	})
+	report.TotalPairs = len(report.Pairs)
+	if opts.Limit > 0 && len(report.Pairs) > opts.Limit {
+		report.Pairs = report.Pairs[:opts.Limit]
+	}
+
+	for name, svc := range services {
+		if svc.crossBytes == 0 {
+			continue
+		}
+		clients := zoneShares(svc.clientBytes, nil)
+		backends := zoneShares(svc.backendBytes, svc.backendPods)
+		out := CrossAZService{
+			Service:                name,
+			ClientZones:            clients,
+			BackendZones:           backends,
+			ClientsSpread:          len(clients) > 1 && clients[0].Percent < opts.ConcentrationPercent,
+			BackendsConcentrated:   backendConcentration(backends) >= opts.ConcentrationPercent,
+			CrossAZBytes:           svc.crossBytes,
+			MonthlyCost:            roundTo(cost(svc.crossBytes)*monthly, 2),
+			MonthlySavings:         roundTo(cost(svc.routable)*monthly, 2),
+			MonthlySavingsIfSpread: roundTo(cost(svc.crossBytes)*monthly, 2),
+		}
+		out.Hotspot = out.ClientsSpread && out.BackendsConcentrated
+		if out.Hotspot {
+			report.HotspotCount++
+		}
+		report.MonthlySavings += out.MonthlySavings
+		report.MonthlySavingsIfSpread += out.MonthlySavingsIfSpread
+		report.Services = append(report.Services, out)
+	}
+	sort.Slice(report.Services, func(i, j int) bool {
+		a, b := report.Services[i], report.Services[j]
+		if a.Hotspot != b.Hotspot {
+			return a.Hotspot
+		}
+		if a.CrossAZBytes != b.CrossAZBytes {
+			return a.CrossAZBytes > b.CrossAZBytes
+		}
+		return a.Service < b.Service
+	})
+
+	report.Cost = roundTo(cost(report.CrossAZBytes), 4)
+	report.MonthlyCost = roundTo(cost(report.CrossAZBytes)*monthly, 2)
+	report.MonthlySavings = roundTo(report.MonthlySavings, 2)
+	report.MonthlySavingsIfSpread = roundTo(report.MonthlySavingsIfSpread, 2)
+	return report
+}
+
+// flowEndpoint names a connection endpoint: its first Service when known, otherwise
+// namespace/workload. Endpoints outside the cluster return "".
+func flowEndpoint(namespace, pod, services string) string {
+	if services != "" {
+		first, _, _ := strings.Cut(services, ",")
+		return first
+	}
+	if pod == "" {
+		return ""
+	}
+	_, workload := store.WorkloadFromPodName(pod)
+	if namespace == "" {
+		return workload
+	}
+	return namespace + "/" + workload
+}
+
+// zoneShares turns per-AZ bytes into shares, largest first. Pods are counted when given.
+func zoneShares(bytes map[string]float64, pods map[string]map[string]bool) []ZoneShare {
+	var total float64
+	for _, b := range bytes {
+		total += b
+	}
+	out := make([]ZoneShare, 0, len(bytes))
+	for zone, b := range bytes {
+		share := ZoneShare{Zone: zone, Bytes: b, Pods: len(pods[zone])}
+		if total > 0 {
+			share.Percent = roundTo(b/total*100, 2)
+		}
+		out = append(out, share)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Bytes != out[j].Bytes {
+			return out[i].Bytes > out[j].Bytes
+		}
+		return out[i].Zone < out[j].Zone
+	})
+	return out
+}
+
+// backendConcentration is the percentage of backend pods in the busiest AZ, falling back to
+// bytes when no backend pod is known (e.g. traffic to a Service VIP).
+func backendConcentration(backends []ZoneShare) float64 {
+	var total, top int
+	for _, b := range backends {
+		total += b.Pods
+		top = max(top, b.Pods)
+	}
+	if total == 0 {
+		if len(backends) == 0 {
+			return 0
+		}
+		return backends[0].Percent
+	}
+	return float64(top) / float64(total) * 100
+}
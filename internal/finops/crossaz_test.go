@@ -0,0 +1,56 @@
+package finops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+func TestAnalyzeCrossAZFindsConcentratedBackends(t *testing.T) {
+	end := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-73 * time.Hour)
+	flows := []vm.ZoneFlow{
+		// web clients in a, b and c call the payments service, whose only backend is in a
+		{SrcNamespace: "shop", SrcPod: "web-7d9f8b6c5d-abcde", SrcAZ: "a", DstNamespace: "shop", DstPod: "payments-0", DstAZ: "a", DstServices: "shop/payments", Bytes: 10 * gibibyte},
+		{SrcNamespace: "shop", SrcPod: "web-7d9f8b6c5d-fghij", SrcAZ: "b", DstNamespace: "shop", DstPod: "payments-0", DstAZ: "a", DstServices: "shop/payments", Bytes: 10 * gibibyte},
+		{SrcNamespace: "shop", SrcPod: "web-7d9f8b6c5d-klmno", SrcAZ: "c", DstNamespace: "shop", DstPod: "payments-0", DstAZ: "a", DstServices: "shop/payments", Bytes: 10 * gibibyte},
+		// the cache has replicas in a and b, so traffic from b can stay local
+		{SrcNamespace: "shop", SrcPod: "web-7d9f8b6c5d-fghij", SrcAZ: "b", DstNamespace: "shop", DstPod: "cache-0", DstAZ: "a", DstServices: "shop/cache", Bytes: 5 * gibibyte},
+		{SrcNamespace: "shop", SrcPod: "web-7d9f8b6c5d-abcde", SrcAZ: "a", DstNamespace: "shop", DstPod: "cache-1", DstAZ: "b", DstServices: "shop/cache", Bytes: 5 * gibibyte},
+		// external destinations are ignored
+		{SrcNamespace: "shop", SrcPod: "web-7d9f8b6c5d-abcde", SrcAZ: "a", DstAZ: "b", Bytes: gibibyte},
+	}
+	report := AnalyzeCrossAZ(flows, start, end, DefaultCrossAZOptions())
+
+	if report.CrossAZBytes != 30*gibibyte || report.TotalBytes != 40*gibibyte {
+		t.Fatalf("unexpected totals %.0f / %.0f", report.CrossAZBytes, report.TotalBytes)
+	}
+	// 30 GiB * $0.01 over 73h is $0.30, or $3.00 over 730h
+	if report.Cost != 0.3 || report.MonthlyCost != 3 {
+		t.Fatalf("unexpected cost %.4f / %.2f", report.Cost, report.MonthlyCost)
+	}
+	if len(report.Pairs) != 2 || report.Pairs[0].Source != "shop/web" || report.Pairs[0].Destination != "shop/payments" {
+		t.Fatalf("expected web->payments ranked first, got %+v", report.Pairs)
+	}
+	if p := report.Pairs[0]; p.CrossAZBytes != 20*gibibyte || p.CrossAZPercent != 66.67 || p.MonthlyCost != 2 || len(p.Zones) != 2 {
+		t.Fatalf("unexpected payments pair %+v", p)
+	}
+
+	if report.HotspotCount != 1 || len(report.Services) != 2 {
+		t.Fatalf("expected one hotspot out of two services, got %+v", report.Services)
+	}
+	payments, cache := report.Services[0], report.Services[1]
+	if payments.Service != "shop/payments" || !payments.Hotspot || len(payments.ClientZones) != 3 {
+		t.Fatalf("expected payments to be a hotspot, got %+v", payments)
+	}
+	if payments.MonthlySavings != 0 || payments.MonthlySavingsIfSpread != 2 {
+		t.Fatalf("payments needs backends in b and c before routing helps: %+v", payments)
+	}
+	if cache.Hotspot || cache.BackendsConcentrated || cache.MonthlySavings != 1 {
+		t.Fatalf("expected cache traffic to be routable locally, got %+v", cache)
+	}
+	if report.MonthlySavings != 1 || report.MonthlySavingsIfSpread != 3 {
+		t.Fatalf("unexpected savings %.2f / %.2f", report.MonthlySavings, report.MonthlySavingsIfSpread)
+	}
+}
@@ -3,21 +3,86 @@ package finops
 import (
 	"context"
 	"math"
+	"sort"
+	"time"
 
 	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
 	"github.com/clustercost/clustercost-dashboard/internal/store"
 	"github.com/clustercost/clustercost-dashboard/internal/vm"
 )
 
-// EfficiencyReport represents the financial analysis of a workload.
+// DefaultEfficiencyLookback is the usage window efficiency is computed over.
+const DefaultEfficiencyLookback = 24 * time.Hour
+
+const (
+	// safetyBuffer is the headroom applied on top of p95 usage when sizing the "safe" cost
+	safetyBuffer = 1.2
+	// fullConfidenceSamples is the sample count at which sample size stops lowering confidence
+	fullConfidenceSamples = 120
+)
+
+// EfficiencyReport represents the financial analysis of a workload, or of a whole namespace
+// when Workload is empty.
 type EfficiencyReport struct {
+	ClusterID          string  `json:"cluster_id,omitempty"`
 	Namespace          string  `json:"namespace"`
+	Workload           string  `json:"workload,omitempty"`
+	WorkloadKind       string  `json:"workload_kind,omitempty"`
 	Service            string  `json:"service"`
+	Pods               int     `json:"pods"`
+	Workloads          int     `json:"workloads,omitempty"`
 	RequestedCostMo    float64 `json:"requested_cost_mo"`
 	ActualUsageCostMo  float64 `json:"actual_usage_cost_mo"`
 	PotentialSavingsMo float64 `json:"potential_savings_mo"`
 	ConfidenceScore    float64 `json:"confidence_score"`
 	EfficiencyScore    float64 `json:"efficiency_score"` // 0-100
+
+	safeCostMo float64
+}
+
+// EfficiencyAnalysis holds efficiency aggregated per workload and per namespace, both sorted
+// by potential savings, plus the pods that could not be evaluated.
+type EfficiencyAnalysis struct {
+	Lookback   time.Duration
+	Workloads  []EfficiencyReport
+	Namespaces []EfficiencyReport
+	Skipped    []SkippedPod
+}
+
+// SkippedPod is a pod left out of the efficiency analysis and why.
+type SkippedPod struct {
+	ClusterID string `json:"cluster_id,omitempty"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Reason    string `json:"reason"`
+}
+
+// EfficiencyTotals sums a set of efficiency rows.
+type EfficiencyTotals struct {
+	RequestedCostMo    float64 `json:"requested_cost_mo"`
+	ActualUsageCostMo  float64 `json:"actual_usage_cost_mo"`
+	PotentialSavingsMo float64 `json:"potential_savings_mo"`
+}
+
+// EfficiencyFilter narrows, orders and pages efficiency rows.
+type EfficiencyFilter struct {
+	MinSavings float64
+	// Sort is one of savings (default), efficiency, confidence, requested, usage or name
+	Sort   string
+	Order  string
+	Limit  int
+	Offset int
+}
+
+// EfficiencySortFields lists the accepted EfficiencyFilter.Sort values.
+var EfficiencySortFields = []string{"savings", "efficiency", "confidence", "requested", "usage", "name"}
+
+// PodEfficiency is the monthly cost picture of a single pod.
+type PodEfficiency struct {
+	RequestedCostMo float64
+	UsageCostMo     float64
+	SafeCostMo      float64
+	Confidence      float64
 }
 
 // Engine calculates efficiency scores.
@@ -34,81 +99,302 @@ func NewEngine(vmClient *vm.Client, pricing *store.PricingCatalog) *Engine {
 	}
 }
 
-// CalculatePodEfficiency computes the efficiency report for a single pod.
-func (e *Engine) CalculatePodEfficiency(ctx context.Context, pod *agentv1.PodMetric, clusterID, nodeRegion, nodeAZ, instanceType string) (*EfficiencyReport, error) {
-	// 1. Get Pricing Dimensions
-	if instanceType == "" {
-		instanceType = "m5.large" // Fallback
+// AnalyzeEfficiency computes efficiency for the given pods, aggregated by workload and namespace.
+// Usage comes from one batch of queries per cluster. Pods without requests or usage data, or
+// whose cluster query failed, are reported in Skipped rather than dropped.
+func (e *Engine) AnalyzeEfficiency(ctx context.Context, pods []store.PodContext, lookback time.Duration) (EfficiencyAnalysis, error) {
+	usage := make(map[vm.PodUsageKey]vm.PodUsageStats)
+	queryErrs := make(map[string]error)
+	queried := make(map[string]bool)
+	for _, pc := range pods {
+		if queried[pc.ClusterID] {
+			continue
+		}
+		queried[pc.ClusterID] = true
+		stats, err := e.vmClient.PodUsageStats(ctx, pc.ClusterID, lookback)
+		if err != nil {
+			if ctx.Err() != nil {
+				return EfficiencyAnalysis{}, ctx.Err()
+			}
+			if err != vm.ErrNoData {
+				queryErrs[pc.ClusterID] = err
+			}
+			continue
+		}
+		for key, s := range stats {
+			usage[key] = s
+			// Pods of an agent without a cluster ID look usage up under "", whatever
+			// cluster_id their series ended up with; a series without one wins.
+			if pc.ClusterID == "" && key.ClusterID != "" {
+				bare := vm.PodUsageKey{Namespace: key.Namespace, Pod: key.Pod}
+				if _, exact := stats[bare]; !exact {
+					usage[bare] = s
+				}
+			}
+		}
 	}
 
-	// Get unit prices
-	cpuPricePerCore, ramPricePerGB := e.pricing.GetNodeResourcePrices(ctx, nodeRegion, instanceType, 2, 8*1024*1024*1024)
+	var rows []podRow
+	var skipped []SkippedPod
+	for _, pod := range groupPodContainers(pods) {
+		skip := func(reason string) {
+			skipped = append(skipped, SkippedPod{ClusterID: pod.ClusterID, Namespace: pod.Pod.Namespace, Pod: pod.Pod.PodName, Reason: reason})
+		}
+		if pod.Pod.Cpu.RequestMillicores == 0 && pod.Pod.Memory.RequestBytes == 0 {
+			skip("no CPU or memory requests")
+			continue
+		}
+		if err := queryErrs[pod.ClusterID]; err != nil {
+			skip("usage query failed: " + err.Error())
+			continue
+		}
+		stats, ok := usage[vm.PodUsageKey{ClusterID: pod.ClusterID, Namespace: pod.Pod.Namespace, Pod: pod.Pod.PodName}]
+		if !ok {
+			skip("no usage data in lookback")
+			continue
+		}
+		instanceType := pod.InstanceType
+		if instanceType == "" {
+			instanceType = "m5.large" // Fallback
+		}
+		cpuPrice, ramPrice := e.pricing.GetNodeResourcePrices(ctx, pod.Region, instanceType, pod.NodeVCPUs, pod.NodeMemoryBytes)
+		kind, name := WorkloadOf(pod.Pod)
+		rows = append(rows, podRow{
+			cluster:   pod.ClusterID,
+			namespace: pod.Pod.Namespace,
+			kind:      kind,
+			workload:  name,
+			eff:       CalculatePodEfficiency(pod.Pod, stats, cpuPrice, ramPrice),
+		})
+	}
+	analysis := aggregateEfficiency(rows, lookback)
+	sort.Slice(skipped, func(i, j int) bool {
+		if skipped[i].Namespace != skipped[j].Namespace {
+			return skipped[i].Namespace < skipped[j].Namespace
+		}
+		return skipped[i].Pod < skipped[j].Pod
+	})
+	analysis.Skipped = skipped
+	return analysis, nil
+}
+
+// FilterEfficiency applies minimum savings, sorting and pagination to efficiency rows.
+// Totals and the total count cover every row passing the filter, not just the page.
+func FilterEfficiency(rows []EfficiencyReport, f EfficiencyFilter) (page []EfficiencyReport, total int, totals EfficiencyTotals) {
+	filtered := make([]EfficiencyReport, 0, len(rows))
+	for _, r := range rows {
+		if r.PotentialSavingsMo < f.MinSavings {
+			continue
+		}
+		filtered = append(filtered, r)
+		totals.RequestedCostMo += r.RequestedCostMo
+		totals.ActualUsageCostMo += r.ActualUsageCostMo
+		totals.PotentialSavingsMo += r.PotentialSavingsMo
+	}
 
-	// 2. Calculate Requested Cost (The "Price Tag")
-	// CPU
-	reqCPUCores := float64(pod.Cpu.RequestMillicores) / 1000.0
-	reqCPUHourly := reqCPUCores * cpuPricePerCore
+	key := func(r EfficiencyReport) float64 {
+		switch f.Sort {
+		case "efficiency":
+			return r.EfficiencyScore
+		case "confidence":
+			return r.ConfidenceScore
+		case "requested":
+			return r.RequestedCostMo
+		case "usage":
+			return r.ActualUsageCostMo
+		default:
+			return r.PotentialSavingsMo
+		}
+	}
+	name := func(r EfficiencyReport) string {
+		return r.ClusterID + "/" + r.Namespace + "/" + r.Workload
+	}
+	// Names sort ascending by default, figures descending
+	desc := f.Order == "desc" || (f.Order == "" && f.Sort != "name")
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if f.Sort != "name" && key(a) != key(b) {
+			return (key(a) > key(b)) == desc
+		}
+		if name(a) == name(b) {
+			return false
+		}
+		return (name(a) < name(b)) != (desc && f.Sort == "name")
+	})
 
-	// Memory
-	reqRAMGB := float64(pod.Memory.RequestBytes) / (1024 * 1024 * 1024)
-	reqRAMHourly := reqRAMGB * ramPricePerGB
+	total = len(filtered)
+	start := f.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if f.Limit > 0 && start+f.Limit < end {
+		end = start + f.Limit
+	}
+	return filtered[start:end], total, totals
+}
 
-	totalReqHourly := reqCPUHourly + reqRAMHourly
-	totalReqMonthly := totalReqHourly * 730 // 730 hours in a month
+// CalculatePodEfficiency prices a pod's requests, its p95 usage and a safe size of p95 plus a
+// 20% buffer. Prices are per core-hour and per GB-hour.
+func CalculatePodEfficiency(pod *agentv1.PodMetric, usage vm.PodUsageStats, cpuPricePerCore, ramPricePerGB float64) PodEfficiency {
+	var reqCPUCores, reqRAMGB float64
+	if pod.Cpu != nil {
+		reqCPUCores = float64(pod.Cpu.RequestMillicores) / 1000.0
+	}
+	if pod.Memory != nil {
+		reqRAMGB = float64(pod.Memory.RequestBytes) / gibibyte
+	}
+	usageRAMGB := usage.MemoryP95Bytes / gibibyte
 
-	// 3. Get Actual Usage (P95 from VictoriaMetrics)
-	p95CPU, p95RAMBytes, err := e.vmClient.GetPodP95Usage(ctx, clusterID, pod.Namespace, pod.PodName)
-	if err != nil {
-		// If VM is down or no data, we can't calculate usage.
-		// Return partial report or error.
-		return nil, err
+	monthly := func(cores, gb float64) float64 {
+		return (cores*cpuPricePerCore + gb*ramPricePerGB) * store.BillingHoursPerMonth
+	}
+	return PodEfficiency{
+		RequestedCostMo: monthly(reqCPUCores, reqRAMGB),
+		UsageCostMo:     monthly(usage.CPUP95Cores, usageRAMGB),
+		SafeCostMo:      monthly(usage.CPUP95Cores*safetyBuffer, usageRAMGB*safetyBuffer),
+		Confidence:      ConfidenceScore(usage),
 	}
+}
 
-	p95RAMGB := float64(p95RAMBytes) / (1024 * 1024 * 1024)
+// ConfidenceScore rates (0-1) how much the usage statistics can be trusted: 30% sample count
+// (full at 120 samples), 40% coverage of the lookback and 30% stability, 1/(1+cv) using the
+// noisier of CPU and memory.
+func ConfidenceScore(usage vm.PodUsageStats) float64 {
+	sampleScore := math.Min(1, usage.Samples/fullConfidenceSamples)
+	coverage := math.Min(1, math.Max(0, usage.Coverage))
+	stability := 1 / (1 + math.Max(usage.CPUVariation, usage.MemoryVariation))
+	return roundTo(0.3*sampleScore+0.4*coverage+0.3*stability, 2)
+}
 
-	// 4. Calculate Usage Cost (The "Reality")
-	usageCPUHourly := p95CPU * cpuPricePerCore
-	usageRAMHourly := p95RAMGB * ramPricePerGB
-	totalUsageHourly := usageCPUHourly + usageRAMHourly
-	totalUsageMonthly := totalUsageHourly * 730
+// WorkloadOf returns the controller owning a pod. Agents that do not report it fall back to
+// the pod name: ReplicaSet hashes are stripped for Deployments and ordinals mark StatefulSets.
+func WorkloadOf(pod *agentv1.PodMetric) (kind, name string) {
+	if pod.WorkloadName != "" {
+		return pod.WorkloadKind, pod.WorkloadName
+	}
+	return store.WorkloadFromPodName(pod.PodName)
+}
 
-	// 5. Calculate Waste & Savings
-	// Safety Buffer: Recommended = P95 * 1.2
-	safeCPU := p95CPU * 1.2
-	safeRAM := p95RAMGB * 1.2
+type podRow struct {
+	cluster, namespace, kind, workload string
+	eff                                PodEfficiency
+}
 
-	// Potential Savings = Cost(Requested) - Cost(Safe)
-	// If Requested < Safe, Savings is 0 (underprovisioned).
-	savingsHourly := 0.0
+// groupPodContainers merges per-container reports of the same pod, summing their requests.
+func groupPodContainers(pods []store.PodContext) []store.PodContext {
+	type podKey struct{ cluster, namespace, pod string }
+	index := make(map[podKey]int, len(pods))
+	out := make([]store.PodContext, 0, len(pods))
+	for _, pc := range pods {
+		if pc.Pod == nil {
+			continue
+		}
+		key := podKey{pc.ClusterID, pc.Pod.Namespace, pc.Pod.PodName}
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			merged := pc
+			merged.Pod = &agentv1.PodMetric{
+				Namespace:    pc.Pod.Namespace,
+				PodName:      pc.Pod.PodName,
+				WorkloadKind: pc.Pod.WorkloadKind,
+				WorkloadName: pc.Pod.WorkloadName,
+				Cpu:          &agentv1.CpuMetrics{},
+				Memory:       &agentv1.MemoryMetrics{},
+			}
+			out = append(out, merged)
+			i = len(out) - 1
+		}
+		if pc.Pod.Cpu != nil {
+			out[i].Pod.Cpu.RequestMillicores += pc.Pod.Cpu.RequestMillicores
+		}
+		if pc.Pod.Memory != nil {
+			out[i].Pod.Memory.RequestBytes += pc.Pod.Memory.RequestBytes
+		}
+	}
+	return out
+}
 
-	costSafeCPU := safeCPU * cpuPricePerCore
-	costSafeRAM := safeRAM * ramPricePerGB
-	totalSafeHourly := costSafeCPU + costSafeRAM
+func aggregateEfficiency(rows []podRow, lookback time.Duration) EfficiencyAnalysis {
+	type workloadKey struct{ cluster, namespace, kind, name string }
+	type namespaceKey struct{ cluster, namespace string }
+	workloads := make(map[workloadKey]*EfficiencyReport)
+	namespaces := make(map[namespaceKey]*EfficiencyReport)
+	nsWorkloads := make(map[namespaceKey]map[workloadKey]struct{})
+	weights := make(map[*EfficiencyReport]float64)
 
-	if totalReqHourly > totalSafeHourly {
-		savingsHourly = totalReqHourly - totalSafeHourly
+	add := func(r *EfficiencyReport, eff PodEfficiency) {
+		r.Pods++
+		r.RequestedCostMo += eff.RequestedCostMo
+		r.ActualUsageCostMo += eff.UsageCostMo
+		r.safeCostMo += eff.SafeCostMo
+		// Confidence is averaged weighted by requested cost (every pod counts at least a little)
+		weight := eff.RequestedCostMo + 1e-9
+		r.ConfidenceScore += eff.Confidence * weight
+		weights[r] += weight
 	}
+	for _, row := range rows {
+		wk := workloadKey{row.cluster, row.namespace, row.kind, row.workload}
+		w := workloads[wk]
+		if w == nil {
+			w = &EfficiencyReport{ClusterID: row.cluster, Namespace: row.namespace, Workload: row.workload, WorkloadKind: row.kind, Service: row.workload}
+			workloads[wk] = w
+		}
+		add(w, row.eff)
 
-	potentialSavingsMo := savingsHourly * 730
+		nk := namespaceKey{row.cluster, row.namespace}
+		ns := namespaces[nk]
+		if ns == nil {
+			ns = &EfficiencyReport{ClusterID: row.cluster, Namespace: row.namespace}
+			namespaces[nk] = ns
+			nsWorkloads[nk] = make(map[workloadKey]struct{})
+		}
+		add(ns, row.eff)
+		nsWorkloads[nk][wk] = struct{}{}
+	}
 
-	// 6. Efficiency Score
-	// Score = (Safe / Requested) * 100
-	// If Unset/Zero requests, efficiency is undefined (or 0).
-	efficiencyScore := 0.0
-	if totalReqHourly > 0 {
-		efficiencyScore = (totalSafeHourly / totalReqHourly) * 100
-		if efficiencyScore > 100 {
-			efficiencyScore = 100 // Cap at 100% (underprovisioned is "efficient" in terms of waste, but risky)
+	finish := func(set []*EfficiencyReport) []EfficiencyReport {
+		out := make([]EfficiencyReport, 0, len(set))
+		for _, r := range set {
+			r.ConfidenceScore = roundTo(r.ConfidenceScore/weights[r], 2)
+			// Potential Savings = Cost(Requested) - Cost(Safe); underprovisioned saves nothing
+			r.PotentialSavingsMo = math.Max(0, r.RequestedCostMo-r.safeCostMo)
+			if r.RequestedCostMo > 0 {
+				// Capped: underprovisioned is "efficient" in terms of waste, but risky
+				r.EfficiencyScore = roundTo(math.Min(100, r.safeCostMo/r.RequestedCostMo*100), 2)
+			}
+			out = append(out, *r)
 		}
+		sort.Slice(out, func(i, j int) bool {
+			if out[i].PotentialSavingsMo != out[j].PotentialSavingsMo {
+				return out[i].PotentialSavingsMo > out[j].PotentialSavingsMo
+			}
+			if out[i].Namespace != out[j].Namespace {
+				return out[i].Namespace < out[j].Namespace
+			}
+			return out[i].Workload < out[j].Workload
+		})
+		return out
+	}
+
+	workloadSet := make([]*EfficiencyReport, 0, len(workloads))
+	for _, w := range workloads {
+		workloadSet = append(workloadSet, w)
 	}
+	namespaceSet := make([]*EfficiencyReport, 0, len(namespaces))
+	for key, ns := range namespaces {
+		ns.Workloads = len(nsWorkloads[key])
+		namespaceSet = append(namespaceSet, ns)
+	}
+	return EfficiencyAnalysis{
+		Lookback:   lookback,
+		Workloads:  finish(workloadSet),
+		Namespaces: finish(namespaceSet),
+	}
+}
 
-	return &EfficiencyReport{
-		Namespace:          pod.Namespace,
-		Service:            pod.PodName, // Or workload name if available
-		RequestedCostMo:    totalReqMonthly,
-		ActualUsageCostMo:  totalUsageMonthly,
-		PotentialSavingsMo: potentialSavingsMo,
-		ConfidenceScore:    0.95, // Hardcoded for MVP as per VM availability
-		EfficiencyScore:    math.Round(efficiencyScore*100) / 100,
-	}, nil
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
 }
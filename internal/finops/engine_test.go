@@ -0,0 +1,223 @@
+package finops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/config"
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+func TestConfidenceScore(t *testing.T) {
+	cases := []struct {
+		name  string
+		usage vm.PodUsageStats
+		want  float64
+	}{
+		{"full steady data", vm.PodUsageStats{Samples: 500, Coverage: 1}, 1},
+		{"no data", vm.PodUsageStats{}, 0.3},
+		{"short-lived pod", vm.PodUsageStats{Samples: 30, Coverage: 0.25}, 0.48},
+		{"noisy usage", vm.PodUsageStats{Samples: 500, Coverage: 1, CPUVariation: 0.2, MemoryVariation: 1}, 0.85},
+	}
+	for _, tc := range cases {
+		if got := ConfidenceScore(tc.usage); got != tc.want {
+			t.Errorf("%s: expected %.2f, got %.2f", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestWorkloadOf(t *testing.T) {
+	cases := []struct {
+		pod        *agentv1.PodMetric
+		kind, name string
+	}{
+		{&agentv1.PodMetric{PodName: "web-7d9f8b6c5d-x2x9k", WorkloadKind: "Deployment", WorkloadName: "frontend"}, "Deployment", "frontend"},
+		{&agentv1.PodMetric{PodName: "api-server-7d9f8b6c5d-x2x9k"}, "Deployment", "api-server"},
+		{&agentv1.PodMetric{PodName: "postgres-0"}, "StatefulSet", "postgres"},
+		{&agentv1.PodMetric{PodName: "debug"}, "Pod", "debug"},
+	}
+	for _, tc := range cases {
+		kind, name := WorkloadOf(tc.pod)
+		if kind != tc.kind || name != tc.name {
+			t.Errorf("%s: expected %s/%s, got %s/%s", tc.pod.PodName, tc.kind, tc.name, kind, name)
+		}
+	}
+}
+
+func TestAggregateEfficiencyByWorkloadAndNamespace(t *testing.T) {
+	pod := func(name string, cpuMilli uint64, memGiB uint64) store.PodContext {
+		return store.PodContext{ClusterID: "c1", Pod: &agentv1.PodMetric{
+			Namespace: "web",
+			PodName:   name,
+			Cpu:       &agentv1.CpuMetrics{RequestMillicores: cpuMilli},
+			Memory:    &agentv1.MemoryMetrics{RequestBytes: memGiB * gibibyte},
+		}}
+	}
+	// Two containers of the same pod are merged before pricing
+	pods := groupPodContainers([]store.PodContext{
+		pod("api-7d9f8b6c5d-aaaaa", 500, 1),
+		pod("api-7d9f8b6c5d-aaaaa", 500, 1),
+		pod("api-7d9f8b6c5d-bbbbb", 1000, 2),
+		pod("cache-0", 1000, 0),
+	})
+	if len(pods) != 3 || pods[0].Pod.Cpu.RequestMillicores != 1000 || pods[0].Pod.Memory.RequestBytes != 2*gibibyte {
+		t.Fatalf("expected containers merged per pod, got %d pods", len(pods))
+	}
+
+	usage := vm.PodUsageStats{CPUP95Cores: 0.25, MemoryP95Bytes: 0.5 * gibibyte, Samples: 500, Coverage: 1}
+	var rows []podRow
+	for _, pc := range pods {
+		kind, name := WorkloadOf(pc.Pod)
+		rows = append(rows, podRow{cluster: pc.ClusterID, namespace: pc.Pod.Namespace, kind: kind, workload: name,
+			eff: CalculatePodEfficiency(pc.Pod, usage, 0.02, 0.002)})
+	}
+	analysis := aggregateEfficiency(rows, DefaultEfficiencyLookback)
+
+	if len(analysis.Workloads) != 2 || len(analysis.Namespaces) != 1 {
+		t.Fatalf("expected 2 workloads in 1 namespace, got %d/%d", len(analysis.Workloads), len(analysis.Namespaces))
+	}
+	api := analysis.Workloads[0]
+	if api.Workload != "api" || api.WorkloadKind != "Deployment" || api.Pods != 2 {
+		t.Fatalf("expected api deployment with 2 pods first, got %+v", api)
+	}
+	// requested: 2 cores, 4 GiB; safe: 2 * (0.3 cores, 0.6 GiB)
+	wantRequested := (2*0.02 + 4*0.002) * 730
+	wantSafe := (0.6*0.02 + 1.2*0.002) * 730
+	if diff := api.RequestedCostMo - wantRequested; diff < -1e-6 || diff > 1e-6 {
+		t.Fatalf("expected requested %.4f, got %.4f", wantRequested, api.RequestedCostMo)
+	}
+	if diff := api.PotentialSavingsMo - (wantRequested - wantSafe); diff < -1e-6 || diff > 1e-6 {
+		t.Fatalf("expected savings %.4f, got %.4f", wantRequested-wantSafe, api.PotentialSavingsMo)
+	}
+	if api.EfficiencyScore != 30 || api.ConfidenceScore != 1 {
+		t.Fatalf("expected efficiency 30 and confidence 1, got %.2f/%.2f", api.EfficiencyScore, api.ConfidenceScore)
+	}
+
+	ns := analysis.Namespaces[0]
+	if ns.Workload != "" || ns.Workloads != 2 || ns.Pods != 3 || ns.ClusterID != "c1" {
+		t.Fatalf("unexpected namespace row: %+v", ns)
+	}
+}
+
+func TestFilterEfficiencySortsPagesAndTotals(t *testing.T) {
+	rows := []EfficiencyReport{
+		{Namespace: "web", Workload: "api", PotentialSavingsMo: 40, RequestedCostMo: 100, EfficiencyScore: 60},
+		{Namespace: "web", Workload: "cache", PotentialSavingsMo: 5, RequestedCostMo: 10, EfficiencyScore: 50},
+		{Namespace: "ml", Workload: "trainer", PotentialSavingsMo: 90, RequestedCostMo: 300, EfficiencyScore: 70},
+		{Namespace: "ml", Workload: "notebook", PotentialSavingsMo: 0, RequestedCostMo: 20, EfficiencyScore: 100},
+	}
+
+	page, total, totals := FilterEfficiency(rows, EfficiencyFilter{MinSavings: 5, Limit: 2})
+	if total != 3 || len(page) != 2 || page[0].Workload != "trainer" || page[1].Workload != "api" {
+		t.Fatalf("unexpected first page: total %d, %+v", total, page)
+	}
+	if totals.PotentialSavingsMo != 135 || totals.RequestedCostMo != 410 {
+		t.Fatalf("expected totals across the filtered set, got %+v", totals)
+	}
+
+	page, _, _ = FilterEfficiency(rows, EfficiencyFilter{MinSavings: 5, Limit: 2, Offset: 2})
+	if len(page) != 1 || page[0].Workload != "cache" {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+
+	page, _, _ = FilterEfficiency(rows, EfficiencyFilter{Sort: "efficiency", Order: "asc"})
+	if page[0].Workload != "cache" || page[3].Workload != "notebook" {
+		t.Fatalf("expected least efficient first, got %+v", page)
+	}
+
+	page, _, _ = FilterEfficiency(rows, EfficiencyFilter{Sort: "name"})
+	if page[0].Workload != "notebook" || page[3].Workload != "cache" {
+		t.Fatalf("expected name ascending, got %+v", page)
+	}
+
+	if page, total, _ := FilterEfficiency(rows, EfficiencyFilter{Offset: 10}); len(page) != 0 || total != 4 {
+		t.Fatalf("expected empty page past the end, got %d of %d", len(page), total)
+	}
+}
+
+func TestAnalyzeEfficiencyReportsSkippedPods(t *testing.T) {
+	vmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		switch {
+		case strings.Contains(query, `cluster_id="broken"`):
+			http.Error(w, "boom", http.StatusInternalServerError)
+		case strings.Contains(query, "quantile_over_time(0.95, clustercost_pod_cpu_usage_milli"):
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"cluster_id":"c1","namespace":"web","pod":"api-0"},"value":[1700000000,"0.1"]}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}
+	}))
+	defer vmServer.Close()
+	client, err := vm.NewClient(config.Config{VictoriaMetricsURL: vmServer.URL})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	engine := NewEngine(client, store.NewPricingCatalog(nil))
+
+	requests := &agentv1.CpuMetrics{RequestMillicores: 500}
+	pods := []store.PodContext{
+		{ClusterID: "c1", Pod: &agentv1.PodMetric{Namespace: "web", PodName: "api-0", Cpu: requests}},
+		{ClusterID: "c1", Pod: &agentv1.PodMetric{Namespace: "web", PodName: "api-1", Cpu: requests}},
+		{ClusterID: "c1", Pod: &agentv1.PodMetric{Namespace: "web", PodName: "sidecar-free"}},
+		{ClusterID: "broken", Pod: &agentv1.PodMetric{Namespace: "web", PodName: "api-0", Cpu: requests}},
+	}
+	analysis, err := engine.AnalyzeEfficiency(context.Background(), pods, time.Hour)
+	if err != nil {
+		t.Fatalf("AnalyzeEfficiency: %v", err)
+	}
+	if len(analysis.Workloads) != 1 || analysis.Workloads[0].Pods != 1 {
+		t.Fatalf("expected one evaluated pod, got %+v", analysis.Workloads)
+	}
+
+	reasons := make(map[string]string)
+	for _, s := range analysis.Skipped {
+		reasons[s.ClusterID+"/"+s.Pod] = s.Reason
+	}
+	if len(reasons) != 3 {
+		t.Fatalf("expected 3 skipped pods, got %+v", analysis.Skipped)
+	}
+	if reasons["c1/api-1"] != "no usage data in lookback" || reasons["c1/sidecar-free"] != "no CPU or memory requests" {
+		t.Fatalf("unexpected skip reasons: %v", reasons)
+	}
+	if !strings.HasPrefix(reasons["broken/api-0"], "usage query failed") {
+		t.Fatalf("expected query failure reason, got %q", reasons["broken/api-0"])
+	}
+}
+
+func TestAnalyzeEfficiencyMatchesPodsWithoutClusterID(t *testing.T) {
+	vmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("query"), "quantile_over_time(0.95, clustercost_pod_cpu_usage_milli") {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"cluster_id":"c1","namespace":"web","pod":"api-0"},"value":[1700000000,"0.1"]}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer vmServer.Close()
+	client, err := vm.NewClient(config.Config{VictoriaMetricsURL: vmServer.URL})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	engine := NewEngine(client, store.NewPricingCatalog(nil))
+
+	pods := []store.PodContext{{
+		Pod:             &agentv1.PodMetric{Namespace: "web", PodName: "api-0", Cpu: &agentv1.CpuMetrics{RequestMillicores: 500}},
+		InstanceType:    "m5.xlarge",
+		NodeVCPUs:       4,
+		NodeMemoryBytes: 16 * gibibyte,
+	}}
+	analysis, err := engine.AnalyzeEfficiency(context.Background(), pods, time.Hour)
+	if err != nil {
+		t.Fatalf("AnalyzeEfficiency: %v", err)
+	}
+	if len(analysis.Workloads) != 1 || len(analysis.Skipped) != 0 {
+		t.Fatalf("expected the pod to match usage, got %+v skipped %+v", analysis.Workloads, analysis.Skipped)
+	}
+}
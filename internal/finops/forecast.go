@@ -0,0 +1,356 @@
+package finops
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+const (
+	// DefaultForecastHistory is four weeks, enough to estimate an hour-of-week profile.
+	DefaultForecastHistory = 28 * 24 * time.Hour
+	// ForecastConfidence is the two-sided coverage of the projected month-end bands.
+	ForecastConfidence = 0.95
+	forecastZ          = 1.96
+
+	hoursPerWeek = 7 * 24
+	// forecastBackfitIterations alternates trend and seasonal fits until both settle.
+	forecastBackfitIterations = 20
+)
+
+// Forecast models, from most to least detailed.
+const (
+	ForecastModelWeekly = "trend+weekly"
+	ForecastModelDaily  = "trend+daily"
+	ForecastModelTrend  = "trend"
+)
+
+// CostForecast is month-to-date spend plus the projected month-end total for one scope.
+type CostForecast struct {
+	Namespace             string           `json:"namespace,omitempty"`
+	Model                 string           `json:"model"`
+	MonthToDateCost       float64          `json:"monthToDateCost"`
+	ProjectedMonthEndCost float64          `json:"projectedMonthEndCost"`
+	LowerBound            float64          `json:"lowerBound"`
+	UpperBound            float64          `json:"upperBound"`
+	CurrentHourlyCost     float64          `json:"currentHourlyCost"`
+	HourlyTrendPerDay     float64          `json:"hourlyTrendPerDay"`
+	Daily                 []ForecastDay    `json:"daily,omitempty"`
+	Budgets               []BudgetCrossing `json:"budgets,omitempty"`
+
+	// projection is the expected cost of each remaining hour, used for budget crossings.
+	projection []vm.CostPoint
+}
+
+// ForecastDay splits one calendar day into actual and projected spend.
+type ForecastDay struct {
+	Date          time.Time `json:"date"`
+	ActualCost    float64   `json:"actualCost"`
+	ProjectedCost float64   `json:"projectedCost"`
+}
+
+// BudgetCrossing reports when a budget limit is expected to be reached this month.
+type BudgetCrossing struct {
+	BudgetID        int64      `json:"budgetId,omitempty"`
+	Name            string     `json:"name"`
+	MonthlyLimit    float64    `json:"monthlyLimit"`
+	AlreadyExceeded bool       `json:"alreadyExceeded"`
+	CrossesAt       *time.Time `json:"crossesAt"`
+}
+
+// CostForecastReport is the cluster forecast plus one forecast per namespace.
+type CostForecastReport struct {
+	GeneratedAt     time.Time      `json:"generatedAt"`
+	PeriodStart     time.Time      `json:"periodStart"`
+	PeriodEnd       time.Time      `json:"periodEnd"`
+	HistoryStart    time.Time      `json:"historyStart"`
+	ConfidenceLevel float64        `json:"confidenceLevel"`
+	Cluster         CostForecast   `json:"cluster"`
+	Namespaces      []CostForecast `json:"namespaces"`
+}
+
+// CostHistorySource provides hourly namespace cost history.
+type CostHistorySource interface {
+	NamespaceCostHistory(ctx context.Context, start, end time.Time, step time.Duration) ([]vm.NamespaceCostSeries, error)
+}
+
+// ForecastCosts loads hourly cost history and forecasts the current month for the cluster and each namespace.
+// History always covers at least the month to date so actuals are complete.
+func ForecastCosts(ctx context.Context, src CostHistorySource, now time.Time, history time.Duration) (CostForecastReport, error) {
+	if history <= 0 {
+		history = DefaultForecastHistory
+	}
+	now = now.UTC()
+	periodStart, periodEnd := MonthBounds(now)
+	end := now.Truncate(time.Hour)
+	start := end.Add(-history)
+	if periodStart.Before(start) {
+		start = periodStart
+	}
+
+	namespaces, err := src.NamespaceCostHistory(ctx, start, end, time.Hour)
+	if err != nil {
+		return CostForecastReport{}, err
+	}
+
+	clusterTotals := make(map[time.Time]float64)
+	report := CostForecastReport{
+		GeneratedAt:     now,
+		PeriodStart:     periodStart,
+		PeriodEnd:       periodEnd,
+		HistoryStart:    start,
+		ConfidenceLevel: ForecastConfidence,
+		Namespaces:      make([]CostForecast, 0, len(namespaces)),
+	}
+	for _, ns := range namespaces {
+		for _, p := range ns.Points {
+			clusterTotals[p.Timestamp] += p.Cost
+		}
+		forecast := ForecastMonth(ns.Points, now)
+		forecast.Namespace = ns.Namespace
+		forecast.Daily = nil
+		report.Namespaces = append(report.Namespaces, forecast)
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		if report.Namespaces[i].ProjectedMonthEndCost == report.Namespaces[j].ProjectedMonthEndCost {
+			return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+		}
+		return report.Namespaces[i].ProjectedMonthEndCost > report.Namespaces[j].ProjectedMonthEndCost
+	})
+
+	cluster := make([]vm.CostPoint, 0, len(clusterTotals))
+	for ts, cost := range clusterTotals {
+		cluster = append(cluster, vm.CostPoint{Timestamp: ts, Cost: cost})
+	}
+	sort.Slice(cluster, func(i, j int) bool { return cluster[i].Timestamp.Before(cluster[j].Timestamp) })
+	report.Cluster = ForecastMonth(cluster, now)
+	return report, nil
+}
+
+// ForecastMonth fits a linear trend plus a seasonal profile to hourly cost points and projects
+// the rest of the calendar month containing now. Each point is the cost of the hour ending at its
+// timestamp. The seasonal profile is hour-of-week once a full week of history exists, hour-of-day
+// once a full day exists, and omitted otherwise. Bands assume independent hourly residuals plus
+// the uncertainty of the fitted trend.
+func ForecastMonth(points []vm.CostPoint, now time.Time) CostForecast {
+	now = now.UTC()
+	periodStart, periodEnd := MonthBounds(now)
+	last := now.Truncate(time.Hour)
+	forecast := CostForecast{Model: ForecastModelTrend}
+	if len(points) == 0 {
+		return forecast
+	}
+
+	days := make(map[time.Time]*ForecastDay)
+	day := func(ts time.Time) *ForecastDay {
+		// The hour ending at midnight belongs to the previous day.
+		date := ts.Add(-time.Nanosecond).Truncate(24 * time.Hour)
+		d, ok := days[date]
+		if !ok {
+			d = &ForecastDay{Date: date}
+			days[date] = d
+		}
+		return d
+	}
+
+	origin := points[0].Timestamp
+	xs := make([]float64, len(points))
+	for i, p := range points {
+		xs[i] = p.Timestamp.Sub(origin).Hours()
+		if p.Timestamp.After(periodStart) && !p.Timestamp.After(last) {
+			forecast.MonthToDateCost += p.Cost
+			day(p.Timestamp).ActualCost += p.Cost
+		}
+	}
+
+	span := points[len(points)-1].Timestamp.Sub(origin)
+	buckets, bucketOf := 0, func(time.Time) int { return 0 }
+	switch {
+	case span >= 7*24*time.Hour:
+		forecast.Model = ForecastModelWeekly
+		buckets, bucketOf = hoursPerWeek, hourOfWeek
+	case span >= 24*time.Hour:
+		forecast.Model = ForecastModelDaily
+		buckets, bucketOf = 24, func(ts time.Time) int { return ts.Hour() }
+	}
+
+	// Fit trend and seasonal profile jointly by backfitting: a trend fitted to raw seasonal data
+	// picks up a spurious slope whenever the history starts and ends at different points in the cycle.
+	seasonal := make([]float64, buckets+1)
+	adjusted := make([]float64, len(points))
+	var fit trendFit
+	for iter := 0; iter < forecastBackfitIterations; iter++ {
+		for i, p := range points {
+			adjusted[i] = p.Cost - seasonal[bucketOf(p.Timestamp)]
+		}
+		fit = fitTrend(xs, adjusted)
+		if buckets == 0 {
+			break
+		}
+		seasonal = seasonalProfile(points, xs, fit, bucketOf, buckets)
+	}
+
+	var sse float64
+	for i, p := range points {
+		e := p.Cost - fit.at(xs[i]) - seasonal[bucketOf(p.Timestamp)]
+		sse += e * e
+	}
+	dof := float64(len(points) - 2)
+	if dof < 1 {
+		dof = 1
+	}
+	sigma := math.Sqrt(sse / dof)
+
+	var remaining, sumCentered float64
+	for ts := last.Add(time.Hour); !ts.After(periodEnd); ts = ts.Add(time.Hour) {
+		x := ts.Sub(origin).Hours()
+		cost := math.Max(0, fit.at(x)+seasonal[bucketOf(ts)])
+		forecast.projection = append(forecast.projection, vm.CostPoint{Timestamp: ts, Cost: cost})
+		day(ts).ProjectedCost += cost
+		remaining += cost
+		sumCentered += x - fit.meanX
+	}
+	forecast.CurrentHourlyCost = points[len(points)-1].Cost
+	forecast.HourlyTrendPerDay = fit.slope * 24
+	forecast.ProjectedMonthEndCost = forecast.MonthToDateCost + remaining
+
+	n := float64(len(forecast.projection))
+	variance := sigma * sigma * (n + n*n/float64(len(points)))
+	if fit.sxx > 0 {
+		variance += sigma * sigma * sumCentered * sumCentered / fit.sxx
+	}
+	band := forecastZ * math.Sqrt(variance)
+	forecast.LowerBound = math.Max(forecast.MonthToDateCost, forecast.ProjectedMonthEndCost-band)
+	forecast.UpperBound = forecast.ProjectedMonthEndCost + band
+
+	forecast.Daily = make([]ForecastDay, 0, len(days))
+	for _, d := range days {
+		forecast.Daily = append(forecast.Daily, *d)
+	}
+	sort.Slice(forecast.Daily, func(i, j int) bool { return forecast.Daily[i].Date.Before(forecast.Daily[j].Date) })
+	return forecast
+}
+
+// CrossingFor reports when cumulative spend reaches limit this month. CrossesAt stays nil when the
+// projection ends below the limit.
+func (f CostForecast) CrossingFor(name string, limit float64) BudgetCrossing {
+	crossing := BudgetCrossing{Name: name, MonthlyLimit: limit}
+	if limit <= 0 {
+		return crossing
+	}
+	if f.MonthToDateCost >= limit {
+		crossing.AlreadyExceeded = true
+		return crossing
+	}
+	total := f.MonthToDateCost
+	for _, p := range f.projection {
+		if p.Cost > 0 && total+p.Cost >= limit {
+			// Interpolate within the hour ending at p.Timestamp.
+			frac := (limit - total) / p.Cost
+			at := p.Timestamp.Add(-time.Hour).Add(time.Duration(frac * float64(time.Hour))).Truncate(time.Minute)
+			crossing.CrossesAt = &at
+			return crossing
+		}
+		total += p.Cost
+	}
+	return crossing
+}
+
+// ApplyBudgets attaches budget crossings to the matching cluster and namespace forecasts.
+// Label-scoped budgets are skipped because history is only broken down by namespace.
+func ApplyBudgets(report *CostForecastReport, budgets []db.Budget) {
+	byNamespace := make(map[string]int, len(report.Namespaces))
+	for i, ns := range report.Namespaces {
+		byNamespace[ns.Namespace] = i
+	}
+	for _, b := range budgets {
+		var target *CostForecast
+		switch b.Scope {
+		case db.BudgetScopeCluster:
+			target = &report.Cluster
+		case db.BudgetScopeNamespace:
+			if i, ok := byNamespace[b.Namespace]; ok {
+				target = &report.Namespaces[i]
+			}
+		}
+		if target == nil {
+			continue
+		}
+		crossing := target.CrossingFor(b.Name, b.MonthlyLimit)
+		crossing.BudgetID = b.ID
+		target.Budgets = append(target.Budgets, crossing)
+	}
+}
+
+type trendFit struct {
+	intercept float64
+	slope     float64
+	meanX     float64
+	sxx       float64
+}
+
+func (t trendFit) at(x float64) float64 {
+	return t.intercept + t.slope*x
+}
+
+// fitTrend is an ordinary least squares line through (xs, ys).
+func fitTrend(xs, ys []float64) trendFit {
+	n := float64(len(xs))
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var sxx, sxy float64
+	for i := range xs {
+		dx := xs[i] - meanX
+		sxx += dx * dx
+		sxy += dx * (ys[i] - meanY)
+	}
+	fit := trendFit{intercept: meanY, meanX: meanX, sxx: sxx}
+	if sxx > 0 {
+		fit.slope = sxy / sxx
+		fit.intercept = meanY - fit.slope*meanX
+	}
+	return fit
+}
+
+// seasonalProfile averages the detrended cost per bucket, centred so the profile sums to zero
+// across observed buckets and the trend keeps the level.
+func seasonalProfile(points []vm.CostPoint, xs []float64, fit trendFit, bucketOf func(time.Time) int, buckets int) []float64 {
+	profile := make([]float64, buckets+1)
+	counts := make([]float64, buckets+1)
+	for i, p := range points {
+		k := bucketOf(p.Timestamp)
+		profile[k] += p.Cost - fit.at(xs[i])
+		counts[k]++
+	}
+	var mean, observed float64
+	for k := range profile {
+		if counts[k] > 0 {
+			profile[k] /= counts[k]
+			mean += profile[k]
+			observed++
+		}
+	}
+	if observed > 0 {
+		mean /= observed
+	}
+	for k := range profile {
+		if counts[k] > 0 {
+			profile[k] -= mean
+		}
+	}
+	return profile
+}
+
+func hourOfWeek(ts time.Time) int {
+	return int(ts.Weekday())*24 + ts.Hour()
+}
@@ -0,0 +1,142 @@
+package finops
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// weekdayCost is $2/h on weekdays and $1/h on weekends.
+func weekdayCost(ts time.Time) float64 {
+	hour := ts.Add(-time.Hour)
+	if hour.Weekday() == time.Saturday || hour.Weekday() == time.Sunday {
+		return 1
+	}
+	return 2
+}
+
+func hourlyHistory(start, end time.Time, cost func(time.Time) float64) []vm.CostPoint {
+	var points []vm.CostPoint
+	for ts := start.Add(time.Hour); !ts.After(end); ts = ts.Add(time.Hour) {
+		points = append(points, vm.CostPoint{Timestamp: ts, Cost: cost(ts)})
+	}
+	return points
+}
+
+func TestForecastMonthWeeklySeasonality(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 12, 30, 0, 0, time.UTC)
+	points := hourlyHistory(now.Truncate(time.Hour).Add(-28*24*time.Hour), now.Truncate(time.Hour), weekdayCost)
+
+	forecast := ForecastMonth(points, now)
+	if forecast.Model != ForecastModelWeekly {
+		t.Fatalf("expected weekly model, got %s", forecast.Model)
+	}
+
+	periodStart, periodEnd := MonthBounds(now)
+	var wantMTD, wantTotal float64
+	for _, p := range hourlyHistory(periodStart, periodEnd, weekdayCost) {
+		if !p.Timestamp.After(now.Truncate(time.Hour)) {
+			wantMTD += p.Cost
+		}
+		wantTotal += p.Cost
+	}
+	if math.Abs(forecast.MonthToDateCost-wantMTD) > 1e-9 {
+		t.Fatalf("expected month-to-date %f, got %f", wantMTD, forecast.MonthToDateCost)
+	}
+	if math.Abs(forecast.ProjectedMonthEndCost-wantTotal) > 1 {
+		t.Fatalf("expected month-end %f, got %f", wantTotal, forecast.ProjectedMonthEndCost)
+	}
+	if forecast.LowerBound > wantTotal || forecast.UpperBound < wantTotal {
+		t.Fatalf("expected band [%f, %f] to contain %f", forecast.LowerBound, forecast.UpperBound, wantTotal)
+	}
+	if len(forecast.Daily) != 30 {
+		t.Fatalf("expected 30 daily entries, got %d", len(forecast.Daily))
+	}
+}
+
+func TestForecastMonthFollowsTrend(t *testing.T) {
+	now := time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)
+	origin := now.Add(-3 * 24 * time.Hour)
+	// Cost grows by $0.01/h every hour.
+	points := hourlyHistory(origin, now, func(ts time.Time) float64 { return 1 + 0.01*ts.Sub(origin).Hours() })
+
+	forecast := ForecastMonth(points, now)
+	if forecast.Model != ForecastModelDaily {
+		t.Fatalf("expected daily model, got %s", forecast.Model)
+	}
+	if math.Abs(forecast.HourlyTrendPerDay-0.24) > 1e-6 {
+		t.Fatalf("expected trend 0.24/day, got %f", forecast.HourlyTrendPerDay)
+	}
+	flat := forecast.MonthToDateCost + forecast.CurrentHourlyCost*20*24
+	if forecast.ProjectedMonthEndCost <= flat {
+		t.Fatalf("expected growth above flat projection %f, got %f", flat, forecast.ProjectedMonthEndCost)
+	}
+}
+
+func TestCrossingFor(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 10, 0, 0, 0, time.UTC)
+	points := hourlyHistory(now.Add(-10*time.Hour), now, func(time.Time) float64 { return 1 })
+	forecast := ForecastMonth(points, now)
+
+	crossing := forecast.CrossingFor("cap", 20)
+	if crossing.AlreadyExceeded || crossing.CrossesAt == nil {
+		t.Fatalf("expected future crossing, got %+v", crossing)
+	}
+	if want := now.Add(10 * time.Hour); !crossing.CrossesAt.Equal(want) {
+		t.Fatalf("expected crossing at %s, got %s", want, crossing.CrossesAt)
+	}
+	if crossing := forecast.CrossingFor("spent", 5); !crossing.AlreadyExceeded {
+		t.Fatalf("expected exceeded budget, got %+v", crossing)
+	}
+	if crossing := forecast.CrossingFor("huge", 1e6); crossing.CrossesAt != nil {
+		t.Fatalf("expected no crossing, got %s", crossing.CrossesAt)
+	}
+}
+
+type fakeHistory []vm.NamespaceCostSeries
+
+func (f fakeHistory) NamespaceCostHistory(context.Context, time.Time, time.Time, time.Duration) ([]vm.NamespaceCostSeries, error) {
+	return f, nil
+}
+
+func TestForecastCostsAggregatesNamespaces(t *testing.T) {
+	now := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)
+	start := now.Add(-48 * time.Hour)
+	src := fakeHistory{
+		{Namespace: "web", Points: hourlyHistory(start, now, func(time.Time) float64 { return 1 })},
+		{Namespace: "ml", Points: hourlyHistory(start, now, func(time.Time) float64 { return 3 })},
+	}
+
+	report, err := ForecastCosts(context.Background(), src, now, 0)
+	if err != nil {
+		t.Fatalf("ForecastCosts: %v", err)
+	}
+	if len(report.Namespaces) != 2 || report.Namespaces[0].Namespace != "ml" {
+		t.Fatalf("expected namespaces ordered by projection, got %+v", report.Namespaces)
+	}
+	if math.Abs(report.Cluster.MonthToDateCost-4*48) > 1e-9 {
+		t.Fatalf("expected cluster month-to-date 192, got %f", report.Cluster.MonthToDateCost)
+	}
+	if math.Abs(report.Cluster.ProjectedMonthEndCost-4*30*24) > 1e-6 {
+		t.Fatalf("expected cluster month-end 2880, got %f", report.Cluster.ProjectedMonthEndCost)
+	}
+
+	ApplyBudgets(&report, []db.Budget{
+		{ID: 1, Name: "cluster", Scope: db.BudgetScopeCluster, MonthlyLimit: 1000},
+		{ID: 2, Name: "ml", Scope: db.BudgetScopeNamespace, Namespace: "ml", MonthlyLimit: 100},
+		{ID: 3, Name: "team", Scope: db.BudgetScopeLabel, LabelKey: "team", LabelValue: "a", MonthlyLimit: 1},
+	})
+	if len(report.Cluster.Budgets) != 1 || report.Cluster.Budgets[0].CrossesAt == nil {
+		t.Fatalf("expected cluster budget crossing, got %+v", report.Cluster.Budgets)
+	}
+	if len(report.Namespaces[0].Budgets) != 1 || !report.Namespaces[0].Budgets[0].AlreadyExceeded {
+		t.Fatalf("expected ml budget exceeded, got %+v", report.Namespaces[0].Budgets)
+	}
+	if len(report.Namespaces[1].Budgets) != 0 {
+		t.Fatalf("expected no budgets on web, got %+v", report.Namespaces[1].Budgets)
+	}
+}
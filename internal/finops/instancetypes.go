@@ -0,0 +1,277 @@
+package finops
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/pricing"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// InstanceTypeOptions controls how node groups are matched against the price table.
+type InstanceTypeOptions struct {
+	Lookback        time.Duration `json:"-"`
+	UsagePercentile float64       `json:"usagePercentile"`
+	// HeadroomPercent is added on top of the usage percentile
+	HeadroomPercent float64 `json:"headroomPercent"`
+	// MaxUtilizationPercent of a proposed node's capacity may be filled, leaving room for
+	// system reservations
+	MaxUtilizationPercent float64 `json:"maxUtilizationPercent"`
+	// MinNodes a proposed group keeps, capped at the current node count
+	MinNodes int `json:"minNodes"`
+	// MinRatioFit is the lowest accepted match between the workload and instance memory per vCPU
+	MinRatioFit float64 `json:"minRatioFit"`
+	// AllowArchitectureChange lets x86_64 groups move to arm64 types and vice versa
+	AllowArchitectureChange bool `json:"allowArchitectureChange"`
+	Alternatives            int  `json:"alternatives"`
+}
+
+// DefaultInstanceTypeOptions sizes groups at p95 usage + 20% over a week, packs nodes to 85%
+// and keeps two nodes where there are two today.
+func DefaultInstanceTypeOptions() InstanceTypeOptions {
+	return InstanceTypeOptions{
+		Lookback:              7 * 24 * time.Hour,
+		UsagePercentile:       0.95,
+		HeadroomPercent:       20,
+		MaxUtilizationPercent: 85,
+		MinNodes:              2,
+		MinRatioFit:           0.5,
+		Alternatives:          3,
+	}
+}
+
+// Validate checks the options are within range.
+func (o InstanceTypeOptions) Validate() error {
+	if o.UsagePercentile <= 0 || o.UsagePercentile > 1 {
+		return fmt.Errorf("percentile must be between 0 and 1")
+	}
+	if o.HeadroomPercent < 0 {
+		return fmt.Errorf("headroom must not be negative")
+	}
+	if o.MaxUtilizationPercent <= 0 || o.MaxUtilizationPercent > 100 {
+		return fmt.Errorf("maxUtilization must be between 0 and 100")
+	}
+	if o.MinNodes < 1 {
+		return fmt.Errorf("minNodes must be at least 1")
+	}
+	if o.MinRatioFit < 0 || o.MinRatioFit > 1 {
+		return fmt.Errorf("minRatioFit must be between 0 and 1")
+	}
+	if o.Lookback <= 0 {
+		return fmt.Errorf("lookback must be positive")
+	}
+	return nil
+}
+
+// InstanceTypeOption is a node group built from one instance type.
+type InstanceTypeOption struct {
+	InstanceType string  `json:"instanceType"`
+	Architecture string  `json:"architecture"`
+	VCPUs        int     `json:"vcpus"`
+	MemoryGiB    float64 `json:"memoryGiB"`
+	HourlyPrice  float64 `json:"hourlyPrice"`
+	Nodes        int     `json:"nodes"`
+	MonthlyCost  float64 `json:"monthlyCost"`
+	// RatioFit is min/max of the workload and instance memory per vCPU; 1 is a perfect match
+	RatioFit                 float64 `json:"ratioFit"`
+	CPUUtilizationPercent    float64 `json:"cpuUtilizationPercent"`
+	MemoryUtilizationPercent float64 `json:"memoryUtilizationPercent"`
+}
+
+// InstanceTypeRecommendation compares a node group with the cheapest instance type that fits it.
+type InstanceTypeRecommendation struct {
+	ClusterID           string  `json:"clusterId,omitempty"`
+	Region              string  `json:"region"`
+	InstanceType        string  `json:"instanceType"`
+	Nodes               int     `json:"nodes"`
+	HourlyPrice         float64 `json:"hourlyPrice"`
+	CurrentMonthlyCost  float64 `json:"currentMonthlyCost"`
+	CPURequestMilli     float64 `json:"cpuRequestMillicores"`
+	MemoryRequestBytes  float64 `json:"memoryRequestBytes"`
+	CPUUsageMilli       float64 `json:"cpuUsageMillicores"`
+	MemoryUsageBytes    float64 `json:"memoryUsageBytes"`
+	RequiredCPUMilli    float64 `json:"requiredCpuMillicores"`
+	RequiredMemoryBytes float64 `json:"requiredMemoryBytes"`
+	// MemoryPerVCPU is the GiB of memory the workload needs per vCPU
+	MemoryPerVCPU       float64              `json:"memoryGiBPerVcpu"`
+	Proposed            *InstanceTypeOption  `json:"proposed,omitempty"`
+	Alternatives        []InstanceTypeOption `json:"alternatives"`
+	ProposedMonthlyCost float64              `json:"proposedMonthlyCost"`
+	MonthlySavings      float64              `json:"monthlySavings"`
+	Reason              string               `json:"reason,omitempty"`
+}
+
+// RegionInstanceCost totals current and proposed node group cost in one region.
+type RegionInstanceCost struct {
+	Region              string  `json:"region"`
+	CurrentMonthlyCost  float64 `json:"currentMonthlyCost"`
+	ProposedMonthlyCost float64 `json:"proposedMonthlyCost"`
+	MonthlySavings      float64 `json:"monthlySavings"`
+}
+
+// InstanceTypeReport lists per node group recommendations and per region totals.
+type InstanceTypeReport struct {
+	GeneratedAt         time.Time                    `json:"generatedAt"`
+	Lookback            string                       `json:"lookback"`
+	Options             InstanceTypeOptions          `json:"options"`
+	CurrentMonthlyCost  float64                      `json:"currentMonthlyCost"`
+	ProposedMonthlyCost float64                      `json:"proposedMonthlyCost"`
+	MonthlySavings      float64                      `json:"monthlySavings"`
+	Regions             []RegionInstanceCost         `json:"regions"`
+	Items               []InstanceTypeRecommendation `json:"items"`
+}
+
+// RecommendInstanceTypes matches every node group (instance type label of the pods' nodes)
+// against the instance types priced in its region. region limits the report when set.
+func (e *Engine) RecommendInstanceTypes(ctx context.Context, region string, opts InstanceTypeOptions) (InstanceTypeReport, error) {
+	groups, err := e.vmClient.NodeGroupUsage(ctx, opts.Lookback, opts.UsagePercentile)
+	if err != nil {
+		return InstanceTypeReport{}, err
+	}
+
+	report := InstanceTypeReport{
+		GeneratedAt: time.Now().UTC(),
+		Lookback:    opts.Lookback.String(),
+		Options:     opts,
+		Regions:     []RegionInstanceCost{},
+		Items:       []InstanceTypeRecommendation{},
+	}
+	offerings := make(map[string][]pricing.Offering)
+	regions := make(map[string]*RegionInstanceCost)
+	for _, g := range groups {
+		if region != "" && g.Region != region {
+			continue
+		}
+		if _, ok := offerings[g.Region]; !ok {
+			offerings[g.Region] = pricing.RegionOfferings(g.Region)
+		}
+		price, ok := pricing.Price(g.Region, g.InstanceType)
+		if !ok {
+			price = e.pricing.GetTotalNodePrice(ctx, g.Region, g.InstanceType)
+		}
+		rec := RecommendInstanceType(g, price, offerings[g.Region], opts)
+		report.Items = append(report.Items, rec)
+
+		totals := regions[g.Region]
+		if totals == nil {
+			totals = &RegionInstanceCost{Region: g.Region}
+			regions[g.Region] = totals
+		}
+		totals.CurrentMonthlyCost += rec.CurrentMonthlyCost
+		totals.ProposedMonthlyCost += rec.ProposedMonthlyCost
+	}
+
+	for _, totals := range regions {
+		totals.MonthlySavings = roundTo(totals.CurrentMonthlyCost-totals.ProposedMonthlyCost, 2)
+		totals.CurrentMonthlyCost = roundTo(totals.CurrentMonthlyCost, 2)
+		totals.ProposedMonthlyCost = roundTo(totals.ProposedMonthlyCost, 2)
+		report.CurrentMonthlyCost += totals.CurrentMonthlyCost
+		report.ProposedMonthlyCost += totals.ProposedMonthlyCost
+		report.Regions = append(report.Regions, *totals)
+	}
+	sort.Slice(report.Regions, func(i, j int) bool { return report.Regions[i].Region < report.Regions[j].Region })
+	sort.SliceStable(report.Items, func(i, j int) bool { return report.Items[i].MonthlySavings > report.Items[j].MonthlySavings })
+	report.CurrentMonthlyCost = roundTo(report.CurrentMonthlyCost, 2)
+	report.ProposedMonthlyCost = roundTo(report.ProposedMonthlyCost, 2)
+	report.MonthlySavings = roundTo(report.CurrentMonthlyCost-report.ProposedMonthlyCost, 2)
+	return report, nil
+}
+
+// RecommendInstanceType sizes the group for max(requests, usage percentile + headroom) and
+// returns the cheapest offering that fits it, plus the next cheapest as alternatives. An
+// offering fits when its nodes hold the largest pod, its memory per vCPU is within MinRatioFit
+// of the workload and the whole group costs less than today. The current type is not proposed.
+func RecommendInstanceType(g vm.NodeGroupUsage, hourlyPrice float64, offerings []pricing.Offering, opts InstanceTypeOptions) InstanceTypeRecommendation {
+	headroom := 1 + opts.HeadroomPercent/100
+	rec := InstanceTypeRecommendation{
+		ClusterID:           g.ClusterID,
+		Region:              g.Region,
+		InstanceType:        g.InstanceType,
+		Nodes:               g.Nodes,
+		HourlyPrice:         hourlyPrice,
+		CurrentMonthlyCost:  roundTo(hourlyPrice*float64(g.Nodes)*store.BillingHoursPerMonth, 2),
+		CPURequestMilli:     g.CPURequestMilli,
+		MemoryRequestBytes:  g.MemoryRequestBytes,
+		CPUUsageMilli:       roundTo(g.CPUUsageMilli, 2),
+		MemoryUsageBytes:    math.Round(g.MemoryUsageBytes),
+		RequiredCPUMilli:    math.Ceil(math.Max(g.CPURequestMilli, g.CPUUsageMilli*headroom)),
+		RequiredMemoryBytes: math.Ceil(math.Max(g.MemoryRequestBytes, g.MemoryUsageBytes*headroom)),
+		Alternatives:        []InstanceTypeOption{},
+	}
+	rec.ProposedMonthlyCost = rec.CurrentMonthlyCost
+	if rec.RequiredCPUMilli > 0 {
+		rec.MemoryPerVCPU = roundTo(rec.RequiredMemoryBytes/gibibyte/(rec.RequiredCPUMilli/1000), 2)
+	}
+	if len(offerings) == 0 {
+		rec.Reason = "no instance prices for region"
+		return rec
+	}
+
+	current, known := pricing.ParseInstanceType(g.InstanceType)
+	minNodes := min(opts.MinNodes, max(g.Nodes, 1))
+	utilization := opts.MaxUtilizationPercent / 100
+	var fits []InstanceTypeOption
+	for _, o := range offerings {
+		if o.InstanceType == g.InstanceType {
+			continue
+		}
+		if known && !opts.AllowArchitectureChange && o.Architecture != current.Architecture {
+			continue
+		}
+		if !known && !opts.AllowArchitectureChange && o.Architecture != "x86_64" {
+			continue
+		}
+		cpuPerNode := float64(o.VCPUs) * 1000 * utilization
+		memPerNode := o.MemoryGiB * gibibyte * utilization
+		if g.MaxPodCPURequestMilli > cpuPerNode || g.MaxPodMemoryRequestBytes > memPerNode {
+			continue
+		}
+		fit := 1.0
+		if rec.MemoryPerVCPU > 0 {
+			perVCPU := o.MemoryGiB / float64(o.VCPUs)
+			fit = math.Min(perVCPU, rec.MemoryPerVCPU) / math.Max(perVCPU, rec.MemoryPerVCPU)
+		}
+		if fit < opts.MinRatioFit {
+			continue
+		}
+		nodes := max(minNodes, int(math.Ceil(rec.RequiredCPUMilli/cpuPerNode)), int(math.Ceil(rec.RequiredMemoryBytes/memPerNode)))
+		cost := o.HourlyPrice * float64(nodes) * store.BillingHoursPerMonth
+		if cost >= rec.CurrentMonthlyCost {
+			continue
+		}
+		fits = append(fits, InstanceTypeOption{
+			InstanceType:             o.InstanceType,
+			Architecture:             o.Architecture,
+			VCPUs:                    o.VCPUs,
+			MemoryGiB:                o.MemoryGiB,
+			HourlyPrice:              o.HourlyPrice,
+			Nodes:                    nodes,
+			MonthlyCost:              roundTo(cost, 2),
+			RatioFit:                 roundTo(fit, 2),
+			CPUUtilizationPercent:    roundTo(rec.RequiredCPUMilli/(float64(nodes*o.VCPUs)*1000)*100, 1),
+			MemoryUtilizationPercent: roundTo(rec.RequiredMemoryBytes/(float64(nodes)*o.MemoryGiB*gibibyte)*100, 1),
+		})
+	}
+	if len(fits) == 0 {
+		rec.Reason = "no cheaper instance type fits the workload"
+		return rec
+	}
+
+	sort.SliceStable(fits, func(i, j int) bool {
+		if fits[i].MonthlyCost != fits[j].MonthlyCost {
+			return fits[i].MonthlyCost < fits[j].MonthlyCost
+		}
+		return fits[i].RatioFit > fits[j].RatioFit
+	})
+	rec.Proposed = &fits[0]
+	rec.ProposedMonthlyCost = fits[0].MonthlyCost
+	rec.MonthlySavings = roundTo(rec.CurrentMonthlyCost-rec.ProposedMonthlyCost, 2)
+	if n := min(opts.Alternatives, len(fits)-1); n > 0 {
+		rec.Alternatives = fits[1 : 1+n]
+	}
+	return rec
+}
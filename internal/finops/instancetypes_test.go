@@ -0,0 +1,85 @@
+package finops
+
+import (
+	"testing"
+
+	"github.com/clustercost/clustercost-dashboard/internal/pricing"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+func testOffering(instanceType string, price float64) pricing.Offering {
+	shape, ok := pricing.ParseInstanceType(instanceType)
+	if !ok {
+		panic("unknown instance type " + instanceType)
+	}
+	return pricing.Offering{Region: "us-east-1", InstanceType: instanceType, HourlyPrice: price, InstanceShape: shape}
+}
+
+func TestRecommendInstanceTypeMatchesMemoryRatio(t *testing.T) {
+	// 4 m5.2xlarge (32 vCPU, 128Gi) running 8 cores and 60Gi of requests: memory heavy
+	group := vm.NodeGroupUsage{
+		Region:                   "us-east-1",
+		InstanceType:             "m5.2xlarge",
+		Nodes:                    4,
+		CPURequestMilli:          8000,
+		MemoryRequestBytes:       60 * gibibyte,
+		CPUUsageMilli:            3000,
+		MemoryUsageBytes:         40 * gibibyte,
+		MaxPodCPURequestMilli:    1000,
+		MaxPodMemoryRequestBytes: 8 * gibibyte,
+	}
+	offerings := []pricing.Offering{
+		testOffering("c5.large", 0.085),
+		testOffering("r5.large", 0.126),
+		testOffering("m5.xlarge", 0.192),
+		testOffering("r5.xlarge", 0.252),
+		testOffering("m5.2xlarge", 0.384),
+		testOffering("m6g.2xlarge", 0.308),
+		testOffering("r5.2xlarge", 0.504),
+	}
+	rec := RecommendInstanceType(group, 0.384, offerings, DefaultInstanceTypeOptions())
+
+	if rec.CurrentMonthlyCost != 1121.28 {
+		t.Fatalf("unexpected current cost %.2f", rec.CurrentMonthlyCost)
+	}
+	if rec.Proposed == nil || rec.Proposed.InstanceType != "r5.large" || rec.Proposed.Nodes != 5 {
+		t.Fatalf("expected 5 r5.large, got %+v (%s)", rec.Proposed, rec.Reason)
+	}
+	if rec.ProposedMonthlyCost != 459.9 || rec.MonthlySavings != 661.38 {
+		t.Fatalf("unexpected costs %.2f / %.2f", rec.ProposedMonthlyCost, rec.MonthlySavings)
+	}
+	if len(rec.Alternatives) == 0 || rec.Alternatives[0].InstanceType != "r5.xlarge" || rec.Alternatives[0].Nodes != 3 {
+		t.Fatalf("expected 3 r5.xlarge as first alternative, got %+v", rec.Alternatives)
+	}
+	for _, alt := range append([]InstanceTypeOption{*rec.Proposed}, rec.Alternatives...) {
+		switch alt.InstanceType {
+		case "c5.large":
+			t.Fatalf("compute optimized type does not fit the memory ratio: %+v", alt)
+		case "m6g.2xlarge":
+			t.Fatalf("arm64 type proposed without allowArchitectureChange: %+v", alt)
+		case "m5.2xlarge":
+			t.Fatalf("current type proposed: %+v", alt)
+		}
+	}
+}
+
+func TestRecommendInstanceTypeKeepsCheapestGroup(t *testing.T) {
+	group := vm.NodeGroupUsage{
+		Region:                   "us-east-1",
+		InstanceType:             "c5.large",
+		Nodes:                    2,
+		CPURequestMilli:          2800,
+		MemoryRequestBytes:       4 * gibibyte,
+		MaxPodCPURequestMilli:    1500,
+		MaxPodMemoryRequestBytes: gibibyte,
+	}
+	offerings := []pricing.Offering{testOffering("c5.large", 0.085), testOffering("c5.xlarge", 0.17), testOffering("m5.large", 0.096)}
+	rec := RecommendInstanceType(group, 0.085, offerings, DefaultInstanceTypeOptions())
+
+	if rec.Proposed != nil || rec.Reason == "" {
+		t.Fatalf("expected no proposal, got %+v", rec.Proposed)
+	}
+	if rec.ProposedMonthlyCost != rec.CurrentMonthlyCost || rec.MonthlySavings != 0 {
+		t.Fatalf("expected unchanged cost, got %.2f -> %.2f", rec.CurrentMonthlyCost, rec.ProposedMonthlyCost)
+	}
+}
@@ -0,0 +1,330 @@
+package finops
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+// RiskTier grades how safe it is to apply a right-sizing recommendation.
+type RiskTier string
+
+const (
+	RiskSafe     RiskTier = "safe"
+	RiskModerate RiskTier = "moderate"
+	RiskRisky    RiskTier = "risky"
+)
+
+const (
+	cpuRoundingMilli    = 5
+	memoryRoundingBytes = 1 << 20
+	gibibyte            = 1 << 30
+
+	// unknownInstanceType marks recommendations for containers whose node type is not known.
+	unknownInstanceType = "unknown"
+)
+
+// RightSizingOptions controls how recommendations are derived from observed usage.
+type RightSizingOptions struct {
+	Lookback              time.Duration `json:"-"`
+	CPUPercentile         float64       `json:"cpuPercentile"`
+	MemoryPercentile      float64       `json:"memoryPercentile"`
+	CPUHeadroomPercent    float64       `json:"cpuHeadroomPercent"`
+	MemoryHeadroomPercent float64       `json:"memoryHeadroomPercent"`
+	// Floors keep tiny or idle containers schedulable
+	MinCPUMilli    float64 `json:"minCpuMillicores"`
+	MinMemoryBytes float64 `json:"minMemoryBytes"`
+	// Peak-to-percentile ratios above these are moderate and risky respectively
+	ModeratePeakRatio float64 `json:"moderatePeakRatio"`
+	RiskyPeakRatio    float64 `json:"riskyPeakRatio"`
+}
+
+// DefaultRightSizingOptions sizes CPU at p95 + 20% and memory at p99 + 30% over a week.
+func DefaultRightSizingOptions() RightSizingOptions {
+	return RightSizingOptions{
+		Lookback:              7 * 24 * time.Hour,
+		CPUPercentile:         0.95,
+		MemoryPercentile:      0.99,
+		CPUHeadroomPercent:    20,
+		MemoryHeadroomPercent: 30,
+		MinCPUMilli:           10,
+		MinMemoryBytes:        32 * memoryRoundingBytes,
+		ModeratePeakRatio:     1.5,
+		RiskyPeakRatio:        3,
+	}
+}
+
+// Validate checks percentiles are in (0,1] and headroom is non-negative.
+func (o RightSizingOptions) Validate() error {
+	if o.CPUPercentile <= 0 || o.CPUPercentile > 1 || o.MemoryPercentile <= 0 || o.MemoryPercentile > 1 {
+		return fmt.Errorf("percentiles must be between 0 and 1")
+	}
+	if o.CPUHeadroomPercent < 0 || o.MemoryHeadroomPercent < 0 {
+		return fmt.Errorf("headroom must not be negative")
+	}
+	if o.Lookback <= 0 {
+		return fmt.Errorf("lookback must be positive")
+	}
+	return nil
+}
+
+// ContainerResources are the requests and limits of one container. Zero limits are unset.
+type ContainerResources struct {
+	CPURequestMilli    float64 `json:"cpuRequestMillicores"`
+	CPULimitMilli      float64 `json:"cpuLimitMillicores"`
+	MemoryRequestBytes float64 `json:"memoryRequestBytes"`
+	MemoryLimitBytes   float64 `json:"memoryLimitBytes"`
+}
+
+// RightSizingRecommendation is the suggested requests/limits for one workload container.
+type RightSizingRecommendation struct {
+	ClusterID             string             `json:"clusterId,omitempty"`
+	Namespace             string             `json:"namespace"`
+	WorkloadKind          string             `json:"workloadKind,omitempty"`
+	Workload              string             `json:"workload,omitempty"`
+	Pod                   string             `json:"pod,omitempty"`
+	Container             string             `json:"container,omitempty"`
+	Replicas              int                `json:"replicas"`
+	Current               ContainerResources `json:"current"`
+	Recommended           ContainerResources `json:"recommended"`
+	CPUPercentileMilli    float64            `json:"cpuPercentileMillicores"`
+	CPUPeakMilli          float64            `json:"cpuPeakMillicores"`
+	MemoryPercentileBytes float64            `json:"memoryPercentileBytes"`
+	MemoryPeakBytes       float64            `json:"memoryPeakBytes"`
+	PeakToPercentileRatio float64            `json:"peakToPercentileRatio"`
+	OOMKills              int                `json:"oomKills"`
+	Risk                  RiskTier           `json:"risk"`
+	RiskReasons           []string           `json:"riskReasons,omitempty"`
+	// InstanceType is the node type savings were priced at, "unknown" when the node has none
+	InstanceType string `json:"instanceType,omitempty"`
+	// MonthlySavings covers every replica; negative means the container is under-provisioned
+	MonthlySavings float64 `json:"monthlySavings"`
+	// Unpriced is set when the instance type is unknown; MonthlySavings is then zero
+	Unpriced bool `json:"unpriced,omitempty"`
+	// Patch is a strategic-merge patch for the owning workload, empty when it is unknown
+	Patch        string `json:"patch,omitempty"`
+	PatchCommand string `json:"patchCommand,omitempty"`
+}
+
+// RightSizingReport lists recommendations ordered by monthly savings.
+type RightSizingReport struct {
+	GeneratedAt         time.Time                   `json:"generatedAt"`
+	Lookback            string                      `json:"lookback"`
+	Options             RightSizingOptions          `json:"options"`
+	TotalMonthlySavings float64                     `json:"totalMonthlySavings"`
+	Items               []RightSizingRecommendation `json:"items"`
+}
+
+// RightSizing recommends requests and limits for every container in the cluster (or namespace)
+// from its usage percentiles over the lookback.
+func (e *Engine) RightSizing(ctx context.Context, namespace string, opts RightSizingOptions) (RightSizingReport, error) {
+	usage, err := e.vmClient.ContainerUsage(ctx, vm.ContainerUsageQuery{
+		Namespace:        namespace,
+		Lookback:         opts.Lookback,
+		CPUPercentile:    opts.CPUPercentile,
+		MemoryPercentile: opts.MemoryPercentile,
+	})
+	if err != nil {
+		return RightSizingReport{}, err
+	}
+
+	report := RightSizingReport{
+		GeneratedAt: time.Now().UTC(),
+		Lookback:    opts.Lookback.String(),
+		Options:     opts,
+		Items:       make([]RightSizingRecommendation, 0, len(usage)),
+	}
+	for _, u := range usage {
+		rec := e.recommend(ctx, u, opts)
+		report.TotalMonthlySavings += rec.MonthlySavings
+		report.Items = append(report.Items, rec)
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].MonthlySavings > report.Items[j].MonthlySavings
+	})
+	return report, nil
+}
+
+// recommend prices a container at the instance type of its node. Containers on nodes of
+// unknown type are sized but their savings are left unpriced.
+func (e *Engine) recommend(ctx context.Context, u vm.ContainerUsage, opts RightSizingOptions) RightSizingRecommendation {
+	if u.InstanceType == "" {
+		rec := RecommendResources(u, opts, 0, 0)
+		rec.InstanceType, rec.Unpriced = unknownInstanceType, true
+		return rec
+	}
+	// Node size is not on container series; the instance type's shape sizes the node.
+	cpuPrice, memPrice := e.pricing.GetNodeResourcePrices(ctx, u.Region, u.InstanceType, 0, 0)
+	rec := RecommendResources(u, opts, cpuPrice, memPrice)
+	rec.InstanceType = u.InstanceType
+	return rec
+}
+
+// RecommendResources sizes requests at the usage percentile plus headroom and limits (only
+// where one is set today) at the peak plus headroom. Memory is never reduced for a container
+// that was OOM killed during the lookback. Prices are per core-hour and per GB-hour.
+func RecommendResources(u vm.ContainerUsage, opts RightSizingOptions, cpuPricePerCore, memPricePerGB float64) RightSizingRecommendation {
+	cpuFactor := 1 + opts.CPUHeadroomPercent/100
+	memFactor := 1 + opts.MemoryHeadroomPercent/100
+
+	kind, workload := u.WorkloadKind, u.Workload
+	if strings.EqualFold(kind, "ReplicaSet") {
+		// Patching a ReplicaSet changes no running pod; its Deployment owns the template.
+		if deployment, ok := store.DeploymentFromReplicaSet(workload); ok {
+			kind, workload = "Deployment", deployment
+		}
+	}
+
+	rec := RightSizingRecommendation{
+		ClusterID:    u.ClusterID,
+		Namespace:    u.Namespace,
+		WorkloadKind: kind,
+		Workload:     workload,
+		Pod:          u.Pod,
+		Container:    u.Container,
+		Replicas:     u.Replicas,
+		Current: ContainerResources{
+			CPURequestMilli:    u.CPURequestMilli,
+			CPULimitMilli:      u.CPULimitMilli,
+			MemoryRequestBytes: u.MemoryRequestBytes,
+			MemoryLimitBytes:   u.MemoryLimitBytes,
+		},
+		CPUPercentileMilli:    u.CPUPercentileMilli,
+		CPUPeakMilli:          u.CPUPeakMilli,
+		MemoryPercentileBytes: u.MemoryPercentileBytes,
+		MemoryPeakBytes:       u.MemoryPeakBytes,
+		OOMKills:              int(u.OOMKills),
+	}
+	if rec.Replicas < 1 {
+		rec.Replicas = 1
+	}
+
+	next := &rec.Recommended
+	next.CPURequestMilli = math.Max(opts.MinCPUMilli, roundUp(u.CPUPercentileMilli*cpuFactor, cpuRoundingMilli))
+	next.MemoryRequestBytes = math.Max(opts.MinMemoryBytes, roundUp(u.MemoryPercentileBytes*memFactor, memoryRoundingBytes))
+	if u.CPULimitMilli > 0 {
+		next.CPULimitMilli = math.Max(next.CPURequestMilli, roundUp(u.CPUPeakMilli*cpuFactor, cpuRoundingMilli))
+	}
+	if u.MemoryLimitBytes > 0 {
+		next.MemoryLimitBytes = math.Max(next.MemoryRequestBytes, roundUp(u.MemoryPeakBytes*memFactor, memoryRoundingBytes))
+	}
+	if u.OOMKills > 0 {
+		next.MemoryRequestBytes = math.Max(next.MemoryRequestBytes, u.MemoryRequestBytes)
+		if u.MemoryLimitBytes > 0 {
+			next.MemoryLimitBytes = math.Max(next.MemoryLimitBytes, roundUp(u.MemoryLimitBytes*memFactor, memoryRoundingBytes))
+		}
+	}
+
+	rec.PeakToPercentileRatio = math.Max(ratio(u.CPUPeakMilli, u.CPUPercentileMilli), ratio(u.MemoryPeakBytes, u.MemoryPercentileBytes))
+	rec.Risk, rec.RiskReasons = assessRisk(rec, opts)
+
+	cpuSavedCores := (u.CPURequestMilli - next.CPURequestMilli) / 1000
+	memSavedGB := (u.MemoryRequestBytes - next.MemoryRequestBytes) / gibibyte
+	hourly := cpuSavedCores*cpuPricePerCore + memSavedGB*memPricePerGB
+	rec.MonthlySavings = math.Round(hourly*store.BillingHoursPerMonth*float64(rec.Replicas)*100) / 100
+
+	rec.Patch, rec.PatchCommand = strategicMergePatch(rec)
+	return rec
+}
+
+func assessRisk(rec RightSizingRecommendation, opts RightSizingOptions) (RiskTier, []string) {
+	risk := RiskSafe
+	var reasons []string
+	raise := func(tier RiskTier, reason string) {
+		if tier == RiskRisky || risk == RiskSafe {
+			risk = tier
+		}
+		reasons = append(reasons, reason)
+	}
+	if rec.OOMKills > 0 {
+		raise(RiskRisky, fmt.Sprintf("%d OOM kills during the lookback; memory is not reduced", rec.OOMKills))
+	}
+	switch {
+	case opts.RiskyPeakRatio > 0 && rec.PeakToPercentileRatio > opts.RiskyPeakRatio:
+		raise(RiskRisky, fmt.Sprintf("peaks reach %.1fx the sizing percentile", rec.PeakToPercentileRatio))
+	case opts.ModeratePeakRatio > 0 && rec.PeakToPercentileRatio > opts.ModeratePeakRatio:
+		raise(RiskModerate, fmt.Sprintf("occasional spikes up to %.1fx the sizing percentile", rec.PeakToPercentileRatio))
+	}
+	return risk, reasons
+}
+
+// strategicMergePatch renders the recommended resources as a kubectl strategic-merge patch for
+// the owning workload. Pods without a known controller or container name, and ReplicaSets not
+// owned by a Deployment, get no patch.
+func strategicMergePatch(rec RightSizingRecommendation) (patch, command string) {
+	if rec.Workload == "" || rec.Container == "" {
+		return "", ""
+	}
+	var path []string
+	switch strings.ToLower(rec.WorkloadKind) {
+	case "cronjob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	case "deployment", "statefulset", "daemonset", "job":
+		path = []string{"spec", "template", "spec"}
+	default:
+		return "", ""
+	}
+
+	var b strings.Builder
+	indent := ""
+	for _, key := range path {
+		fmt.Fprintf(&b, "%s%s:\n", indent, key)
+		indent += "  "
+	}
+	fmt.Fprintf(&b, "%scontainers:\n%s- name: %s\n", indent, indent, rec.Container)
+	indent += "  "
+	fmt.Fprintf(&b, "%sresources:\n", indent)
+	fmt.Fprintf(&b, "%s  requests:\n", indent)
+	fmt.Fprintf(&b, "%s    cpu: %s\n", indent, formatCPUQuantity(rec.Recommended.CPURequestMilli))
+	fmt.Fprintf(&b, "%s    memory: %s\n", indent, formatMemoryQuantity(rec.Recommended.MemoryRequestBytes))
+	if rec.Recommended.CPULimitMilli > 0 || rec.Recommended.MemoryLimitBytes > 0 {
+		fmt.Fprintf(&b, "%s  limits:\n", indent)
+		if rec.Recommended.CPULimitMilli > 0 {
+			fmt.Fprintf(&b, "%s    cpu: %s\n", indent, formatCPUQuantity(rec.Recommended.CPULimitMilli))
+		}
+		if rec.Recommended.MemoryLimitBytes > 0 {
+			fmt.Fprintf(&b, "%s    memory: %s\n", indent, formatMemoryQuantity(rec.Recommended.MemoryLimitBytes))
+		}
+	}
+
+	kind := strings.ToLower(rec.WorkloadKind)
+	file := fmt.Sprintf("%s-%s-resources.yaml", rec.Workload, rec.Container)
+	command = fmt.Sprintf("kubectl -n %s patch %s %s --type strategic --patch-file %s", rec.Namespace, kind, rec.Workload, file)
+	return b.String(), command
+}
+
+func formatCPUQuantity(milli float64) string {
+	m := int64(math.Round(milli))
+	if m%1000 == 0 {
+		return fmt.Sprintf("%d", m/1000)
+	}
+	return fmt.Sprintf("%dm", m)
+}
+
+func formatMemoryQuantity(bytes float64) string {
+	b := int64(math.Round(bytes))
+	if b%gibibyte == 0 {
+		return fmt.Sprintf("%dGi", b/gibibyte)
+	}
+	return fmt.Sprintf("%dMi", int64(math.Ceil(float64(b)/memoryRoundingBytes)))
+}
+
+func roundUp(value, step float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	return math.Ceil(value/step) * step
+}
+
+func ratio(peak, percentile float64) float64 {
+	if percentile <= 0 {
+		return 0
+	}
+	return peak / percentile
+}
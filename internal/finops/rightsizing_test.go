@@ -0,0 +1,163 @@
+package finops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+func TestRecommendResourcesShrinksOverprovisionedContainer(t *testing.T) {
+	usage := vm.ContainerUsage{
+		Namespace:             "web",
+		WorkloadKind:          "Deployment",
+		Workload:              "frontend",
+		Container:             "app",
+		Replicas:              3,
+		CPURequestMilli:       2000,
+		CPULimitMilli:         4000,
+		MemoryRequestBytes:    4 * gibibyte,
+		MemoryPercentileBytes: 400 * memoryRoundingBytes,
+		MemoryPeakBytes:       450 * memoryRoundingBytes,
+		CPUPercentileMilli:    200,
+		CPUPeakMilli:          260,
+	}
+	rec := RecommendResources(usage, DefaultRightSizingOptions(), 0.04, 0.005)
+
+	if rec.Recommended.CPURequestMilli != 240 || rec.Recommended.CPULimitMilli != 315 {
+		t.Fatalf("unexpected cpu recommendation: %+v", rec.Recommended)
+	}
+	if rec.Recommended.MemoryRequestBytes != 520*memoryRoundingBytes || rec.Recommended.MemoryLimitBytes != 0 {
+		t.Fatalf("unexpected memory recommendation: %+v", rec.Recommended)
+	}
+	if rec.Risk != RiskSafe || len(rec.RiskReasons) != 0 {
+		t.Fatalf("expected safe recommendation, got %s %v", rec.Risk, rec.RiskReasons)
+	}
+	// (1.76 cores * 0.04 + (4 - 520/1024) GB * 0.005) * 730h * 3 replicas
+	want := (1.76*0.04 + (4-520.0/1024)*0.005) * 730 * 3
+	if diff := rec.MonthlySavings - want; diff < -0.01 || diff > 0.01 {
+		t.Fatalf("expected savings ~%.2f, got %.2f", want, rec.MonthlySavings)
+	}
+
+	wantPatch := `spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 240m
+            memory: 520Mi
+          limits:
+            cpu: 315m
+`
+	if rec.Patch != wantPatch {
+		t.Fatalf("unexpected patch:\n%s", rec.Patch)
+	}
+	if rec.PatchCommand != "kubectl -n web patch deployment frontend --type strategic --patch-file frontend-app-resources.yaml" {
+		t.Fatalf("unexpected patch command: %s", rec.PatchCommand)
+	}
+}
+
+func TestRecommendResourcesRiskTiers(t *testing.T) {
+	opts := DefaultRightSizingOptions()
+	base := vm.ContainerUsage{
+		Namespace:             "batch",
+		WorkloadKind:          "CronJob",
+		Workload:              "nightly",
+		Container:             "worker",
+		Replicas:              1,
+		CPURequestMilli:       1000,
+		MemoryRequestBytes:    gibibyte,
+		MemoryLimitBytes:      gibibyte,
+		CPUPercentileMilli:    100,
+		CPUPeakMilli:          200,
+		MemoryPercentileBytes: 256 * memoryRoundingBytes,
+		MemoryPeakBytes:       300 * memoryRoundingBytes,
+	}
+
+	moderate := RecommendResources(base, opts, 0.04, 0.005)
+	if moderate.Risk != RiskModerate || moderate.PeakToPercentileRatio != 2 {
+		t.Fatalf("expected moderate risk at 2x peaks, got %s (%.2f)", moderate.Risk, moderate.PeakToPercentileRatio)
+	}
+	if !strings.HasPrefix(moderate.Patch, "spec:\n  jobTemplate:\n    spec:\n      template:\n        spec:\n") {
+		t.Fatalf("expected cronjob patch path, got:\n%s", moderate.Patch)
+	}
+
+	spiky := base
+	spiky.CPUPeakMilli = 500
+	if rec := RecommendResources(spiky, opts, 0.04, 0.005); rec.Risk != RiskRisky {
+		t.Fatalf("expected risky at 5x peaks, got %s", rec.Risk)
+	}
+
+	oom := base
+	oom.CPUPeakMilli = 110
+	oom.OOMKills = 2
+	rec := RecommendResources(oom, opts, 0.04, 0.005)
+	if rec.Risk != RiskRisky || len(rec.RiskReasons) != 1 {
+		t.Fatalf("expected risky after OOM kills, got %s %v", rec.Risk, rec.RiskReasons)
+	}
+	if rec.Recommended.MemoryRequestBytes != gibibyte || rec.Recommended.MemoryLimitBytes != 1332*memoryRoundingBytes {
+		t.Fatalf("expected memory kept and limit raised after OOM kills, got %+v", rec.Recommended)
+	}
+}
+
+func TestRecommendResourcesWithoutWorkloadHasNoPatch(t *testing.T) {
+	rec := RecommendResources(vm.ContainerUsage{Namespace: "default", Pod: "debug", Container: "shell", CPURequestMilli: 100}, DefaultRightSizingOptions(), 0.04, 0.005)
+	if rec.Patch != "" || rec.PatchCommand != "" {
+		t.Fatalf("expected no patch for a bare pod, got %q", rec.Patch)
+	}
+	if rec.Recommended.CPURequestMilli != 10 || rec.Recommended.MemoryRequestBytes != 32*memoryRoundingBytes {
+		t.Fatalf("expected floors for idle container, got %+v", rec.Recommended)
+	}
+	if rec.Replicas != 1 {
+		t.Fatalf("expected at least one replica, got %d", rec.Replicas)
+	}
+}
+
+func TestRecommendResourcesPatchesReplicaSetOwner(t *testing.T) {
+	usage := vm.ContainerUsage{Namespace: "web", WorkloadKind: "ReplicaSet", Workload: "frontend-7d9f8c6b5d", Container: "app", CPURequestMilli: 1000}
+	rec := RecommendResources(usage, DefaultRightSizingOptions(), 0.04, 0.005)
+	if rec.WorkloadKind != "Deployment" || rec.Workload != "frontend" {
+		t.Fatalf("expected the owning deployment, got %s/%s", rec.WorkloadKind, rec.Workload)
+	}
+	if !strings.HasPrefix(rec.PatchCommand, "kubectl -n web patch deployment frontend ") {
+		t.Fatalf("unexpected patch command: %s", rec.PatchCommand)
+	}
+
+	usage.Workload = "standalone"
+	if rec := RecommendResources(usage, DefaultRightSizingOptions(), 0.04, 0.005); rec.Patch != "" || rec.PatchCommand != "" {
+		t.Fatalf("expected no patch for a bare replicaset, got %q", rec.PatchCommand)
+	}
+}
+
+func TestRightSizingLeavesUnknownInstanceTypesUnpriced(t *testing.T) {
+	engine := &Engine{pricing: store.NewPricingCatalog(nil)}
+	usage := vm.ContainerUsage{Namespace: "web", Container: "app", CPURequestMilli: 2000, CPUPercentileMilli: 100}
+
+	rec := engine.recommend(context.Background(), usage, DefaultRightSizingOptions())
+	if !rec.Unpriced || rec.InstanceType != "unknown" || rec.MonthlySavings != 0 {
+		t.Fatalf("expected unpriced recommendation, got %+v", rec)
+	}
+	if rec.Recommended.CPURequestMilli >= usage.CPURequestMilli {
+		t.Fatalf("expected unpriced containers to still be sized, got %+v", rec.Recommended)
+	}
+
+	usage.InstanceType = "m5.xlarge"
+	if rec := engine.recommend(context.Background(), usage, DefaultRightSizingOptions()); rec.Unpriced || rec.MonthlySavings <= 0 {
+		t.Fatalf("expected priced savings on a known instance type, got %+v", rec)
+	}
+}
+
+func TestRightSizingOptionsValidate(t *testing.T) {
+	if err := DefaultRightSizingOptions().Validate(); err != nil {
+		t.Fatalf("expected defaults to validate, got %v", err)
+	}
+	opts := DefaultRightSizingOptions()
+	opts.CPUPercentile = 95
+	if err := opts.Validate(); err == nil {
+		t.Fatalf("expected percentile above 1 to be rejected")
+	}
+}
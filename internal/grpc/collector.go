@@ -14,6 +14,7 @@ type Collector struct {
 	ingestor ReportIngestor
 	store    *store.Store
 	logLevel string
+	counters *counterTracker
 }
 
 type ReportIngestor interface {
@@ -21,11 +22,14 @@ type ReportIngestor interface {
 	EnqueueNetwork(agentName string, req *agentv1.NetworkReportRequest) bool
 }
 
-func NewCollector(ingestor ReportIngestor, st *store.Store, logLevel string) *Collector {
+// NewCollector creates the gRPC collector. counterMode is CounterModeCumulative (the default
+// when empty) or CounterModeDelta and describes how agents report connection bytes.
+func NewCollector(ingestor ReportIngestor, st *store.Store, logLevel, counterMode string) *Collector {
 	return &Collector{
 		ingestor: ingestor,
 		store:    st,
 		logLevel: logLevel,
+		counters: newCounterTracker(counterMode),
 	}
 }
 
@@ -84,6 +88,15 @@ func (c *Collector) processNetworkReport(req *agentv1.NetworkReportRequest) erro
 		return fmt.Errorf("missing agent_id")
 	}
 
+	// Ingest monotonic counters regardless of agent restarts or counter mode
+	result := c.counters.normalize(agentName, req)
+	if result.Resets > 0 && c.logLevel == "debug" {
+		log.Printf("[DEBUG-GRPC] Agent %s: %d connection counter resets (restart: %t)", agentName, result.Resets, result.Restarted)
+	}
+	if c.store != nil {
+		c.store.RecordCounterResets(agentName, result.Resets, result.Restarted)
+	}
+
 	if c.ingestor != nil {
 		if ok := c.ingestor.EnqueueNetwork(agentName, req); !ok {
 			return fmt.Errorf("ingest queue full")
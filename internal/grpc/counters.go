@@ -0,0 +1,172 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+// Connection byte counter modes. Agents either report cumulative counters, which reset when
+// the agent restarts or a connection is re-established, or per-interval deltas.
+const (
+	CounterModeCumulative = "cumulative"
+	CounterModeDelta      = "delta"
+)
+
+// counterIdleTTL bounds how long a connection that stopped reporting keeps its counter state.
+const counterIdleTTL = time.Hour
+
+// restartResetRatio is the share of known connections that must reset in one report for it to
+// count as an agent restart rather than individual connections being re-established.
+const restartResetRatio = 0.5
+
+type connectionCounter struct {
+	rawSent, rawReceived uint64
+	sent, received       uint64
+	lastSeen             time.Time
+}
+
+type agentCounters struct {
+	connections map[string]*connectionCounter
+}
+
+// counterTracker turns the connection bytes of network reports into monotonically increasing
+// counters per connection. It runs before the ingestor's cardinality reduction, which merges
+// connections into fewer series; the ingestor keeps its own totals per reduced series
+// (vm/cardinality.go) so those stay monotonic too.
+type counterTracker struct {
+	mu     sync.Mutex
+	mode   string
+	agents map[string]*agentCounters
+}
+
+func newCounterTracker(mode string) *counterTracker {
+	if mode == "" {
+		mode = CounterModeCumulative
+	}
+	return &counterTracker{mode: mode, agents: make(map[string]*agentCounters)}
+}
+
+// counterResult describes the resets found while normalizing one report.
+type counterResult struct {
+	Resets    int
+	Restarted bool
+}
+
+// normalize rewrites the bytes of req.CompactConnections in place. Connections sharing a key
+// within the report (they would land on the same series) are merged first. In cumulative mode
+// a value below the previous one is a reset and counting resumes from the new value; in delta
+// mode every value is added to the running total.
+func (t *counterTracker) normalize(agentID string, req *agentv1.NetworkReportRequest) counterResult {
+	var result counterResult
+	if req == nil || len(req.CompactConnections) == 0 {
+		return result
+	}
+	now := reportTime(req.TimestampSeconds)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	agent := t.agents[agentID]
+	if agent == nil {
+		agent = &agentCounters{connections: make(map[string]*connectionCounter)}
+		t.agents[agentID] = agent
+	}
+
+	merged := req.CompactConnections[:0]
+	byKey := make(map[string]*agentv1.CompactNetworkConnection, len(req.CompactConnections))
+	for _, conn := range req.CompactConnections {
+		if conn == nil {
+			continue
+		}
+		key, ok := connectionKey(req.Endpoints, conn)
+		if !ok {
+			continue
+		}
+		if first := byKey[key]; first != nil {
+			first.BytesSent += conn.BytesSent
+			first.BytesReceived += conn.BytesReceived
+			continue
+		}
+		byKey[key] = conn
+		merged = append(merged, conn)
+	}
+	req.CompactConnections = merged
+
+	known := 0
+	for key, conn := range byKey {
+		counter := agent.connections[key]
+		if counter == nil {
+			counter = &connectionCounter{}
+			agent.connections[key] = counter
+			if t.mode == CounterModeCumulative {
+				// First sighting: the agent's value is the best starting point we have
+				counter.sent, counter.received = conn.BytesSent, conn.BytesReceived
+				counter.rawSent, counter.rawReceived = conn.BytesSent, conn.BytesReceived
+				counter.lastSeen = now
+				continue
+			}
+		} else {
+			known++
+		}
+
+		if t.mode == CounterModeDelta {
+			counter.sent += conn.BytesSent
+			counter.received += conn.BytesReceived
+		} else {
+			reset := conn.BytesSent < counter.rawSent || conn.BytesReceived < counter.rawReceived
+			if reset {
+				result.Resets++
+				counter.sent += conn.BytesSent
+				counter.received += conn.BytesReceived
+			} else {
+				counter.sent += conn.BytesSent - counter.rawSent
+				counter.received += conn.BytesReceived - counter.rawReceived
+			}
+			counter.rawSent, counter.rawReceived = conn.BytesSent, conn.BytesReceived
+		}
+		counter.lastSeen = now
+		conn.BytesSent, conn.BytesReceived = counter.sent, counter.received
+	}
+	result.Restarted = known > 0 && float64(result.Resets) >= float64(known)*restartResetRatio
+
+	for key, counter := range agent.connections {
+		if now.Sub(counter.lastSeen) > counterIdleTTL {
+			delete(agent.connections, key)
+		}
+	}
+	return result
+}
+
+// connectionKey identifies the series a connection is written to, mirroring every label the
+// ingestor writes for it: connections that differ only in egress class or direction are
+// separate series with separate counters.
+func connectionKey(endpoints []*agentv1.NetworkEndpoint, conn *agentv1.CompactNetworkConnection) (string, bool) {
+	if int(conn.SrcIndex) >= len(endpoints) || int(conn.DstIndex) >= len(endpoints) {
+		return "", false
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d/%s/%s/%s/%t", conn.Protocol, conn.EgressClass, conn.DstKind, conn.ServiceMatch, conn.IsEgress)
+	for _, ep := range []*agentv1.NetworkEndpoint{endpoints[conn.SrcIndex], endpoints[conn.DstIndex]} {
+		if ep == nil {
+			b.WriteString("|-")
+			continue
+		}
+		fmt.Fprintf(&b, "|%s/%s/%s/%s/%s/%s", ep.Ip, ep.Namespace, ep.PodName, ep.NodeName, ep.AvailabilityZone, ep.DnsName)
+		for _, svc := range ep.Services {
+			if svc != nil {
+				fmt.Fprintf(&b, "/%s.%s", svc.Namespace, svc.Name)
+			}
+		}
+	}
+	return b.String(), true
+}
+
+func reportTime(tsSeconds int64) time.Time {
+	if tsSeconds > 0 {
+		return time.Unix(tsSeconds, 0)
+	}
+	return time.Now()
+}
@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"testing"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+func counterReport(ts int64, conns ...[2]uint64) *agentv1.NetworkReportRequest {
+	req := &agentv1.NetworkReportRequest{
+		AgentId:          "agent-1",
+		TimestampSeconds: ts,
+		Endpoints: []*agentv1.NetworkEndpoint{
+			{Ip: "10.0.0.1", Namespace: "shop", PodName: "api-1"},
+			{Ip: "10.0.0.2", Namespace: "shop", PodName: "db-0"},
+			{Ip: "10.0.0.3", Namespace: "shop", PodName: "cache-0"},
+		},
+	}
+	for i, c := range conns {
+		req.CompactConnections = append(req.CompactConnections, &agentv1.CompactNetworkConnection{
+			SrcIndex: 0, DstIndex: uint32(1 + i), Protocol: 6, BytesSent: c[0], BytesReceived: c[1],
+		})
+	}
+	return req
+}
+
+func TestCounterTrackerCumulativeResets(t *testing.T) {
+	tr := newCounterTracker("")
+
+	req := counterReport(1000, [2]uint64{100, 10}, [2]uint64{50, 5})
+	if res := tr.normalize("agent-1", req); res.Resets != 0 || res.Restarted {
+		t.Fatalf("unexpected resets on first report: %+v", res)
+	}
+	if req.CompactConnections[0].BytesSent != 100 {
+		t.Fatalf("expected first report to pass through, got %d", req.CompactConnections[0].BytesSent)
+	}
+
+	// db connection was re-established, cache kept counting
+	req = counterReport(1060, [2]uint64{30, 3}, [2]uint64{80, 8})
+	res := tr.normalize("agent-1", req)
+	if res.Resets != 1 || !res.Restarted {
+		t.Fatalf("expected one reset (half the connections), got %+v", res)
+	}
+	if got := req.CompactConnections[0]; got.BytesSent != 130 || got.BytesReceived != 13 {
+		t.Fatalf("expected counting to resume after reset, got %d/%d", got.BytesSent, got.BytesReceived)
+	}
+	if got := req.CompactConnections[1]; got.BytesSent != 80 {
+		t.Fatalf("expected unchanged counter to pass through, got %d", got.BytesSent)
+	}
+
+	req = counterReport(1120, [2]uint64{40, 4}, [2]uint64{90, 9})
+	if res := tr.normalize("agent-1", req); res.Resets != 0 {
+		t.Fatalf("unexpected resets %+v", res)
+	}
+	if got := req.CompactConnections[0].BytesSent; got != 140 {
+		t.Fatalf("expected 140, got %d", got)
+	}
+}
+
+func TestCounterTrackerDeltaMode(t *testing.T) {
+	tr := newCounterTracker(CounterModeDelta)
+	for i, want := range []uint64{100, 130, 135} {
+		sent := []uint64{100, 30, 5}[i]
+		req := counterReport(int64(1000+60*i), [2]uint64{sent, 0})
+		if res := tr.normalize("agent-1", req); res.Resets != 0 {
+			t.Fatalf("deltas never reset, got %+v", res)
+		}
+		if got := req.CompactConnections[0].BytesSent; got != want {
+			t.Fatalf("report %d: expected running total %d, got %d", i, want, got)
+		}
+	}
+}
+
+func TestCounterTrackerMergesDuplicateKeys(t *testing.T) {
+	tr := newCounterTracker(CounterModeCumulative)
+	req := counterReport(1000, [2]uint64{100, 10})
+	req.CompactConnections = append(req.CompactConnections, &agentv1.CompactNetworkConnection{
+		SrcIndex: 0, DstIndex: 1, Protocol: 6, BytesSent: 20, BytesReceived: 2,
+	}, &agentv1.CompactNetworkConnection{SrcIndex: 0, DstIndex: 9})
+	tr.normalize("agent-1", req)
+	if len(req.CompactConnections) != 1 {
+		t.Fatalf("expected duplicates merged and invalid entries dropped, got %d", len(req.CompactConnections))
+	}
+	if got := req.CompactConnections[0]; got.BytesSent != 120 || got.BytesReceived != 12 {
+		t.Fatalf("expected merged bytes 120/12, got %d/%d", got.BytesSent, got.BytesReceived)
+	}
+}
+
+func TestCounterTrackerKeepsEgressClassesApart(t *testing.T) {
+	tr := newCounterTracker(CounterModeCumulative)
+	report := func(ts int64, internet, zone uint64) *agentv1.NetworkReportRequest {
+		req := counterReport(ts)
+		req.CompactConnections = []*agentv1.CompactNetworkConnection{
+			{SrcIndex: 0, DstIndex: 1, Protocol: 6, IsEgress: true, EgressClass: "internet", BytesSent: internet},
+			{SrcIndex: 0, DstIndex: 1, Protocol: 6, IsEgress: true, EgressClass: "cross_az", BytesSent: zone},
+			{SrcIndex: 0, DstIndex: 1, Protocol: 6, BytesSent: 1},
+		}
+		return req
+	}
+
+	req := report(1000, 100, 10)
+	tr.normalize("agent-1", req)
+	if len(req.CompactConnections) != 3 {
+		t.Fatalf("expected connections with different egress labels to stay apart, got %d", len(req.CompactConnections))
+	}
+	req = report(1060, 150, 20)
+	if res := tr.normalize("agent-1", req); res.Resets != 0 {
+		t.Fatalf("expected no resets when each class keeps counting, got %+v", res)
+	}
+	if got := req.CompactConnections[0].BytesSent; got != 150 {
+		t.Fatalf("expected the internet counter to pass through, got %d", got)
+	}
+}
+
+func TestCounterTrackerEvictsIdleConnections(t *testing.T) {
+	tr := newCounterTracker(CounterModeCumulative)
+	tr.normalize("agent-1", counterReport(1000, [2]uint64{100, 10}, [2]uint64{50, 5}))
+	tr.normalize("agent-1", counterReport(1000+int64(counterIdleTTL.Seconds())+60, [2]uint64{200, 20}))
+	if n := len(tr.agents["agent-1"].connections); n != 1 {
+		t.Fatalf("expected idle connection evicted, %d left", n)
+	}
+}
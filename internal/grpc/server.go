@@ -25,7 +25,7 @@ func NewServer(cfg config.Config, ingestor ReportIngestor, st *store.Store) *Ser
 
 	gsrv := grpc.NewServer(opts...)
 
-	collector := NewCollector(ingestor, st, cfg.LogLevel)
+	collector := NewCollector(ingestor, st, cfg.LogLevel, cfg.NetworkCounterMode)
 	agentv1.RegisterCollectorServer(gsrv, collector)
 
 	// Register reflection service on gRPC server (useful for grpcurl).
@@ -0,0 +1,108 @@
+package pricing
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InstanceShape is the vCPU count, memory and CPU architecture of an instance type.
+type InstanceShape struct {
+	Family       string
+	VCPUs        int
+	MemoryGiB    float64
+	Architecture string
+}
+
+// Offering is an instance type available in a region at an on-demand hourly price.
+type Offering struct {
+	Region       string
+	InstanceType string
+	HourlyPrice  float64
+	InstanceShape
+}
+
+// instanceTypePattern matches current generation compute (c), general purpose (m) and memory
+// optimized (r) families, e.g. m5.large, c6gn.2xlarge or m7i-flex.xlarge.
+var instanceTypePattern = regexp.MustCompile(`^([cmr])(\d+)([a-z]*)(-flex)?\.(medium|large|(\d*)xlarge)$`)
+
+// memoryPerVCPU is the GiB of memory per vCPU of each family class.
+var memoryPerVCPU = map[string]float64{"c": 2, "m": 4, "r": 8}
+
+// memoryPerVCPUOverrides covers families that deviate from their class.
+var memoryPerVCPUOverrides = map[string]float64{"c5n": 2.625}
+
+// ParseInstanceType derives the shape of an EC2 instance type from its name. Only generation 5
+// and newer c, m and r families are recognized; burstable, accelerated, storage optimized and
+// metal types report false.
+func ParseInstanceType(instanceType string) (InstanceShape, bool) {
+	m := instanceTypePattern.FindStringSubmatch(instanceType)
+	if m == nil {
+		return InstanceShape{}, false
+	}
+	class, attrs := m[1], m[3]
+	generation, err := strconv.Atoi(m[2])
+	if err != nil || generation < 5 {
+		return InstanceShape{}, false
+	}
+	family := class + m[2] + attrs
+
+	var vcpus int
+	switch size := m[5]; {
+	case size == "medium":
+		vcpus = 1
+	case size == "large":
+		vcpus = 2
+	case m[6] == "":
+		vcpus = 4
+	default:
+		n, err := strconv.Atoi(m[6])
+		if err != nil {
+			return InstanceShape{}, false
+		}
+		vcpus = 4 * n
+	}
+
+	perVCPU, ok := memoryPerVCPUOverrides[family]
+	if !ok {
+		perVCPU = memoryPerVCPU[class]
+	}
+	arch := "x86_64"
+	if strings.Contains(attrs, "g") {
+		// Graviton families carry a "g" attribute (m6g, c7gn, r6gd)
+		arch = "arm64"
+	}
+	return InstanceShape{Family: family + m[4], VCPUs: vcpus, MemoryGiB: float64(vcpus) * perVCPU, Architecture: arch}, true
+}
+
+// Price returns the on-demand hourly price of an instance type in a region from InstancePrices.
+func Price(region, instanceType string) (float64, bool) {
+	price, ok := InstancePrices[region+"|"+instanceType]
+	return price, ok && price > 0
+}
+
+// RegionOfferings returns the priced instance types of a region whose shape is known,
+// cheapest first.
+func RegionOfferings(region string) []Offering {
+	prefix := region + "|"
+	var out []Offering
+	for key, price := range InstancePrices {
+		if price <= 0 || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		instanceType := strings.TrimPrefix(key, prefix)
+		shape, ok := ParseInstanceType(instanceType)
+		if !ok {
+			continue
+		}
+		out = append(out, Offering{Region: region, InstanceType: instanceType, HourlyPrice: price, InstanceShape: shape})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].HourlyPrice != out[j].HourlyPrice {
+			return out[i].HourlyPrice < out[j].HourlyPrice
+		}
+		return out[i].InstanceType < out[j].InstanceType
+	})
+	return out
+}
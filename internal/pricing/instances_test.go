@@ -0,0 +1,40 @@
+package pricing
+
+import "testing"
+
+func TestParseInstanceType(t *testing.T) {
+	cases := map[string]InstanceShape{
+		"m5.large":         {Family: "m5", VCPUs: 2, MemoryGiB: 8, Architecture: "x86_64"},
+		"c6gn.2xlarge":     {Family: "c6gn", VCPUs: 8, MemoryGiB: 16, Architecture: "arm64"},
+		"r6i.xlarge":       {Family: "r6i", VCPUs: 4, MemoryGiB: 32, Architecture: "x86_64"},
+		"c5n.large":        {Family: "c5n", VCPUs: 2, MemoryGiB: 5.25, Architecture: "x86_64"},
+		"m7i-flex.4xlarge": {Family: "m7i-flex", VCPUs: 16, MemoryGiB: 64, Architecture: "x86_64"},
+		"m6g.medium":       {Family: "m6g", VCPUs: 1, MemoryGiB: 4, Architecture: "arm64"},
+	}
+	for name, want := range cases {
+		got, ok := ParseInstanceType(name)
+		if !ok || got != want {
+			t.Errorf("%s: expected %+v, got %+v (ok=%v)", name, want, got, ok)
+		}
+	}
+	for _, name := range []string{"t3.large", "m4.xlarge", "p3.2xlarge", "m5.metal", "i3.large", ""} {
+		if _, ok := ParseInstanceType(name); ok {
+			t.Errorf("%s: expected unknown shape", name)
+		}
+	}
+}
+
+func TestRegionOfferingsAreSortedByPrice(t *testing.T) {
+	offerings := RegionOfferings("us-east-1")
+	if len(offerings) == 0 {
+		t.Fatal("expected offerings for us-east-1")
+	}
+	for i := 1; i < len(offerings); i++ {
+		if offerings[i].HourlyPrice < offerings[i-1].HourlyPrice {
+			t.Fatalf("offerings not sorted at %d: %v > %v", i, offerings[i-1], offerings[i])
+		}
+	}
+	if len(RegionOfferings("mars-north-1")) != 0 {
+		t.Fatal("expected no offerings for unknown region")
+	}
+}
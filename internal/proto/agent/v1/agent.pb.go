@@ -433,7 +433,13 @@ type PodMetric struct {
 	// Cost-Aware Network
 	Network *NetworkMetrics `protobuf:"bytes,8,opt,name=network,proto3" json:"network,omitempty"`
 	// Storage I/O
-	Storage       *StorageMetrics `protobuf:"bytes,9,opt,name=storage,proto3" json:"storage,omitempty"`
+	Storage *StorageMetrics `protobuf:"bytes,9,opt,name=storage,proto3" json:"storage,omitempty"`
+	// Accelerators
+	Gpu *GpuMetrics `protobuf:"bytes,10,opt,name=gpu,proto3" json:"gpu,omitempty"`
+	// Container and owning workload (e.g. Deployment/web), used for right-sizing patches
+	ContainerName string `protobuf:"bytes,11,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	WorkloadKind  string `protobuf:"bytes,12,opt,name=workload_kind,json=workloadKind,proto3" json:"workload_kind,omitempty"`
+	WorkloadName  string `protobuf:"bytes,13,opt,name=workload_name,json=workloadName,proto3" json:"workload_name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -531,6 +537,34 @@ func (x *PodMetric) GetStorage() *StorageMetrics {
 	return nil
 }
 
+func (x *PodMetric) GetGpu() *GpuMetrics {
+	if x != nil {
+		return x.Gpu
+	}
+	return nil
+}
+
+func (x *PodMetric) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *PodMetric) GetWorkloadKind() string {
+	if x != nil {
+		return x.WorkloadKind
+	}
+	return ""
+}
+
+func (x *PodMetric) GetWorkloadName() string {
+	if x != nil {
+		return x.WorkloadName
+	}
+	return ""
+}
+
 type CpuMetrics struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// K8s Requests (mCPU)
@@ -603,7 +637,9 @@ type MemoryMetrics struct {
 	// K8s Requests (bytes)
 	RequestBytes uint64 `protobuf:"varint,3,opt,name=request_bytes,json=requestBytes,proto3" json:"request_bytes,omitempty"`
 	// K8s Limits (bytes)
-	LimitBytes    uint64 `protobuf:"varint,4,opt,name=limit_bytes,json=limitBytes,proto3" json:"limit_bytes,omitempty"`
+	LimitBytes uint64 `protobuf:"varint,4,opt,name=limit_bytes,json=limitBytes,proto3" json:"limit_bytes,omitempty"`
+	// Cumulative OOM kills of the container
+	OomKillsTotal uint64 `protobuf:"varint,5,opt,name=oom_kills_total,json=oomKillsTotal,proto3" json:"oom_kills_total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -666,6 +702,85 @@ func (x *MemoryMetrics) GetLimitBytes() uint64 {
 	return 0
 }
 
+func (x *MemoryMetrics) GetOomKillsTotal() uint64 {
+	if x != nil {
+		return x.OomKillsTotal
+	}
+	return 0
+}
+
+type GpuMetrics struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// K8s Requests (devices, e.g. nvidia.com/gpu)
+	RequestCount uint32 `protobuf:"varint,1,opt,name=request_count,json=requestCount,proto3" json:"request_count,omitempty"`
+	// Devices bound to the pod by the device plugin
+	AllocatedCount uint32 `protobuf:"varint,2,opt,name=allocated_count,json=allocatedCount,proto3" json:"allocated_count,omitempty"`
+	// Average SM utilization across allocated devices (0-100)
+	UtilizationPercent float64 `protobuf:"fixed64,3,opt,name=utilization_percent,json=utilizationPercent,proto3" json:"utilization_percent,omitempty"`
+	// Framebuffer memory in use (bytes)
+	MemoryUsedBytes uint64 `protobuf:"varint,4,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memory_used_bytes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GpuMetrics) Reset() {
+	*x = GpuMetrics{}
+	mi := &file_agent_v1_agent_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GpuMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GpuMetrics) ProtoMessage() {}
+
+func (x *GpuMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_agent_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GpuMetrics.ProtoReflect.Descriptor instead.
+func (*GpuMetrics) Descriptor() ([]byte, []int) {
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GpuMetrics) GetRequestCount() uint32 {
+	if x != nil {
+		return x.RequestCount
+	}
+	return 0
+}
+
+func (x *GpuMetrics) GetAllocatedCount() uint32 {
+	if x != nil {
+		return x.AllocatedCount
+	}
+	return 0
+}
+
+func (x *GpuMetrics) GetUtilizationPercent() float64 {
+	if x != nil {
+		return x.UtilizationPercent
+	}
+	return 0
+}
+
+func (x *GpuMetrics) GetMemoryUsedBytes() uint64 {
+	if x != nil {
+		return x.MemoryUsedBytes
+	}
+	return 0
+}
+
 type NetworkMetrics struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Throughput
@@ -681,7 +796,7 @@ type NetworkMetrics struct {
 
 func (x *NetworkMetrics) Reset() {
 	*x = NetworkMetrics{}
-	mi := &file_agent_v1_agent_proto_msgTypes[7]
+	mi := &file_agent_v1_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -693,7 +808,7 @@ func (x *NetworkMetrics) String() string {
 func (*NetworkMetrics) ProtoMessage() {}
 
 func (x *NetworkMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_agent_proto_msgTypes[7]
+	mi := &file_agent_v1_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -706,7 +821,7 @@ func (x *NetworkMetrics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NetworkMetrics.ProtoReflect.Descriptor instead.
 func (*NetworkMetrics) Descriptor() ([]byte, []int) {
-	return file_agent_v1_agent_proto_rawDescGZIP(), []int{7}
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *NetworkMetrics) GetBytesSent() uint64 {
@@ -761,7 +876,7 @@ type NetworkConnection struct {
 
 func (x *NetworkConnection) Reset() {
 	*x = NetworkConnection{}
-	mi := &file_agent_v1_agent_proto_msgTypes[8]
+	mi := &file_agent_v1_agent_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -773,7 +888,7 @@ func (x *NetworkConnection) String() string {
 func (*NetworkConnection) ProtoMessage() {}
 
 func (x *NetworkConnection) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_agent_proto_msgTypes[8]
+	mi := &file_agent_v1_agent_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -786,7 +901,7 @@ func (x *NetworkConnection) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NetworkConnection.ProtoReflect.Descriptor instead.
 func (*NetworkConnection) Descriptor() ([]byte, []int) {
-	return file_agent_v1_agent_proto_rawDescGZIP(), []int{8}
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *NetworkConnection) GetSrc() *NetworkEndpoint {
@@ -866,14 +981,27 @@ type NodeMetric struct {
 	// Total throttled CPU time from cgroup cpu.stat (ns).
 	ThrottlingNs uint64 `protobuf:"varint,10,opt,name=throttling_ns,json=throttlingNs,proto3" json:"throttling_ns,omitempty"`
 	// Cost-Aware Network (Host traffic)
-	Network       *NetworkMetrics `protobuf:"bytes,11,opt,name=network,proto3" json:"network,omitempty"`
+	Network *NetworkMetrics `protobuf:"bytes,11,opt,name=network,proto3" json:"network,omitempty"`
+	// Accelerators (devices, e.g. nvidia.com/gpu)
+	GpuCapacity    uint32 `protobuf:"varint,12,opt,name=gpu_capacity,json=gpuCapacity,proto3" json:"gpu_capacity,omitempty"`
+	GpuAllocatable uint32 `protobuf:"varint,13,opt,name=gpu_allocatable,json=gpuAllocatable,proto3" json:"gpu_allocatable,omitempty"`
+	GpuRequested   uint32 `protobuf:"varint,14,opt,name=gpu_requested,json=gpuRequested,proto3" json:"gpu_requested,omitempty"`
+	// Average SM utilization across all devices on the node (0-100)
+	GpuUtilizationPercent float64 `protobuf:"fixed64,15,opt,name=gpu_utilization_percent,json=gpuUtilizationPercent,proto3" json:"gpu_utilization_percent,omitempty"`
+	// Node state from the API server. Agents that predate these fields send no
+	// conditions, which the dashboard reports as an Unknown status.
+	Ready         bool              `protobuf:"varint,16,opt,name=ready,proto3" json:"ready,omitempty"`
+	PodCount      uint32            `protobuf:"varint,17,opt,name=pod_count,json=podCount,proto3" json:"pod_count,omitempty"`
+	Conditions    []*NodeCondition  `protobuf:"bytes,18,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,19,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Taints        []*NodeTaint      `protobuf:"bytes,20,rep,name=taints,proto3" json:"taints,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NodeMetric) Reset() {
 	*x = NodeMetric{}
-	mi := &file_agent_v1_agent_proto_msgTypes[9]
+	mi := &file_agent_v1_agent_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -885,7 +1013,7 @@ func (x *NodeMetric) String() string {
 func (*NodeMetric) ProtoMessage() {}
 
 func (x *NodeMetric) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_agent_proto_msgTypes[9]
+	mi := &file_agent_v1_agent_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -898,7 +1026,7 @@ func (x *NodeMetric) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeMetric.ProtoReflect.Descriptor instead.
 func (*NodeMetric) Descriptor() ([]byte, []int) {
-	return file_agent_v1_agent_proto_rawDescGZIP(), []int{9}
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *NodeMetric) GetNodeName() string {
@@ -978,6 +1106,192 @@ func (x *NodeMetric) GetNetwork() *NetworkMetrics {
 	return nil
 }
 
+func (x *NodeMetric) GetGpuCapacity() uint32 {
+	if x != nil {
+		return x.GpuCapacity
+	}
+	return 0
+}
+
+func (x *NodeMetric) GetGpuAllocatable() uint32 {
+	if x != nil {
+		return x.GpuAllocatable
+	}
+	return 0
+}
+
+func (x *NodeMetric) GetGpuRequested() uint32 {
+	if x != nil {
+		return x.GpuRequested
+	}
+	return 0
+}
+
+func (x *NodeMetric) GetGpuUtilizationPercent() float64 {
+	if x != nil {
+		return x.GpuUtilizationPercent
+	}
+	return 0
+}
+
+func (x *NodeMetric) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
+func (x *NodeMetric) GetPodCount() uint32 {
+	if x != nil {
+		return x.PodCount
+	}
+	return 0
+}
+
+func (x *NodeMetric) GetConditions() []*NodeCondition {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+func (x *NodeMetric) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *NodeMetric) GetTaints() []*NodeTaint {
+	if x != nil {
+		return x.Taints
+	}
+	return nil
+}
+
+type NodeCondition struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Condition type, e.g. Ready, MemoryPressure, DiskPressure, PIDPressure
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// True, False or Unknown
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeCondition) Reset() {
+	*x = NodeCondition{}
+	mi := &file_agent_v1_agent_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeCondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeCondition) ProtoMessage() {}
+
+func (x *NodeCondition) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_agent_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeCondition.ProtoReflect.Descriptor instead.
+func (*NodeCondition) Descriptor() ([]byte, []int) {
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *NodeCondition) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *NodeCondition) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *NodeCondition) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type NodeTaint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// NoSchedule, PreferNoSchedule or NoExecute
+	Effect        string `protobuf:"bytes,3,opt,name=effect,proto3" json:"effect,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeTaint) Reset() {
+	*x = NodeTaint{}
+	mi := &file_agent_v1_agent_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeTaint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeTaint) ProtoMessage() {}
+
+func (x *NodeTaint) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_agent_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeTaint.ProtoReflect.Descriptor instead.
+func (*NodeTaint) Descriptor() ([]byte, []int) {
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *NodeTaint) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *NodeTaint) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *NodeTaint) GetEffect() string {
+	if x != nil {
+		return x.Effect
+	}
+	return ""
+}
+
 type NetworkEndpoint struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Ip               string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
@@ -993,7 +1307,7 @@ type NetworkEndpoint struct {
 
 func (x *NetworkEndpoint) Reset() {
 	*x = NetworkEndpoint{}
-	mi := &file_agent_v1_agent_proto_msgTypes[10]
+	mi := &file_agent_v1_agent_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1005,7 +1319,7 @@ func (x *NetworkEndpoint) String() string {
 func (*NetworkEndpoint) ProtoMessage() {}
 
 func (x *NetworkEndpoint) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_agent_proto_msgTypes[10]
+	mi := &file_agent_v1_agent_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1018,7 +1332,7 @@ func (x *NetworkEndpoint) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NetworkEndpoint.ProtoReflect.Descriptor instead.
 func (*NetworkEndpoint) Descriptor() ([]byte, []int) {
-	return file_agent_v1_agent_proto_rawDescGZIP(), []int{10}
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *NetworkEndpoint) GetIp() string {
@@ -1080,7 +1394,7 @@ type ServiceRef struct {
 
 func (x *ServiceRef) Reset() {
 	*x = ServiceRef{}
-	mi := &file_agent_v1_agent_proto_msgTypes[11]
+	mi := &file_agent_v1_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1092,7 +1406,7 @@ func (x *ServiceRef) String() string {
 func (*ServiceRef) ProtoMessage() {}
 
 func (x *ServiceRef) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_agent_proto_msgTypes[11]
+	mi := &file_agent_v1_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1105,7 +1419,7 @@ func (x *ServiceRef) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServiceRef.ProtoReflect.Descriptor instead.
 func (*ServiceRef) Descriptor() ([]byte, []int) {
-	return file_agent_v1_agent_proto_rawDescGZIP(), []int{11}
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ServiceRef) GetNamespace() string {
@@ -1132,13 +1446,15 @@ type StorageMetrics struct {
 	WriteOps uint64 `protobuf:"varint,4,opt,name=write_ops,json=writeOps,proto3" json:"write_ops,omitempty"`
 	// Latency
 	TotalLatencyNs uint64 `protobuf:"varint,5,opt,name=total_latency_ns,json=totalLatencyNs,proto3" json:"total_latency_ns,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Persistent volumes mounted by the pod
+	Volumes       []*VolumeMetric `protobuf:"bytes,6,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StorageMetrics) Reset() {
 	*x = StorageMetrics{}
-	mi := &file_agent_v1_agent_proto_msgTypes[12]
+	mi := &file_agent_v1_agent_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1150,7 +1466,7 @@ func (x *StorageMetrics) String() string {
 func (*StorageMetrics) ProtoMessage() {}
 
 func (x *StorageMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_agent_proto_msgTypes[12]
+	mi := &file_agent_v1_agent_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1163,7 +1479,7 @@ func (x *StorageMetrics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StorageMetrics.ProtoReflect.Descriptor instead.
 func (*StorageMetrics) Descriptor() ([]byte, []int) {
-	return file_agent_v1_agent_proto_rawDescGZIP(), []int{12}
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *StorageMetrics) GetReadBytes() uint64 {
@@ -1201,6 +1517,83 @@ func (x *StorageMetrics) GetTotalLatencyNs() uint64 {
 	return 0
 }
 
+func (x *StorageMetrics) GetVolumes() []*VolumeMetric {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+type VolumeMetric struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	PvcName      string                 `protobuf:"bytes,1,opt,name=pvc_name,json=pvcName,proto3" json:"pvc_name,omitempty"`
+	StorageClass string                 `protobuf:"bytes,2,opt,name=storage_class,json=storageClass,proto3" json:"storage_class,omitempty"`
+	// Provisioned size (PVC capacity)
+	CapacityBytes uint64 `protobuf:"varint,3,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"`
+	// Filesystem usage as reported by kubelet
+	UsedBytes     uint64 `protobuf:"varint,4,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VolumeMetric) Reset() {
+	*x = VolumeMetric{}
+	mi := &file_agent_v1_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeMetric) ProtoMessage() {}
+
+func (x *VolumeMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeMetric.ProtoReflect.Descriptor instead.
+func (*VolumeMetric) Descriptor() ([]byte, []int) {
+	return file_agent_v1_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VolumeMetric) GetPvcName() string {
+	if x != nil {
+		return x.PvcName
+	}
+	return ""
+}
+
+func (x *VolumeMetric) GetStorageClass() string {
+	if x != nil {
+		return x.StorageClass
+	}
+	return ""
+}
+
+func (x *VolumeMetric) GetCapacityBytes() uint64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *VolumeMetric) GetUsedBytes() uint64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
 var File_agent_v1_agent_proto protoreflect.FileDescriptor
 
 const file_agent_v1_agent_proto_rawDesc = "" +
@@ -1245,7 +1638,7 @@ const file_agent_v1_agent_proto_rawDesc = "" +
 	" \x01(\bR\bisEgress\"Q\n" +
 	"\x0eReportResponse\x12\x1a\n" +
 	"\baccepted\x18\x01 \x01(\bR\baccepted\x12#\n" +
-	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\"\xdc\x02\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\"\xf5\x03\n" +
 	"\tPodMetric\x12\x17\n" +
 	"\apod_uid\x18\x01 \x01(\tR\x06podUid\x12!\n" +
 	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\x12\x19\n" +
@@ -1255,18 +1648,30 @@ const file_agent_v1_agent_proto_rawDesc = "" +
 	"\x03cpu\x18\x06 \x01(\v2\x14.agent.v1.CpuMetricsR\x03cpu\x12/\n" +
 	"\x06memory\x18\a \x01(\v2\x17.agent.v1.MemoryMetricsR\x06memory\x122\n" +
 	"\anetwork\x18\b \x01(\v2\x18.agent.v1.NetworkMetricsR\anetwork\x122\n" +
-	"\astorage\x18\t \x01(\v2\x18.agent.v1.StorageMetricsR\astorage\"\x91\x01\n" +
+	"\astorage\x18\t \x01(\v2\x18.agent.v1.StorageMetricsR\astorage\x12&\n" +
+	"\x03gpu\x18\n" +
+	" \x01(\v2\x14.agent.v1.GpuMetricsR\x03gpu\x12%\n" +
+	"\x0econtainer_name\x18\v \x01(\tR\rcontainerName\x12#\n" +
+	"\rworkload_kind\x18\f \x01(\tR\fworkloadKind\x12#\n" +
+	"\rworkload_name\x18\r \x01(\tR\fworkloadName\"\x91\x01\n" +
 	"\n" +
 	"CpuMetrics\x12-\n" +
 	"\x12request_millicores\x18\x04 \x01(\x04R\x11requestMillicores\x12)\n" +
 	"\x10limit_millicores\x18\x05 \x01(\x04R\x0flimitMillicores\x12)\n" +
-	"\x10usage_millicores\x18\x06 \x01(\x04R\x0fusageMillicores\"\x9e\x01\n" +
+	"\x10usage_millicores\x18\x06 \x01(\x04R\x0fusageMillicores\"\xc6\x01\n" +
 	"\rMemoryMetrics\x12\x1b\n" +
 	"\trss_bytes\x18\x01 \x01(\x04R\brssBytes\x12*\n" +
 	"\x11page_faults_major\x18\x02 \x01(\x04R\x0fpageFaultsMajor\x12#\n" +
 	"\rrequest_bytes\x18\x03 \x01(\x04R\frequestBytes\x12\x1f\n" +
 	"\vlimit_bytes\x18\x04 \x01(\x04R\n" +
-	"limitBytes\"\xed\x01\n" +
+	"limitBytes\x12&\n" +
+	"\x0foom_kills_total\x18\x05 \x01(\x04R\roomKillsTotal\"\xb7\x01\n" +
+	"\n" +
+	"GpuMetrics\x12#\n" +
+	"\rrequest_count\x18\x01 \x01(\rR\frequestCount\x12'\n" +
+	"\x0fallocated_count\x18\x02 \x01(\rR\x0eallocatedCount\x12/\n" +
+	"\x13utilization_percent\x18\x03 \x01(\x01R\x12utilizationPercent\x12*\n" +
+	"\x11memory_used_bytes\x18\x04 \x01(\x04R\x0fmemoryUsedBytes\"\xed\x01\n" +
 	"\x0eNetworkMetrics\x12\x1d\n" +
 	"\n" +
 	"bytes_sent\x18\x01 \x01(\x04R\tbytesSent\x12%\n" +
@@ -1285,7 +1690,7 @@ const file_agent_v1_agent_proto_rawDesc = "" +
 	"\bdst_kind\x18\b \x01(\tR\adstKind\x12#\n" +
 	"\rservice_match\x18\t \x01(\tR\fserviceMatch\x12\x1b\n" +
 	"\tis_egress\x18\n" +
-	" \x01(\bR\bisEgress\"\xb6\x04\n" +
+	" \x01(\bR\bisEgress\"\xed\a\n" +
 	"\n" +
 	"NodeMetric\x12\x1b\n" +
 	"\tnode_name\x18\x01 \x01(\tR\bnodeName\x120\n" +
@@ -1299,7 +1704,29 @@ const file_agent_v1_agent_proto_rawDesc = "" +
 	"\x16requested_memory_bytes\x18\t \x01(\x04R\x14requestedMemoryBytes\x12#\n" +
 	"\rthrottling_ns\x18\n" +
 	" \x01(\x04R\fthrottlingNs\x122\n" +
-	"\anetwork\x18\v \x01(\v2\x18.agent.v1.NetworkMetricsR\anetwork\"\xf1\x01\n" +
+	"\anetwork\x18\v \x01(\v2\x18.agent.v1.NetworkMetricsR\anetwork\x12!\n" +
+	"\fgpu_capacity\x18\f \x01(\rR\vgpuCapacity\x12'\n" +
+	"\x0fgpu_allocatable\x18\r \x01(\rR\x0egpuAllocatable\x12#\n" +
+	"\rgpu_requested\x18\x0e \x01(\rR\fgpuRequested\x126\n" +
+	"\x17gpu_utilization_percent\x18\x0f \x01(\x01R\x15gpuUtilizationPercent\x12\x14\n" +
+	"\x05ready\x18\x10 \x01(\bR\x05ready\x12\x1b\n" +
+	"\tpod_count\x18\x11 \x01(\rR\bpodCount\x127\n" +
+	"\n" +
+	"conditions\x18\x12 \x03(\v2\x17.agent.v1.NodeConditionR\n" +
+	"conditions\x128\n" +
+	"\x06labels\x18\x13 \x03(\v2 .agent.v1.NodeMetric.LabelsEntryR\x06labels\x12+\n" +
+	"\x06taints\x18\x14 \x03(\v2\x13.agent.v1.NodeTaintR\x06taints\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"S\n" +
+	"\rNodeCondition\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"K\n" +
+	"\tNodeTaint\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x16\n" +
+	"\x06effect\x18\x03 \x01(\tR\x06effect\"\xf1\x01\n" +
 	"\x0fNetworkEndpoint\x12\x0e\n" +
 	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x1c\n" +
 	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x19\n" +
@@ -1311,7 +1738,7 @@ const file_agent_v1_agent_proto_rawDesc = "" +
 	"\n" +
 	"ServiceRef\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\"\xb2\x01\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xe4\x01\n" +
 	"\x0eStorageMetrics\x12\x1d\n" +
 	"\n" +
 	"read_bytes\x18\x01 \x01(\x04R\treadBytes\x12\x1f\n" +
@@ -1319,7 +1746,14 @@ const file_agent_v1_agent_proto_rawDesc = "" +
 	"writeBytes\x12\x19\n" +
 	"\bread_ops\x18\x03 \x01(\x04R\areadOps\x12\x1b\n" +
 	"\twrite_ops\x18\x04 \x01(\x04R\bwriteOps\x12(\n" +
-	"\x10total_latency_ns\x18\x05 \x01(\x04R\x0etotalLatencyNs2\xa1\x01\n" +
+	"\x10total_latency_ns\x18\x05 \x01(\x04R\x0etotalLatencyNs\x120\n" +
+	"\avolumes\x18\x06 \x03(\v2\x16.agent.v1.VolumeMetricR\avolumes\"\x94\x01\n" +
+	"\fVolumeMetric\x12\x19\n" +
+	"\bpvc_name\x18\x01 \x01(\tR\apvcName\x12#\n" +
+	"\rstorage_class\x18\x02 \x01(\tR\fstorageClass\x12%\n" +
+	"\x0ecapacity_bytes\x18\x03 \x01(\x04R\rcapacityBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x04 \x01(\x04R\tusedBytes2\xa1\x01\n" +
 	"\tCollector\x12I\n" +
 	"\rReportMetrics\x12\x1e.agent.v1.MetricsReportRequest\x1a\x18.agent.v1.ReportResponse\x12I\n" +
 	"\rReportNetwork\x12\x1e.agent.v1.NetworkReportRequest\x1a\x18.agent.v1.ReportResponseB7Z5clustercost-agent-k8s/internal/proto/agent/v1;agentv1b\x06proto3"
@@ -1336,7 +1770,7 @@ func file_agent_v1_agent_proto_rawDescGZIP() []byte {
 	return file_agent_v1_agent_proto_rawDescData
 }
 
-var file_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_agent_v1_agent_proto_goTypes = []any{
 	(*MetricsReportRequest)(nil),     // 0: agent.v1.MetricsReportRequest
 	(*NetworkReportRequest)(nil),     // 1: agent.v1.NetworkReportRequest
@@ -1345,37 +1779,47 @@ var file_agent_v1_agent_proto_goTypes = []any{
 	(*PodMetric)(nil),                // 4: agent.v1.PodMetric
 	(*CpuMetrics)(nil),               // 5: agent.v1.CpuMetrics
 	(*MemoryMetrics)(nil),            // 6: agent.v1.MemoryMetrics
-	(*NetworkMetrics)(nil),           // 7: agent.v1.NetworkMetrics
-	(*NetworkConnection)(nil),        // 8: agent.v1.NetworkConnection
-	(*NodeMetric)(nil),               // 9: agent.v1.NodeMetric
-	(*NetworkEndpoint)(nil),          // 10: agent.v1.NetworkEndpoint
-	(*ServiceRef)(nil),               // 11: agent.v1.ServiceRef
-	(*StorageMetrics)(nil),           // 12: agent.v1.StorageMetrics
+	(*GpuMetrics)(nil),               // 7: agent.v1.GpuMetrics
+	(*NetworkMetrics)(nil),           // 8: agent.v1.NetworkMetrics
+	(*NetworkConnection)(nil),        // 9: agent.v1.NetworkConnection
+	(*NodeMetric)(nil),               // 10: agent.v1.NodeMetric
+	(*NodeCondition)(nil),            // 11: agent.v1.NodeCondition
+	(*NodeTaint)(nil),                // 12: agent.v1.NodeTaint
+	(*NetworkEndpoint)(nil),          // 13: agent.v1.NetworkEndpoint
+	(*ServiceRef)(nil),               // 14: agent.v1.ServiceRef
+	(*StorageMetrics)(nil),           // 15: agent.v1.StorageMetrics
+	(*VolumeMetric)(nil),             // 16: agent.v1.VolumeMetric
+	nil,                              // 17: agent.v1.NodeMetric.LabelsEntry
 }
 var file_agent_v1_agent_proto_depIdxs = []int32{
 	4,  // 0: agent.v1.MetricsReportRequest.pods:type_name -> agent.v1.PodMetric
-	9,  // 1: agent.v1.MetricsReportRequest.nodes:type_name -> agent.v1.NodeMetric
-	10, // 2: agent.v1.NetworkReportRequest.endpoints:type_name -> agent.v1.NetworkEndpoint
+	10, // 1: agent.v1.MetricsReportRequest.nodes:type_name -> agent.v1.NodeMetric
+	13, // 2: agent.v1.NetworkReportRequest.endpoints:type_name -> agent.v1.NetworkEndpoint
 	2,  // 3: agent.v1.NetworkReportRequest.compact_connections:type_name -> agent.v1.CompactNetworkConnection
-	8,  // 4: agent.v1.NetworkReportRequest.connections:type_name -> agent.v1.NetworkConnection
+	9,  // 4: agent.v1.NetworkReportRequest.connections:type_name -> agent.v1.NetworkConnection
 	4,  // 5: agent.v1.NetworkReportRequest.pods:type_name -> agent.v1.PodMetric
 	5,  // 6: agent.v1.PodMetric.cpu:type_name -> agent.v1.CpuMetrics
 	6,  // 7: agent.v1.PodMetric.memory:type_name -> agent.v1.MemoryMetrics
-	7,  // 8: agent.v1.PodMetric.network:type_name -> agent.v1.NetworkMetrics
-	12, // 9: agent.v1.PodMetric.storage:type_name -> agent.v1.StorageMetrics
-	10, // 10: agent.v1.NetworkConnection.src:type_name -> agent.v1.NetworkEndpoint
-	10, // 11: agent.v1.NetworkConnection.dst:type_name -> agent.v1.NetworkEndpoint
-	7,  // 12: agent.v1.NodeMetric.network:type_name -> agent.v1.NetworkMetrics
-	11, // 13: agent.v1.NetworkEndpoint.services:type_name -> agent.v1.ServiceRef
-	0,  // 14: agent.v1.Collector.ReportMetrics:input_type -> agent.v1.MetricsReportRequest
-	1,  // 15: agent.v1.Collector.ReportNetwork:input_type -> agent.v1.NetworkReportRequest
-	3,  // 16: agent.v1.Collector.ReportMetrics:output_type -> agent.v1.ReportResponse
-	3,  // 17: agent.v1.Collector.ReportNetwork:output_type -> agent.v1.ReportResponse
-	16, // [16:18] is the sub-list for method output_type
-	14, // [14:16] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	8,  // 8: agent.v1.PodMetric.network:type_name -> agent.v1.NetworkMetrics
+	15, // 9: agent.v1.PodMetric.storage:type_name -> agent.v1.StorageMetrics
+	7,  // 10: agent.v1.PodMetric.gpu:type_name -> agent.v1.GpuMetrics
+	13, // 11: agent.v1.NetworkConnection.src:type_name -> agent.v1.NetworkEndpoint
+	13, // 12: agent.v1.NetworkConnection.dst:type_name -> agent.v1.NetworkEndpoint
+	8,  // 13: agent.v1.NodeMetric.network:type_name -> agent.v1.NetworkMetrics
+	11, // 14: agent.v1.NodeMetric.conditions:type_name -> agent.v1.NodeCondition
+	17, // 15: agent.v1.NodeMetric.labels:type_name -> agent.v1.NodeMetric.LabelsEntry
+	12, // 16: agent.v1.NodeMetric.taints:type_name -> agent.v1.NodeTaint
+	14, // 17: agent.v1.NetworkEndpoint.services:type_name -> agent.v1.ServiceRef
+	16, // 18: agent.v1.StorageMetrics.volumes:type_name -> agent.v1.VolumeMetric
+	0,  // 19: agent.v1.Collector.ReportMetrics:input_type -> agent.v1.MetricsReportRequest
+	1,  // 20: agent.v1.Collector.ReportNetwork:input_type -> agent.v1.NetworkReportRequest
+	3,  // 21: agent.v1.Collector.ReportMetrics:output_type -> agent.v1.ReportResponse
+	3,  // 22: agent.v1.Collector.ReportNetwork:output_type -> agent.v1.ReportResponse
+	21, // [21:23] is the sub-list for method output_type
+	19, // [19:21] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_agent_v1_agent_proto_init() }
@@ -1389,7 +1833,7 @@ func file_agent_v1_agent_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_v1_agent_proto_rawDesc), len(file_agent_v1_agent_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,170 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds the whole SMTP exchange of one message.
+const smtpTimeout = time.Minute
+
+// ErrMailerNotConfigured is returned for email schedules when no SMTP server is set up.
+var ErrMailerNotConfigured = errors.New("smtp is not configured")
+
+// Mailer sends plain text email.
+type Mailer interface {
+	Send(ctx context.Context, to []string, subject, body string) error
+}
+
+// SMTPMailer delivers email through an SMTP relay. STARTTLS is used when the server offers it.
+type SMTPMailer struct {
+	addr    string
+	from    string
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+// NewSMTPMailer returns a mailer for host:port, or nil when host is empty.
+// Credentials are optional; without them the relay must accept unauthenticated mail.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	if host == "" {
+		return nil
+	}
+	if port <= 0 {
+		port = 587
+	}
+	if from == "" {
+		from = "clustercost@localhost"
+	}
+	m := &SMTPMailer{addr: net.JoinHostPort(host, strconv.Itoa(port)), from: from, timeout: smtpTimeout}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// Send delivers one message to all recipients.
+func (m *SMTPMailer) Send(ctx context.Context, to []string, subject, body string) error {
+	if m == nil {
+		return ErrMailerNotConfigured
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	// Strip line breaks so user supplied names cannot inject headers.
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if err := m.deliver(ctx, to, msg.Bytes()); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+// deliver runs the SMTP exchange of smtp.SendMail on a connection that is closed when ctx is
+// done and has a deadline of at most m.timeout, so a stalled relay does not outlive the caller
+// even when ctx never ends.
+func (m *SMTPMailer) deliver(ctx context.Context, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	deadline := time.Now().Add(m.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(m.auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(m.from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// webhookPayload carries the rendered text for chat receivers (Slack "text") and the structured report.
+type webhookPayload struct {
+	Text   string `json:"text"`
+	Report Report `json:"report"`
+}
+
+func postWebhook(ctx context.Context, client *http.Client, url string, r Report) error {
+	body, err := json.Marshal(webhookPayload{Text: r.Text(), Report: r})
+	if err != nil {
+		return fmt.Errorf("encode report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+const (
+	// topNamespaceLimit is how many namespaces a report lists by cost.
+	topNamespaceLimit = 10
+	// hoursPerMonth matches the dashboard monthly projection.
+	hoursPerMonth = 24 * 30
+)
+
+// Source provides the cost data rendered into reports.
+type Source interface {
+	Overview(ctx context.Context, limit int, mode store.AllocationMode) (store.OverviewPayload, error)
+	NamespaceDetail(ctx context.Context, name string) (store.NamespaceSummary, error)
+	CostSince(ctx context.Context, since time.Time, scope map[string]string) (float64, error)
+}
+
+// Report is the content of one scheduled delivery.
+type Report struct {
+	ScheduleID        int64                     `json:"scheduleId"`
+	ScheduleName      string                    `json:"scheduleName"`
+	Frequency         db.ReportFrequency        `json:"frequency"`
+	ClusterID         string                    `json:"clusterId,omitempty"`
+	Namespaces        []string                  `json:"namespaces,omitempty"`
+	GeneratedAt       time.Time                 `json:"generatedAt"`
+	PeriodStart       time.Time                 `json:"periodStart"`
+	PeriodCost        float64                   `json:"periodCost"`
+	HourlyCost        float64                   `json:"hourlyCost"`
+	MonthlyCost       float64                   `json:"monthlyCost"`
+	TopNamespaces     []store.TopNamespaceEntry `json:"topNamespaces"`
+	SavingsCandidates []store.SavingsCandidate  `json:"savingsCandidates"`
+}
+
+// Build renders a report for a schedule. A namespace filter narrows totals, top namespaces and
+// savings candidates to the listed namespaces.
+func Build(ctx context.Context, src Source, schedule db.ReportSchedule, now time.Time) (Report, error) {
+	ctx = vm.WithClusterID(ctx, schedule.ClusterID)
+	now = now.UTC()
+	report := Report{
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		Frequency:    schedule.Frequency,
+		ClusterID:    schedule.ClusterID,
+		Namespaces:   schedule.Namespaces,
+		GeneratedAt:  now,
+		PeriodStart:  now.Add(-schedule.Frequency.Period(now)),
+	}
+
+	overview, err := src.Overview(ctx, topNamespaceLimit, store.AllocationUsage)
+	if err != nil {
+		return Report{}, err
+	}
+
+	if len(schedule.Namespaces) == 0 {
+		report.HourlyCost = overview.TotalHourlyCost
+		report.MonthlyCost = overview.TotalMonthlyCost
+		report.TopNamespaces = overview.TopNamespacesByCost
+		report.SavingsCandidates = overview.SavingsCandidates
+		report.PeriodCost, err = src.CostSince(ctx, report.PeriodStart, nil)
+		if err != nil && err != vm.ErrNoData {
+			return Report{}, err
+		}
+		return report, nil
+	}
+
+	selected := make(map[string]bool, len(schedule.Namespaces))
+	for _, ns := range schedule.Namespaces {
+		selected[ns] = true
+		detail, err := src.NamespaceDetail(ctx, ns)
+		if err == vm.ErrNoData {
+			continue
+		}
+		if err != nil {
+			return Report{}, err
+		}
+		report.HourlyCost += detail.HourlyCost
+		report.TopNamespaces = append(report.TopNamespaces, store.TopNamespaceEntry{
+			Namespace:   detail.Namespace,
+			Environment: detail.Environment,
+			HourlyCost:  detail.HourlyCost,
+		})
+		spent, err := src.CostSince(ctx, report.PeriodStart, map[string]string{"namespace": ns})
+		if err != nil && err != vm.ErrNoData {
+			return Report{}, err
+		}
+		report.PeriodCost += spent
+	}
+	report.MonthlyCost = report.HourlyCost * hoursPerMonth
+	sort.Slice(report.TopNamespaces, func(i, j int) bool {
+		return report.TopNamespaces[i].HourlyCost > report.TopNamespaces[j].HourlyCost
+	})
+	if len(report.TopNamespaces) > topNamespaceLimit {
+		report.TopNamespaces = report.TopNamespaces[:topNamespaceLimit]
+	}
+	for _, candidate := range overview.SavingsCandidates {
+		if selected[candidate.Namespace] {
+			report.SavingsCandidates = append(report.SavingsCandidates, candidate)
+		}
+	}
+	return report, nil
+}
+
+// Subject is the email subject line.
+func (r Report) Subject() string {
+	return fmt.Sprintf("[ClusterCost] %s - %s cost report", r.ScheduleName, r.Frequency)
+}
+
+// Text renders the report as plain text for email bodies and chat webhooks.
+func (r Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ClusterCost %s report: %s\n", r.Frequency, r.ScheduleName)
+	if r.ClusterID != "" {
+		fmt.Fprintf(&b, "Cluster: %s\n", r.ClusterID)
+	}
+	if len(r.Namespaces) > 0 {
+		fmt.Fprintf(&b, "Namespaces: %s\n", strings.Join(r.Namespaces, ", "))
+	}
+	fmt.Fprintf(&b, "Generated: %s\n\n", r.GeneratedAt.Format(time.RFC1123))
+
+	fmt.Fprintf(&b, "Spend since %s: $%.2f\n", r.PeriodStart.Format("2006-01-02 15:04 MST"), r.PeriodCost)
+	fmt.Fprintf(&b, "Current run rate: $%.2f/h ($%.2f/month)\n", r.HourlyCost, r.MonthlyCost)
+
+	b.WriteString("\nTop namespaces by cost:\n")
+	if len(r.TopNamespaces) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for i, ns := range r.TopNamespaces {
+		fmt.Fprintf(&b, "  %d. %s  $%.2f/h\n", i+1, ns.Namespace, ns.HourlyCost)
+	}
+
+	b.WriteString("\nSavings candidates:\n")
+	if len(r.SavingsCandidates) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, c := range r.SavingsCandidates {
+		fmt.Fprintf(&b, "  - %s  $%.2f/h, CPU %s of request, memory %s of request\n",
+			c.Namespace, c.HourlyCost, percentOf(c.CPUUsageMilli, c.CPURequestMilli), percentOf(c.MemoryUsageBytes, c.MemoryRequestBytes))
+	}
+	return b.String()
+}
+
+func percentOf(usage, request int64) string {
+	if request <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f%%", float64(usage)/float64(request)*100)
+}
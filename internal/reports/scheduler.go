@@ -0,0 +1,135 @@
+package reports
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+)
+
+const (
+	// checkInterval is how often the scheduler looks for due schedules.
+	checkInterval = time.Minute
+	// runTimeout bounds one scheduled delivery so a stalled relay or webhook cannot hold up
+	// the schedules after it.
+	runTimeout = 2 * time.Minute
+
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Scheduler delivers stored report schedules when they are due and records every run.
+type Scheduler struct {
+	db         *db.Store
+	source     Source
+	mailer     Mailer
+	client     *http.Client
+	logger     *log.Logger
+	runTimeout time.Duration
+	now        func() time.Time
+}
+
+// NewScheduler creates a report scheduler. mailer may be nil when SMTP is not configured;
+// email schedules then fail with ErrMailerNotConfigured.
+func NewScheduler(database *db.Store, source Source, mailer Mailer, logger *log.Logger) *Scheduler {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &Scheduler{
+		db:         database,
+		source:     source,
+		mailer:     mailer,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+		runTimeout: runTimeout,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run delivers due schedules every minute until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RunDue(ctx); err != nil {
+				s.logger.Printf("report scheduling failed: %v", err)
+			}
+		}
+	}
+}
+
+// RunDue delivers every enabled schedule whose next run has passed. Delivery failures are
+// recorded in the run history and do not stop the pass; each delivery gets runTimeout.
+func (s *Scheduler) RunDue(ctx context.Context) error {
+	schedules, err := s.db.ListReportSchedules()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	for _, schedule := range schedules {
+		if !schedule.Enabled || schedule.NextRunAt.IsZero() || schedule.NextRunAt.After(now) {
+			continue
+		}
+		runCtx, cancel := context.WithTimeout(ctx, s.runTimeout)
+		_, err := s.RunSchedule(runCtx, schedule, TriggerSchedule)
+		cancel()
+		if err != nil {
+			s.logger.Printf("report schedule %d (%s): %v", schedule.ID, schedule.Name, err)
+		}
+	}
+	return nil
+}
+
+// RunSchedule builds and delivers a report now and records the run. Scheduled runs also advance
+// the schedule to its next slot; manual runs leave it unchanged.
+func (s *Scheduler) RunSchedule(ctx context.Context, schedule db.ReportSchedule, trigger string) (db.ReportRun, error) {
+	started := s.now()
+	deliveryErr := s.deliver(ctx, schedule, started)
+
+	run := db.ReportRun{
+		ScheduleID: schedule.ID,
+		Trigger:    trigger,
+		Status:     db.ReportRunSuccess,
+		StartedAt:  started,
+		FinishedAt: s.now(),
+	}
+	if deliveryErr != nil {
+		run.Status = db.ReportRunFailed
+		run.Error = deliveryErr.Error()
+	}
+	run, err := s.db.RecordReportRun(run)
+	if err != nil {
+		return run, err
+	}
+
+	next := schedule.NextRunAt
+	if trigger == TriggerSchedule {
+		next = schedule.NextRun(started)
+	}
+	if err := s.db.MarkReportScheduleRun(schedule.ID, started, next); err != nil {
+		return run, err
+	}
+	return run, deliveryErr
+}
+
+func (s *Scheduler) deliver(ctx context.Context, schedule db.ReportSchedule, now time.Time) error {
+	report, err := Build(ctx, s.source, schedule, now)
+	if err != nil {
+		return err
+	}
+	switch schedule.Channel {
+	case db.ReportChannelEmail:
+		if s.mailer == nil {
+			return ErrMailerNotConfigured
+		}
+		return s.mailer.Send(ctx, schedule.Recipients, report.Subject(), report.Text())
+	default:
+		return postWebhook(ctx, s.client, schedule.WebhookURL, report)
+	}
+}
@@ -0,0 +1,340 @@
+package reports
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/db"
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+	"github.com/clustercost/clustercost-dashboard/internal/vm"
+)
+
+type fakeSource struct{}
+
+func (fakeSource) Overview(context.Context, int, store.AllocationMode) (store.OverviewPayload, error) {
+	return store.OverviewPayload{
+		TotalHourlyCost:  3,
+		TotalMonthlyCost: 3 * hoursPerMonth,
+		TopNamespacesByCost: []store.TopNamespaceEntry{
+			{Namespace: "ml", HourlyCost: 2},
+			{Namespace: "web", HourlyCost: 1},
+		},
+		SavingsCandidates: []store.SavingsCandidate{
+			{Namespace: "web", HourlyCost: 1, CPURequestMilli: 1000, CPUUsageMilli: 100, MemoryRequestBytes: 100, MemoryUsageBytes: 50},
+		},
+	}, nil
+}
+
+func (fakeSource) NamespaceDetail(_ context.Context, name string) (store.NamespaceSummary, error) {
+	switch name {
+	case "ml":
+		return store.NamespaceSummary{Namespace: "ml", HourlyCost: 2}, nil
+	case "web":
+		return store.NamespaceSummary{Namespace: "web", HourlyCost: 1}, nil
+	}
+	return store.NamespaceSummary{}, vm.ErrNoData
+}
+
+func (fakeSource) CostSince(_ context.Context, _ time.Time, scope map[string]string) (float64, error) {
+	if scope["namespace"] == "web" {
+		return 24, nil
+	}
+	return 72, nil
+}
+
+// smtpStandIn is a minimal SMTP server that records delivered messages.
+type smtpStandIn struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []string
+	rcpts    []string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{listener: ln}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var msg strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				msg.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, msg.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func newTestStore(t *testing.T) *db.Store {
+	t.Helper()
+	sqlite, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlite.Close() })
+	return sqlite
+}
+
+func TestSchedulerDeliversDueEmailReports(t *testing.T) {
+	sqlite := newTestStore(t)
+	smtpServer := newSMTPStandIn(t)
+	mailer := NewSMTPMailer("127.0.0.1", smtpServer.port(), "", "", "reports@example.com")
+
+	schedule, err := sqlite.CreateReportSchedule(db.ReportSchedule{
+		Name:       "finance",
+		Frequency:  db.ReportDaily,
+		Hour:       7,
+		Namespaces: []string{"web"},
+		Channel:    db.ReportChannelEmail,
+		Recipients: []string{"finance@example.com"},
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("CreateReportSchedule: %v", err)
+	}
+
+	scheduler := NewScheduler(sqlite, fakeSource{}, mailer, nil)
+	due := schedule.NextRunAt.Add(time.Minute)
+	scheduler.now = func() time.Time { return due }
+
+	if err := scheduler.RunDue(context.Background()); err != nil {
+		t.Fatalf("RunDue: %v", err)
+	}
+	smtpServer.mu.Lock()
+	messages, rcpts := smtpServer.messages, smtpServer.rcpts
+	smtpServer.mu.Unlock()
+	if len(messages) != 1 || len(rcpts) != 1 || rcpts[0] != "finance@example.com" {
+		t.Fatalf("expected one email to finance, got %d messages to %v", len(messages), rcpts)
+	}
+	msg := messages[0]
+	for _, want := range []string{"Subject: [ClusterCost] finance - daily cost report", "Spend since", "$24.00", "1. web", "CPU 10% of request"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("expected email to contain %q:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "ml") {
+		t.Fatalf("expected namespace filter to exclude ml:\n%s", msg)
+	}
+
+	runs, err := sqlite.ListReportRuns(schedule.ID, 10)
+	if err != nil || len(runs) != 1 || runs[0].Status != db.ReportRunSuccess || runs[0].Trigger != TriggerSchedule {
+		t.Fatalf("expected one successful run, got %+v (%v)", runs, err)
+	}
+	updated, _ := sqlite.GetReportSchedule(schedule.ID)
+	if !updated.NextRunAt.Equal(schedule.NextRunAt.AddDate(0, 0, 1)) {
+		t.Fatalf("expected next run to advance a day, got %s", updated.NextRunAt)
+	}
+
+	// Not due again until the next slot
+	if err := scheduler.RunDue(context.Background()); err != nil {
+		t.Fatalf("RunDue: %v", err)
+	}
+	if runs, _ := sqlite.ListReportRuns(schedule.ID, 10); len(runs) != 1 {
+		t.Fatalf("expected no second run, got %d", len(runs))
+	}
+}
+
+func TestSchedulerRecordsWebhookFailures(t *testing.T) {
+	sqlite := newTestStore(t)
+	var received webhookPayload
+	status := http.StatusOK
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer sink.Close()
+
+	schedule, err := sqlite.CreateReportSchedule(db.ReportSchedule{
+		Name:       "slack",
+		Frequency:  db.ReportWeekly,
+		Channel:    db.ReportChannelWebhook,
+		WebhookURL: sink.URL,
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("CreateReportSchedule: %v", err)
+	}
+	scheduler := NewScheduler(sqlite, fakeSource{}, nil, nil)
+
+	run, err := scheduler.RunSchedule(context.Background(), schedule, TriggerManual)
+	if err != nil || run.Status != db.ReportRunSuccess {
+		t.Fatalf("expected success, got %+v (%v)", run, err)
+	}
+	if received.Report.PeriodCost != 72 || len(received.Report.TopNamespaces) != 2 || !strings.Contains(received.Text, "weekly report") {
+		t.Fatalf("unexpected webhook payload: %+v", received)
+	}
+	unchanged, _ := sqlite.GetReportSchedule(schedule.ID)
+	if !unchanged.NextRunAt.Equal(schedule.NextRunAt) {
+		t.Fatalf("expected manual run to keep next run, got %s", unchanged.NextRunAt)
+	}
+
+	status = http.StatusInternalServerError
+	run, err = scheduler.RunSchedule(context.Background(), schedule, TriggerManual)
+	if err == nil || run.Status != db.ReportRunFailed || !strings.Contains(run.Error, strconv.Itoa(status)) {
+		t.Fatalf("expected recorded failure, got %+v (%v)", run, err)
+	}
+
+	email := schedule
+	email.Channel = db.ReportChannelEmail
+	email.Recipients = []string{"a@example.com"}
+	if run, err := scheduler.RunSchedule(context.Background(), email, TriggerManual); err != ErrMailerNotConfigured || run.Status != db.ReportRunFailed {
+		t.Fatalf("expected ErrMailerNotConfigured, got %+v (%v)", run, err)
+	}
+}
+
+// newStalledRelay accepts connections but never greets, like a hung SMTP relay, and returns its port.
+func newStalledRelay(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = conn.Read(make([]byte, 1))
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestSMTPMailerStopsOnCancel(t *testing.T) {
+	port := newStalledRelay(t)
+	mailer := NewSMTPMailer("127.0.0.1", port, "", "", "reports@example.com")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- mailer.Send(ctx, []string{"finance@example.com"}, "subject", "body") }()
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("send did not return after the context expired")
+	}
+}
+
+func TestSMTPMailerTimesOutWithoutContextDeadline(t *testing.T) {
+	mailer := NewSMTPMailer("127.0.0.1", newStalledRelay(t), "", "", "reports@example.com")
+	mailer.timeout = 50 * time.Millisecond
+
+	done := make(chan error, 1)
+	go func() { done <- mailer.Send(context.Background(), []string{"finance@example.com"}, "subject", "body") }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected an i/o timeout, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("send did not return after the connection deadline")
+	}
+}
+
+func TestSchedulerBoundsEachRun(t *testing.T) {
+	sqlite := newTestStore(t)
+	mailer := NewSMTPMailer("127.0.0.1", newStalledRelay(t), "", "", "reports@example.com")
+	schedule, err := sqlite.CreateReportSchedule(db.ReportSchedule{
+		Name:       "finance",
+		Frequency:  db.ReportDaily,
+		Channel:    db.ReportChannelEmail,
+		Recipients: []string{"finance@example.com"},
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("CreateReportSchedule: %v", err)
+	}
+
+	scheduler := NewScheduler(sqlite, fakeSource{}, mailer, nil)
+	scheduler.runTimeout = 50 * time.Millisecond
+	due := schedule.NextRunAt.Add(time.Minute)
+	scheduler.now = func() time.Time { return due }
+
+	done := make(chan error, 1)
+	go func() { done <- scheduler.RunDue(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RunDue: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunDue did not return after the run timeout")
+	}
+	runs, err := sqlite.ListReportRuns(schedule.ID, 10)
+	if err != nil || len(runs) != 1 || runs[0].Status != db.ReportRunFailed || runs[0].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("expected one timed out run, got %+v (%v)", runs, err)
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import "strings"
+
+// AllocationMode selects which resource quantity is used to attribute node cost to workloads.
+type AllocationMode string
+
+const (
+	// AllocationUsage attributes cost by observed CPU and memory usage.
+	AllocationUsage AllocationMode = "usage"
+	// AllocationRequest attributes cost by reserved CPU and memory requests.
+	AllocationRequest AllocationMode = "request"
+	// AllocationMax attributes cost by the larger of usage and requests per resource.
+	AllocationMax AllocationMode = "max"
+)
+
+// ParseAllocationMode validates a user supplied mode. Empty input selects usage.
+func ParseAllocationMode(raw string) (AllocationMode, bool) {
+	switch mode := AllocationMode(strings.ToLower(strings.TrimSpace(raw))); mode {
+	case "":
+		return AllocationUsage, true
+	case AllocationUsage, AllocationRequest, AllocationMax:
+		return mode, true
+	default:
+		return "", false
+	}
+}
+
+// OrDefault returns the mode, falling back to usage when unset.
+func (m AllocationMode) OrDefault() AllocationMode {
+	if m == "" {
+		return AllocationUsage
+	}
+	return m
+}
+
+// AllocatedQuantity returns the amount of a resource attributed to a workload under the given mode.
+func AllocatedQuantity(mode AllocationMode, usage, request int64) int64 {
+	switch mode.OrDefault() {
+	case AllocationRequest:
+		return request
+	case AllocationMax:
+		if request > usage {
+			return request
+		}
+		return usage
+	default:
+		return usage
+	}
+}
+
+// ApplyNodeAllocation splits the node hourly cost into the share attributed to workloads and the idle remainder.
+// Policy: 50% of node cost follows CPU, 50% follows memory, mirroring GetNodeResourcePrices.
+func ApplyNodeAllocation(node *NodeSummary, mode AllocationMode) {
+	if node == nil {
+		return
+	}
+	cpuUsage := int64(node.CPUUsagePercent / 100 * float64(node.CPUAllocatableMilli))
+	memUsage := int64(node.MemoryUsagePercent / 100 * float64(node.MemoryAllocatableBytes))
+
+	cpuShare := clampFloat(usageRatio(float64(AllocatedQuantity(mode, cpuUsage, node.CPURequestMilli)), float64(node.CPUAllocatableMilli)), 0, 1)
+	memShare := clampFloat(usageRatio(float64(AllocatedQuantity(mode, memUsage, node.MemoryRequestBytes)), float64(node.MemoryAllocatableBytes)), 0, 1)
+
+	node.AllocatedHourlyCost = node.HourlyCost * (0.5*cpuShare + 0.5*memShare)
+	node.IdleHourlyCost = node.HourlyCost - node.AllocatedHourlyCost
+}
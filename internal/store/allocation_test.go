@@ -0,0 +1,108 @@
+package store
+
+import (
+	"math"
+	"testing"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+func TestParseAllocationMode(t *testing.T) {
+	cases := map[string]AllocationMode{
+		"":        AllocationUsage,
+		"usage":   AllocationUsage,
+		"Request": AllocationRequest,
+		" max ":   AllocationMax,
+	}
+	for raw, expected := range cases {
+		mode, ok := ParseAllocationMode(raw)
+		if !ok || mode != expected {
+			t.Errorf("ParseAllocationMode(%q) = %q, %v; expected %q", raw, mode, ok, expected)
+		}
+	}
+	if _, ok := ParseAllocationMode("limits"); ok {
+		t.Error("expected unknown mode to be rejected")
+	}
+}
+
+func TestAllocatedQuantity(t *testing.T) {
+	if got := AllocatedQuantity(AllocationUsage, 200, 500); got != 200 {
+		t.Errorf("usage: expected 200, got %d", got)
+	}
+	if got := AllocatedQuantity(AllocationRequest, 200, 500); got != 500 {
+		t.Errorf("request: expected 500, got %d", got)
+	}
+	if got := AllocatedQuantity(AllocationMax, 800, 500); got != 800 {
+		t.Errorf("max: expected 800, got %d", got)
+	}
+	if got := AllocatedQuantity("", 200, 500); got != 200 {
+		t.Errorf("default: expected usage 200, got %d", got)
+	}
+}
+
+func TestApplyNodeAllocationSplitsIdleCost(t *testing.T) {
+	node := &NodeSummary{
+		HourlyCost:             1.0,
+		CPUUsagePercent:        25,
+		MemoryUsagePercent:     10,
+		CPUAllocatableMilli:    4000,
+		MemoryAllocatableBytes: 8 * 1024 * 1024 * 1024,
+		CPURequestMilli:        2000,
+		MemoryRequestBytes:     4 * 1024 * 1024 * 1024,
+	}
+
+	// Requests reserve 50% CPU and 50% memory -> half of the node is allocated.
+	ApplyNodeAllocation(node, AllocationRequest)
+	if math.Abs(node.AllocatedHourlyCost-0.5) > 1e-9 || math.Abs(node.IdleHourlyCost-0.5) > 1e-9 {
+		t.Fatalf("request: expected 0.5/0.5 split, got %f/%f", node.AllocatedHourlyCost, node.IdleHourlyCost)
+	}
+
+	// Usage is 25% CPU and 10% memory -> 0.5*0.25 + 0.5*0.10 = 0.175.
+	ApplyNodeAllocation(node, AllocationUsage)
+	if math.Abs(node.AllocatedHourlyCost-0.175) > 1e-9 {
+		t.Fatalf("usage: expected 0.175 allocated, got %f", node.AllocatedHourlyCost)
+	}
+}
+
+func TestNamespaceListRequestAllocation(t *testing.T) {
+	s := newTestStore()
+	s.UpdateMetrics("test-agent", &agentv1.MetricsReportRequest{
+		AgentId:   "test-agent",
+		ClusterId: "cluster-1",
+		NodeName:  "node-1",
+		Nodes: []*agentv1.NodeMetric{
+			{NodeName: "node-1", CapacityCpuMillicores: 2000, CapacityMemoryBytes: 8 * 1024 * 1024 * 1024},
+		},
+		Pods: []*agentv1.PodMetric{
+			{
+				Namespace: "idle",
+				PodName:   "reserved-1",
+				Cpu:       &agentv1.CpuMetrics{UsageMillicores: 0, RequestMillicores: 2000},
+				Memory:    &agentv1.MemoryMetrics{RssBytes: 0, RequestBytes: 8 * 1024 * 1024 * 1024},
+			},
+		},
+	})
+
+	usage, err := s.NamespaceList(NamespaceFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("NamespaceList failed: %v", err)
+	}
+	if usage.Allocation != AllocationUsage {
+		t.Fatalf("expected default allocation usage, got %q", usage.Allocation)
+	}
+	if usage.Items[0].HourlyCost != 0 {
+		t.Fatalf("expected zero usage cost, got %f", usage.Items[0].HourlyCost)
+	}
+
+	// Mock pricing is $1.00/hr for the reported 2 vCPU / 8GB node; requests reserve the whole node.
+	request, err := s.NamespaceList(NamespaceFilter{Limit: 10, Allocation: AllocationRequest})
+	if err != nil {
+		t.Fatalf("NamespaceList failed: %v", err)
+	}
+	if request.Allocation != AllocationRequest {
+		t.Fatalf("expected allocation request, got %q", request.Allocation)
+	}
+	if math.Abs(request.Items[0].HourlyCost-1.0) > 1e-9 {
+		t.Fatalf("expected request cost 1.0, got %f", request.Items[0].HourlyCost)
+	}
+}
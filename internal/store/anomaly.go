@@ -0,0 +1,34 @@
+package store
+
+import "time"
+
+// AnomalyDirection tells whether spend rose above or fell below its baseline.
+type AnomalyDirection string
+
+const (
+	AnomalySpike AnomalyDirection = "spike"
+	AnomalyDrop  AnomalyDirection = "drop"
+)
+
+// Anomaly is a namespace whose hourly spend deviates from the seasonal baseline
+// (same hour and weekday in previous weeks).
+type Anomaly struct {
+	ID                 int64            `json:"id"`
+	ClusterID          string           `json:"clusterId"`
+	Namespace          string           `json:"namespace"`
+	WindowStart        time.Time        `json:"windowStart"`
+	DetectedAt         time.Time        `json:"detectedAt"`
+	ExpectedHourlyCost float64          `json:"expectedHourlyCost"`
+	ActualHourlyCost   float64          `json:"actualHourlyCost"`
+	DeviationPercent   float64          `json:"deviationPercent"`
+	Score              float64          `json:"score"`
+	Direction          AnomalyDirection `json:"direction"`
+}
+
+// AnomalyFilter narrows stored anomaly lookups.
+type AnomalyFilter struct {
+	ClusterID string
+	Namespace string
+	Since     time.Time
+	Limit     int
+}
@@ -0,0 +1,230 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// Egress classes as ingested by the agent.
+const (
+	EgressClassPublic   = "public"
+	EgressClassCrossAZ  = "cross_az"
+	EgressClassInternal = "internal"
+)
+
+// NamespaceDetail is the namespace drill-down: its summary row plus where its cost comes from.
+type NamespaceDetail struct {
+	NamespaceSummary
+	TopPods       []PodSummary         `json:"topPods"`
+	Nodes         []NamespaceNodeShare `json:"nodes"`
+	EgressWindow  string               `json:"egressWindow"`
+	Egress        []EgressBreakdown    `json:"egress"`
+	CostSparkline []CostSample         `json:"costSparkline"`
+}
+
+// NamespaceNodeShare is the part of a namespace running on one node. NamespacePercent is the
+// node's share of the namespace cost; NodePercent the namespace's share of the pod cost on the node.
+type NamespaceNodeShare struct {
+	NodeName         string  `json:"nodeName"`
+	InstanceType     string  `json:"instanceType,omitempty"`
+	Pods             int     `json:"pods"`
+	HourlyCost       float64 `json:"hourlyCost"`
+	NamespacePercent float64 `json:"namespacePercent"`
+	NodePercent      float64 `json:"nodePercent"`
+}
+
+// EgressBreakdown is the traffic and cost of one egress class.
+type EgressBreakdown struct {
+	Class   string  `json:"class"`
+	Bytes   int64   `json:"bytes"`
+	CostUSD float64 `json:"costUsd"`
+	Percent float64 `json:"percent"`
+}
+
+// CostSample is one point of a cost sparkline: the cost accrued in the step ending at Timestamp.
+type CostSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Cost      float64   `json:"cost"`
+}
+
+// NamespaceNodeShares groups a namespace's pods by node, most expensive first. nodeTotals is
+// the pod cost of every namespace per node; nodes missing from it get no NodePercent.
+func NamespaceNodeShares(pods []PodSummary, nodeTotals map[string]float64) []NamespaceNodeShare {
+	byNode := make(map[string]*NamespaceNodeShare)
+	total := 0.0
+	for _, p := range pods {
+		if p.NodeName == "" {
+			continue
+		}
+		share := byNode[p.NodeName]
+		if share == nil {
+			share = &NamespaceNodeShare{NodeName: p.NodeName, InstanceType: p.InstanceType}
+			byNode[p.NodeName] = share
+		}
+		share.Pods++
+		share.HourlyCost += p.HourlyCost
+		total += p.HourlyCost
+	}
+
+	out := make([]NamespaceNodeShare, 0, len(byNode))
+	for _, share := range byNode {
+		if total > 0 {
+			share.NamespacePercent = share.HourlyCost / total * 100
+		}
+		if nodeTotal := nodeTotals[share.NodeName]; nodeTotal > 0 {
+			share.NodePercent = share.HourlyCost / nodeTotal * 100
+		}
+		out = append(out, *share)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].HourlyCost != out[j].HourlyCost {
+			return out[i].HourlyCost > out[j].HourlyCost
+		}
+		return out[i].NodeName < out[j].NodeName
+	})
+	return out
+}
+
+// NewEgressBreakdown prices egress bytes per class; internal traffic is free.
+func NewEgressBreakdown(publicBytes, crossAZBytes, internalBytes int64) []EgressBreakdown {
+	const gib = 1024 * 1024 * 1024
+	out := []EgressBreakdown{
+		{Class: EgressClassPublic, Bytes: publicBytes, CostUSD: float64(publicBytes) / gib * CostEgressPublic},
+		{Class: EgressClassCrossAZ, Bytes: crossAZBytes, CostUSD: float64(crossAZBytes) / gib * CostEgressCrossAZ},
+		{Class: EgressClassInternal, Bytes: internalBytes},
+	}
+	if total := publicBytes + crossAZBytes + internalBytes; total > 0 {
+		for i := range out {
+			out[i].Percent = float64(out[i].Bytes) / float64(total) * 100
+		}
+	}
+	return out
+}
+
+// NodeDetail is the node drill-down: its summary row, who consumes it and how it was loaded over time.
+type NodeDetail struct {
+	NodeSummary
+	Namespaces    []NodeConsumer   `json:"namespaces"`
+	TopPods       []NodeConsumer   `json:"topPods"`
+	Idle          NodeIdleCost     `json:"idle"`
+	HistoryWindow string           `json:"historyWindow"`
+	HistoryStep   string           `json:"historyStep"`
+	Throttling    []ThrottleSample `json:"throttling"`
+	CapacityTrend []CapacitySample `json:"capacityTrend"`
+}
+
+// NodeConsumer is the share of a node used and requested by a namespace or, when PodName is
+// set, a single pod. Shares follow ApplyNodeAllocation: half CPU, half memory, of allocatable.
+type NodeConsumer struct {
+	Namespace          string  `json:"namespace"`
+	PodName            string  `json:"podName,omitempty"`
+	Pods               int     `json:"pods"`
+	CPUUsageMilli      int64   `json:"cpuUsageMilli"`
+	CPURequestMilli    int64   `json:"cpuRequestMilli"`
+	MemoryUsageBytes   int64   `json:"memoryUsageBytes"`
+	MemoryRequestBytes int64   `json:"memoryRequestBytes"`
+	UsagePercent       float64 `json:"usagePercent"`
+	RequestPercent     float64 `json:"requestPercent"`
+	UsageHourlyCost    float64 `json:"usageHourlyCost"`
+	RequestHourlyCost  float64 `json:"requestHourlyCost"`
+}
+
+// NodeIdleCost is the hourly node cost no workload uses, and the part no workload requests.
+type NodeIdleCost struct {
+	UsageHourlyCost   float64 `json:"usageHourlyCost"`
+	RequestHourlyCost float64 `json:"requestHourlyCost"`
+}
+
+// ThrottleSample is the CPU throttling rate of a node at Timestamp, in throttled cores.
+type ThrottleSample struct {
+	Timestamp      time.Time `json:"timestamp"`
+	ThrottledCores float64   `json:"throttledCores"`
+}
+
+// CapacitySample is the node capacity, allocatable and requested resources at Timestamp.
+type CapacitySample struct {
+	Timestamp              time.Time `json:"timestamp"`
+	CPUCapacityMilli       int64     `json:"cpuCapacityMilli"`
+	CPUAllocatableMilli    int64     `json:"cpuAllocatableMilli"`
+	CPURequestMilli        int64     `json:"cpuRequestMilli"`
+	MemoryCapacityBytes    int64     `json:"memoryCapacityBytes"`
+	MemoryAllocatableBytes int64     `json:"memoryAllocatableBytes"`
+	MemoryRequestBytes     int64     `json:"memoryRequestBytes"`
+}
+
+// NodeConsumers splits a node's cost among the pods running on it, returning the per-namespace
+// totals and the per-pod shares, both ordered by usage cost.
+func NodeConsumers(node NodeSummary, pods []PodSummary) (namespaces, podShares []NodeConsumer) {
+	byNamespace := make(map[string]*NodeConsumer)
+	podShares = make([]NodeConsumer, 0, len(pods))
+	for _, p := range pods {
+		if p.NodeName != node.NodeName {
+			continue
+		}
+		pod := NodeConsumer{
+			Namespace:          p.Namespace,
+			PodName:            p.PodName,
+			Pods:               1,
+			CPUUsageMilli:      p.CPUUsageMilli,
+			CPURequestMilli:    p.CPURequestMilli,
+			MemoryUsageBytes:   p.MemoryUsageBytes,
+			MemoryRequestBytes: p.MemoryRequestBytes,
+		}
+		pod.price(node)
+		podShares = append(podShares, pod)
+
+		ns := byNamespace[p.Namespace]
+		if ns == nil {
+			ns = &NodeConsumer{Namespace: p.Namespace}
+			byNamespace[p.Namespace] = ns
+		}
+		ns.Pods++
+		ns.CPUUsageMilli += p.CPUUsageMilli
+		ns.CPURequestMilli += p.CPURequestMilli
+		ns.MemoryUsageBytes += p.MemoryUsageBytes
+		ns.MemoryRequestBytes += p.MemoryRequestBytes
+	}
+
+	namespaces = make([]NodeConsumer, 0, len(byNamespace))
+	for _, ns := range byNamespace {
+		ns.price(node)
+		namespaces = append(namespaces, *ns)
+	}
+	sortConsumers(namespaces)
+	sortConsumers(podShares)
+	return namespaces, podShares
+}
+
+// NewNodeIdleCost returns the idle cost of a node under usage and under request allocation.
+func NewNodeIdleCost(node NodeSummary) NodeIdleCost {
+	usage, request := node, node
+	ApplyNodeAllocation(&usage, AllocationUsage)
+	ApplyNodeAllocation(&request, AllocationRequest)
+	return NodeIdleCost{UsageHourlyCost: usage.IdleHourlyCost, RequestHourlyCost: request.IdleHourlyCost}
+}
+
+func (c *NodeConsumer) price(node NodeSummary) {
+	share := func(cpu, mem int64) float64 {
+		cpuShare := usageRatio(float64(cpu), float64(node.CPUAllocatableMilli))
+		memShare := usageRatio(float64(mem), float64(node.MemoryAllocatableBytes))
+		return 0.5*cpuShare + 0.5*memShare
+	}
+	usage := share(c.CPUUsageMilli, c.MemoryUsageBytes)
+	request := share(c.CPURequestMilli, c.MemoryRequestBytes)
+	c.UsagePercent = usage * 100
+	c.RequestPercent = request * 100
+	c.UsageHourlyCost = node.HourlyCost * usage
+	c.RequestHourlyCost = node.HourlyCost * request
+}
+
+func sortConsumers(out []NodeConsumer) {
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].UsageHourlyCost != out[j].UsageHourlyCost {
+			return out[i].UsageHourlyCost > out[j].UsageHourlyCost
+		}
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].PodName < out[j].PodName
+	})
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNamespaceNodeShares(t *testing.T) {
+	pods := []PodSummary{
+		{PodName: "a", NodeName: "node-1", InstanceType: "m5.large", HourlyCost: 0.3},
+		{PodName: "b", NodeName: "node-1", InstanceType: "m5.large", HourlyCost: 0.3},
+		{PodName: "c", NodeName: "node-2", HourlyCost: 0.4},
+		{PodName: "pending", HourlyCost: 1},
+	}
+	shares := NamespaceNodeShares(pods, map[string]float64{"node-1": 1.2})
+
+	if len(shares) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(shares))
+	}
+	first := shares[0]
+	if first.NodeName != "node-1" || first.Pods != 2 || first.InstanceType != "m5.large" {
+		t.Fatalf("unexpected first share: %+v", first)
+	}
+	if math.Abs(first.NamespacePercent-60) > 1e-9 || math.Abs(first.NodePercent-50) > 1e-9 {
+		t.Fatalf("unexpected percentages: %+v", first)
+	}
+	if shares[1].NodeName != "node-2" || shares[1].NodePercent != 0 {
+		t.Fatalf("node without total should have no node percent: %+v", shares[1])
+	}
+}
+
+func TestNewEgressBreakdown(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	out := NewEgressBreakdown(2*gib, gib, gib)
+
+	if len(out) != 3 {
+		t.Fatalf("expected 3 classes, got %d", len(out))
+	}
+	if out[0].Class != EgressClassPublic || math.Abs(out[0].CostUSD-2*CostEgressPublic) > 1e-9 || out[0].Percent != 50 {
+		t.Fatalf("unexpected public breakdown: %+v", out[0])
+	}
+	if math.Abs(out[1].CostUSD-CostEgressCrossAZ) > 1e-9 || out[1].Percent != 25 {
+		t.Fatalf("unexpected cross-AZ breakdown: %+v", out[1])
+	}
+	if out[2].CostUSD != 0 {
+		t.Fatalf("internal egress should be free: %+v", out[2])
+	}
+
+	for _, b := range NewEgressBreakdown(0, 0, 0) {
+		if b.Percent != 0 {
+			t.Fatalf("no traffic should give no percent: %+v", b)
+		}
+	}
+}
+
+func TestNodeConsumers(t *testing.T) {
+	node := NodeSummary{
+		NodeName:               "node-1",
+		HourlyCost:             1,
+		CPUAllocatableMilli:    4000,
+		MemoryAllocatableBytes: 4 << 30,
+	}
+	pods := []PodSummary{
+		{Namespace: "web", PodName: "a", NodeName: "node-1", CPUUsageMilli: 1000, CPURequestMilli: 2000, MemoryUsageBytes: 1 << 30, MemoryRequestBytes: 2 << 30},
+		{Namespace: "web", PodName: "b", NodeName: "node-1", CPUUsageMilli: 1000, MemoryUsageBytes: 1 << 30},
+		{Namespace: "batch", PodName: "c", NodeName: "node-1", CPURequestMilli: 1000, MemoryRequestBytes: 1 << 30},
+		{Namespace: "web", PodName: "elsewhere", NodeName: "node-2", CPUUsageMilli: 4000},
+	}
+	namespaces, podShares := NodeConsumers(node, pods)
+
+	if len(namespaces) != 2 || len(podShares) != 3 {
+		t.Fatalf("expected 2 namespaces and 3 pods, got %d and %d", len(namespaces), len(podShares))
+	}
+	web := namespaces[0]
+	if web.Namespace != "web" || web.Pods != 2 || web.UsagePercent != 50 || web.RequestPercent != 50 {
+		t.Fatalf("unexpected web share: %+v", web)
+	}
+	if math.Abs(web.UsageHourlyCost-0.5) > 1e-9 {
+		t.Fatalf("expected half the node cost, got %v", web.UsageHourlyCost)
+	}
+	if batch := namespaces[1]; batch.UsageHourlyCost != 0 || batch.RequestPercent != 25 {
+		t.Fatalf("unexpected batch share: %+v", batch)
+	}
+	if podShares[0].PodName != "a" || podShares[2].PodName != "c" {
+		t.Fatalf("pods not ordered by usage cost: %+v", podShares)
+	}
+}
+
+func TestNewNodeIdleCost(t *testing.T) {
+	node := NodeSummary{
+		HourlyCost:             1,
+		CPUUsagePercent:        25,
+		MemoryUsagePercent:     25,
+		CPUAllocatableMilli:    4000,
+		MemoryAllocatableBytes: 4 << 30,
+		CPURequestMilli:        3000,
+		MemoryRequestBytes:     3 << 30,
+	}
+	idle := NewNodeIdleCost(node)
+	if math.Abs(idle.UsageHourlyCost-0.75) > 1e-9 || math.Abs(idle.RequestHourlyCost-0.25) > 1e-9 {
+		t.Fatalf("unexpected idle cost: %+v", idle)
+	}
+}
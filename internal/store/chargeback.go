@@ -0,0 +1,46 @@
+package store
+
+import "time"
+
+// Chargeback grouping modes.
+const (
+	ChargebackByNamespace = "namespace"
+	ChargebackByLabel     = "label"
+)
+
+// ChargebackUnlabeled groups namespace series that do not carry the requested label.
+const ChargebackUnlabeled = "unlabeled"
+
+// ChargebackLine is the cost billed to one namespace or label value over a report period.
+type ChargebackLine struct {
+	Group             string  `json:"group"`
+	CPUCoreHours      float64 `json:"cpuCoreHours"`
+	MemoryGBHours     float64 `json:"memoryGbHours"`
+	EgressPublicGB    float64 `json:"egressPublicGb"`
+	EgressCrossAZGB   float64 `json:"egressCrossAzGb"`
+	ComputeCost       float64 `json:"computeCost"`
+	MemoryCost        float64 `json:"memoryCost"`
+	GPUCost           float64 `json:"gpuCost"`
+	StorageCost       float64 `json:"storageCost"`
+	NetworkEgressCost float64 `json:"networkEgressCost"`
+	IdleShareCost     float64 `json:"idleShareCost"`
+	TotalCost         float64 `json:"totalCost"`
+}
+
+// ChargebackReport is an invoice-style cost breakdown for a closed period.
+// Unit prices are captured at generation time so stored reports stay stable when pricing changes.
+type ChargebackReport struct {
+	ID                   int64            `json:"id"`
+	ClusterID            string           `json:"clusterId"`
+	GroupBy              string           `json:"groupBy"`
+	LabelKey             string           `json:"labelKey,omitempty"`
+	PeriodStart          time.Time        `json:"periodStart"`
+	PeriodEnd            time.Time        `json:"periodEnd"`
+	GeneratedAt          time.Time        `json:"generatedAt"`
+	CPUPricePerCoreHour  float64          `json:"cpuPricePerCoreHour"`
+	MemoryPricePerGBHour float64          `json:"memoryPricePerGbHour"`
+	NodeComputeCost      float64          `json:"nodeComputeCost"`
+	IdleCost             float64          `json:"idleCost"`
+	TotalCost            float64          `json:"totalCost"`
+	Lines                []ChargebackLine `json:"lines"`
+}
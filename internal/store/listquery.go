@@ -0,0 +1,340 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// List sort fields shared by the namespace, node and pod lists.
+const (
+	SortCost          = "cost"
+	SortCPUEfficiency = "cpuEfficiency"
+	SortMemoryUsage   = "memoryUsage"
+	SortEgress        = "egress"
+	SortPodCount      = "podCount"
+	SortName          = "name"
+)
+
+// SortFields lists the numeric fields lists can be sorted and range-filtered on.
+var SortFields = []string{SortCost, SortCPUEfficiency, SortMemoryUsage, SortEgress, SortPodCount}
+
+// ListQuery holds the sort, range and label selector options common to every list.
+// The zero value sorts by cost, most expensive first, and filters nothing.
+type ListQuery struct {
+	Sort      string
+	Ascending bool
+	// Ranges bounds the numeric fields, keyed by sort field
+	Ranges   map[string]Range
+	Selector LabelSelector
+}
+
+// Range bounds a numeric field; nil ends are open.
+type Range struct {
+	Min *float64
+	Max *float64
+}
+
+func (r Range) contains(v float64) bool {
+	return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+}
+
+// ParseSortField normalizes a sort field; the empty string sorts by cost.
+func ParseSortField(raw string) (string, bool) {
+	if raw == "" {
+		return SortCost, true
+	}
+	for _, field := range append(SortFields, SortName) {
+		if strings.EqualFold(raw, field) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// listRow is what a list query sees of a row.
+type listRow struct {
+	name   string
+	values map[string]float64
+	labels map[string]string
+}
+
+// applyListQuery drops the rows outside the ranges or not matching the selector and sorts the
+// rest. Names sort ascending and break ties.
+func applyListQuery[T any](rows []T, q ListQuery, rowOf func(T) listRow) []T {
+	type entry struct {
+		item T
+		row  listRow
+	}
+	entries := make([]entry, 0, len(rows))
+	for _, item := range rows {
+		row := rowOf(item)
+		if !q.Selector.Matches(row.labels) {
+			continue
+		}
+		inRange := true
+		for field, r := range q.Ranges {
+			if !r.contains(row.values[field]) {
+				inRange = false
+				break
+			}
+		}
+		if inRange {
+			entries = append(entries, entry{item, row})
+		}
+	}
+
+	field, ok := ParseSortField(q.Sort)
+	if !ok {
+		field = SortCost
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].row, entries[j].row
+		if field != SortName && a.values[field] != b.values[field] {
+			if q.Ascending {
+				return a.values[field] < b.values[field]
+			}
+			return a.values[field] > b.values[field]
+		}
+		if field == SortName && !q.Ascending {
+			return a.name > b.name
+		}
+		return a.name < b.name
+	})
+
+	out := make([]T, len(entries))
+	for i, e := range entries {
+		out[i] = e.item
+	}
+	return out
+}
+
+func efficiencyPercent(usage, request int64) float64 {
+	return percent(float64(usage), float64(request))
+}
+
+func namespaceRow(ns NamespaceSummary) listRow {
+	return listRow{
+		name: ns.Namespace,
+		values: map[string]float64{
+			SortCost:          ns.HourlyCost,
+			SortCPUEfficiency: efficiencyPercent(ns.CPUUsageMilli, ns.CPURequestMilli),
+			SortMemoryUsage:   float64(ns.MemoryUsageBytes),
+			SortEgress:        float64(ns.EgressBytes),
+			SortPodCount:      float64(ns.PodCount),
+		},
+		labels: withIdentity(ns.Labels, "namespace", ns.Namespace, "environment", ns.Environment),
+	}
+}
+
+func nodeRow(node NodeSummary) listRow {
+	cpuUsage := int64(node.CPUUsagePercent / 100 * float64(node.CPUAllocatableMilli))
+	return listRow{
+		name: node.NodeName,
+		values: map[string]float64{
+			SortCost:          node.HourlyCost,
+			SortCPUEfficiency: efficiencyPercent(cpuUsage, node.CPURequestMilli),
+			SortMemoryUsage:   node.MemoryUsagePercent / 100 * float64(node.MemoryAllocatableBytes),
+			SortEgress:        float64(node.EgressPublicBytes + node.EgressCrossAZBytes),
+			SortPodCount:      float64(node.PodCount),
+		},
+		labels: withIdentity(node.Labels, "node", node.NodeName, "cluster_id", node.ClusterID, "instance_type", node.InstanceType, "status", node.Status),
+	}
+}
+
+func podRow(p PodSummary) listRow {
+	return listRow{
+		name: p.Namespace + "/" + p.PodName,
+		values: map[string]float64{
+			SortCost:          p.HourlyCost,
+			SortCPUEfficiency: efficiencyPercent(p.CPUUsageMilli, p.CPURequestMilli),
+			SortMemoryUsage:   float64(p.MemoryUsageBytes),
+			SortEgress:        float64(p.EgressBytes),
+			SortPodCount:      1,
+		},
+		labels: withIdentity(nil,
+			"namespace", p.Namespace, "pod", p.PodName, "node", p.NodeName,
+			"workload_kind", p.WorkloadKind, "workload", p.WorkloadName,
+			"region", p.Region, "instance_type", p.InstanceType),
+	}
+}
+
+// withIdentity adds the identity labels of a row to its own labels, which win on conflict.
+func withIdentity(labels map[string]string, kv ...string) map[string]string {
+	out := make(map[string]string, len(labels)+len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			out[kv[i]] = kv[i+1]
+		}
+	}
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
+
+// FilterNamespaces applies the list query to namespace rows.
+func FilterNamespaces(rows []NamespaceSummary, q ListQuery) []NamespaceSummary {
+	return applyListQuery(rows, q, namespaceRow)
+}
+
+// FilterNodes applies the list query to node rows.
+func FilterNodes(rows []NodeSummary, q ListQuery) []NodeSummary {
+	return applyListQuery(rows, q, nodeRow)
+}
+
+// LabelSelector is a parsed Kubernetes-style label selector. The zero value matches everything.
+type LabelSelector struct {
+	requirements []labelRequirement
+}
+
+type labelRequirement struct {
+	key    string
+	op     string
+	values []string
+}
+
+// Selector operators.
+const (
+	selectorEquals    = "="
+	selectorNotEquals = "!="
+	selectorIn        = "in"
+	selectorNotIn     = "notin"
+	selectorExists    = "exists"
+	selectorNotExists = "!"
+)
+
+// ParseLabelSelector parses a comma-separated selector such as
+// "team=payments,tier!=batch,env in (prod,staging),!spot,gpu".
+func ParseLabelSelector(raw string) (LabelSelector, error) {
+	var sel LabelSelector
+	for _, term := range splitSelector(raw) {
+		req, err := parseRequirement(term)
+		if err != nil {
+			return LabelSelector{}, err
+		}
+		sel.requirements = append(sel.requirements, req)
+	}
+	return sel, nil
+}
+
+// Empty reports whether the selector has no requirements.
+func (s LabelSelector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether labels satisfy every requirement.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, req := range s.requirements {
+		value, ok := labels[req.key]
+		switch req.op {
+		case selectorEquals:
+			if !ok || value != req.values[0] {
+				return false
+			}
+		case selectorNotEquals:
+			if ok && value == req.values[0] {
+				return false
+			}
+		case selectorIn:
+			if !ok || !containsString(req.values, value) {
+				return false
+			}
+		case selectorNotIn:
+			if ok && containsString(req.values, value) {
+				return false
+			}
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitSelector splits on the commas outside parentheses.
+func splitSelector(raw string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, r := range raw {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+	terms = append(terms, raw[start:])
+
+	out := terms[:0]
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+func parseRequirement(term string) (labelRequirement, error) {
+	if strings.HasPrefix(term, "!") && !strings.ContainsAny(term, "=()") {
+		key := strings.TrimSpace(term[1:])
+		if key == "" {
+			return labelRequirement{}, fmt.Errorf("invalid selector %q", term)
+		}
+		return labelRequirement{key: key, op: selectorNotExists}, nil
+	}
+	for _, op := range []string{"!=", "==", "="} {
+		if key, value, ok := strings.Cut(term, op); ok {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if key == "" {
+				return labelRequirement{}, fmt.Errorf("invalid selector %q", term)
+			}
+			if op == "!=" {
+				return labelRequirement{key: key, op: selectorNotEquals, values: []string{value}}, nil
+			}
+			return labelRequirement{key: key, op: selectorEquals, values: []string{value}}, nil
+		}
+	}
+	if open := strings.Index(term, "("); open >= 0 {
+		if !strings.HasSuffix(term, ")") {
+			return labelRequirement{}, fmt.Errorf("invalid selector %q", term)
+		}
+		fields := strings.Fields(term[:open])
+		if len(fields) != 2 || (fields[1] != selectorIn && fields[1] != selectorNotIn) {
+			return labelRequirement{}, fmt.Errorf("invalid selector %q", term)
+		}
+		var values []string
+		for _, v := range strings.Split(term[open+1:len(term)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return labelRequirement{}, fmt.Errorf("invalid selector %q", term)
+		}
+		return labelRequirement{key: fields[0], op: fields[1], values: values}, nil
+	}
+	if strings.ContainsAny(term, " ()") {
+		return labelRequirement{}, fmt.Errorf("invalid selector %q", term)
+	}
+	return labelRequirement{key: term, op: selectorExists}, nil
+}
+
+func containsString(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package store
+
+import "testing"
+
+func TestParseLabelSelector(t *testing.T) {
+	sel, err := ParseLabelSelector("team=payments, tier!=batch, env in (prod, staging), gpu, !spot")
+	if err != nil {
+		t.Fatalf("ParseLabelSelector returned error: %v", err)
+	}
+	cases := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{map[string]string{"team": "payments", "env": "prod", "gpu": "true"}, true},
+		{map[string]string{"team": "payments", "env": "staging", "gpu": "", "tier": "web"}, true},
+		{map[string]string{"team": "payments", "env": "prod", "gpu": "true", "tier": "batch"}, false},
+		{map[string]string{"team": "payments", "env": "dev", "gpu": "true"}, false},
+		{map[string]string{"team": "payments", "env": "prod"}, false},
+		{map[string]string{"team": "payments", "env": "prod", "gpu": "true", "spot": "true"}, false},
+	}
+	for i, tc := range cases {
+		if got := sel.Matches(tc.labels); got != tc.want {
+			t.Errorf("case %d: expected %v, got %v", i, tc.want, got)
+		}
+	}
+
+	for _, raw := range []string{"=x", "env in ()", "env within (a)", "!", "bad key"} {
+		if _, err := ParseLabelSelector(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+	if sel, _ := ParseLabelSelector(""); !sel.Empty() || !sel.Matches(nil) {
+		t.Fatalf("empty selector should match everything")
+	}
+}
+
+func TestFilterNamespacesSortsAndFilters(t *testing.T) {
+	rows := []NamespaceSummary{
+		{Namespace: "api", HourlyCost: 3, CPUUsageMilli: 900, CPURequestMilli: 1000, PodCount: 4, EgressBytes: 10},
+		{Namespace: "batch", HourlyCost: 1, CPUUsageMilli: 100, CPURequestMilli: 1000, PodCount: 9, EgressBytes: 300},
+		{Namespace: "web", HourlyCost: 2, CPUUsageMilli: 500, CPURequestMilli: 1000, PodCount: 2, Labels: map[string]string{"team": "frontend"}},
+	}
+	names := func(out []NamespaceSummary) string {
+		s := ""
+		for _, ns := range out {
+			s += ns.Namespace + " "
+		}
+		return s
+	}
+
+	if got := names(FilterNamespaces(rows, ListQuery{})); got != "api web batch " {
+		t.Fatalf("default should sort by cost descending, got %s", got)
+	}
+	if got := names(FilterNamespaces(rows, ListQuery{Sort: SortEgress})); got != "batch api web " {
+		t.Fatalf("expected egress order, got %s", got)
+	}
+	if got := names(FilterNamespaces(rows, ListQuery{Sort: SortPodCount, Ascending: true})); got != "web api batch " {
+		t.Fatalf("expected ascending pod count order, got %s", got)
+	}
+	if got := names(FilterNamespaces(rows, ListQuery{Sort: SortName, Ascending: false})); got != "web batch api " {
+		t.Fatalf("expected descending name order, got %s", got)
+	}
+
+	minCost, maxEfficiency := 1.5, 60.0
+	q := ListQuery{Ranges: map[string]Range{
+		SortCost:          {Min: &minCost},
+		SortCPUEfficiency: {Max: &maxEfficiency},
+	}}
+	if got := names(FilterNamespaces(rows, q)); got != "web " {
+		t.Fatalf("expected only web within the ranges, got %s", got)
+	}
+
+	sel, _ := ParseLabelSelector("team=frontend")
+	if got := names(FilterNamespaces(rows, ListQuery{Selector: sel})); got != "web " {
+		t.Fatalf("expected selector to match web, got %s", got)
+	}
+	sel, _ = ParseLabelSelector("namespace in (api,batch)")
+	if got := names(FilterNamespaces(rows, ListQuery{Selector: sel})); got != "api batch " {
+		t.Fatalf("expected identity labels to be selectable, got %s", got)
+	}
+}
+
+func TestFilterPodsSortsByMemory(t *testing.T) {
+	pods := []PodSummary{
+		{Namespace: "a", PodName: "small", HourlyCost: 2, MemoryUsageBytes: 1 << 20, WorkloadName: "api"},
+		{Namespace: "a", PodName: "big", HourlyCost: 1, MemoryUsageBytes: 1 << 30, WorkloadName: "worker"},
+	}
+	out, total := FilterPods(pods, PodFilter{Limit: 10, ListQuery: ListQuery{Sort: SortMemoryUsage}})
+	if total != 2 || out[0].PodName != "big" {
+		t.Fatalf("expected big first, got %+v", out)
+	}
+	sel, _ := ParseLabelSelector("workload=api")
+	out, total = FilterPods(pods, PodFilter{Limit: 10, ListQuery: ListQuery{Selector: sel}})
+	if total != 1 || out[0].PodName != "small" {
+		t.Fatalf("expected selector on workload label, got %+v", out)
+	}
+}
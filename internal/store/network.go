@@ -0,0 +1,232 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	deploymentPodName  = regexp.MustCompile(`^(.+)-[a-z0-9]{6,10}-[a-z0-9]{5}$`)
+	replicaSetName     = regexp.MustCompile(`^(.+)-[a-z0-9]{6,10}$`)
+	statefulSetPodName = regexp.MustCompile(`^(.+)-[0-9]+$`)
+)
+
+// WorkloadFromPodName guesses the owner of a pod from the names controllers generate:
+// ReplicaSet hashes are stripped for Deployments and ordinals mark StatefulSets.
+func WorkloadFromPodName(podName string) (kind, name string) {
+	if m := deploymentPodName.FindStringSubmatch(podName); m != nil {
+		return "Deployment", m[1]
+	}
+	if m := statefulSetPodName.FindStringSubmatch(podName); m != nil {
+		return "StatefulSet", m[1]
+	}
+	return "Pod", podName
+}
+
+// DeploymentFromReplicaSet strips the pod-template hash Deployments append to the
+// ReplicaSets they create. ok is false for names that do not carry one.
+func DeploymentFromReplicaSet(name string) (deployment string, ok bool) {
+	if m := replicaSetName.FindStringSubmatch(name); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+// NetworkGranularity selects the level network edges are collapsed to.
+type NetworkGranularity string
+
+const (
+	GranularityPod       NetworkGranularity = "pod"
+	GranularityWorkload  NetworkGranularity = "workload"
+	GranularityService   NetworkGranularity = "service"
+	GranularityNamespace NetworkGranularity = "namespace"
+)
+
+// ParseNetworkGranularity validates a granularity, defaulting to pod.
+func ParseNetworkGranularity(raw string) (NetworkGranularity, error) {
+	switch g := NetworkGranularity(strings.ToLower(raw)); g {
+	case "":
+		return GranularityPod, nil
+	case GranularityPod, GranularityWorkload, GranularityService, GranularityNamespace:
+		return g, nil
+	default:
+		return "", fmt.Errorf("granularity must be pod, workload, service or namespace")
+	}
+}
+
+// NetworkGraphNode is one vertex of a collapsed network graph with its aggregate volume.
+// Out counts bytes the node sent, In the bytes it received, on either side of a connection.
+type NetworkGraphNode struct {
+	ID             string  `json:"id"`
+	Kind           string  `json:"kind"`
+	Namespace      string  `json:"namespace,omitempty"`
+	Name           string  `json:"name"`
+	BytesIn        int64   `json:"bytesIn"`
+	BytesOut       int64   `json:"bytesOut"`
+	ConnectionsIn  int64   `json:"connectionsIn"`
+	ConnectionsOut int64   `json:"connectionsOut"`
+	EgressCostUSD  float64 `json:"egressCostUsd"`
+}
+
+// NetworkLink is the traffic between two graph nodes, summed over the edges it collapses.
+type NetworkLink struct {
+	Source          string  `json:"source"`
+	Target          string  `json:"target"`
+	BytesSent       int64   `json:"bytesSent"`
+	BytesReceived   int64   `json:"bytesReceived"`
+	ConnectionCount int64   `json:"connectionCount"`
+	EgressCostUSD   float64 `json:"egressCostUsd"`
+	Hairpin         bool    `json:"hairpin,omitempty"`
+	Edges           int     `json:"edges"`
+}
+
+// AggregateNetworkEdges collapses pod-level edges into links between graph nodes at the given
+// granularity, largest first. Pods map to their Deployment/StatefulSet for workload, to the
+// Service that fronts them for service (as seen from traffic addressed to it through a
+// Service match, else their workload), and to their namespace. External destinations are
+// grouped by provider service when classified, else by DNS name or IP.
+func AggregateNetworkEdges(edges []NetworkEdge, granularity NetworkGranularity) ([]NetworkGraphNode, []NetworkLink) {
+	podServices := make(map[string]string)
+	if granularity == GranularityService {
+		for _, e := range edges {
+			if services := matchedServices(e); e.DstPodName != "" && services != "" {
+				first, _, _ := strings.Cut(services, ",")
+				podServices[e.DstNamespace+"/"+e.DstPodName] = first
+			}
+		}
+	}
+
+	nodes := make(map[string]*NetworkGraphNode)
+	links := make(map[[2]string]*NetworkLink)
+	for _, e := range edges {
+		src := graphNode(e.SrcNamespace, e.SrcPodName, "", e.SrcDNSName, e.SrcIP, "", "", granularity, podServices)
+		dst := graphNode(e.DstNamespace, e.DstPodName, matchedServices(e), e.DstDNSName, e.DstIP, e.DstProvider, e.DstProviderService, granularity, podServices)
+		if src == nil || dst == nil {
+			continue
+		}
+		if n := nodes[src.ID]; n == nil {
+			nodes[src.ID] = src
+		}
+		if n := nodes[dst.ID]; n == nil {
+			nodes[dst.ID] = dst
+		}
+		s, d := nodes[src.ID], nodes[dst.ID]
+		s.BytesOut += e.BytesSent
+		s.BytesIn += e.BytesReceived
+		s.ConnectionsOut += e.ConnectionCount
+		s.EgressCostUSD += e.EgressCostUSD
+		d.BytesIn += e.BytesSent
+		d.BytesOut += e.BytesReceived
+		d.ConnectionsIn += e.ConnectionCount
+
+		key := [2]string{src.ID, dst.ID}
+		link := links[key]
+		if link == nil {
+			link = &NetworkLink{Source: src.ID, Target: dst.ID}
+			links[key] = link
+		}
+		link.BytesSent += e.BytesSent
+		link.BytesReceived += e.BytesReceived
+		link.ConnectionCount += e.ConnectionCount
+		link.EgressCostUSD += e.EgressCostUSD
+		link.Hairpin = link.Hairpin || e.Hairpin
+		link.Edges++
+	}
+
+	outNodes := make([]NetworkGraphNode, 0, len(nodes))
+	for _, n := range nodes {
+		outNodes = append(outNodes, *n)
+	}
+	sort.Slice(outNodes, func(i, j int) bool {
+		ti, tj := outNodes[i].BytesIn+outNodes[i].BytesOut, outNodes[j].BytesIn+outNodes[j].BytesOut
+		if ti != tj {
+			return ti > tj
+		}
+		return outNodes[i].ID < outNodes[j].ID
+	})
+	outLinks := make([]NetworkLink, 0, len(links))
+	for _, l := range links {
+		outLinks = append(outLinks, *l)
+	}
+	sort.Slice(outLinks, func(i, j int) bool {
+		a, b := outLinks[i], outLinks[j]
+		if a.EgressCostUSD != b.EgressCostUSD {
+			return a.EgressCostUSD > b.EgressCostUSD
+		}
+		if a.BytesSent+a.BytesReceived != b.BytesSent+b.BytesReceived {
+			return a.BytesSent+a.BytesReceived > b.BytesSent+b.BytesReceived
+		}
+		return a.Source+a.Target < b.Source+b.Target
+	})
+	return outNodes, outLinks
+}
+
+// matchedServices returns the Services the edge's traffic was addressed to. The agent sets
+// service_match to "none" when the destination was not reached through a Service, in which
+// case dst_services only lists Services whose selectors happen to cover the pod.
+func matchedServices(e NetworkEdge) string {
+	if e.ServiceMatch == "none" {
+		return ""
+	}
+	return e.DstServices
+}
+
+// graphNode names one side of an edge at the requested granularity. The ID is prefixed with
+// the node kind so a namespace never collides with a workload of the same name.
+func graphNode(namespace, pod, services, dnsName, ip, provider, providerService string, granularity NetworkGranularity, podServices map[string]string) *NetworkGraphNode {
+	node := func(kind, ns, name string) *NetworkGraphNode {
+		id := kind + ":" + name
+		if ns != "" && kind != "namespace" {
+			id = kind + ":" + ns + "/" + name
+		}
+		return &NetworkGraphNode{ID: id, Kind: kind, Namespace: ns, Name: name}
+	}
+
+	if namespace == "" && pod == "" && services == "" {
+		switch {
+		case granularity != GranularityPod && provider != "":
+			return node("external", "", provider+"/"+providerService)
+		case dnsName != "":
+			return node("external", "", dnsName)
+		case ip != "":
+			return node("external", "", ip)
+		}
+		return nil
+	}
+
+	switch granularity {
+	case GranularityNamespace:
+		if namespace == "" {
+			if svcNamespace, _, ok := strings.Cut(services, "/"); ok {
+				namespace = svcNamespace
+			}
+		}
+		return node("namespace", "", namespace)
+	case GranularityService:
+		service := podServices[namespace+"/"+pod]
+		if service == "" && services != "" {
+			service, _, _ = strings.Cut(services, ",")
+		}
+		if service != "" {
+			ns, name, ok := strings.Cut(service, "/")
+			if !ok {
+				ns, name = namespace, service
+			}
+			return node("service", ns, name)
+		}
+		fallthrough
+	case GranularityWorkload:
+		if pod == "" {
+			return node("service", namespace, services)
+		}
+		_, workload := WorkloadFromPodName(pod)
+		return node("workload", namespace, workload)
+	default:
+		if pod == "" {
+			return node("service", namespace, services)
+		}
+		return node("pod", namespace, pod)
+	}
+}
@@ -0,0 +1,128 @@
+package store
+
+import "testing"
+
+func TestWorkloadFromPodName(t *testing.T) {
+	cases := map[string][2]string{
+		"api-7d9f8c6b5d-x2k4p": {"Deployment", "api"},
+		"postgres-0":           {"StatefulSet", "postgres"},
+		"standalone":           {"Pod", "standalone"},
+	}
+	for pod, want := range cases {
+		kind, name := WorkloadFromPodName(pod)
+		if kind != want[0] || name != want[1] {
+			t.Errorf("WorkloadFromPodName(%q) = %s/%s, want %s/%s", pod, kind, name, want[0], want[1])
+		}
+	}
+}
+
+func TestDeploymentFromReplicaSet(t *testing.T) {
+	if name, ok := DeploymentFromReplicaSet("api-7d9f8c6b5d"); !ok || name != "api" {
+		t.Fatalf("expected api, got %q (%v)", name, ok)
+	}
+	if _, ok := DeploymentFromReplicaSet("standalone"); ok {
+		t.Fatal("expected a name without a pod-template hash to have no deployment")
+	}
+}
+
+func TestParseNetworkGranularity(t *testing.T) {
+	if g, err := ParseNetworkGranularity(""); err != nil || g != GranularityPod {
+		t.Fatalf("empty granularity = %q, %v", g, err)
+	}
+	if g, err := ParseNetworkGranularity("Service"); err != nil || g != GranularityService {
+		t.Fatalf("Service granularity = %q, %v", g, err)
+	}
+	if _, err := ParseNetworkGranularity("cluster"); err == nil {
+		t.Fatal("expected error for unknown granularity")
+	}
+}
+
+func topologyEdges() []NetworkEdge {
+	return []NetworkEdge{
+		{SrcNamespace: "web", SrcPodName: "frontend-7d9f8c6b5d-aaaaa", DstNamespace: "shop", DstPodName: "api-6c8b9d7f4d-bbbbb", DstServices: "shop/api", BytesSent: 100, BytesReceived: 400, ConnectionCount: 2, EgressCostUSD: 0.5},
+		{SrcNamespace: "web", SrcPodName: "frontend-7d9f8c6b5d-ccccc", DstNamespace: "shop", DstPodName: "api-6c8b9d7f4d-ddddd", DstServices: "shop/api", BytesSent: 50, BytesReceived: 200, ConnectionCount: 1, EgressCostUSD: 0.25},
+		{SrcNamespace: "shop", SrcPodName: "api-6c8b9d7f4d-bbbbb", DstNamespace: "shop", DstPodName: "postgres-0", BytesSent: 10, BytesReceived: 30, ConnectionCount: 4},
+		{SrcNamespace: "shop", SrcPodName: "api-6c8b9d7f4d-ddddd", DstIP: "52.216.1.1", DstProvider: "AWS", DstProviderService: "S3", BytesSent: 1000, ConnectionCount: 1, EgressCostUSD: 2},
+	}
+}
+
+func TestAggregateNetworkEdgesWorkload(t *testing.T) {
+	nodes, links := AggregateNetworkEdges(topologyEdges(), GranularityWorkload)
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %d: %+v", len(links), links)
+	}
+	if links[0].Source != "workload:shop/api" || links[0].Target != "external:AWS/S3" {
+		t.Fatalf("expected S3 egress first, got %+v", links[0])
+	}
+	front := links[1]
+	if front.Source != "workload:web/frontend" || front.Target != "workload:shop/api" {
+		t.Fatalf("unexpected frontend link %+v", front)
+	}
+	if front.BytesSent != 150 || front.BytesReceived != 600 || front.ConnectionCount != 3 || front.EgressCostUSD != 0.75 || front.Edges != 2 {
+		t.Fatalf("frontend link not summed: %+v", front)
+	}
+
+	byID := make(map[string]NetworkGraphNode)
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	api := byID["workload:shop/api"]
+	// In: 150 from frontend + 30 back from postgres; out: 600 to frontend + 10 + 1000
+	if api.BytesIn != 180 || api.BytesOut != 1610 || api.ConnectionsIn != 3 || api.ConnectionsOut != 5 {
+		t.Fatalf("unexpected api totals %+v", api)
+	}
+	if got := byID["workload:shop/postgres"]; got.Kind != "workload" || got.BytesIn != 10 {
+		t.Fatalf("unexpected postgres node %+v", got)
+	}
+}
+
+func TestAggregateNetworkEdgesService(t *testing.T) {
+	_, links := AggregateNetworkEdges(topologyEdges(), GranularityService)
+	found := false
+	for _, l := range links {
+		// Traffic from the api pods is attributed to the Service that fronts them
+		if l.Source == "service:shop/api" && l.Target == "workload:shop/postgres" {
+			found = true
+		}
+		if l.Source == "workload:shop/api" {
+			t.Fatalf("api pods should collapse into their service: %+v", l)
+		}
+	}
+	if !found {
+		t.Fatalf("expected service:shop/api -> postgres link, got %+v", links)
+	}
+}
+
+func TestAggregateNetworkEdgesNamespace(t *testing.T) {
+	nodes, links := AggregateNetworkEdges(topologyEdges(), GranularityNamespace)
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %+v", links)
+	}
+	for _, l := range links {
+		if l.Source == "namespace:shop" && l.Target == "namespace:shop" && l.BytesSent != 10 {
+			t.Fatalf("unexpected intra-namespace link %+v", l)
+		}
+	}
+	if len(nodes) != 3 {
+		t.Fatalf("expected web, shop and S3 nodes, got %+v", nodes)
+	}
+}
+
+func TestAggregateNetworkEdgesServiceMatch(t *testing.T) {
+	edges := []NetworkEdge{
+		// Direct pod IP traffic: the selector covers the pod but no Service was used
+		{SrcNamespace: "web", SrcPodName: "frontend-7d9f8c6b5d-aaaaa", DstNamespace: "shop", DstPodName: "api-6c8b9d7f4d-bbbbb", DstServices: "shop/api", ServiceMatch: "none", BytesSent: 100},
+		{SrcNamespace: "web", SrcPodName: "frontend-7d9f8c6b5d-aaaaa", DstNamespace: "shop", DstPodName: "cart-5f6d7c8b9a-ccccc", DstServices: "shop/cart", ServiceMatch: "endpoint", BytesSent: 50},
+	}
+	_, links := AggregateNetworkEdges(edges, GranularityService)
+	targets := make(map[string]bool)
+	for _, l := range links {
+		targets[l.Target] = true
+	}
+	if !targets["workload:shop/api"] || targets["service:shop/api"] {
+		t.Fatalf("unmatched traffic should stay on the workload, got %+v", links)
+	}
+	if !targets["service:shop/cart"] {
+		t.Fatalf("matched traffic should land on the service, got %+v", links)
+	}
+}
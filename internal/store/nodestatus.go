@@ -0,0 +1,118 @@
+package store
+
+import (
+	"sort"
+	"strings"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+// Node statuses. Unknown covers agents that do not report node conditions.
+const (
+	NodeStatusReady    = "Ready"
+	NodeStatusNotReady = "NotReady"
+	NodeStatusUnknown  = "Unknown"
+)
+
+// NodeCondition is one condition of a node as reported by the API server.
+type NodeCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// pressureConditions are the conditions under which the kubelet starts evicting or the node
+// cannot take traffic.
+var pressureConditions = map[string]bool{
+	"MemoryPressure":     true,
+	"DiskPressure":       true,
+	"PIDPressure":        true,
+	"NetworkUnavailable": true,
+}
+
+// ParseNodeStatus normalizes a status filter; the empty string matches every node.
+func ParseNodeStatus(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "":
+		return "", true
+	case "ready":
+		return NodeStatusReady, true
+	case "notready", "not-ready", "not_ready":
+		return NodeStatusNotReady, true
+	case "unknown":
+		return NodeStatusUnknown, true
+	default:
+		return "", false
+	}
+}
+
+// NodeStatusOf derives the status of a reported node from its Ready condition, falling back to
+// the ready flag when the agent sent no conditions.
+func NodeStatusOf(n *agentv1.NodeMetric) string {
+	for _, c := range n.GetConditions() {
+		if c.GetType() != "Ready" {
+			continue
+		}
+		if c.GetStatus() == "True" {
+			return NodeStatusReady
+		}
+		if c.GetStatus() == "False" {
+			return NodeStatusNotReady
+		}
+		return NodeStatusUnknown
+	}
+	if n.GetReady() {
+		return NodeStatusReady
+	}
+	if len(n.GetConditions()) > 0 {
+		return NodeStatusNotReady
+	}
+	return NodeStatusUnknown
+}
+
+// NodeUnderPressure reports whether any pressure condition of a node is True.
+func NodeUnderPressure(conditions []NodeCondition) bool {
+	for _, c := range conditions {
+		if pressureConditions[c.Type] && c.Status == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeConditionsOf converts the reported conditions, ordered by type.
+func NodeConditionsOf(n *agentv1.NodeMetric) []NodeCondition {
+	out := make([]NodeCondition, 0, len(n.GetConditions()))
+	for _, c := range n.GetConditions() {
+		if c.GetType() == "" {
+			continue
+		}
+		out = append(out, NodeCondition{Type: c.GetType(), Status: c.GetStatus(), Reason: c.GetReason()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
+// FormatTaint renders a taint the way kubectl does: key=value:effect.
+func FormatTaint(key, value, effect string) string {
+	out := key
+	if value != "" {
+		out += "=" + value
+	}
+	if effect != "" {
+		out += ":" + effect
+	}
+	return out
+}
+
+// NodeTaintsOf renders the reported taints.
+func NodeTaintsOf(n *agentv1.NodeMetric) []string {
+	out := make([]string, 0, len(n.GetTaints()))
+	for _, t := range n.GetTaints() {
+		if t.GetKey() == "" {
+			continue
+		}
+		out = append(out, FormatTaint(t.GetKey(), t.GetValue(), t.GetEffect()))
+	}
+	return out
+}
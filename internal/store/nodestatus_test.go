@@ -0,0 +1,72 @@
+package store
+
+import (
+	"testing"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+func TestNodeStatusOf(t *testing.T) {
+	cases := []struct {
+		name string
+		node *agentv1.NodeMetric
+		want string
+	}{
+		{"ready condition", &agentv1.NodeMetric{Conditions: []*agentv1.NodeCondition{{Type: "Ready", Status: "True"}}}, NodeStatusReady},
+		{"not ready condition", &agentv1.NodeMetric{Ready: true, Conditions: []*agentv1.NodeCondition{{Type: "Ready", Status: "False"}}}, NodeStatusNotReady},
+		{"unknown condition", &agentv1.NodeMetric{Conditions: []*agentv1.NodeCondition{{Type: "Ready", Status: "Unknown"}}}, NodeStatusUnknown},
+		{"ready flag only", &agentv1.NodeMetric{Ready: true}, NodeStatusReady},
+		{"conditions without ready", &agentv1.NodeMetric{Conditions: []*agentv1.NodeCondition{{Type: "DiskPressure", Status: "False"}}}, NodeStatusNotReady},
+		{"legacy agent", &agentv1.NodeMetric{}, NodeStatusUnknown},
+	}
+	for _, tc := range cases {
+		if got := NodeStatusOf(tc.node); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestParseNodeStatus(t *testing.T) {
+	for raw, want := range map[string]string{"": "", "ready": NodeStatusReady, "NotReady": NodeStatusNotReady, "unknown": NodeStatusUnknown} {
+		got, ok := ParseNodeStatus(raw)
+		if !ok || got != want {
+			t.Errorf("ParseNodeStatus(%q) = %q, %v; want %q", raw, got, ok, want)
+		}
+	}
+	if _, ok := ParseNodeStatus("cordoned"); ok {
+		t.Fatalf("expected unknown status to be rejected")
+	}
+}
+
+func TestNodeListFiltersByStatus(t *testing.T) {
+	s := newTestStore()
+	s.UpdateMetrics("agent-1", &agentv1.MetricsReportRequest{
+		AgentId:  "agent-1",
+		NodeName: "node-a",
+		Nodes: []*agentv1.NodeMetric{
+			{
+				NodeName:   "node-a",
+				PodCount:   7,
+				Conditions: []*agentv1.NodeCondition{{Type: "Ready", Status: "True"}, {Type: "PIDPressure", Status: "True"}},
+				Labels:     map[string]string{"pool": "general"},
+				Taints:     []*agentv1.NodeTaint{{Key: "spot", Effect: "NoSchedule"}},
+			},
+			{NodeName: "node-b", Conditions: []*agentv1.NodeCondition{{Type: "Ready", Status: "False"}}},
+		},
+	})
+
+	resp, err := s.NodeList(NodeFilter{Status: NodeStatusReady, Limit: 10})
+	if err != nil {
+		t.Fatalf("NodeList returned error: %v", err)
+	}
+	if resp.TotalCount != 1 || resp.Items[0].NodeName != "node-a" {
+		t.Fatalf("expected only node-a, got %+v", resp.Items)
+	}
+	node := resp.Items[0]
+	if node.PodCount != 7 || !node.IsUnderPressure || node.Labels["pool"] != "general" {
+		t.Fatalf("unexpected node state: %+v", node)
+	}
+	if len(node.Taints) != 1 || node.Taints[0] != "spot:NoSchedule" {
+		t.Fatalf("unexpected taints: %v", node.Taints)
+	}
+}
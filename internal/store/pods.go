@@ -0,0 +1,355 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+// PodFilter controls pods list filtering.
+type PodFilter struct {
+	Namespace string
+	Node      string
+	Search    string
+	Limit     int
+	Offset    int
+	ListQuery
+}
+
+// PodSummary mirrors the pods API output. Containers of a pod are summed and the cost is
+// priced on requests, like clustercost_pod_hourly_cost.
+type PodSummary struct {
+	Namespace          string  `json:"namespace"`
+	PodName            string  `json:"podName"`
+	NodeName           string  `json:"nodeName,omitempty"`
+	WorkloadKind       string  `json:"workloadKind,omitempty"`
+	WorkloadName       string  `json:"workloadName,omitempty"`
+	Region             string  `json:"region,omitempty"`
+	InstanceType       string  `json:"instanceType,omitempty"`
+	Containers         int     `json:"containers"`
+	HourlyCost         float64 `json:"hourlyCost"`
+	CPURequestMilli    int64   `json:"cpuRequestMilli"`
+	CPULimitMilli      int64   `json:"cpuLimitMilli"`
+	CPUUsageMilli      int64   `json:"cpuUsageMilli"`
+	MemoryRequestBytes int64   `json:"memoryRequestBytes"`
+	MemoryLimitBytes   int64   `json:"memoryLimitBytes"`
+	MemoryUsageBytes   int64   `json:"memoryUsageBytes"`
+	GPUHourlyCost      float64 `json:"gpuHourlyCost"`
+	StorageHourlyCost  float64 `json:"storageHourlyCost"`
+	// EgressBytes is the public and cross-AZ egress over the last hour
+	EgressBytes int64 `json:"egressBytes"`
+}
+
+// PodListResponse wraps paginated pod results.
+type PodListResponse struct {
+	Items      []PodSummary `json:"items"`
+	TotalCount int          `json:"totalCount"`
+	Timestamp  time.Time    `json:"timestamp"`
+}
+
+// ResourceComparison sets a pod's usage against its request and limit.
+type ResourceComparison struct {
+	Request          int64   `json:"request"`
+	Limit            int64   `json:"limit"`
+	Usage            int64   `json:"usage"`
+	UsagePercent     float64 `json:"usagePercent"`
+	UnusedRequest    int64   `json:"unusedRequest"`
+	OverRequest      bool    `json:"overRequest"`
+	LimitUtilization float64 `json:"limitUtilization,omitempty"`
+}
+
+// PodCostBreakdown splits a pod's hourly cost by resource.
+type PodCostBreakdown struct {
+	CPUHourly     float64 `json:"cpuHourly"`
+	MemoryHourly  float64 `json:"memoryHourly"`
+	GPUHourly     float64 `json:"gpuHourly"`
+	StorageHourly float64 `json:"storageHourly"`
+	EgressHourly  float64 `json:"egressHourly"`
+	TotalHourly   float64 `json:"totalHourly"`
+	MonthlyCost   float64 `json:"monthlyCost"`
+}
+
+// PodNetwork is a pod's traffic over Window, with egress priced at the public and cross-AZ rates.
+type PodNetwork struct {
+	Window              string  `json:"window"`
+	TxBytes             int64   `json:"txBytes"`
+	RxBytes             int64   `json:"rxBytes"`
+	EgressPublicBytes   int64   `json:"egressPublicBytes"`
+	EgressCrossAZBytes  int64   `json:"egressCrossAzBytes"`
+	EgressInternalBytes int64   `json:"egressInternalBytes"`
+	EgressCostHourly    float64 `json:"egressCostHourly"`
+}
+
+// PodDetail is the drill-down for a single pod.
+type PodDetail struct {
+	PodSummary
+	CPU     ResourceComparison `json:"cpu"`
+	Memory  ResourceComparison `json:"memory"`
+	Cost    PodCostBreakdown   `json:"cost"`
+	Network PodNetwork         `json:"network"`
+}
+
+// NewPodDetail builds the drill-down from a summary, the node's CPU and memory prices and the
+// pod's network over the last window (which must cover one hour for EgressCostHourly). The
+// compute cost is split between CPU and memory in proportion to what their requests cost.
+func NewPodDetail(p PodSummary, cpuPrice, memPrice float64, network PodNetwork) PodDetail {
+	detail := PodDetail{
+		PodSummary: p,
+		CPU:        compareResource(p.CPURequestMilli, p.CPULimitMilli, p.CPUUsageMilli),
+		Memory:     compareResource(p.MemoryRequestBytes, p.MemoryLimitBytes, p.MemoryUsageBytes),
+		Network:    network,
+	}
+
+	cpuCost := float64(p.CPURequestMilli) / 1000 * cpuPrice
+	memCost := float64(p.MemoryRequestBytes) / (1024 * 1024 * 1024) * memPrice
+	compute := p.HourlyCost - p.GPUHourlyCost - p.StorageHourlyCost
+	if compute > 0 && cpuCost+memCost > 0 {
+		scale := compute / (cpuCost + memCost)
+		cpuCost, memCost = cpuCost*scale, memCost*scale
+	}
+	detail.Cost = PodCostBreakdown{
+		CPUHourly:     cpuCost,
+		MemoryHourly:  memCost,
+		GPUHourly:     p.GPUHourlyCost,
+		StorageHourly: p.StorageHourlyCost,
+		EgressHourly:  network.EgressCostHourly,
+	}
+	detail.Cost.TotalHourly = cpuCost + memCost + p.GPUHourlyCost + p.StorageHourlyCost + network.EgressCostHourly
+	detail.Cost.MonthlyCost = detail.Cost.TotalHourly * BillingHoursPerMonth
+	return detail
+}
+
+// EgressHourlyCost prices public and cross-AZ egress bytes.
+func EgressHourlyCost(publicBytes, crossAZBytes int64) float64 {
+	const gib = 1024 * 1024 * 1024
+	return float64(publicBytes)/gib*CostEgressPublic + float64(crossAZBytes)/gib*CostEgressCrossAZ
+}
+
+func compareResource(request, limit, usage int64) ResourceComparison {
+	out := ResourceComparison{Request: request, Limit: limit, Usage: usage}
+	if request > 0 {
+		out.UsagePercent = float64(usage) / float64(request) * 100
+		out.UnusedRequest = max(request-usage, 0)
+		out.OverRequest = usage > request
+	}
+	if limit > 0 {
+		out.LimitUtilization = float64(usage) / float64(limit) * 100
+	}
+	return out
+}
+
+// FilterPods applies the namespace, node and search filters and the list query, then paginates.
+// It returns the page and the number of pods that matched.
+func FilterPods(pods []PodSummary, filter PodFilter) ([]PodSummary, int) {
+	searchLower := strings.ToLower(filter.Search)
+	out := make([]PodSummary, 0, len(pods))
+	for _, p := range pods {
+		if filter.Namespace != "" && p.Namespace != filter.Namespace {
+			continue
+		}
+		if filter.Node != "" && p.NodeName != filter.Node {
+			continue
+		}
+		if searchLower != "" && !strings.Contains(strings.ToLower(p.PodName), searchLower) &&
+			!strings.Contains(strings.ToLower(p.WorkloadName), searchLower) {
+			continue
+		}
+		out = append(out, p)
+	}
+	out = applyListQuery(out, filter.ListQuery, podRow)
+
+	total := len(out)
+	start := clampIndex(filter.Offset, total)
+	end := clampIndex(filter.Offset+filter.Limit, total)
+	return out[start:end], total
+}
+
+// PodList returns filtered pods with pagination.
+func (s *Store) PodList(filter PodFilter) (PodListResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pods, err := s.aggregatePodsLocked()
+	if err != nil {
+		return PodListResponse{}, err
+	}
+	all := make([]PodSummary, 0, len(pods))
+	for _, p := range pods {
+		all = append(all, p.summary)
+	}
+	items, total := FilterPods(all, filter)
+
+	timestamp := s.latestScrapeLocked()
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	return PodListResponse{Items: items, TotalCount: total, Timestamp: timestamp}, nil
+}
+
+// PodDetail returns the drill-down for a pod. Network traffic is the difference between the
+// agent's last two reports, scaled to one hour.
+func (s *Store) PodDetail(namespace, name string) (PodDetail, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pods, err := s.aggregatePodsLocked()
+	if err != nil {
+		return PodDetail{}, err
+	}
+	p, ok := pods[namespace+"/"+name]
+	if !ok {
+		return PodDetail{}, ErrNoData
+	}
+
+	return NewPodDetail(p.summary, p.cpuPrice, p.memPrice, p.network()), nil
+}
+
+// network extrapolates the traffic between the previous and the latest report to an hour.
+func (p *podAggregate) network() PodNetwork {
+	network := PodNetwork{Window: "1h"}
+	if p.previous == nil {
+		return network
+	}
+	network.TxBytes = hourlyIncrease(p.current.BytesSent, p.previous.BytesSent, p.interval)
+	network.RxBytes = hourlyIncrease(p.current.BytesReceived, p.previous.BytesReceived, p.interval)
+	network.EgressPublicBytes = hourlyIncrease(p.current.EgressPublicBytes, p.previous.EgressPublicBytes, p.interval)
+	network.EgressCrossAZBytes = hourlyIncrease(p.current.EgressCrossAzBytes, p.previous.EgressCrossAzBytes, p.interval)
+	network.EgressInternalBytes = hourlyIncrease(p.current.EgressInternalBytes, p.previous.EgressInternalBytes, p.interval)
+	network.EgressCostHourly = EgressHourlyCost(network.EgressPublicBytes, network.EgressCrossAZBytes)
+	return network
+}
+
+// hourlyIncrease scales the increase of a counter over interval to an hour; resets count as zero.
+func hourlyIncrease(cur, prev uint64, interval time.Duration) int64 {
+	if interval <= 0 || cur < prev {
+		return 0
+	}
+	return int64(float64(cur-prev) * float64(time.Hour) / float64(interval))
+}
+
+type podAggregate struct {
+	summary            PodSummary
+	cpuPrice, memPrice float64
+	current, previous  *agentv1.NetworkMetrics
+	interval           time.Duration
+}
+
+// aggregatePodsLocked sums the containers of every pod in the latest reports, keyed by
+// namespace/pod, and prices them on requests.
+func (s *Store) aggregatePodsLocked() (map[string]*podAggregate, error) {
+	pods := make(map[string]*podAggregate)
+	haveData := false
+	for _, snap := range s.snapshots {
+		if snap == nil || snap.Report == nil {
+			continue
+		}
+		haveData = true
+		report := snap.Report
+		region := report.Region
+		if region == "" {
+			region = report.AvailabilityZone
+		}
+		if region == "" {
+			region = "us-east-1"
+		}
+		instanceType := report.InstanceType
+		if instanceType == "" {
+			instanceType = "default"
+		}
+		vCPUs, ramBytes, gpus := reportedNodeShape(report)
+		cpuPrice, memPrice, gpuPrice := s.pricing.GetNodeResourcePricesWithGPU(context.Background(), region, instanceType, vCPUs, ramBytes, gpus)
+
+		previous := make(map[string]*agentv1.NetworkMetrics)
+		if snap.PreviousReport != nil {
+			for _, pod := range snap.PreviousReport.Pods {
+				if pod != nil && pod.Network != nil {
+					previous[pod.Namespace+"/"+pod.PodName+"/"+pod.ContainerName] = pod.Network
+				}
+			}
+		}
+
+		for _, pod := range report.Pods {
+			if pod == nil || strings.TrimSpace(pod.Namespace) == "" || pod.PodName == "" {
+				continue
+			}
+			key := pod.Namespace + "/" + pod.PodName
+			agg := pods[key]
+			if agg == nil {
+				agg = &podAggregate{
+					summary: PodSummary{
+						Namespace:    pod.Namespace,
+						PodName:      pod.PodName,
+						NodeName:     report.NodeName,
+						WorkloadKind: pod.WorkloadKind,
+						WorkloadName: pod.WorkloadName,
+						Region:       region,
+						InstanceType: report.InstanceType,
+					},
+					cpuPrice: cpuPrice,
+					memPrice: memPrice,
+					interval: snap.LastScrapeDur,
+				}
+				pods[key] = agg
+			}
+			p := &agg.summary
+			p.Containers++
+
+			if pod.Cpu != nil {
+				p.CPURequestMilli += safeInt64(pod.Cpu.RequestMillicores)
+				p.CPULimitMilli += safeInt64(pod.Cpu.LimitMillicores)
+				p.CPUUsageMilli += safeInt64(pod.Cpu.UsageMillicores)
+			}
+			if pod.Memory != nil {
+				p.MemoryRequestBytes += safeInt64(pod.Memory.RequestBytes)
+				p.MemoryLimitBytes += safeInt64(pod.Memory.LimitBytes)
+				p.MemoryUsageBytes += safeInt64(pod.Memory.RssBytes)
+			}
+			if pod.Gpu != nil {
+				gpus := int64(pod.Gpu.AllocatedCount)
+				if gpus == 0 {
+					gpus = int64(pod.Gpu.RequestCount)
+				}
+				p.GPUHourlyCost += float64(gpus) * gpuPrice
+			}
+			if pod.Storage != nil {
+				for _, vol := range pod.Storage.Volumes {
+					if vol != nil {
+						p.StorageHourlyCost += s.pricing.GetStorageHourlyCost(vol.StorageClass, safeInt64(vol.CapacityBytes))
+					}
+				}
+			}
+			if pod.Network != nil {
+				agg.current = addNetwork(agg.current, pod.Network)
+				if prev := previous[key+"/"+pod.ContainerName]; prev != nil {
+					agg.previous = addNetwork(agg.previous, prev)
+				}
+			}
+		}
+	}
+	if !haveData {
+		return nil, ErrNoData
+	}
+
+	for _, agg := range pods {
+		p := &agg.summary
+		p.HourlyCost = calculateHourlyCost(float64(p.CPURequestMilli)/1000, float64(p.MemoryRequestBytes)/(1024*1024*1024), 0, 0, agg.cpuPrice, agg.memPrice) +
+			p.GPUHourlyCost + p.StorageHourlyCost
+		network := agg.network()
+		p.EgressBytes = network.EgressPublicBytes + network.EgressCrossAZBytes
+	}
+	return pods, nil
+}
+
+func addNetwork(total, m *agentv1.NetworkMetrics) *agentv1.NetworkMetrics {
+	if total == nil {
+		total = &agentv1.NetworkMetrics{}
+	}
+	total.BytesSent += m.BytesSent
+	total.BytesReceived += m.BytesReceived
+	total.EgressPublicBytes += m.EgressPublicBytes
+	total.EgressCrossAzBytes += m.EgressCrossAzBytes
+	total.EgressInternalBytes += m.EgressInternalBytes
+	return total
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+func podReport(txBytes uint64) *agentv1.MetricsReportRequest {
+	return &agentv1.MetricsReportRequest{
+		AgentId:          "test-agent",
+		ClusterId:        "cluster-1",
+		NodeName:         "node-1",
+		AvailabilityZone: "us-east-1",
+		Pods: []*agentv1.PodMetric{
+			{
+				Namespace: "shop", PodName: "api-1", ContainerName: "app", WorkloadKind: "Deployment", WorkloadName: "api",
+				Cpu:     &agentv1.CpuMetrics{RequestMillicores: 500, LimitMillicores: 1000, UsageMillicores: 200},
+				Memory:  &agentv1.MemoryMetrics{RequestBytes: 1 << 30, RssBytes: 1 << 29},
+				Network: &agentv1.NetworkMetrics{BytesSent: txBytes, EgressPublicBytes: txBytes},
+			},
+			{
+				Namespace: "shop", PodName: "api-1", ContainerName: "sidecar",
+				Cpu: &agentv1.CpuMetrics{RequestMillicores: 100, UsageMillicores: 50},
+			},
+			{
+				Namespace: "batch", PodName: "job-1", ContainerName: "worker",
+				Cpu: &agentv1.CpuMetrics{RequestMillicores: 2000, UsageMillicores: 2500},
+			},
+		},
+	}
+}
+
+func TestStorePodList(t *testing.T) {
+	s := newTestStore()
+	s.UpdateMetrics("test-agent", podReport(0))
+
+	resp, err := s.PodList(PodFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("PodList returned error: %v", err)
+	}
+	if resp.TotalCount != 2 || len(resp.Items) != 2 {
+		t.Fatalf("expected 2 pods, got %+v", resp)
+	}
+	if resp.Items[0].PodName != "job-1" {
+		t.Fatalf("expected most expensive pod first, got %s", resp.Items[0].PodName)
+	}
+	api := resp.Items[1]
+	if api.Containers != 2 || api.CPURequestMilli != 600 || api.NodeName != "node-1" || api.WorkloadName != "api" {
+		t.Fatalf("expected containers summed, got %+v", api)
+	}
+
+	resp, _ = s.PodList(PodFilter{Namespace: "shop", Limit: 10})
+	if resp.TotalCount != 1 {
+		t.Fatalf("expected namespace filter to keep one pod, got %d", resp.TotalCount)
+	}
+	resp, _ = s.PodList(PodFilter{Search: "API", Limit: 10})
+	if resp.TotalCount != 1 || resp.Items[0].PodName != "api-1" {
+		t.Fatalf("expected search to match api-1, got %+v", resp.Items)
+	}
+	resp, _ = s.PodList(PodFilter{Node: "node-2", Limit: 10})
+	if resp.TotalCount != 0 {
+		t.Fatalf("expected no pods on node-2, got %d", resp.TotalCount)
+	}
+}
+
+func TestStorePodDetail(t *testing.T) {
+	s := newTestStore()
+	s.UpdateMetrics("test-agent", podReport(0))
+	s.UpdateMetrics("test-agent", podReport(1<<30))
+	s.snapshots["test-agent"].LastScrapeDur = 30 * time.Minute
+
+	detail, err := s.PodDetail("shop", "api-1")
+	if err != nil {
+		t.Fatalf("PodDetail returned error: %v", err)
+	}
+	if detail.CPU.Request != 600 || detail.CPU.Usage != 250 || detail.CPU.UnusedRequest != 350 || detail.CPU.OverRequest {
+		t.Fatalf("unexpected CPU comparison %+v", detail.CPU)
+	}
+	if detail.Network.EgressPublicBytes != 2<<30 {
+		t.Fatalf("expected 2GiB egress per hour, got %d", detail.Network.EgressPublicBytes)
+	}
+	if math.Abs(detail.Network.EgressCostHourly-2*CostEgressPublic) > 1e-9 {
+		t.Fatalf("expected egress cost %.4f, got %.4f", 2*CostEgressPublic, detail.Network.EgressCostHourly)
+	}
+	c := detail.Cost
+	if math.Abs(c.CPUHourly+c.MemoryHourly-detail.HourlyCost) > 1e-9 {
+		t.Fatalf("expected CPU+memory to add up to the compute cost, got %+v", c)
+	}
+	if math.Abs(c.TotalHourly-(detail.HourlyCost+c.EgressHourly)) > 1e-9 || c.MonthlyCost != c.TotalHourly*BillingHoursPerMonth {
+		t.Fatalf("unexpected totals %+v", c)
+	}
+
+	if _, err := s.PodDetail("shop", "missing"); err != ErrNoData {
+		t.Fatalf("expected ErrNoData for missing pod, got %v", err)
+	}
+}
+
+func TestNewPodDetailScalesToRecordedCost(t *testing.T) {
+	p := PodSummary{HourlyCost: 1.5, GPUHourlyCost: 0.5, CPURequestMilli: 1000, MemoryRequestBytes: 1 << 30}
+	detail := NewPodDetail(p, 0.04, 0.01, PodNetwork{})
+	if math.Abs(detail.Cost.CPUHourly-0.8) > 1e-9 || math.Abs(detail.Cost.MemoryHourly-0.2) > 1e-9 {
+		t.Fatalf("expected compute split 0.8/0.2, got %+v", detail.Cost)
+	}
+	if detail.Memory.UsagePercent != 0 || detail.Memory.UnusedRequest != 1<<30 {
+		t.Fatalf("unexpected memory comparison %+v", detail.Memory)
+	}
+}
@@ -1,6 +1,10 @@
 package store
 
-import "context"
+import (
+	"context"
+
+	"github.com/clustercost/clustercost-dashboard/internal/pricing"
+)
 
 // Pricing constants
 const (
@@ -8,6 +12,15 @@ const (
 	CostEgressPublic   = 0.09 // $0.09 per GB
 	CostEgressCrossAZ  = 0.01 // $0.01 per GB
 	CostEgressInternal = 0.00 // Free
+
+	// DefaultGPUCostShare is the fraction of a GPU instance price attributed to its accelerators.
+	// On-demand p3/g5 prices are roughly 5x a general purpose instance with the same vCPU/RAM,
+	// so ~80% of the bill is the GPU itself.
+	DefaultGPUCostShare = 0.8
+
+	// BillingHoursPerMonth converts monthly list prices (e.g. EBS $/GB-month) to hourly rates.
+	// Cloud providers bill 730 hours per month, unlike the 720 used for dashboard projections.
+	BillingHoursPerMonth = 730
 )
 
 // PricingProvider defines the interface for fetching node pricing.
@@ -19,20 +32,61 @@ type PricingProvider interface {
 type PricingCatalog struct {
 	// Map instance type to hourly price
 	InstancePrices map[string]float64
-	Provider       PricingProvider
+	// Map instance type to the number of attached GPUs
+	InstanceGPUs map[string]int64
+	// Fraction of a GPU node price attributed to its GPUs
+	GPUCostShare float64
+	// Map storage class to $/GB-month
+	StorageClassPrices map[string]float64
+	Provider           PricingProvider
 }
 
 // NewPricingCatalog returns a catalog with some default mocked pricing.
 func NewPricingCatalog(provider PricingProvider) *PricingCatalog {
 	return &PricingCatalog{
 		InstancePrices: map[string]float64{
-			"t3.medium": 0.0416,
-			"t3.large":  0.0832,
-			"m5.large":  0.096,
-			"m5.xlarge": 0.192,
-			"c5.large":  0.085,
-			"r5.large":  0.126,
-			"default":   0.05, // Fallback
+			"t3.medium":  0.0416,
+			"t3.large":   0.0832,
+			"m5.large":   0.096,
+			"m5.xlarge":  0.192,
+			"c5.large":   0.085,
+			"r5.large":   0.126,
+			"g5.xlarge":  1.006,
+			"p3.2xlarge": 3.06,
+			"default":    0.05, // Fallback
+		},
+		InstanceGPUs: map[string]int64{
+			"p3.2xlarge":    1,
+			"p3.8xlarge":    4,
+			"p3.16xlarge":   8,
+			"p3dn.24xlarge": 8,
+			"p4d.24xlarge":  8,
+			"g4dn.xlarge":   1,
+			"g4dn.2xlarge":  1,
+			"g4dn.4xlarge":  1,
+			"g4dn.8xlarge":  1,
+			"g4dn.16xlarge": 1,
+			"g4dn.12xlarge": 4,
+			"g4dn.metal":    8,
+			"g5.xlarge":     1,
+			"g5.2xlarge":    1,
+			"g5.4xlarge":    1,
+			"g5.8xlarge":    1,
+			"g5.16xlarge":   1,
+			"g5.12xlarge":   4,
+			"g5.24xlarge":   4,
+			"g5.48xlarge":   8,
+		},
+		GPUCostShare: DefaultGPUCostShare,
+		StorageClassPrices: map[string]float64{
+			"gp2":      0.10,
+			"gp3":      0.08,
+			"io1":      0.125,
+			"io2":      0.125,
+			"st1":      0.045,
+			"sc1":      0.015,
+			"standard": 0.05,
+			"default":  0.10, // Fallback
 		},
 		Provider: provider,
 	}
@@ -57,11 +111,53 @@ func (pc *PricingCatalog) GetTotalNodePrice(ctx context.Context, region, instanc
 	return price
 }
 
+// GPUCount returns the number of GPUs on a node. A positive reported count wins over the catalog.
+func (pc *PricingCatalog) GPUCount(instanceType string, reported int64) int64 {
+	if reported > 0 {
+		return reported
+	}
+	return pc.InstanceGPUs[instanceType]
+}
+
+// SplitNodePrice divides the node hourly price into the CPU/RAM pool and the GPU pool.
+// Nodes without GPUs keep their full price in the CPU/RAM pool.
+func (pc *PricingCatalog) SplitNodePrice(ctx context.Context, region, instanceType string, gpus int64) (computePool, gpuPool float64) {
+	total := pc.GetTotalNodePrice(ctx, region, instanceType)
+	if pc.GPUCount(instanceType, gpus) <= 0 {
+		return total, 0
+	}
+	gpuPool = total * clampFloat(pc.GPUCostShare, 0, 1)
+	return total - gpuPool, gpuPool
+}
+
 // GetNodeResourcePrices calculates the cost per vCPU and per GB of RAM based on the instance type.
 // Policy: 50% of instance cost allocated to CPU, 50% allocated to RAM.
+// On GPU instances the GPU share is taken out first; see GetNodeResourcePricesWithGPU.
 func (pc *PricingCatalog) GetNodeResourcePrices(ctx context.Context, region, instanceType string, vCPUs int64, ramBytes int64) (cpuPricePerCore, ramPricePerGB float64) {
-	totalHourlyPrice := pc.GetTotalNodePrice(ctx, region, instanceType)
+	cpuPricePerCore, ramPricePerGB, _ = pc.GetNodeResourcePricesWithGPU(ctx, region, instanceType, vCPUs, ramBytes, 0)
+	return cpuPricePerCore, ramPricePerGB
+}
+
+// GetNodeResourcePricesWithGPU calculates the cost per vCPU, per GB of RAM and per GPU device.
+// vCPUs and ramBytes are the node's size; zero falls back to the shape of the instance type.
+// Policy: GPUCostShare of a GPU instance goes to its GPUs, the rest is split 50/50 between CPU and RAM.
+// gpus <= 0 falls back to the catalog GPU count for the instance type.
+func (pc *PricingCatalog) GetNodeResourcePricesWithGPU(ctx context.Context, region, instanceType string, vCPUs, ramBytes, gpus int64) (cpuPricePerCore, ramPricePerGB, gpuPricePerDevice float64) {
+	gpus = pc.GPUCount(instanceType, gpus)
+	totalHourlyPrice, gpuPoolCost := pc.SplitNodePrice(ctx, region, instanceType, gpus)
+	if gpus > 0 {
+		gpuPricePerDevice = gpuPoolCost / float64(gpus)
+	}
 
+	// Unknown node size: derive it from the instance type name before assuming a default.
+	if shape, ok := pricing.ParseInstanceType(instanceType); ok {
+		if vCPUs <= 0 {
+			vCPUs = int64(shape.VCPUs)
+		}
+		if ramBytes <= 0 {
+			ramBytes = int64(shape.MemoryGiB * 1024 * 1024 * 1024)
+		}
+	}
 	if vCPUs <= 0 {
 		vCPUs = 2 // Default fallback
 	}
@@ -77,7 +173,21 @@ func (pc *PricingCatalog) GetNodeResourcePrices(ctx context.Context, region, ins
 	cpuPricePerCore = cpuPoolCost / float64(vCPUs)
 	ramPricePerGB = ramPoolCost / ramGB
 
-	return cpuPricePerCore, ramPricePerGB
+	return cpuPricePerCore, ramPricePerGB, gpuPricePerDevice
+}
+
+// GetStorageHourlyCost returns the hourly cost of a provisioned volume.
+// Volumes are billed on capacity, not usage, so the PVC size drives cost.
+func (pc *PricingCatalog) GetStorageHourlyCost(storageClass string, capacityBytes int64) float64 {
+	if capacityBytes <= 0 {
+		return 0
+	}
+	price, ok := pc.StorageClassPrices[storageClass]
+	if !ok {
+		price = pc.StorageClassPrices["default"]
+	}
+	capacityGB := float64(capacityBytes) / (1024 * 1024 * 1024)
+	return capacityGB * price / BillingHoursPerMonth
 }
 
 // Estimated Cost Calculation
@@ -26,6 +26,14 @@ func TestPricingCatalog_GetNodeResourcePrices(t *testing.T) {
 		}
 	})
 
+	// Unknown node size: the m5.large shape (2 vCPU, 8GB) is derived from the type name
+	t.Run("instance shape fallback", func(t *testing.T) {
+		cpuPrice, ramPrice := pc.GetNodeResourcePrices(context.Background(), "us-east-1", "m5.large", 0, 0)
+		if cpuPrice != 0.024 || ramPrice != 0.006 {
+			t.Errorf("Expected m5.large shape prices 0.024/0.006, got %f/%f", cpuPrice, ramPrice)
+		}
+	})
+
 	// Test case 2: Default fallback
 	// Price: $0.05/hr
 	// Defaults: 2 vCPU, 4GB RAM
@@ -44,6 +52,49 @@ func TestPricingCatalog_GetNodeResourcePrices(t *testing.T) {
 			t.Errorf("Expected RAM price %f, got %f", expectedRAM, ramPrice)
 		}
 	})
+
+	// Test case 3: g5.xlarge (4 vCPU, 16GB RAM, 1 GPU)
+	// Price: $1.006/hr
+	// GPU Pool: 80% = $0.8048 -> Per GPU: $0.8048
+	// Compute Pool: $0.2012 -> Per Core: $0.02515, Per GB: $0.0062875
+	t.Run("g5.xlarge GPU split", func(t *testing.T) {
+		cpuPrice, ramPrice, gpuPrice := pc.GetNodeResourcePricesWithGPU(context.Background(), "us-east-1", "g5.xlarge", 4, 16*1024*1024*1024, 0)
+
+		if diff := gpuPrice - 0.8048; diff < -1e-9 || diff > 1e-9 {
+			t.Errorf("Expected GPU price 0.8048, got %f", gpuPrice)
+		}
+		if diff := cpuPrice - 0.02515; diff < -1e-9 || diff > 1e-9 {
+			t.Errorf("Expected CPU price 0.02515, got %f", cpuPrice)
+		}
+		if diff := ramPrice - 0.0062875; diff < -1e-9 || diff > 1e-9 {
+			t.Errorf("Expected RAM price 0.0062875, got %f", ramPrice)
+		}
+	})
+
+	// Test case 4: Non-GPU instance keeps the full price for CPU/RAM
+	t.Run("No GPU pool without GPUs", func(t *testing.T) {
+		compute, gpu := pc.SplitNodePrice(context.Background(), "us-east-1", "m5.large", 0)
+		if compute != 0.096 || gpu != 0 {
+			t.Errorf("Expected compute 0.096 and gpu 0, got %f and %f", compute, gpu)
+		}
+	})
+}
+
+func TestPricingCatalog_GetStorageHourlyCost(t *testing.T) {
+	pc := NewPricingCatalog(nil)
+	gib := int64(1024 * 1024 * 1024)
+
+	// 100 GiB gp3 @ $0.08/GB-month -> $8/month -> 8/730 per hour
+	if got, want := pc.GetStorageHourlyCost("gp3", 100*gib), 8.0/BillingHoursPerMonth; got != want {
+		t.Errorf("Expected gp3 cost %f, got %f", want, got)
+	}
+	// Unknown class falls back to default ($0.10/GB-month)
+	if got, want := pc.GetStorageHourlyCost("fast-ssd", 10*gib), 1.0/BillingHoursPerMonth; got != want {
+		t.Errorf("Expected default cost %f, got %f", want, got)
+	}
+	if got := pc.GetStorageHourlyCost("gp3", 0); got != 0 {
+		t.Errorf("Expected zero cost for empty volume, got %f", got)
+	}
 }
 
 func TestCalculateHourlyCost(t *testing.T) {
@@ -36,6 +36,7 @@ type Store struct {
 	mu                      sync.RWMutex
 	agentConfigs            map[string]config.AgentConfig
 	snapshots               map[string]*AgentSnapshot
+	counterResets           map[string]CounterResetStats
 	recommendedAgentVersion string
 
 	pricing *PricingCatalog
@@ -55,6 +56,19 @@ type AgentSnapshot struct {
 	LastScrape    time.Time
 	LastScrapeDur time.Duration // Duration since previous scrape used for rate calc
 	LastError     string
+
+	// nodeNetwork keeps the network counters each node reported over the last NetworkWindow
+	nodeNetwork map[string][]networkSample
+}
+
+// NetworkWindow is the window node traffic is reported over in both the store and the
+// VictoriaMetrics node lists: the increase of the agent's byte counters over the last hour.
+const NetworkWindow = time.Hour
+
+// networkSample is one report of a node's cumulative network counters.
+type networkSample struct {
+	at      time.Time
+	metrics *agentv1.NetworkMetrics
 }
 
 // OverviewPayload matches the payload served by /api/cost/overview.
@@ -66,6 +80,8 @@ type OverviewPayload struct {
 	EnvCostHourly       map[string]float64  `json:"envCostHourly"`
 	TopNamespacesByCost []TopNamespaceEntry `json:"topNamespacesByCost"`
 	SavingsCandidates   []SavingsCandidate  `json:"savingsCandidates"`
+	Allocation          AllocationMode      `json:"allocation"`
+	Anomalies           []Anomaly           `json:"anomalies"`
 }
 
 // TopNamespaceEntry highlights the most expensive namespaces.
@@ -97,8 +113,18 @@ type NamespaceSummary struct {
 	CPUUsageMilli      int64             `json:"cpuUsageMilli"`
 	CPUUsagePercent    float64           `json:"cpuUsagePercent"`
 	MemoryUsageBytes   int64             `json:"memoryUsageBytes"`
+	EgressBytes        int64             `json:"egressBytes"`
 	Labels             map[string]string `json:"labels"`
 	Environment        string            `json:"environment"`
+	// GPU (devices)
+	GPURequestCount       int64   `json:"gpuRequestCount"`
+	GPUAllocatedCount     int64   `json:"gpuAllocatedCount"`
+	GPUUtilizationPercent float64 `json:"gpuUtilizationPercent"`
+	GPUHourlyCost         float64 `json:"gpuHourlyCost"`
+	// Storage (persistent volumes)
+	StorageCapacityBytes int64   `json:"storageCapacityBytes"`
+	StorageUsedBytes     int64   `json:"storageUsedBytes"`
+	StorageHourlyCost    float64 `json:"storageHourlyCost"`
 }
 
 // NamespaceListResponse wraps paginated namespaces results.
@@ -106,23 +132,36 @@ type NamespaceListResponse struct {
 	Items      []NamespaceSummary `json:"items"`
 	TotalCount int                `json:"totalCount"`
 	Timestamp  time.Time          `json:"timestamp"`
+	Allocation AllocationMode     `json:"allocation"`
 }
 
 // NodeSummary mirrors the nodes API output.
 type NodeSummary struct {
 	NodeName               string            `json:"nodeName"`
+	ClusterID              string            `json:"clusterId,omitempty"`
 	HourlyCost             float64           `json:"hourlyCost"`
 	CPUUsagePercent        float64           `json:"cpuUsagePercent"`
 	MemoryUsagePercent     float64           `json:"memoryUsagePercent"`
 	CPUAllocatableMilli    int64             `json:"cpuAllocatableMilli"`
 	MemoryAllocatableBytes int64             `json:"memoryAllocatableBytes"`
+	CPURequestMilli        int64             `json:"cpuRequestMilli"`
+	MemoryRequestBytes     int64             `json:"memoryRequestBytes"`
+	AllocatedHourlyCost    float64           `json:"allocatedHourlyCost"`
+	IdleHourlyCost         float64           `json:"idleHourlyCost"`
 	PodCount               int               `json:"podCount"`
 	Status                 string            `json:"status"`
 	IsUnderPressure        bool              `json:"isUnderPressure"`
 	InstanceType           string            `json:"instanceType,omitempty"`
 	Labels                 map[string]string `json:"labels"`
 	Taints                 []string          `json:"taints"`
-	// Network (Host Level)
+	Conditions             []NodeCondition   `json:"conditions"`
+	// GPU (devices)
+	GPUCapacity           int64   `json:"gpuCapacity"`
+	GPUAllocatable        int64   `json:"gpuAllocatable"`
+	GPURequested          int64   `json:"gpuRequested"`
+	GPUUtilizationPercent float64 `json:"gpuUtilizationPercent"`
+	// Network (Host Level), bytes: the increase of the node's counters over NetworkWindow
+	// (one hour) in both store and VictoriaMetrics mode
 	NetTxBytes          int64 `json:"netTxBytes"`
 	NetRxBytes          int64 `json:"netRxBytes"`
 	EgressPublicBytes   int64 `json:"egressPublicBytes"`
@@ -132,9 +171,10 @@ type NodeSummary struct {
 
 // NodeListResponse wraps paginated node results.
 type NodeListResponse struct {
-	Items      []NodeSummary `json:"items"`
-	TotalCount int           `json:"totalCount"`
-	Timestamp  time.Time     `json:"timestamp"`
+	Items      []NodeSummary  `json:"items"`
+	TotalCount int            `json:"totalCount"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Allocation AllocationMode `json:"allocation"`
 }
 
 // CPUResource describes CPU efficiency metrics.
@@ -160,6 +200,17 @@ type NetworkResource struct {
 	EgressCostHourly float64 `json:"egressCostHourly"`
 }
 
+// StorageResource describes persistent volume capacity, cost and I/O.
+type StorageResource struct {
+	CapacityBytes            int64   `json:"capacityBytes"`
+	UsedBytes                int64   `json:"usedBytes"`
+	UtilizationPercent       float64 `json:"utilizationPercent"`
+	HourlyCost               float64 `json:"hourlyCost"`
+	EstimatedHourlyWasteCost float64 `json:"estimatedHourlyWasteCost"`
+	ReadBytesTotal           int64   `json:"readBytesTotal"`
+	WriteBytesTotal          int64   `json:"writeBytesTotal"`
+}
+
 // NamespaceWasteEntry highlights inefficient namespaces.
 type NamespaceWasteEntry struct {
 	Namespace                string  `json:"namespace"`
@@ -175,26 +226,32 @@ type ResourcesPayload struct {
 	CPU            CPUResource           `json:"cpu"`
 	Memory         MemoryResource        `json:"memory"`
 	Network        NetworkResource       `json:"network"`
+	Storage        StorageResource       `json:"storage"`
 	NamespaceWaste []NamespaceWasteEntry `json:"namespaceWaste"`
 }
 
 // NetworkEdge describes an aggregated network connection for topology graphs.
 type NetworkEdge struct {
-	SrcNamespace    string  `json:"srcNamespace"`
-	SrcPodName      string  `json:"srcPodName"`
-	SrcNodeName     string  `json:"srcNodeName"`
-	SrcIP           string  `json:"srcIp"`
-	SrcDNSName      string  `json:"srcDnsName"`
-	SrcAZ           string  `json:"srcAvailabilityZone"`
-	DstNamespace    string  `json:"dstNamespace"`
-	DstPodName      string  `json:"dstPodName"`
-	DstNodeName     string  `json:"dstNodeName"`
-	DstIP           string  `json:"dstIp"`
-	DstDNSName      string  `json:"dstDnsName"`
-	DstAZ           string  `json:"dstAvailabilityZone"`
-	DstKind         string  `json:"dstKind"`
-	ServiceMatch    string  `json:"serviceMatch"`
-	DstServices     string  `json:"dstServices"`
+	SrcNamespace string `json:"srcNamespace"`
+	SrcPodName   string `json:"srcPodName"`
+	SrcNodeName  string `json:"srcNodeName"`
+	SrcIP        string `json:"srcIp"`
+	SrcDNSName   string `json:"srcDnsName"`
+	SrcAZ        string `json:"srcAvailabilityZone"`
+	DstNamespace string `json:"dstNamespace"`
+	DstPodName   string `json:"dstPodName"`
+	DstNodeName  string `json:"dstNodeName"`
+	DstIP        string `json:"dstIp"`
+	DstDNSName   string `json:"dstDnsName"`
+	DstAZ        string `json:"dstAvailabilityZone"`
+	DstKind      string `json:"dstKind"`
+	ServiceMatch string `json:"serviceMatch"`
+	DstServices  string `json:"dstServices"`
+	// DstProvider and DstProviderService classify external destinations (e.g. AWS / S3)
+	DstProvider        string `json:"dstProvider,omitempty"`
+	DstProviderService string `json:"dstProviderService,omitempty"`
+	// Hairpin marks traffic that left through a public address and came back into the cluster
+	Hairpin         bool    `json:"hairpin,omitempty"`
 	Protocol        int64   `json:"protocol"`
 	BytesSent       int64   `json:"bytesSent"`
 	BytesReceived   int64   `json:"bytesReceived"`
@@ -256,6 +313,16 @@ type AgentInfo struct {
 	Error          string    `json:"error,omitempty"`
 	ClusterID      string    `json:"clusterId,omitempty"`
 	NodeName       string    `json:"nodeName,omitempty"`
+	// CounterResets counts connection byte counters that went backwards; Restarts the
+	// network reports where most counters reset at once
+	CounterResets int64 `json:"counterResets"`
+	Restarts      int64 `json:"restarts"`
+}
+
+// CounterResetStats accumulates the connection counter resets detected for an agent.
+type CounterResetStats struct {
+	CounterResets int64
+	Restarts      int64
 }
 
 // NamespaceFilter controls namespaces list filtering.
@@ -264,22 +331,31 @@ type NamespaceFilter struct {
 	Search      string
 	Limit       int
 	Offset      int
+	Allocation  AllocationMode
+	ListQuery
 }
 
 // NodeFilter controls nodes list filtering.
 type NodeFilter struct {
-	Search string
-	Limit  int
-	Offset int
+	Search     string
+	Status     string
+	Limit      int
+	Offset     int
+	Allocation AllocationMode
+	ListQuery
 }
 
 // PodContext wraps a PodMetric with its location metadata.
 type PodContext struct {
 	Pod          *agentv1.PodMetric
 	ClusterID    string
+	NodeName     string
 	Region       string
 	AZ           string
 	InstanceType string
+	// NodeVCPUs and NodeMemoryBytes size the node for pricing; zero when it was not reported
+	NodeVCPUs       int64
+	NodeMemoryBytes int64
 }
 
 // New creates a store seeded with agent configurations.
@@ -296,6 +372,7 @@ func New(cfgs []config.AgentConfig, recommendedAgentVersion string) *Store {
 	return &Store{
 		agentConfigs:            agentConfigs,
 		snapshots:               make(map[string]*AgentSnapshot, len(cfgs)),
+		counterResets:           make(map[string]CounterResetStats, len(cfgs)),
 		recommendedAgentVersion: recommendedAgentVersion,
 		pricing:                 NewPricingCatalog(pricingClient),
 	}
@@ -316,12 +393,14 @@ func (s *Store) UpdateMetrics(agentID string, req *agentv1.MetricsReportRequest)
 	var lastScrape time.Time
 	var existingNetwork *agentv1.NetworkReportRequest
 	var existingPrevNetwork *agentv1.NetworkReportRequest
+	var nodeNetwork map[string][]networkSample
 
 	if existing, ok := s.snapshots[agentID]; ok {
 		prev = existing.Report
 		lastScrape = existing.LastScrape
 		existingNetwork = existing.Network
 		existingPrevNetwork = existing.PreviousNetwork
+		nodeNetwork = existing.nodeNetwork
 	}
 
 	now := time.Now().UTC()
@@ -338,7 +417,51 @@ func (s *Store) UpdateMetrics(agentID string, req *agentv1.MetricsReportRequest)
 		LastError:       "",
 		Network:         existingNetwork,
 		PreviousNetwork: existingPrevNetwork,
+		nodeNetwork:     recordNodeNetwork(nodeNetwork, req, now),
+	}
+}
+
+// recordNodeNetwork appends the node network counters of a report to each node's history,
+// keeping the samples of the last NetworkWindow plus the newest one before it as the window's
+// baseline. Nodes missing from the report are dropped.
+func recordNodeNetwork(history map[string][]networkSample, req *agentv1.MetricsReportRequest, now time.Time) map[string][]networkSample {
+	out := make(map[string][]networkSample, len(req.GetNodes()))
+	cutoff := now.Add(-NetworkWindow)
+	for _, n := range req.GetNodes() {
+		if n == nil || n.Network == nil {
+			continue
+		}
+		samples := append(history[n.NodeName], networkSample{at: now, metrics: n.Network})
+		first := 0
+		for first+1 < len(samples) && !samples[first+1].at.After(cutoff) {
+			first++
+		}
+		out[n.NodeName] = append([]networkSample(nil), samples[first:]...)
 	}
+	return out
+}
+
+// windowIncrease returns how much a counter grew over NetworkWindow, like PromQL increase():
+// a counter that went backwards restarted from zero, and a history that does not span the
+// window exactly is scaled to it.
+func windowIncrease(samples []networkSample, counter func(*agentv1.NetworkMetrics) uint64) int64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	covered := samples[len(samples)-1].at.Sub(samples[0].at)
+	if covered <= 0 {
+		return 0
+	}
+	var total uint64
+	for i := 1; i < len(samples); i++ {
+		cur, prev := counter(samples[i].metrics), counter(samples[i-1].metrics)
+		if cur >= prev {
+			total += cur - prev
+		} else {
+			total += cur
+		}
+	}
+	return int64(float64(total) * float64(NetworkWindow) / float64(covered))
 }
 
 // UpdateNetwork stores the latest network report for a given agent.
@@ -361,6 +484,34 @@ func (s *Store) UpdateNetwork(agentID string, req *agentv1.NetworkReportRequest)
 	snap.Network = req
 }
 
+// RecordCounterResets adds the connection counter resets detected in a network report.
+func (s *Store) RecordCounterResets(agentID string, resets int, restarted bool) {
+	if resets == 0 && !restarted {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.counterResets[agentID]
+	stats.CounterResets += int64(resets)
+	if restarted {
+		stats.Restarts++
+	}
+	s.counterResets[agentID] = stats
+}
+
+// CounterResets returns the connection counter resets recorded so far, keyed by agent ID.
+func (s *Store) CounterResets() map[string]CounterResetStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string]CounterResetStats, len(s.counterResets))
+	for id, stats := range s.counterResets {
+		out[id] = stats
+	}
+	return out
+}
+
 // GetAllPods returns all pods from all agents with their context.
 func (s *Store) GetAllPods() []PodContext {
 	s.mu.RLock()
@@ -381,14 +532,18 @@ func (s *Store) GetAllPods() []PodContext {
 				region = "us-east-1"
 			}
 		}
+		vCPUs, ramBytes, _ := reportedNodeShape(snap.Report)
 
 		for _, pod := range snap.Report.Pods {
 			pods = append(pods, PodContext{
-				Pod:          pod,
-				ClusterID:    snap.Report.ClusterId,
-				Region:       region,
-				AZ:           snap.Report.AvailabilityZone,
-				InstanceType: snap.Report.InstanceType,
+				Pod:             pod,
+				ClusterID:       snap.Report.ClusterId,
+				NodeName:        snap.Report.NodeName,
+				Region:          region,
+				AZ:              snap.Report.AvailabilityZone,
+				InstanceType:    snap.Report.InstanceType,
+				NodeVCPUs:       vCPUs,
+				NodeMemoryBytes: ramBytes,
 			})
 		}
 	}
@@ -396,11 +551,12 @@ func (s *Store) GetAllPods() []PodContext {
 }
 
 // Overview aggregates cluster level information for the overview dashboard.
-func (s *Store) Overview(limit int) (OverviewPayload, error) {
+func (s *Store) Overview(limit int, mode AllocationMode) (OverviewPayload, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	namespaces, err := s.aggregateNamespacesLocked()
+	mode = mode.OrDefault()
+	namespaces, err := s.aggregateNamespacesLocked(mode)
 	if err != nil {
 		return OverviewPayload{}, err
 	}
@@ -460,6 +616,8 @@ func (s *Store) Overview(limit int) (OverviewPayload, error) {
 		EnvCostHourly:       envCost,
 		TopNamespacesByCost: topNamespaces,
 		SavingsCandidates:   candidates,
+		Allocation:          mode,
+		Anomalies:           []Anomaly{},
 	}, nil
 }
 
@@ -468,7 +626,8 @@ func (s *Store) NamespaceList(filter NamespaceFilter) (NamespaceListResponse, er
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	namespaces, err := s.aggregateNamespacesLocked()
+	mode := filter.Allocation.OrDefault()
+	namespaces, err := s.aggregateNamespacesLocked(mode)
 	if err != nil {
 		return NamespaceListResponse{}, err
 	}
@@ -478,6 +637,16 @@ func (s *Store) NamespaceList(filter NamespaceFilter) (NamespaceListResponse, er
 		searchLower = strings.ToLower(filter.Search)
 	}
 
+	if pods, err := s.aggregatePodsLocked(); err == nil {
+		egress := make(map[string]int64)
+		for _, p := range pods {
+			egress[p.summary.Namespace] += p.summary.EgressBytes
+		}
+		for _, ns := range namespaces {
+			ns.EgressBytes = egress[ns.Namespace]
+		}
+	}
+
 	out := make([]NamespaceSummary, 0, len(namespaces))
 	for _, ns := range namespaces {
 		if !environmentMatches(filter.Environment, ns.Environment) {
@@ -488,9 +657,7 @@ func (s *Store) NamespaceList(filter NamespaceFilter) (NamespaceListResponse, er
 		}
 		out = append(out, *ns)
 	}
-	sort.Slice(out, func(i, j int) bool {
-		return out[i].HourlyCost > out[j].HourlyCost
-	})
+	out = FilterNamespaces(out, filter.ListQuery)
 
 	total := len(out)
 	start := clampIndex(filter.Offset, total)
@@ -508,6 +675,7 @@ func (s *Store) NamespaceList(filter NamespaceFilter) (NamespaceListResponse, er
 		Items:      out[start:end],
 		TotalCount: total,
 		Timestamp:  timestamp,
+		Allocation: mode,
 	}, nil
 }
 
@@ -516,7 +684,7 @@ func (s *Store) NamespaceDetail(name string) (NamespaceSummary, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	namespaces, err := s.aggregateNamespacesLocked()
+	namespaces, err := s.aggregateNamespacesLocked(AllocationUsage)
 	if err != nil {
 		return NamespaceSummary{}, err
 	}
@@ -542,17 +710,21 @@ func (s *Store) NodeList(filter NodeFilter) (NodeListResponse, error) {
 		searchLower = strings.ToLower(filter.Search)
 	}
 
+	mode := filter.Allocation.OrDefault()
 	out := make([]NodeSummary, 0, len(nodes))
 	for _, node := range nodes {
 		if searchLower != "" && !strings.Contains(strings.ToLower(node.NodeName), searchLower) {
 			continue
 		}
+		if filter.Status != "" && node.Status != filter.Status {
+			continue
+		}
+		ApplyNodeAllocation(node, mode)
 		out = append(out, *node)
 	}
-
-	sort.Slice(out, func(i, j int) bool {
-		return out[i].HourlyCost > out[j].HourlyCost
-	})
+	// Same-named nodes of different clusters keep a stable order through the sort
+	sort.Slice(out, func(i, j int) bool { return out[i].ClusterID < out[j].ClusterID })
+	out = FilterNodes(out, filter.ListQuery)
 
 	total := len(out)
 	start := clampIndex(filter.Offset, total)
@@ -570,6 +742,7 @@ func (s *Store) NodeList(filter NodeFilter) (NodeListResponse, error) {
 		Items:      out[start:end],
 		TotalCount: total,
 		Timestamp:  timestamp,
+		Allocation: mode,
 	}, nil
 }
 
@@ -582,10 +755,17 @@ func (s *Store) NodeDetail(name string) (NodeSummary, error) {
 	if err != nil {
 		return NodeSummary{}, err
 	}
-	node, ok := nodes[name]
-	if !ok {
+	// Node names are only unique within a cluster; take the first cluster that has it.
+	var node *NodeSummary
+	for _, n := range nodes {
+		if n.NodeName == name && (node == nil || n.ClusterID < node.ClusterID) {
+			node = n
+		}
+	}
+	if node == nil {
 		return NodeSummary{}, ErrNoData
 	}
+	ApplyNodeAllocation(node, AllocationUsage)
 	return *node, nil
 }
 
@@ -597,8 +777,9 @@ func (s *Store) Resources() (ResourcesPayload, error) {
 	// Recalculate everything from snapshots
 	var cpuUsage, cpuRequest, memUsage, memRequest int64
 	var estimatedNodeCost float64
+	var storage StorageResource
 
-	namespaces, nsErr := s.aggregateNamespacesLocked()
+	namespaces, nsErr := s.aggregateNamespacesLocked(AllocationUsage)
 	if nsErr != nil && nsErr != ErrNoData {
 		return ResourcesPayload{}, nsErr
 	}
@@ -608,7 +789,13 @@ func (s *Store) Resources() (ResourcesPayload, error) {
 		cpuRequest += ns.CPURequestMilli
 		memUsage += ns.MemoryUsageBytes
 		memRequest += ns.MemoryRequestBytes
+		storage.CapacityBytes += ns.StorageCapacityBytes
+		storage.UsedBytes += ns.StorageUsedBytes
+		storage.HourlyCost += ns.StorageHourlyCost
 	}
+	storage.ReadBytesTotal, storage.WriteBytesTotal = s.storageIOTotalsLocked()
+	storage.UtilizationPercent = percent(float64(storage.UsedBytes), float64(storage.CapacityBytes))
+	storage.EstimatedHourlyWasteCost = wasteCost(storage.HourlyCost, float64(storage.UsedBytes), float64(storage.CapacityBytes))
 
 	estimatedNodeCost = s.sumNodeHourlyCostLocked()
 
@@ -643,6 +830,7 @@ func (s *Store) Resources() (ResourcesPayload, error) {
 			EfficiencyPercent:        memEfficiency,
 			EstimatedHourlyWasteCost: memWasteCost,
 		},
+		Storage:        storage,
 		NamespaceWaste: namespaceWaste,
 	}, nil
 }
@@ -732,6 +920,12 @@ func (s *Store) Agents() []AgentInfo {
 		}
 		agentMap[id] = info
 	}
+	for id, resets := range s.counterResets {
+		if info, ok := agentMap[id]; ok {
+			info.CounterResets, info.Restarts = resets.CounterResets, resets.Restarts
+			agentMap[id] = info
+		}
+	}
 
 	result := make([]AgentInfo, 0, len(agentMap))
 	for _, info := range agentMap {
@@ -848,7 +1042,7 @@ func (s *Store) latestNodesTimestampLocked() time.Time {
 	return s.latestScrapeLocked()
 }
 
-func (s *Store) aggregateNamespacesLocked() (map[string]*NamespaceSummary, error) {
+func (s *Store) aggregateNamespacesLocked(mode AllocationMode) (map[string]*NamespaceSummary, error) {
 	collector := make(map[string]*NamespaceSummary)
 	haveData := false
 
@@ -857,26 +1051,20 @@ func (s *Store) aggregateNamespacesLocked() (map[string]*NamespaceSummary, error
 			continue
 		}
 
-		// Determine node price for this snapshot
-		// We don't have node capacity in V2 Report (cpu_allocatable presumably in node metrics if sent?)
-		// ReportRequest does NOT have node capacity.
-		// For the 50/50 split math, we need total capacity (vCPUs, RAM bytes).
-		// Currently V2 proto does NOT send capacity.
-		// We have to assume a default capacity or look it up if we knew the instance type.
-		// ReportRequest doesn't have InstanceType either?
-		// Wait, user provided proto v2 only has: agent_id, cluster_id, node_name, az, pods.
-		// It lacks InstanceType and NodeCapacity.
-		// PROPOSAL: We must infer or assume defaults until agent sends metadata.
-		// Using "m5.large" proxies (2 vCPU, 8GB) for calculation if unknown.
-
-		// TODO: Agent V2 should send Node Metadata (InstanceType, Capacity) for accurate pricing.
-		// For now, we use a default "standard" node.
+		// Determine node price for this snapshot from the reporting node's size
+		// (the 50/50 split divides the price by vCPUs and RAM).
 		// Region/AZ: check meta
 		region := snap.Report.AvailabilityZone
 		if region == "" {
 			region = "us-east-1"
 		}
-		cpuPrice, memPrice := s.pricing.GetNodeResourcePrices(context.Background(), region, "default", 2, 8*1024*1024*1024)
+		// GPU instances carry most of their price in the accelerators, so split by instance type when known.
+		instanceType := snap.Report.InstanceType
+		if instanceType == "" {
+			instanceType = "default"
+		}
+		vCPUs, ramBytes, gpus := reportedNodeShape(snap.Report)
+		cpuPrice, memPrice, gpuPrice := s.pricing.GetNodeResourcePricesWithGPU(context.Background(), region, instanceType, vCPUs, ramBytes, gpus)
 
 		for _, pod := range snap.Report.Pods {
 			haveData = true
@@ -897,16 +1085,21 @@ func (s *Store) aggregateNamespacesLocked() (map[string]*NamespaceSummary, error
 
 			// Aggregate Costs
 			memUsageBytes := safeInt64(0)
+			memRequestBytes := safeInt64(0)
 			if pod.Memory != nil {
 				memUsageBytes = safeInt64(pod.Memory.RssBytes)
+				memRequestBytes = safeInt64(pod.Memory.RequestBytes)
 			}
-			memGB := float64(memUsageBytes) / (1024 * 1024 * 1024)
+			memGB := float64(AllocatedQuantity(mode, memUsageBytes, memRequestBytes)) / (1024 * 1024 * 1024)
 
-			// CPU Usage (Cores) - provided as millicores in the report
-			cpuUsageCores := 0.0
+			// CPU (Cores) - provided as millicores in the report, attributed according to the allocation mode
+			cpuUsageMilli := safeInt64(0)
+			cpuRequestMilli := safeInt64(0)
 			if pod.Cpu != nil {
-				cpuUsageCores = float64(pod.Cpu.UsageMillicores) / 1000.0
+				cpuUsageMilli = safeInt64(pod.Cpu.UsageMillicores)
+				cpuRequestMilli = safeInt64(pod.Cpu.RequestMillicores)
 			}
+			cpuUsageCores := float64(AllocatedQuantity(mode, cpuUsageMilli, cpuRequestMilli)) / 1000.0
 
 			// Network Cost (Egress) - Simplified for MVP
 			egressPublicGB := 0.0
@@ -915,6 +1108,36 @@ func (s *Store) aggregateNamespacesLocked() (map[string]*NamespaceSummary, error
 			// Calculate Total Hourly Cost Rate
 			hourCost := calculateHourlyCost(cpuUsageCores, memGB, egressPublicGB, egressCrossAZGB, cpuPrice, memPrice)
 
+			// GPU Cost - devices are exclusive, so bound devices are the cost driver (fallback to requests)
+			if pod.Gpu != nil {
+				gpus := int64(pod.Gpu.AllocatedCount)
+				if gpus == 0 {
+					gpus = int64(pod.Gpu.RequestCount)
+				}
+				gpuCost := float64(gpus) * gpuPrice
+				hourCost += gpuCost
+				entry.GPUHourlyCost += gpuCost
+				entry.GPURequestCount += int64(pod.Gpu.RequestCount)
+				entry.GPUAllocatedCount += int64(pod.Gpu.AllocatedCount)
+				// Accumulate device-weighted utilization; normalized below
+				entry.GPUUtilizationPercent += pod.Gpu.UtilizationPercent * float64(gpus)
+			}
+
+			// Storage Cost - volumes are billed on provisioned capacity
+			if pod.Storage != nil {
+				for _, vol := range pod.Storage.Volumes {
+					if vol == nil {
+						continue
+					}
+					capacity := safeInt64(vol.CapacityBytes)
+					storageCost := s.pricing.GetStorageHourlyCost(vol.StorageClass, capacity)
+					hourCost += storageCost
+					entry.StorageHourlyCost += storageCost
+					entry.StorageCapacityBytes += capacity
+					entry.StorageUsedBytes += safeInt64(vol.UsedBytes)
+				}
+			}
+
 			entry.HourlyCost += hourCost
 			entry.MemoryUsageBytes += memUsageBytes
 			if pod.Cpu != nil {
@@ -933,6 +1156,16 @@ func (s *Store) aggregateNamespacesLocked() (map[string]*NamespaceSummary, error
 
 	// Post-processing: Calculate Percentages based on Totals
 	for _, ns := range collector {
+		gpuDevices := ns.GPUAllocatedCount
+		if gpuDevices == 0 {
+			gpuDevices = ns.GPURequestCount
+		}
+		if gpuDevices > 0 {
+			ns.GPUUtilizationPercent /= float64(gpuDevices)
+		} else {
+			ns.GPUUtilizationPercent = 0
+		}
+
 		// Priority: Limit > Request > Node Capacity (Estimate)
 		denominator := float64(ns.CPULimitMilli)
 		if denominator == 0 {
@@ -962,6 +1195,7 @@ func (s *Store) aggregateNamespacesLocked() (map[string]*NamespaceSummary, error
 	return collector, nil
 }
 
+// aggregateNodesLocked merges the nodes of all reports, keyed by cluster ID and node name.
 func (s *Store) aggregateNodesLocked() (map[string]*NodeSummary, error) {
 	nodes := make(map[string]*NodeSummary)
 	haveData := false
@@ -978,18 +1212,34 @@ func (s *Store) aggregateNodesLocked() (map[string]*NodeSummary, error) {
 				continue
 			}
 			name := n.NodeName
+			key := snap.Report.ClusterId + "/" + name
 
-			entry, ok := nodes[name]
+			entry, ok := nodes[key]
 			if !ok {
 				entry = &NodeSummary{
 					NodeName:               name,
+					ClusterID:              snap.Report.ClusterId,
 					Labels:                 make(map[string]string),
-					Status:                 "Ready",
 					InstanceType:           "default", // placeholder
 					CPUAllocatableMilli:    safeInt64(n.AllocatableCpuMillicores),
 					MemoryAllocatableBytes: safeInt64(n.AllocatableMemoryBytes),
 				}
-				nodes[name] = entry
+				nodes[key] = entry
+			}
+			entry.CPURequestMilli = safeInt64(n.RequestedCpuMillicores)
+			entry.MemoryRequestBytes = safeInt64(n.RequestedMemoryBytes)
+			entry.GPUCapacity = int64(n.GpuCapacity)
+			entry.GPUAllocatable = int64(n.GpuAllocatable)
+			entry.GPURequested = int64(n.GpuRequested)
+			entry.GPUUtilizationPercent = n.GpuUtilizationPercent
+
+			// State from the API server
+			entry.Status = NodeStatusOf(n)
+			entry.Conditions = NodeConditionsOf(n)
+			entry.IsUnderPressure = NodeUnderPressure(entry.Conditions)
+			entry.Taints = NodeTaintsOf(n)
+			for k, v := range n.Labels {
+				entry.Labels[k] = v
 			}
 
 			// Capture metrics
@@ -1000,13 +1250,13 @@ func (s *Store) aggregateNodesLocked() (map[string]*NodeSummary, error) {
 				entry.MemoryUsagePercent = (float64(n.MemoryUsageBytes) / float64(n.AllocatableMemoryBytes)) * 100
 			}
 
-			// Network (Host)
-			if n.Network != nil {
-				entry.NetTxBytes = safeInt64(n.Network.BytesSent)
-				entry.NetRxBytes = safeInt64(n.Network.BytesReceived)
-				entry.EgressPublicBytes = safeInt64(n.Network.EgressPublicBytes)
-				entry.EgressCrossAZBytes = safeInt64(n.Network.EgressCrossAzBytes)
-				entry.EgressInternalBytes = safeInt64(n.Network.EgressInternalBytes)
+			// Network (Host), the increase over NetworkWindow like the VictoriaMetrics node list
+			if samples := snap.nodeNetwork[name]; len(samples) > 1 {
+				entry.NetTxBytes = windowIncrease(samples, (*agentv1.NetworkMetrics).GetBytesSent)
+				entry.NetRxBytes = windowIncrease(samples, (*agentv1.NetworkMetrics).GetBytesReceived)
+				entry.EgressPublicBytes = windowIncrease(samples, (*agentv1.NetworkMetrics).GetEgressPublicBytes)
+				entry.EgressCrossAZBytes = windowIncrease(samples, (*agentv1.NetworkMetrics).GetEgressCrossAzBytes)
+				entry.EgressInternalBytes = windowIncrease(samples, (*agentv1.NetworkMetrics).GetEgressInternalBytes)
 			}
 
 			// Cost calculation (simplified for now, using hardcoded price or placeholder)
@@ -1017,7 +1267,9 @@ func (s *Store) aggregateNodesLocked() (map[string]*NodeSummary, error) {
 			// Count pods logic:
 			// Assuming Agent runs as DaemonSet, all pods in this report belong to the agent's node.
 			// The agent's node is specified in snap.Report.NodeName.
-			if n.NodeName == snap.Report.NodeName {
+			if n.PodCount > 0 {
+				entry.PodCount = int(n.PodCount)
+			} else if n.NodeName == snap.Report.NodeName {
 				entry.PodCount = len(snap.Report.Pods)
 			}
 		}
@@ -1029,6 +1281,42 @@ func (s *Store) aggregateNodesLocked() (map[string]*NodeSummary, error) {
 	return nodes, nil
 }
 
+// storageIOTotalsLocked sums the storage I/O counters reported for all pods.
+func (s *Store) storageIOTotalsLocked() (readBytes, writeBytes int64) {
+	for _, snap := range s.snapshots {
+		if snap == nil || snap.Report == nil {
+			continue
+		}
+		for _, pod := range snap.Report.Pods {
+			if pod == nil || pod.Storage == nil {
+				continue
+			}
+			readBytes += safeInt64(pod.Storage.ReadBytes)
+			writeBytes += safeInt64(pod.Storage.WriteBytes)
+		}
+	}
+	return readBytes, writeBytes
+}
+
+// reportedNodeShape returns the vCPUs, memory and GPUs of the reporting node, preferring
+// capacity over allocatable as the ingestor does. Zeroes mean the node was not reported.
+func reportedNodeShape(report *agentv1.MetricsReportRequest) (vCPUs, ramBytes, gpus int64) {
+	for _, node := range report.Nodes {
+		if node == nil || node.NodeName != report.NodeName {
+			continue
+		}
+		cpu, mem := node.CapacityCpuMillicores, node.CapacityMemoryBytes
+		if cpu == 0 {
+			cpu = node.AllocatableCpuMillicores
+		}
+		if mem == 0 {
+			mem = node.AllocatableMemoryBytes
+		}
+		return safeInt64(cpu / 1000), safeInt64(mem), int64(node.GpuCapacity)
+	}
+	return 0, 0, 0
+}
+
 func (s *Store) sumNodeHourlyCostLocked() float64 {
 	nodes, err := s.aggregateNodesLocked()
 	if err != nil {
@@ -2,6 +2,7 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"github.com/clustercost/clustercost-dashboard/internal/config"
 	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
@@ -89,6 +90,21 @@ func TestAgentStatusConnectedWhenDataFresh(t *testing.T) {
 	}
 }
 
+func TestAgentsReportCounterResets(t *testing.T) {
+	s := newTestStore()
+	s.RecordCounterResets("test-agent", 3, false)
+	s.RecordCounterResets("test-agent", 10, true)
+	s.RecordCounterResets("test-agent", 0, false)
+
+	agents := s.Agents()
+	if len(agents) != 1 {
+		t.Fatalf("expected one agent, got %d", len(agents))
+	}
+	if agents[0].CounterResets != 13 || agents[0].Restarts != 1 {
+		t.Fatalf("expected 13 resets and 1 restart, got %d and %d", agents[0].CounterResets, agents[0].Restarts)
+	}
+}
+
 func TestCPUUsageCalculation(t *testing.T) {
 	s := newTestStore()
 	agentID := "test-agent"
@@ -148,3 +164,93 @@ func TestCPUUsageCalculation(t *testing.T) {
 		t.Errorf("expected 25.0 percent CPU usage, got %f", summary.CPUUsagePercent)
 	}
 }
+
+func TestResourcesIncludesStorage(t *testing.T) {
+	s := newTestStore()
+	gib := uint64(1024 * 1024 * 1024)
+
+	s.UpdateMetrics("test-agent", &agentv1.MetricsReportRequest{
+		AgentId:          "test-agent",
+		ClusterId:        "cluster-1",
+		NodeName:         "node-1",
+		AvailabilityZone: "us-east-1",
+		Pods: []*agentv1.PodMetric{
+			{
+				Namespace: "db",
+				PodName:   "postgres-0",
+				Cpu:       &agentv1.CpuMetrics{UsageMillicores: 100, RequestMillicores: 200},
+				Storage: &agentv1.StorageMetrics{
+					ReadBytes:  10,
+					WriteBytes: 20,
+					Volumes: []*agentv1.VolumeMetric{
+						{PvcName: "data-postgres-0", StorageClass: "gp3", CapacityBytes: 100 * gib, UsedBytes: 25 * gib},
+					},
+				},
+			},
+		},
+	})
+
+	res, err := s.Resources()
+	if err != nil {
+		t.Fatalf("Resources returned error: %v", err)
+	}
+	expectedCost := 8.0 / BillingHoursPerMonth
+	if res.Storage.CapacityBytes != int64(100*gib) || res.Storage.UsedBytes != int64(25*gib) {
+		t.Fatalf("unexpected storage bytes: %+v", res.Storage)
+	}
+	if res.Storage.UtilizationPercent != 25 {
+		t.Fatalf("expected utilization 25%%, got %f", res.Storage.UtilizationPercent)
+	}
+	if diff := res.Storage.HourlyCost - expectedCost; diff < -1e-9 || diff > 1e-9 {
+		t.Fatalf("expected storage cost %f, got %f", expectedCost, res.Storage.HourlyCost)
+	}
+	if diff := res.Storage.EstimatedHourlyWasteCost - expectedCost*0.75; diff < -1e-9 || diff > 1e-9 {
+		t.Fatalf("expected storage waste %f, got %f", expectedCost*0.75, res.Storage.EstimatedHourlyWasteCost)
+	}
+	if res.Storage.ReadBytesTotal != 10 || res.Storage.WriteBytesTotal != 20 {
+		t.Fatalf("unexpected storage I/O: %+v", res.Storage)
+	}
+
+	list, err := s.NamespaceList(NamespaceFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("NamespaceList returned error: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].StorageHourlyCost != res.Storage.HourlyCost {
+		t.Fatalf("expected namespace storage cost %f, got %+v", res.Storage.HourlyCost, list.Items)
+	}
+}
+
+func TestNodeListReportsTrafficOverNetworkWindow(t *testing.T) {
+	report := func(sent uint64) *agentv1.MetricsReportRequest {
+		return &agentv1.MetricsReportRequest{
+			ClusterId: "cluster-1",
+			NodeName:  "node-1",
+			Nodes: []*agentv1.NodeMetric{{
+				NodeName: "node-1",
+				Network:  &agentv1.NetworkMetrics{BytesSent: sent, EgressPublicBytes: sent / 2},
+			}},
+		}
+	}
+
+	// Reports every 30 minutes; the agent restarted between the second and third
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var history map[string][]networkSample
+	for i, sent := range []uint64{0, 100, 50, 150} {
+		history = recordNodeNetwork(history, report(sent), start.Add(time.Duration(i)*30*time.Minute))
+	}
+	if got := len(history["node-1"]); got != 3 {
+		t.Fatalf("expected the window plus one baseline sample, got %d samples", got)
+	}
+
+	s := newTestStore()
+	s.UpdateMetrics("test-agent", report(150))
+	s.snapshots["test-agent"].nodeNetwork = history
+	list, err := s.NodeList(NodeFilter{Limit: 10})
+	if err != nil || len(list.Items) != 1 {
+		t.Fatalf("NodeList: %+v (%v)", list, err)
+	}
+	// 50 bytes after the restart plus 100 since, over exactly one window
+	if node := list.Items[0]; node.NetTxBytes != 150 || node.EgressPublicBytes != 75 {
+		t.Fatalf("expected 150 bytes sent and 75 public egress over the hour, got %d and %d", node.NetTxBytes, node.EgressPublicBytes)
+	}
+}
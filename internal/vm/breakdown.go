@@ -0,0 +1,273 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+const (
+	// detailTopPods is how many pods a drill-down lists
+	detailTopPods = 10
+	// detailEgressWindow is the window egress is summed over in a drill-down
+	detailEgressWindow = 24 * time.Hour
+	// sparklineWindow and sparklineStep shape the hourly cost sparkline
+	sparklineWindow = 7 * 24 * time.Hour
+	sparklineStep   = time.Hour
+	// nodeHistoryWindow and nodeHistoryStep shape the node throttling and capacity trends
+	nodeHistoryWindow = 24 * time.Hour
+	nodeHistoryStep   = 15 * time.Minute
+)
+
+// NamespaceBreakdown returns the namespace row with its most expensive pods, the nodes it runs
+// on, its egress by class and a 7-day hourly cost sparkline. The queries run in parallel; only
+// the namespace row is required, the other sections are empty when they have no data.
+func (c *Client) NamespaceBreakdown(ctx context.Context, name string) (store.NamespaceDetail, error) {
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+	labels := map[string]string{"namespace": name}
+
+	var (
+		summary    store.NamespaceSummary
+		pods       []store.PodSummary
+		nodeTotals map[string]float64
+		egress     []store.EgressBreakdown
+		sparkline  []store.CostSample
+	)
+	err := parallel(
+		func() (err error) {
+			summary, err = c.NamespaceDetail(ctx, name)
+			return err
+		},
+		optional(func() (err error) {
+			pods, err = c.podMetrics(ctx, labels, containerRunningWindow)
+			return err
+		}),
+		optional(func() (err error) {
+			nodeTotals, err = c.nodePodCost(ctx, clusterID)
+			return err
+		}),
+		optional(func() (err error) {
+			egress, err = c.egressBreakdown(ctx, "clustercost_namespace_network_egress_%s_bytes_total", labels, clusterID)
+			return err
+		}),
+		optional(func() (err error) {
+			sparkline, err = c.costSparkline(ctx, "clustercost_namespace_hourly_cost", labels, clusterID)
+			return err
+		}),
+	)
+	if err != nil {
+		return store.NamespaceDetail{}, err
+	}
+
+	if egress == nil {
+		egress = store.NewEgressBreakdown(0, 0, 0)
+	}
+	if sparkline == nil {
+		sparkline = []store.CostSample{}
+	}
+	topPods, _ := store.FilterPods(pods, store.PodFilter{Limit: detailTopPods})
+	return store.NamespaceDetail{
+		NamespaceSummary: summary,
+		TopPods:          topPods,
+		Nodes:            store.NamespaceNodeShares(pods, nodeTotals),
+		EgressWindow:     formatDuration(detailEgressWindow),
+		Egress:           egress,
+		CostSparkline:    sparkline,
+	}, nil
+}
+
+// NodeBreakdown returns the node row with the namespaces and pods consuming it, its idle cost
+// and the last day of CPU throttling and capacity vs allocatable vs requested. Only the node
+// row is required, the other sections are empty when they have no data.
+func (c *Client) NodeBreakdown(ctx context.Context, name string) (store.NodeDetail, error) {
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+	labels := map[string]string{"node": name}
+	end := time.Now().UTC().Truncate(nodeHistoryStep)
+	start := end.Add(-nodeHistoryWindow)
+
+	var (
+		summary    store.NodeSummary
+		pods       []store.PodSummary
+		throttling []store.ThrottleSample
+		capacity   []store.CapacitySample
+	)
+	err := parallel(
+		func() (err error) {
+			summary, err = c.NodeDetail(ctx, name)
+			return err
+		},
+		optional(func() (err error) {
+			pods, err = c.podMetrics(ctx, labels, containerRunningWindow)
+			return err
+		}),
+		optional(func() (err error) {
+			throttling, err = c.throttlingTrend(ctx, labels, clusterID, start, end)
+			return err
+		}),
+		optional(func() (err error) {
+			capacity, err = c.capacityTrend(ctx, labels, clusterID, start, end)
+			return err
+		}),
+	)
+	if err != nil {
+		return store.NodeDetail{}, err
+	}
+
+	if throttling == nil {
+		throttling = []store.ThrottleSample{}
+	}
+	if capacity == nil {
+		capacity = []store.CapacitySample{}
+	}
+	namespaces, podShares := store.NodeConsumers(summary, pods)
+	if len(podShares) > detailTopPods {
+		podShares = podShares[:detailTopPods]
+	}
+	return store.NodeDetail{
+		NodeSummary:   summary,
+		Namespaces:    namespaces,
+		TopPods:       podShares,
+		Idle:          store.NewNodeIdleCost(summary),
+		HistoryWindow: formatDuration(nodeHistoryWindow),
+		HistoryStep:   formatDuration(nodeHistoryStep),
+		Throttling:    throttling,
+		CapacityTrend: capacity,
+	}, nil
+}
+
+// optional wraps a breakdown section that may have no data yet, so ErrNoData leaves the
+// section empty instead of failing the whole breakdown.
+func optional(fn func() error) func() error {
+	return func() error {
+		if err := fn(); err != nil && !errors.Is(err, ErrNoData) {
+			return err
+		}
+		return nil
+	}
+}
+
+// throttlingTrend converts the throttled nanoseconds counter into throttled cores per step.
+func (c *Client) throttlingTrend(ctx context.Context, labels map[string]string, clusterID string, start, end time.Time) ([]store.ThrottleSample, error) {
+	selector := metricSelector("clustercost_node_cpu_throttling_ns_total", c.scopedLabels(labels, clusterID))
+	expr := fmt.Sprintf("sum(rate(%s[%s])) / 1e9", selector, formatDuration(nodeHistoryStep))
+	result, err := c.queryRange(ctx, expr, start, end, nodeHistoryStep)
+	if err != nil {
+		return nil, err
+	}
+	out := []store.ThrottleSample{}
+	for _, s := range result {
+		for _, p := range s.points {
+			if math.IsNaN(p.value) {
+				continue
+			}
+			out = append(out, store.ThrottleSample{Timestamp: p.timestamp, ThrottledCores: p.value})
+		}
+	}
+	return out, nil
+}
+
+// capacityTrend merges the node capacity, allocatable and requested series by timestamp.
+func (c *Client) capacityTrend(ctx context.Context, labels map[string]string, clusterID string, start, end time.Time) ([]store.CapacitySample, error) {
+	queries := []struct {
+		metric string
+		assign func(s *store.CapacitySample, v float64)
+	}{
+		{"clustercost_node_cpu_capacity_milli", func(s *store.CapacitySample, v float64) { s.CPUCapacityMilli = int64(v) }},
+		{"clustercost_node_cpu_allocatable_milli", func(s *store.CapacitySample, v float64) { s.CPUAllocatableMilli = int64(v) }},
+		{"clustercost_node_cpu_requested_milli", func(s *store.CapacitySample, v float64) { s.CPURequestMilli = int64(v) }},
+		{"clustercost_node_memory_capacity_bytes", func(s *store.CapacitySample, v float64) { s.MemoryCapacityBytes = int64(v) }},
+		{"clustercost_node_memory_allocatable_bytes", func(s *store.CapacitySample, v float64) { s.MemoryAllocatableBytes = int64(v) }},
+		{"clustercost_node_memory_requested_bytes", func(s *store.CapacitySample, v float64) { s.MemoryRequestBytes = int64(v) }},
+	}
+
+	byTime := make(map[int64]*store.CapacitySample)
+	for _, q := range queries {
+		selector := metricSelector(q.metric, c.scopedLabels(labels, clusterID))
+		expr := fmt.Sprintf("max(last_over_time(%s[%s]))", selector, formatDuration(nodeHistoryStep))
+		result, err := c.queryRange(ctx, expr, start, end, nodeHistoryStep)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range result {
+			for _, p := range s.points {
+				if math.IsNaN(p.value) {
+					continue
+				}
+				sample := byTime[p.timestamp.Unix()]
+				if sample == nil {
+					sample = &store.CapacitySample{Timestamp: p.timestamp}
+					byTime[p.timestamp.Unix()] = sample
+				}
+				q.assign(sample, p.value)
+			}
+		}
+	}
+
+	out := make([]store.CapacitySample, 0, len(byTime))
+	for _, sample := range byTime {
+		out = append(out, *sample)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out, nil
+}
+
+// nodePodCost sums the hourly cost of the pods running on each node.
+func (c *Client) nodePodCost(ctx context.Context, clusterID string) (map[string]float64, error) {
+	selector := metricSelector("clustercost_pod_hourly_cost", c.scopedLabels(nil, clusterID))
+	samples, err := c.query(ctx, fmt.Sprintf("sum by (node) (last_over_time(%s[%s]))", selector, formatDuration(containerRunningWindow)))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]float64, len(samples))
+	for _, s := range samples {
+		if node := s.labels["node"]; node != "" {
+			out[node] = s.value
+		}
+	}
+	return out, nil
+}
+
+// egressBreakdown sums the increase of the public, cross-AZ and internal egress counters over
+// detailEgressWindow. metricFormat holds a %s for the class.
+func (c *Client) egressBreakdown(ctx context.Context, metricFormat string, labels map[string]string, clusterID string) ([]store.EgressBreakdown, error) {
+	bytes := make(map[string]int64, 3)
+	for _, class := range []string{store.EgressClassPublic, store.EgressClassCrossAZ, store.EgressClassInternal} {
+		selector := metricSelector(fmt.Sprintf(metricFormat, class), c.scopedLabels(labels, clusterID))
+		samples, err := c.query(ctx, fmt.Sprintf("sum(increase(%s[%s]))", selector, formatDuration(detailEgressWindow)))
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) > 0 {
+			bytes[class] = int64(samples[0].value)
+		}
+	}
+	return store.NewEgressBreakdown(bytes[store.EgressClassPublic], bytes[store.EgressClassCrossAZ], bytes[store.EgressClassInternal]), nil
+}
+
+// costSparkline returns the cost accrued per hour over the last sparklineWindow.
+func (c *Client) costSparkline(ctx context.Context, metric string, labels map[string]string, clusterID string) ([]store.CostSample, error) {
+	end := time.Now().UTC().Truncate(sparklineStep)
+	selector := metricSelector(metric, c.scopedLabels(labels, clusterID))
+	expr := fmt.Sprintf("sum(avg_over_time(%s[%s])) * %g", selector, formatDuration(sparklineStep), sparklineStep.Hours())
+	result, err := c.queryRange(ctx, expr, end.Add(-sparklineWindow), end, sparklineStep)
+	if err != nil {
+		return nil, err
+	}
+	out := []store.CostSample{}
+	for _, s := range result {
+		for _, p := range s.points {
+			if math.IsNaN(p.value) {
+				continue
+			}
+			out = append(out, store.CostSample{Timestamp: p.timestamp, Cost: p.value})
+		}
+	}
+	return out, nil
+}
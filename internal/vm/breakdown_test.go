@@ -0,0 +1,23 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOptionalSectionsDoNotFailBreakdowns(t *testing.T) {
+	required := func() error { return nil }
+	noData := func() error { return fmt.Errorf("sparkline: %w", ErrNoData) }
+	if err := parallel(required, optional(noData)); err != nil {
+		t.Fatalf("expected an optional section without data to be skipped, got %v", err)
+	}
+
+	broken := errors.New("query failed")
+	if err := parallel(required, optional(func() error { return broken })); !errors.Is(err, broken) {
+		t.Fatalf("expected other errors to fail the breakdown, got %v", err)
+	}
+	if err := parallel(func() error { return ErrNoData }, optional(required)); !errors.Is(err, ErrNoData) {
+		t.Fatalf("expected a required section without data to fail the breakdown, got %v", err)
+	}
+}
@@ -0,0 +1,405 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/config"
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+// Modes for pod (ephemeral) and external IP labels on connection series.
+const (
+	ipModeKeep      = "keep"
+	ipModeDrop      = "drop"
+	ipModeBucket    = "bucket"
+	ipModeAggregate = "aggregate"
+	ipModeSubnet    = "subnet"
+
+	// externalTrafficIP replaces external IPs in aggregate mode
+	externalTrafficIP = "external-traffic"
+	// otherDstKind marks the per-node series that connections beyond the top N roll up into
+	otherDstKind = "other"
+)
+
+// cardinalityPolicy reduces connection series before they reach VictoriaMetrics. Every
+// connection otherwise becomes its own series keyed by src/dst IP, pod, node and DNS name.
+type cardinalityPolicy struct {
+	// ephemeralIPs is keep, drop or bucket (/24, /64 for IPv6) for IPs of pod endpoints
+	ephemeralIPs string
+	// externalIPs is keep, aggregate (external-traffic) or subnet (/24, /64) for endpoints
+	// outside the cluster
+	externalIPs string
+	// topN keeps the N largest series per report (one node) and rolls the rest into "other"
+	topN  int
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+// newCardinalityPolicy builds the policy from config. It returns nil when every control is
+// off, so the ingestor keeps writing one series per connection without extra allocations.
+func newCardinalityPolicy(cfg config.Config) (*cardinalityPolicy, error) {
+	p := &cardinalityPolicy{
+		ephemeralIPs: strings.ToLower(cfg.NetworkEphemeralIPs),
+		externalIPs:  strings.ToLower(cfg.NetworkExternalIPs),
+		topN:         cfg.NetworkTopConnectionsPerNode,
+		allow:        labelSet(cfg.NetworkLabelAllow),
+		deny:         labelSet(cfg.NetworkLabelDeny),
+	}
+	if p.ephemeralIPs == "" {
+		p.ephemeralIPs = ipModeKeep
+	}
+	if p.externalIPs == "" {
+		p.externalIPs = ipModeKeep
+	}
+	switch p.ephemeralIPs {
+	case ipModeKeep, ipModeDrop, ipModeBucket:
+	default:
+		return nil, fmt.Errorf("network ephemeral IP mode must be keep, drop or bucket, got %q", cfg.NetworkEphemeralIPs)
+	}
+	switch p.externalIPs {
+	case ipModeKeep, ipModeDrop, ipModeAggregate, ipModeSubnet:
+	default:
+		return nil, fmt.Errorf("network external IP mode must be keep, drop, aggregate or subnet, got %q", cfg.NetworkExternalIPs)
+	}
+	if p.topN < 0 {
+		return nil, fmt.Errorf("network top connections per node must not be negative")
+	}
+	if p.ephemeralIPs == ipModeKeep && p.externalIPs == ipModeKeep && p.topN == 0 && len(p.allow) == 0 && len(p.deny) == 0 {
+		return nil, nil
+	}
+	return p, nil
+}
+
+func labelSet(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k = strings.TrimSpace(k); k != "" {
+			set[k] = true
+		}
+	}
+	return set
+}
+
+// cardinalityStats counts, per agent, how many connections were received and what happened
+// to their series. Dropped connections merged into another series once labels were reduced;
+// rolled-up series were folded into the per-node "other" bucket.
+type cardinalityStats struct {
+	received atomic.Int64
+	written  atomic.Int64
+	dropped  atomic.Int64
+	rolledUp atomic.Int64
+}
+
+func (i *Ingestor) statsFor(agentName string) *cardinalityStats {
+	if s, ok := i.cardinalityStats.Load(agentName); ok {
+		return s.(*cardinalityStats)
+	}
+	s, _ := i.cardinalityStats.LoadOrStore(agentName, &cardinalityStats{})
+	return s.(*cardinalityStats)
+}
+
+// reducedIdleTTL bounds how long a connection or reduced series that stopped reporting keeps
+// its counter state, matching the gRPC collector's counter tracking.
+const reducedIdleTTL = time.Hour
+
+// topNRetainFactor is the hysteresis of the top N: a series kept in the previous report stays
+// out of "other" while it ranks within topN*topNRetainFactor, so series near the cut-off do not
+// flap between their own series and "other" on every report.
+const topNRetainFactor = 2
+
+// reducedCounter is the value last seen for one connection (raw) or written for one reduced
+// series (total).
+type reducedCounter struct {
+	sent, received uint64
+	lastSeen       time.Time
+	// kept marks reduced series that made the top N in the last report
+	kept bool
+}
+
+// reducedState is the counter state of one agent's node. Merged series sum counters whose
+// membership changes from report to report (connections come and go, move in and out of the
+// top N), so the sums are not written directly: each connection adds the growth of its own
+// counter since the previous report to the series it lands on, and written series never
+// decrease.
+type reducedState struct {
+	mu          sync.Mutex
+	connections map[string]*reducedCounter // full connection labels -> raw agent counter
+	series      map[string]*reducedCounter // reduced labels -> written total
+}
+
+func (i *Ingestor) reducedStateFor(agentName, nodeName string) *reducedState {
+	key := agentName + "\x00" + nodeName
+	if s, ok := i.reducedSeries.Load(key); ok {
+		return s.(*reducedState)
+	}
+	s, _ := i.reducedSeries.LoadOrStore(key, &reducedState{
+		connections: make(map[string]*reducedCounter),
+		series:      make(map[string]*reducedCounter),
+	})
+	return s.(*reducedState)
+}
+
+// connectionSeries is one reduced series within a report: the growth of its members since the
+// previous report.
+type connectionSeries struct {
+	labels         string
+	sent, received uint64
+	kept           bool
+}
+
+// appendReducedConnections writes the connections of a network report through the cardinality
+// policy: labels are rewritten, the growth of connections sharing the resulting labels is
+// summed, and series beyond the top N have their growth added to one "other" series for the
+// reporting node. Every written series only ever increases, so increase() sees no resets from
+// the reduction. The reduction counters are emitted with the agent's base labels so the effect
+// can be graphed.
+func (i *Ingestor) appendReducedConnections(buf, labelBuf *bytes.Buffer, scratch []byte, base []label, agentName string, req *agentv1.NetworkReportRequest, tsMillis int64) {
+	p := i.cardinality
+	stats := i.statsFor(agentName)
+	state := i.reducedStateFor(agentName, req.NodeName)
+	endpoints := req.Endpoints
+	now := time.UnixMilli(tsMillis)
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	byLabels := make(map[string]*connectionSeries)
+	var series []*connectionSeries
+	var received, dropped, rolledUp int64
+	extra := make([]label, 0, 20)
+	for _, compact := range req.CompactConnections {
+		if compact == nil {
+			continue
+		}
+		if int(compact.SrcIndex) >= len(endpoints) || int(compact.DstIndex) >= len(endpoints) {
+			continue
+		}
+		received++
+		src, dst := endpoints[compact.SrcIndex], endpoints[compact.DstIndex]
+
+		labelBuf.Reset()
+		writeCompactLabels(labelBuf, base, compact, src, dst)
+		sent, recv := state.growth(labelBuf.String(), compact.BytesSent, compact.BytesReceived, now)
+
+		extra = p.connectionLabels(extra[:0], compact, src, dst)
+		labelBuf.Reset()
+		writeLabels(labelBuf, base, extra...)
+		key := labelBuf.String()
+		s := byLabels[key]
+		if s == nil {
+			s = &connectionSeries{labels: key}
+			if prev := state.series[key]; prev != nil {
+				s.kept = prev.kept
+			}
+			byLabels[key] = s
+			series = append(series, s)
+		} else {
+			dropped++
+		}
+		s.sent += sent
+		s.received += recv
+	}
+
+	if p.topN > 0 && len(series) > p.topN {
+		series = state.rollUp(series, p.topN, &rolledUp, func() string {
+			labelBuf.Reset()
+			writeLabels(labelBuf, base, label{"src_node", req.NodeName}, label{"dst_kind", otherDstKind})
+			return labelBuf.String()
+		})
+	} else {
+		for _, s := range series {
+			s.kept = true
+		}
+	}
+
+	for _, s := range series {
+		total := state.series[s.labels]
+		if total == nil {
+			total = &reducedCounter{}
+			state.series[s.labels] = total
+		}
+		total.sent += s.sent
+		total.received += s.received
+		total.kept = s.kept
+		total.lastSeen = now
+		labels := []byte(s.labels)
+		writeIntSample(buf, scratch, "clustercost_connection_bytes_sent_total", labels, safeInt64(total.sent), tsMillis)
+		writeIntSample(buf, scratch, "clustercost_connection_bytes_received_total", labels, safeInt64(total.received), tsMillis)
+	}
+	state.expire(now)
+
+	stats.received.Add(received)
+	stats.written.Add(int64(len(series)))
+	stats.dropped.Add(dropped)
+	stats.rolledUp.Add(rolledUp)
+
+	labelBuf.Reset()
+	writeLabels(labelBuf, base)
+	baseBlob := labelBuf.Bytes()
+	writeIntSample(buf, scratch, "clustercost_ingest_connections_received_total", baseBlob, stats.received.Load(), tsMillis)
+	writeIntSample(buf, scratch, "clustercost_ingest_connection_series_written_total", baseBlob, stats.written.Load(), tsMillis)
+	writeIntSample(buf, scratch, "clustercost_ingest_connection_series_dropped_total", baseBlob, stats.dropped.Load(), tsMillis)
+	writeIntSample(buf, scratch, "clustercost_ingest_connection_series_rolled_up_total", baseBlob, stats.rolledUp.Load(), tsMillis)
+}
+
+// growth returns how much a connection's counters grew since the previous report. A value
+// below the previous one is a reset and counts from zero; a connection seen for the first time
+// contributes its whole value, as its own series would.
+func (s *reducedState) growth(key string, sent, received uint64, now time.Time) (uint64, uint64) {
+	c := s.connections[key]
+	if c == nil {
+		s.connections[key] = &reducedCounter{sent: sent, received: received, lastSeen: now}
+		return sent, received
+	}
+	dSent, dReceived := sent, received
+	if sent >= c.sent {
+		dSent = sent - c.sent
+	}
+	if received >= c.received {
+		dReceived = received - c.received
+	}
+	c.sent, c.received, c.lastSeen = sent, received, now
+	return dSent, dReceived
+}
+
+// rollUp keeps topN series and adds the growth of the rest to the "other" series. Series kept
+// in the previous report are preferred while they rank within topN*topNRetainFactor; the
+// remaining places go to the largest growth.
+func (s *reducedState) rollUp(series []*connectionSeries, topN int, rolledUp *int64, otherLabels func() string) []*connectionSeries {
+	sort.SliceStable(series, func(a, b int) bool {
+		return series[a].sent+series[a].received > series[b].sent+series[b].received
+	})
+	keep := make([]bool, len(series))
+	kept := 0
+	for idx, cs := range series {
+		if kept < topN && cs.kept && idx < topN*topNRetainFactor {
+			keep[idx] = true
+			kept++
+		}
+	}
+	for idx := range series {
+		if kept < topN && !keep[idx] {
+			keep[idx] = true
+			kept++
+		}
+	}
+
+	other := &connectionSeries{labels: otherLabels(), kept: true}
+	out := series[:0]
+	for idx, cs := range series {
+		if keep[idx] {
+			cs.kept = true
+			out = append(out, cs)
+			continue
+		}
+		other.sent += cs.sent
+		other.received += cs.received
+		if prev := s.series[cs.labels]; prev != nil {
+			prev.kept = false
+		}
+		*rolledUp++
+	}
+	return append(out, other)
+}
+
+// expire drops the state of connections and series that stopped reporting.
+func (s *reducedState) expire(now time.Time) {
+	for key, c := range s.connections {
+		if now.Sub(c.lastSeen) > reducedIdleTTL {
+			delete(s.connections, key)
+		}
+	}
+	for key, c := range s.series {
+		if now.Sub(c.lastSeen) > reducedIdleTTL {
+			delete(s.series, key)
+		}
+	}
+}
+
+// connectionLabels returns the labels writeCompactLabels would write, with IPs rewritten and
+// the allow/deny lists applied. Empty values are skipped by writeLabels.
+func (p *cardinalityPolicy) connectionLabels(out []label, compact *agentv1.CompactNetworkConnection, src, dst *agentv1.NetworkEndpoint) []label {
+	add := func(key, value string) {
+		if p.allow != nil && !p.allow[key] {
+			return
+		}
+		if p.deny[key] {
+			return
+		}
+		out = append(out, label{key, value})
+	}
+	add("protocol", strconv.FormatUint(uint64(compact.Protocol), 10))
+	add("egress_class", compact.EgressClass)
+	add("dst_kind", compact.DstKind)
+	add("service_match", compact.ServiceMatch)
+	add("is_egress", strconv.FormatBool(compact.IsEgress))
+	for _, side := range []struct {
+		prefix string
+		ep     *agentv1.NetworkEndpoint
+	}{{"src", src}, {"dst", dst}} {
+		ep := side.ep
+		if ep == nil {
+			continue
+		}
+		add(side.prefix+"_ip", p.endpointIP(ep))
+		add(side.prefix+"_namespace", ep.Namespace)
+		add(side.prefix+"_pod", ep.PodName)
+		add(side.prefix+"_node", ep.NodeName)
+		add(side.prefix+"_availability_zone", ep.AvailabilityZone)
+		add(side.prefix+"_dns_name", ep.DnsName)
+	}
+	if dst != nil {
+		add("dst_services", joinServiceRefs(dst.Services))
+	}
+	return out
+}
+
+// endpointIP applies the IP mode for the endpoint's kind. Pod IPs are ephemeral (they change
+// with every reschedule); endpoints without pod, node, namespace or Service are external.
+func (p *cardinalityPolicy) endpointIP(ep *agentv1.NetworkEndpoint) string {
+	mode := ipModeKeep
+	switch {
+	case ep.PodName != "":
+		mode = p.ephemeralIPs
+	case ep.NodeName == "" && ep.Namespace == "" && len(ep.Services) == 0:
+		mode = p.externalIPs
+	}
+	switch mode {
+	case ipModeDrop:
+		return ""
+	case ipModeAggregate:
+		return externalTrafficIP
+	case ipModeBucket, ipModeSubnet:
+		return ipSubnet(ep.Ip)
+	default:
+		return ep.Ip
+	}
+}
+
+// ipSubnet returns the /24 (IPv4) or /64 (IPv6) network of an address, or "" if it does not parse.
+func ipSubnet(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	bits := 64
+	if addr.Unmap().Is4() {
+		addr, bits = addr.Unmap(), 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
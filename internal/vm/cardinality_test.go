@@ -0,0 +1,200 @@
+package vm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/clustercost/clustercost-dashboard/internal/config"
+	agentv1 "github.com/clustercost/clustercost-dashboard/internal/proto/agent/v1"
+)
+
+func TestNewCardinalityPolicy(t *testing.T) {
+	p, err := newCardinalityPolicy(config.Config{})
+	if err != nil || p != nil {
+		t.Fatalf("expected no policy by default, got %+v, %v", p, err)
+	}
+	if _, err := newCardinalityPolicy(config.Config{NetworkEphemeralIPs: "hash"}); err == nil {
+		t.Fatal("expected error for unknown ephemeral IP mode")
+	}
+	if _, err := newCardinalityPolicy(config.Config{NetworkExternalIPs: "bucket"}); err == nil {
+		t.Fatal("expected error for unknown external IP mode")
+	}
+	p, err = newCardinalityPolicy(config.Config{NetworkExternalIPs: "Aggregate"})
+	if err != nil || p == nil || p.externalIPs != ipModeAggregate {
+		t.Fatalf("unexpected policy %+v, %v", p, err)
+	}
+}
+
+func TestIPSubnet(t *testing.T) {
+	cases := map[string]string{
+		"10.1.2.3":        "10.1.2.0/24",
+		"::ffff:10.1.2.3": "10.1.2.0/24",
+		"2001:db8::1":     "2001:db8::/64",
+		"not-an-ip":       "",
+	}
+	for ip, want := range cases {
+		if got := ipSubnet(ip); got != want {
+			t.Errorf("ipSubnet(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
+
+func cardinalityReport() *agentv1.NetworkReportRequest {
+	return &agentv1.NetworkReportRequest{
+		AgentId:          "agent-1",
+		ClusterId:        "cluster-1",
+		NodeName:         "node-a",
+		TimestampSeconds: 1700000000,
+		Endpoints: []*agentv1.NetworkEndpoint{
+			{Ip: "10.0.0.1", Namespace: "shop", PodName: "api-1", NodeName: "node-a"},
+			{Ip: "10.0.0.2", Namespace: "shop", PodName: "api-1", NodeName: "node-a"},
+			{Ip: "52.216.1.1"},
+			{Ip: "52.216.9.9"},
+			{Ip: "10.0.1.5", Namespace: "shop", PodName: "db-0", NodeName: "node-b"},
+		},
+		CompactConnections: []*agentv1.CompactNetworkConnection{
+			{SrcIndex: 0, DstIndex: 2, Protocol: 6, BytesSent: 100, BytesReceived: 10, DstKind: "external"},
+			{SrcIndex: 1, DstIndex: 3, Protocol: 6, BytesSent: 200, BytesReceived: 20, DstKind: "external"},
+			{SrcIndex: 0, DstIndex: 4, Protocol: 6, BytesSent: 5, BytesReceived: 1, DstKind: "pod"},
+		},
+	}
+}
+
+func TestAppendReducedConnectionsMergesSeries(t *testing.T) {
+	policy, err := newCardinalityPolicy(config.Config{NetworkEphemeralIPs: "drop", NetworkExternalIPs: "aggregate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ing := &Ingestor{cardinality: policy}
+	var buf bytes.Buffer
+	ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", networkReq: cardinalityReport()})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var sent []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "clustercost_connection_bytes_sent_total{") {
+			sent = append(sent, line)
+		}
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected the two external connections to merge, got %v", sent)
+	}
+	_, labels, value, _ := parseMetricLine(t, sent[0])
+	if value != "300" {
+		t.Fatalf("expected merged bytes 300, got %s", value)
+	}
+	assertLabel(t, labels, "dst_ip", externalTrafficIP)
+	if _, ok := labels["src_ip"]; ok {
+		t.Fatalf("expected pod IP to be dropped, got %v", labels)
+	}
+	assertLabel(t, labels, "src_pod", "api-1")
+
+	checkMetric(t, lines, "clustercost_ingest_connections_received_total", "3")
+	checkMetric(t, lines, "clustercost_ingest_connection_series_written_total", "2")
+	checkMetric(t, lines, "clustercost_ingest_connection_series_dropped_total", "1")
+	checkMetric(t, lines, "clustercost_ingest_connection_series_rolled_up_total", "0")
+}
+
+func TestAppendReducedConnectionsTopNAndDeny(t *testing.T) {
+	policy, err := newCardinalityPolicy(config.Config{
+		NetworkExternalIPs:           "subnet",
+		NetworkTopConnectionsPerNode: 1,
+		NetworkLabelDeny:             []string{"src_ip", "protocol"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ing := &Ingestor{cardinality: policy}
+	var buf bytes.Buffer
+	ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", networkReq: cardinalityReport()})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var sent []map[string]string
+	var values []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "clustercost_connection_bytes_sent_total{") {
+			_, labels, value, _ := parseMetricLine(t, line)
+			sent = append(sent, labels)
+			values = append(values, value)
+		}
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected top series plus other, got %v", values)
+	}
+	// Both external IPs share 52.216.x only at /16, so they stay apart at /24
+	assertLabel(t, sent[0], "dst_ip", "52.216.9.0/24")
+	if values[0] != "200" {
+		t.Fatalf("expected largest series first, got %s", values[0])
+	}
+	if _, ok := sent[0]["protocol"]; ok {
+		t.Fatalf("expected denied label to be removed, got %v", sent[0])
+	}
+	assertLabel(t, sent[1], "dst_kind", otherDstKind)
+	assertLabel(t, sent[1], "src_node", "node-a")
+	if values[1] != "105" {
+		t.Fatalf("expected other bucket 105, got %s", values[1])
+	}
+	checkMetric(t, lines, "clustercost_ingest_connection_series_rolled_up_total", "2")
+}
+
+func sentValues(t *testing.T, out string) map[string]string {
+	t.Helper()
+	values := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasPrefix(line, "clustercost_connection_bytes_sent_total{") {
+			_, labels, value, _ := parseMetricLine(t, line)
+			values[labels["dst_ip"]+"|"+labels["dst_kind"]] = value
+		}
+	}
+	return values
+}
+
+func TestAppendReducedConnectionsNeverDecrease(t *testing.T) {
+	policy, err := newCardinalityPolicy(config.Config{
+		NetworkExternalIPs:           "subnet",
+		NetworkTopConnectionsPerNode: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ing := &Ingestor{cardinality: policy}
+	report := func(mutate func(*agentv1.NetworkReportRequest)) map[string]string {
+		req := cardinalityReport()
+		mutate(req)
+		var buf bytes.Buffer
+		ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", networkReq: req})
+		return sentValues(t, buf.String())
+	}
+
+	first := report(func(*agentv1.NetworkReportRequest) {})
+	if first["52.216.9.0/24|external"] != "200" || first["|other"] != "105" {
+		t.Fatalf("unexpected first report %v", first)
+	}
+
+	// The pod connection ends and the 52.216.1.1 connection overtakes the top series only
+	// slightly: the top series stays, and "other" keeps growing instead of losing 5 bytes.
+	second := report(func(req *agentv1.NetworkReportRequest) {
+		req.TimestampSeconds += 60
+		req.CompactConnections[0].BytesSent = 400
+		req.CompactConnections[1].BytesSent = 250
+		req.CompactConnections = req.CompactConnections[:2]
+	})
+	if second["52.216.9.0/24|external"] != "250" {
+		t.Fatalf("expected sticky top series to grow to 250, got %v", second)
+	}
+	if second["|other"] != "405" {
+		t.Fatalf("expected other to add the 300 byte growth, got %v", second)
+	}
+
+	// The agent restarts its counters: growth counts from zero, nothing moves backwards.
+	third := report(func(req *agentv1.NetworkReportRequest) {
+		req.TimestampSeconds += 120
+		req.CompactConnections[0].BytesSent = 10
+		req.CompactConnections[1].BytesSent = 20
+		req.CompactConnections[2].BytesSent = 1
+	})
+	if third["52.216.9.0/24|external"] != "270" || third["|other"] != "416" {
+		t.Fatalf("expected counters to keep growing across the reset, got %v", third)
+	}
+}
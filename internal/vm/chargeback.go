@@ -0,0 +1,244 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+// ErrInvalidLabelKey is returned when a grouping label is not a valid Prometheus label name
+// or is not a label namespace series carry.
+var ErrInvalidLabelKey = errors.New("invalid label key")
+
+var labelKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Chargeback bills each namespace (or each value of labelKey on namespace series) for the
+// resources it reserved between start and end. CPU and memory are priced from the node compute
+// pool accrued over the period (50/50 split, as on the dashboard); GPU and storage use their
+// attributed costs; egress uses list prices per GB. Node capacity no group reserved is idle
+// and shared out in proportion to each group's CPU and memory cost.
+func (c *Client) Chargeback(ctx context.Context, start, end time.Time, labelKey string) (store.ChargebackReport, error) {
+	groupBy := store.ChargebackByNamespace
+	if labelKey == "" || labelKey == "namespace" {
+		labelKey = "namespace"
+	} else {
+		if err := c.ValidateGroupLabel(labelKey); err != nil {
+			return store.ChargebackReport{}, err
+		}
+		groupBy = store.ChargebackByLabel
+	}
+	window := end.Sub(start).Truncate(time.Hour)
+	if window < time.Hour {
+		return store.ChargebackReport{}, fmt.Errorf("chargeback period must cover at least one hour")
+	}
+
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+	period := chargebackPeriod{window: formatDuration(window), end: end.Unix()}
+
+	lines := make(map[string]*store.ChargebackLine)
+	line := func(group string) *store.ChargebackLine {
+		if group == "" {
+			group = store.ChargebackUnlabeled
+		}
+		l := lines[group]
+		if l == nil {
+			l = &store.ChargebackLine{Group: group}
+			lines[group] = l
+		}
+		return l
+	}
+	groupQueries := []struct {
+		expr   string
+		assign func(l *store.ChargebackLine, v float64)
+	}{
+		{period.hourSum(c.scopedSelector("clustercost_namespace_cpu_request_millicores", clusterID), labelKey), func(l *store.ChargebackLine, v float64) {
+			l.CPUCoreHours = v / 1000
+		}},
+		{period.hourSum(c.scopedSelector("clustercost_namespace_memory_request_bytes", clusterID), labelKey), func(l *store.ChargebackLine, v float64) {
+			l.MemoryGBHours = v / (1024 * 1024 * 1024)
+		}},
+		{period.hourSum(c.scopedSelector("clustercost_namespace_gpu_hourly_cost", clusterID), labelKey), func(l *store.ChargebackLine, v float64) {
+			l.GPUCost = v
+		}},
+		{period.hourSum(c.scopedSelector("clustercost_namespace_storage_hourly_cost", clusterID), labelKey), func(l *store.ChargebackLine, v float64) {
+			l.StorageCost = v
+		}},
+		{period.increase(c.scopedSelector("clustercost_namespace_network_egress_public_bytes_total", clusterID), labelKey), func(l *store.ChargebackLine, v float64) {
+			l.EgressPublicGB = v / (1024 * 1024 * 1024)
+		}},
+		{period.increase(c.scopedSelector("clustercost_namespace_network_egress_cross_az_bytes_total", clusterID), labelKey), func(l *store.ChargebackLine, v float64) {
+			l.EgressCrossAZGB = v / (1024 * 1024 * 1024)
+		}},
+	}
+	for _, q := range groupQueries {
+		samples, err := c.query(ctx, q.expr)
+		if err != nil {
+			return store.ChargebackReport{}, err
+		}
+		for _, sample := range samples {
+			if math.IsNaN(sample.value) {
+				continue
+			}
+			q.assign(line(sample.labels[labelKey]), sample.value)
+		}
+	}
+	if len(lines) == 0 {
+		return store.ChargebackReport{}, ErrNoData
+	}
+
+	pool, err := c.nodeComputePool(ctx, clusterID, period)
+	if err != nil {
+		return store.ChargebackReport{}, err
+	}
+
+	report := store.ChargebackReport{
+		ClusterID:       clusterID,
+		GroupBy:         groupBy,
+		PeriodStart:     start.UTC(),
+		PeriodEnd:       end.UTC(),
+		GeneratedAt:     time.Now().UTC(),
+		NodeComputeCost: pool.cost,
+		Lines:           make([]store.ChargebackLine, 0, len(lines)),
+	}
+	if groupBy == store.ChargebackByLabel {
+		report.LabelKey = labelKey
+	}
+	if pool.cost > 0 && pool.coreHours > 0 && pool.gbHours > 0 {
+		report.CPUPricePerCoreHour = pool.cost * 0.5 / pool.coreHours
+		report.MemoryPricePerGBHour = pool.cost * 0.5 / pool.gbHours
+	} else {
+		// No node history: fall back to list prices of the default instance.
+		report.CPUPricePerCoreHour, report.MemoryPricePerGBHour = store.NewPricingCatalog(nil).GetNodeResourcePrices(ctx, "", "default", 0, 0)
+	}
+
+	var allocated float64
+	for _, l := range lines {
+		l.ComputeCost = l.CPUCoreHours * report.CPUPricePerCoreHour
+		l.MemoryCost = l.MemoryGBHours * report.MemoryPricePerGBHour
+		l.NetworkEgressCost = l.EgressPublicGB*store.CostEgressPublic + l.EgressCrossAZGB*store.CostEgressCrossAZ
+		allocated += l.ComputeCost + l.MemoryCost
+	}
+	if pool.cost > allocated {
+		report.IdleCost = pool.cost - allocated
+	}
+	for _, l := range lines {
+		if allocated > 0 {
+			l.IdleShareCost = report.IdleCost * (l.ComputeCost + l.MemoryCost) / allocated
+		}
+		l.TotalCost = l.ComputeCost + l.MemoryCost + l.GPUCost + l.StorageCost + l.NetworkEgressCost + l.IdleShareCost
+		report.TotalCost += l.TotalCost
+		report.Lines = append(report.Lines, *l)
+	}
+	sort.Slice(report.Lines, func(i, j int) bool {
+		if report.Lines[i].TotalCost == report.Lines[j].TotalCost {
+			return report.Lines[i].Group < report.Lines[j].Group
+		}
+		return report.Lines[i].TotalCost > report.Lines[j].TotalCost
+	})
+	return report, nil
+}
+
+// chargebackPeriod renders range expressions pinned to the end of a closed period.
+type chargebackPeriod struct {
+	window string
+	end    int64
+}
+
+// hourSum adds up the hourly value of a gauge over the period, so series only count while present.
+func (p chargebackPeriod) hourSum(selector, groupBy string) string {
+	return fmt.Sprintf("sum by (%s) (sum_over_time(last_over_time(%s[1h])[%s:1h] @ %d))", groupBy, selector, p.window, p.end)
+}
+
+func (p chargebackPeriod) increase(selector, groupBy string) string {
+	return fmt.Sprintf("sum by (%s) (increase(%s[%s] @ %d))", groupBy, selector, p.window, p.end)
+}
+
+type computePool struct {
+	cost      float64
+	coreHours float64
+	gbHours   float64
+}
+
+// nodeComputePool prices every node-hour in the period with the CPU/memory share of its instance price.
+func (c *Client) nodeComputePool(ctx context.Context, clusterID string, period chargebackPeriod) (computePool, error) {
+	type nodeUsage struct {
+		instanceType string
+		region       string
+		hours        float64
+		gpus         float64
+	}
+	nodes := make(map[string]*nodeUsage)
+	nodeExpr := func(fn, metric string) string {
+		inner := fmt.Sprintf("max by (node, instance_type, cluster_region) (last_over_time(%s[1h]))", c.scopedSelector(metric, clusterID))
+		return fmt.Sprintf("%s((%s)[%s:1h] @ %d)", fn, inner, period.window, period.end)
+	}
+	load := func(expr string, assign func(n *nodeUsage, v float64)) error {
+		samples, err := c.query(ctx, expr)
+		if err != nil {
+			return err
+		}
+		for _, sample := range samples {
+			name := sample.labels["node"]
+			if name == "" || math.IsNaN(sample.value) {
+				continue
+			}
+			n := nodes[name]
+			if n == nil {
+				n = &nodeUsage{}
+				nodes[name] = n
+			}
+			if n.instanceType == "" {
+				n.instanceType = sample.labels["instance_type"]
+			}
+			if n.region == "" {
+				n.region = sample.labels["cluster_region"]
+			}
+			assign(n, sample.value)
+		}
+		return nil
+	}
+
+	var pool computePool
+	if err := load(nodeExpr("count_over_time", "clustercost_node_cpu_allocatable_milli"), func(n *nodeUsage, v float64) { n.hours = math.Max(n.hours, v) }); err != nil {
+		return pool, err
+	}
+	if err := load(nodeExpr("max_over_time", "clustercost_node_gpu_capacity"), func(n *nodeUsage, v float64) { n.gpus = math.Max(n.gpus, v) }); err != nil {
+		return pool, err
+	}
+	cpuHours, err := c.query(ctx, fmt.Sprintf("sum(%s)", nodeExpr("sum_over_time", "clustercost_node_cpu_allocatable_milli")))
+	if err != nil {
+		return pool, err
+	}
+	memHours, err := c.query(ctx, fmt.Sprintf("sum(%s)", nodeExpr("sum_over_time", "clustercost_node_memory_allocatable_bytes")))
+	if err != nil {
+		return pool, err
+	}
+	if len(cpuHours) > 0 {
+		pool.coreHours = cpuHours[0].value / 1000
+	}
+	if len(memHours) > 0 {
+		pool.gbHours = memHours[0].value / (1024 * 1024 * 1024)
+	}
+
+	pricing := store.NewPricingCatalog(nil)
+	for _, n := range nodes {
+		instanceType := n.instanceType
+		if instanceType == "" {
+			instanceType = "default"
+		}
+		computeCost, _ := pricing.SplitNodePrice(ctx, n.region, instanceType, int64(n.gpus))
+		pool.cost += computeCost * n.hours
+	}
+	return pool, nil
+}
+
+func (c *Client) scopedSelector(metric, clusterID string) string {
+	return metricSelector(metric, c.scopedLabels(nil, clusterID))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -23,6 +24,7 @@ var ErrNoData = errors.New("no data available")
 
 const (
 	queryPath             = "/api/v1/query"
+	queryRangePath        = "/api/v1/query_range"
 	datasetFreshThreshold = 2 * time.Minute
 	agentOfflineThreshold = 5 * time.Minute
 	defaultQueryTimeout   = 5 * time.Second
@@ -32,6 +34,7 @@ const (
 // Client queries VictoriaMetrics for dashboard data.
 type Client struct {
 	baseURL                 string
+	rangeURL                string
 	lookback                time.Duration
 	recommendedAgentVersion string
 	agents                  []config.AgentConfig
@@ -42,6 +45,11 @@ type Client struct {
 	cacheTTL                time.Duration
 	cacheMu                 sync.Mutex
 	cache                   map[string]cachedQuery
+	// groupLabels are the namespace series labels budgets and chargeback can group by
+	groupLabels []string
+	// streamClient only bounds the wait for response headers, so a streamed result is not cut
+	// off by the query timeout while the caller is still consuming it
+	streamClient *http.Client
 }
 
 type cachedQuery struct {
@@ -55,7 +63,11 @@ func NewClient(cfg config.Config) (*Client, error) {
 		return nil, fmt.Errorf("victoria metrics url is required")
 	}
 
-	base, err := buildQueryURL(cfg.VictoriaMetricsURL)
+	base, err := buildQueryURL(cfg.VictoriaMetricsURL, queryPath)
+	if err != nil {
+		return nil, err
+	}
+	rangeURL, err := buildQueryURL(cfg.VictoriaMetricsURL, queryRangePath)
 	if err != nil {
 		return nil, err
 	}
@@ -70,17 +82,28 @@ func NewClient(cfg config.Config) (*Client, error) {
 		lookback = 24 * time.Hour
 	}
 
+	_, groupLabels, err := newNamespaceLabels(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	streamTransport := http.DefaultTransport.(*http.Transport).Clone()
+	streamTransport.ResponseHeaderTimeout = timeout
+
 	c := &Client{
 		baseURL:                 base,
+		rangeURL:                rangeURL,
 		lookback:                lookback,
 		recommendedAgentVersion: cfg.RecommendedAgentVersion,
 		agents:                  cfg.Agents,
 		httpClient:              &http.Client{Timeout: timeout},
+		streamClient:            &http.Client{Transport: streamTransport},
 		authToken:               cfg.VictoriaMetricsToken,
 		username:                cfg.VictoriaMetricsUsername,
 		password:                cfg.VictoriaMetricsPassword,
 		cacheTTL:                defaultQueryCacheTTL,
 		cache:                   make(map[string]cachedQuery),
+		groupLabels:             groupLabels,
 	}
 
 	go func() {
@@ -102,7 +125,7 @@ func (c *Client) Ping(ctx context.Context) error {
 	return err
 }
 
-func buildQueryURL(base string) (string, error) {
+func buildQueryURL(base, endpoint string) (string, error) {
 	parsed, err := url.Parse(base)
 	if err != nil {
 		return "", fmt.Errorf("invalid victoria metrics url: %w", err)
@@ -110,7 +133,7 @@ func buildQueryURL(base string) (string, error) {
 	if parsed.Scheme == "" {
 		return "", fmt.Errorf("victoria metrics url missing scheme: %s", base)
 	}
-	parsed.Path = path.Join(parsed.Path, queryPath)
+	parsed.Path = path.Join(parsed.Path, endpoint)
 	return parsed.String(), nil
 }
 
@@ -120,16 +143,31 @@ type sample struct {
 	timestamp time.Time
 }
 
-type vmResponse struct {
+// vmEnvelope is the status wrapper shared by every query API response.
+type vmEnvelope struct {
 	Status string `json:"status"`
-	Data   struct {
+	Error  string `json:"error"`
+}
+
+func (e vmEnvelope) check() error {
+	if e.Status == "success" {
+		return nil
+	}
+	if e.Error != "" {
+		return fmt.Errorf("victoria metrics error: %s", e.Error)
+	}
+	return fmt.Errorf("victoria metrics status %s", e.Status)
+}
+
+type vmResponse struct {
+	vmEnvelope
+	Data struct {
 		ResultType string `json:"resultType"`
 		Result     []struct {
 			Metric map[string]string `json:"metric"`
 			Value  []any             `json:"value"`
 		} `json:"result"`
 	} `json:"data"`
-	Error string `json:"error"`
 }
 
 func (c *Client) query(ctx context.Context, expr string) ([]sample, error) {
@@ -137,17 +175,118 @@ func (c *Client) query(ctx context.Context, expr string) ([]sample, error) {
 		return cached, nil
 	}
 
-	u, err := url.Parse(c.baseURL)
+	params := url.Values{}
+	params.Set("query", expr)
+	var payload vmResponse
+	if err := c.get(ctx, c.baseURL, params, &payload); err != nil {
+		return nil, err
+	}
+	if err := payload.check(); err != nil {
+		return nil, err
+	}
+
+	out := make([]sample, 0, len(payload.Data.Result))
+	for _, item := range payload.Data.Result {
+		if len(item.Value) != 2 {
+			continue
+		}
+		ts, ok := parseFloat(item.Value[0])
+		if !ok {
+			continue
+		}
+		val, ok := parseFloat(item.Value[1])
+		if !ok {
+			continue
+		}
+		out = append(out, sample{
+			labels:    item.Metric,
+			value:     val,
+			timestamp: time.Unix(int64(ts), 0),
+		})
+	}
+	c.storeCached(expr, out)
+	return out, nil
+}
+
+type series struct {
+	labels map[string]string
+	points []point
+}
+
+type point struct {
+	timestamp time.Time
+	value     float64
+}
+
+type vmRangeResponse struct {
+	vmEnvelope
+	Data struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][]any           `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// queryRange evaluates expr at every step between start and end. Results are not cached.
+func (c *Client) queryRange(ctx context.Context, expr string, start, end time.Time, step time.Duration) ([]series, error) {
+	params := url.Values{}
+	params.Set("query", expr)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", formatDuration(step))
+	var payload vmRangeResponse
+	if err := c.get(ctx, c.rangeURL, params, &payload); err != nil {
+		return nil, err
+	}
+	if err := payload.check(); err != nil {
+		return nil, err
+	}
+
+	out := make([]series, 0, len(payload.Data.Result))
+	for _, item := range payload.Data.Result {
+		s := series{labels: item.Metric, points: make([]point, 0, len(item.Values))}
+		for _, pair := range item.Values {
+			if len(pair) != 2 {
+				continue
+			}
+			ts, ok := parseFloat(pair[0])
+			if !ok {
+				continue
+			}
+			val, ok := parseFloat(pair[1])
+			if !ok {
+				continue
+			}
+			s.points = append(s.points, point{timestamp: time.Unix(int64(ts), 0).UTC(), value: val})
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// get performs an authenticated GET against a query endpoint and decodes the JSON payload.
+func (c *Client) get(ctx context.Context, endpoint string, params url.Values, payload any) error {
+	return c.do(ctx, c.httpClient, endpoint, params, func(body io.Reader) error {
+		if err := json.NewDecoder(body).Decode(payload); err != nil {
+			return fmt.Errorf("decode victoria metrics response: %w", err)
+		}
+		return nil
+	})
+}
+
+// do sends a query API request and hands the successful response body to read.
+func (c *Client) do(ctx context.Context, client *http.Client, endpoint string, params url.Values, read func(io.Reader) error) error {
+	u, err := url.Parse(endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("parse query url: %w", err)
+		return fmt.Errorf("parse query url: %w", err)
 	}
-	q := u.Query()
-	q.Set("query", expr)
-	u.RawQuery = q.Encode()
+	u.RawQuery = params.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("build request: %w", err)
+		return fmt.Errorf("build request: %w", err)
 	}
 	req.Header.Set("Accept", "application/json")
 
@@ -157,29 +296,102 @@ func (c *Client) query(ctx context.Context, expr string) ([]sample, error) {
 		req.SetBasicAuth(c.username, c.password)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("query victoria metrics: %w", err)
+		return fmt.Errorf("query victoria metrics: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("victoria metrics responded with status %d", resp.StatusCode)
+		return fmt.Errorf("victoria metrics responded with status %d", resp.StatusCode)
 	}
+	return read(resp.Body)
+}
 
-	var payload vmResponse
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return nil, fmt.Errorf("decode victoria metrics response: %w", err)
+// streamQuery runs an instant query like query, but decodes the result one sample at a time
+// and passes each to fn instead of buffering the response. Results are not cached. An error
+// from fn stops decoding and is returned.
+func (c *Client) streamQuery(ctx context.Context, expr string, fn func(sample) error) error {
+	params := url.Values{}
+	params.Set("query", expr)
+	return c.do(ctx, c.streamClient, c.baseURL, params, func(body io.Reader) error {
+		var envelope vmEnvelope
+		emit := func(s sample) error {
+			if err := fn(s); err != nil {
+				return callbackError{err}
+			}
+			return nil
+		}
+		dec := json.NewDecoder(body)
+		err := decodeObject(dec, func(key string) error {
+			switch key {
+			case "status":
+				return dec.Decode(&envelope.Status)
+			case "error":
+				return dec.Decode(&envelope.Error)
+			case "data":
+				return decodeObject(dec, func(key string) error {
+					if key != "result" {
+						return dec.Decode(new(json.RawMessage))
+					}
+					return decodeResult(dec, emit)
+				})
+			default:
+				return dec.Decode(new(json.RawMessage))
+			}
+		})
+		var stopped callbackError
+		if errors.As(err, &stopped) {
+			return stopped.err
+		}
+		if err != nil {
+			return fmt.Errorf("decode victoria metrics response: %w", err)
+		}
+		return envelope.check()
+	})
+}
+
+// callbackError carries an error returned by a streamQuery callback through the decoder so it
+// is not reported as a malformed response.
+type callbackError struct{ err error }
+
+func (e callbackError) Error() string { return e.err.Error() }
+
+// decodeObject calls field for each key of the JSON object at the decoder position; field
+// must consume the value.
+func decodeObject(dec *json.Decoder, field func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
 	}
-	if payload.Status != "success" {
-		if payload.Error != "" {
-			return nil, fmt.Errorf("victoria metrics error: %s", payload.Error)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("unexpected token %v", tok)
+		}
+		if err := field(key); err != nil {
+			return err
 		}
-		return nil, fmt.Errorf("victoria metrics status %s", payload.Status)
 	}
+	return expectDelim(dec, '}')
+}
 
-	out := make([]sample, 0, len(payload.Data.Result))
-	for _, item := range payload.Data.Result {
+// decodeResult passes each well-formed vector sample of a result array to fn.
+func decodeResult(dec *json.Decoder, fn func(sample) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		var item struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+		}
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
 		if len(item.Value) != 2 {
 			continue
 		}
@@ -191,14 +403,22 @@ func (c *Client) query(ctx context.Context, expr string) ([]sample, error) {
 		if !ok {
 			continue
 		}
-		out = append(out, sample{
-			labels:    item.Metric,
-			value:     val,
-			timestamp: time.Unix(int64(ts), 0),
-		})
+		if err := fn(sample{labels: item.Metric, value: val, timestamp: time.Unix(int64(ts), 0)}); err != nil {
+			return err
+		}
 	}
-	c.storeCached(expr, out)
-	return out, nil
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %v, got %v", want, tok)
+	}
+	return nil
 }
 
 func (c *Client) loadCached(expr string) ([]sample, bool) {
@@ -231,6 +451,26 @@ func (c *Client) storeCached(expr string, samples []sample) {
 	}
 }
 
+// parallel runs the queries concurrently and returns the first error, in argument order.
+func parallel(fns ...func() error) error {
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn()
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func parseFloat(v any) (float64, bool) {
 	switch t := v.(type) {
 	case float64:
@@ -333,70 +573,3 @@ func (c *Client) scopedLabels(labels map[string]string, clusterID string) map[st
 	}
 	return labels
 }
-
-// GetPodP95Usage returns the 95th percentile of CPU and Memory usage for a specific pod over the lookback period.
-// cpu is in cores, memory is in bytes.
-func (c *Client) GetPodP95Usage(ctx context.Context, clusterID, namespace, podName string) (cpuCores float64, memoryBytes float64, err error) {
-	// Construct labels for specific pod
-	labels := map[string]string{
-		"namespace": namespace,
-		"pod":       podName,
-	}
-	if clusterID != "" {
-		labels["cluster_id"] = clusterID
-	}
-
-	// CPU Query: quantile_over_time(0.95, clustercost_pod_cpu_usage_milli{...}[1h]) / 1000
-	// Pod CPU usage is reported as millicores (gauge).
-	cpuQuery := fmt.Sprintf("quantile_over_time(0.95, clustercost_pod_cpu_usage_milli%s[%s]) / 1000",
-		formatLabels(labels), c.lookback.String())
-
-	// Memory Query: quantile_over_time(0.95, clustercost_pod_memory_rss_bytes{...}[1h])
-	memQuery := fmt.Sprintf("quantile_over_time(0.95, clustercost_pod_memory_rss_bytes%s[%s])",
-		formatLabels(labels), c.lookback.String())
-
-	// Execute CPU query
-	cpuSamples, err := c.query(ctx, cpuQuery)
-	if err != nil {
-		return 0, 0, fmt.Errorf("query cpu p95: %w", err)
-	}
-	if len(cpuSamples) > 0 {
-		cpuCores = cpuSamples[0].value
-	}
-
-	// Execute Memory query
-	memSamples, err := c.query(ctx, memQuery)
-	if err != nil {
-		return 0, 0, fmt.Errorf("query memory p95: %w", err)
-	}
-	if len(memSamples) > 0 {
-		memoryBytes = memSamples[0].value
-	}
-
-	return cpuCores, memoryBytes, nil
-}
-
-func formatLabels(labels map[string]string) string {
-	if len(labels) == 0 {
-		return ""
-	}
-	var b strings.Builder
-	b.WriteByte('{')
-	keys := make([]string, 0, len(labels))
-	for k := range labels {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	for i, k := range keys {
-		if i > 0 {
-			b.WriteByte(',')
-		}
-		b.WriteString(k)
-		b.WriteString(`="`)
-		b.WriteString(labels[k]) // Simple escape for now, assume safe chars
-		b.WriteByte('"')
-	}
-	b.WriteByte('}')
-	return b.String()
-}
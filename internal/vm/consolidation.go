@@ -0,0 +1,68 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PodPlacement is a running pod, the node it is scheduled on and its summed container requests.
+type PodPlacement struct {
+	Namespace          string
+	Pod                string
+	Node               string
+	WorkloadKind       string
+	CPURequestMilli    float64
+	MemoryRequestBytes float64
+}
+
+// PodPlacements returns the pods that reported within the last few minutes with their requests.
+func (c *Client) PodPlacements(ctx context.Context) ([]PodPlacement, error) {
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+
+	type podKey struct{ namespace, pod, node string }
+	pods := make(map[podKey]*PodPlacement)
+	queries := []struct {
+		metric string
+		assign func(p *PodPlacement, v float64)
+	}{
+		{"clustercost_pod_cpu_request_millicores", func(p *PodPlacement, v float64) { p.CPURequestMilli = v }},
+		{"clustercost_pod_memory_request_bytes", func(p *PodPlacement, v float64) { p.MemoryRequestBytes = v }},
+	}
+	for _, q := range queries {
+		selector := metricSelector(q.metric, c.scopedLabels(nil, clusterID))
+		expr := fmt.Sprintf("sum by (namespace, pod, node, workload_kind) (last_over_time(%s[%s]))", selector, formatDuration(containerRunningWindow))
+		samples, err := c.query(ctx, expr)
+		if err != nil {
+			return nil, fmt.Errorf("query pod placements: %w", err)
+		}
+		for _, s := range samples {
+			key := podKey{s.labels["namespace"], s.labels["pod"], s.labels["node"]}
+			if key.pod == "" || key.node == "" || math.IsNaN(s.value) {
+				continue
+			}
+			p := pods[key]
+			if p == nil {
+				p = &PodPlacement{Namespace: key.namespace, Pod: key.pod, Node: key.node, WorkloadKind: s.labels["workload_kind"]}
+				pods[key] = p
+			}
+			q.assign(p, s.value)
+		}
+	}
+	if len(pods) == 0 {
+		return nil, ErrNoData
+	}
+	out := make([]PodPlacement, 0, len(pods))
+	for _, p := range pods {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Node != out[j].Node {
+			return out[i].Node < out[j].Node
+		}
+		return out[i].Namespace+"/"+out[i].Pod < out[j].Namespace+"/"+out[j].Pod
+	})
+	return out, nil
+}
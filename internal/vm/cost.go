@@ -0,0 +1,157 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CostSince returns the accumulated namespace cost (USD) between since and now.
+// Hourly cost gauges are integrated over the window, so short reporting gaps are interpolated.
+// scope narrows the namespace series (e.g. namespace or environment); nil covers the whole cluster.
+func (c *Client) CostSince(ctx context.Context, since time.Time, scope map[string]string) (float64, error) {
+	window := time.Since(since)
+	if window < time.Second {
+		return 0, nil
+	}
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+
+	labels := make(map[string]string, len(scope)+1)
+	for key, value := range scope {
+		labels[key] = value
+	}
+	selector := metricSelector("clustercost_namespace_hourly_cost", c.scopedLabels(labels, clusterID))
+	expr := fmt.Sprintf("sum(integrate(%s[%s])) / 3600", selector, formatDuration(window))
+
+	samples, err := c.query(ctx, expr)
+	if err != nil {
+		return 0, err
+	}
+	if len(samples) == 0 {
+		return 0, ErrNoData
+	}
+	return samples[0].value, nil
+}
+
+// CostAnomalyScore returns how many standard deviations the current hourly cost is above its
+// average over the baseline window (z-score). A flat history yields 0.
+func (c *Client) CostAnomalyScore(ctx context.Context, scope map[string]string, baseline time.Duration) (float64, error) {
+	if baseline <= 0 {
+		baseline = c.lookback
+	}
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+
+	labels := make(map[string]string, len(scope)+1)
+	for key, value := range scope {
+		labels[key] = value
+	}
+	selector := metricSelector("clustercost_namespace_hourly_cost", c.scopedLabels(labels, clusterID))
+	window := formatDuration(baseline)
+	expr := fmt.Sprintf("(sum(last_over_time(%[1]s[10m])) - avg_over_time(sum(%[1]s)[%[2]s:5m])) / stddev_over_time(sum(%[1]s)[%[2]s:5m])", selector, window)
+
+	samples, err := c.query(ctx, expr)
+	if err != nil {
+		return 0, err
+	}
+	if len(samples) == 0 {
+		return 0, ErrNoData
+	}
+	score := samples[0].value
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return 0, nil
+	}
+	return score, nil
+}
+
+// NamespaceCost is the average hourly cost of a namespace over a window.
+type NamespaceCost struct {
+	ClusterID  string
+	Namespace  string
+	HourlyCost float64
+}
+
+// NamespaceHourlyCosts returns the average hourly cost per cluster and namespace over window,
+// shifted back by offset (e.g. one week for a seasonal baseline). All clusters are included.
+func (c *Client) NamespaceHourlyCosts(ctx context.Context, window, offset time.Duration) ([]NamespaceCost, error) {
+	if window <= 0 {
+		window = time.Hour
+	}
+	var offsetClause string
+	if offset > 0 {
+		offsetClause = " offset " + formatDuration(offset)
+	}
+	expr := fmt.Sprintf("sum by (cluster_id, namespace) (avg_over_time(clustercost_namespace_hourly_cost[%s]%s))", formatDuration(window), offsetClause)
+
+	samples, err := c.query(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]NamespaceCost, 0, len(samples))
+	for _, sample := range samples {
+		ns := sample.labels["namespace"]
+		if ns == "" || math.IsNaN(sample.value) {
+			continue
+		}
+		out = append(out, NamespaceCost{
+			ClusterID:  sample.labels["cluster_id"],
+			Namespace:  ns,
+			HourlyCost: sample.value,
+		})
+	}
+	return out, nil
+}
+
+// CostPoint is the cost accrued over one step of a cost history.
+type CostPoint struct {
+	Timestamp time.Time
+	Cost      float64
+}
+
+// NamespaceCostSeries is the cost history of one namespace.
+type NamespaceCostSeries struct {
+	Namespace string
+	Points    []CostPoint
+}
+
+// NamespaceCostHistory returns the cost accrued by each namespace per step between start and end.
+// Each point covers the step ending at its timestamp; hours without samples are omitted.
+func (c *Client) NamespaceCostHistory(ctx context.Context, start, end time.Time, step time.Duration) ([]NamespaceCostSeries, error) {
+	if step <= 0 {
+		step = time.Hour
+	}
+	if !end.After(start) {
+		return nil, ErrNoData
+	}
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+
+	selector := metricSelector("clustercost_namespace_hourly_cost", c.scopedLabels(nil, clusterID))
+	expr := fmt.Sprintf("sum by (namespace) (avg_over_time(%s[%s])) * %g", selector, formatDuration(step), step.Hours())
+
+	result, err := c.queryRange(ctx, expr, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]NamespaceCostSeries, 0, len(result))
+	for _, s := range result {
+		ns := s.labels["namespace"]
+		if ns == "" || len(s.points) == 0 {
+			continue
+		}
+		history := NamespaceCostSeries{Namespace: ns, Points: make([]CostPoint, 0, len(s.points))}
+		for _, p := range s.points {
+			if math.IsNaN(p.value) {
+				continue
+			}
+			history.Points = append(history.Points, CostPoint{Timestamp: p.timestamp, Cost: p.value})
+		}
+		out = append(out, history)
+	}
+	if len(out) == 0 {
+		return nil, ErrNoData
+	}
+	return out, nil
+}
@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+// ZoneFlow is the traffic between two pods (or a pod and a service) whose availability zones
+// are both known, summed in both directions over a window.
+type ZoneFlow struct {
+	SrcNamespace string
+	SrcPod       string
+	SrcAZ        string
+	DstNamespace string
+	DstPod       string
+	DstAZ        string
+	DstServices  string
+	Bytes        float64
+}
+
+var zoneFlowLabels = []string{
+	"src_namespace",
+	"src_pod",
+	"src_availability_zone",
+	"dst_namespace",
+	"dst_pod",
+	"dst_availability_zone",
+	"dst_services",
+}
+
+// ZoneFlows returns every zone-attributed connection flow in the window, same-AZ included, so
+// callers can compare cross-AZ traffic with the total. Namespaces limits flows to those with
+// a source or destination in the list.
+func (c *Client) ZoneFlows(ctx context.Context, opts store.NetworkTopologyOptions) ([]ZoneFlow, error) {
+	clusterID := opts.ClusterID
+	if clusterID == "" {
+		clusterID = c.resolveClusterID(ctx)
+	}
+	if clusterID == "" {
+		return nil, ErrNoData
+	}
+	ctx = WithClusterID(ctx, clusterID)
+
+	if opts.End.IsZero() {
+		opts.End = time.Now().UTC()
+	}
+	if opts.Start.IsZero() || !opts.Start.Before(opts.End) {
+		opts.Start = opts.End.Add(-c.lookback)
+	}
+	window := formatDuration(opts.End.Sub(opts.Start))
+	labels := map[string]string{"cluster_id": clusterID}
+	queryNamespace := ""
+	if len(opts.Namespaces) == 1 {
+		queryNamespace = opts.Namespaces[0]
+	}
+	namespaceSet := make(map[string]bool, len(opts.Namespaces))
+	for _, namespace := range opts.Namespaces {
+		if namespace != "" {
+			namespaceSet[namespace] = true
+		}
+	}
+	groupBy := strings.Join(zoneFlowLabels, ",")
+
+	flows := make(map[string]*ZoneFlow)
+	for _, metric := range []string{"clustercost_connection_bytes_sent_total", "clustercost_connection_bytes_received_total"} {
+		expr := connectionMetricExpr(metric, labels, queryNamespace, window, opts.End.UTC().Unix(), groupBy, "increase")
+		samples, err := c.query(ctx, expr)
+		if err != nil {
+			return nil, fmt.Errorf("query zone flows: %w", err)
+		}
+		for _, s := range samples {
+			l := s.labels
+			if l["src_availability_zone"] == "" || l["dst_availability_zone"] == "" || math.IsNaN(s.value) || s.value <= 0 {
+				continue
+			}
+			if len(namespaceSet) > 0 && !namespaceSet[l["src_namespace"]] && !namespaceSet[l["dst_namespace"]] {
+				continue
+			}
+			key := edgeKey(l, zoneFlowLabels)
+			flow := flows[key]
+			if flow == nil {
+				flow = &ZoneFlow{
+					SrcNamespace: l["src_namespace"],
+					SrcPod:       l["src_pod"],
+					SrcAZ:        l["src_availability_zone"],
+					DstNamespace: l["dst_namespace"],
+					DstPod:       l["dst_pod"],
+					DstAZ:        l["dst_availability_zone"],
+					DstServices:  l["dst_services"],
+				}
+				flows[key] = flow
+			}
+			flow.Bytes += s.value
+		}
+	}
+	if len(flows) == 0 {
+		return nil, ErrNoData
+	}
+
+	out := make([]ZoneFlow, 0, len(flows))
+	for _, flow := range flows {
+		out = append(out, *flow)
+	}
+	return out, nil
+}
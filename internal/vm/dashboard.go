@@ -3,6 +3,7 @@ package vm
 import (
 	"context"
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strconv"
@@ -14,8 +15,9 @@ import (
 
 const hoursPerMonth = 24 * 30
 
-func (c *Client) Overview(ctx context.Context, limit int) (store.OverviewPayload, error) {
-	namespaces, ts, err := c.namespaceMetrics(ctx, "", "")
+func (c *Client) Overview(ctx context.Context, limit int, mode store.AllocationMode) (store.OverviewPayload, error) {
+	mode = mode.OrDefault()
+	namespaces, ts, err := c.namespaceMetrics(ctx, "", "", mode)
 	if err != nil {
 		return store.OverviewPayload{}, err
 	}
@@ -76,11 +78,14 @@ func (c *Client) Overview(ctx context.Context, limit int) (store.OverviewPayload
 		EnvCostHourly:       envCost,
 		TopNamespacesByCost: topNamespaces,
 		SavingsCandidates:   findSavingsCandidates(list),
+		Allocation:          mode,
+		Anomalies:           []store.Anomaly{},
 	}, nil
 }
 
 func (c *Client) NamespaceList(ctx context.Context, filter store.NamespaceFilter) (store.NamespaceListResponse, error) {
-	namespaces, ts, err := c.namespaceMetrics(ctx, filter.Environment, "")
+	mode := filter.Allocation.OrDefault()
+	namespaces, ts, err := c.namespaceMetrics(ctx, filter.Environment, "", mode)
 	if err != nil {
 		return store.NamespaceListResponse{}, err
 	}
@@ -97,10 +102,7 @@ func (c *Client) NamespaceList(ctx context.Context, filter store.NamespaceFilter
 		}
 		out = append(out, *ns)
 	}
-
-	sort.Slice(out, func(i, j int) bool {
-		return out[i].HourlyCost > out[j].HourlyCost
-	})
+	out = store.FilterNamespaces(out, filter.ListQuery)
 
 	total := len(out)
 	start := clampIndex(filter.Offset, total)
@@ -114,11 +116,12 @@ func (c *Client) NamespaceList(ctx context.Context, filter store.NamespaceFilter
 		Items:      out[start:end],
 		TotalCount: total,
 		Timestamp:  ts,
+		Allocation: mode,
 	}, nil
 }
 
 func (c *Client) NamespaceDetail(ctx context.Context, name string) (store.NamespaceSummary, error) {
-	namespaces, _, err := c.namespaceMetrics(ctx, "", name)
+	namespaces, _, err := c.namespaceMetrics(ctx, "", name, store.AllocationUsage)
 	if err != nil {
 		return store.NamespaceSummary{}, err
 	}
@@ -141,17 +144,19 @@ func (c *Client) NodeList(ctx context.Context, filter store.NodeFilter) (store.N
 		searchLower = strings.ToLower(filter.Search)
 	}
 
+	mode := filter.Allocation.OrDefault()
 	out := make([]store.NodeSummary, 0, len(nodes))
 	for _, node := range nodes {
 		if searchLower != "" && !strings.Contains(strings.ToLower(node.NodeName), searchLower) {
 			continue
 		}
+		if filter.Status != "" && node.Status != filter.Status {
+			continue
+		}
+		store.ApplyNodeAllocation(node, mode)
 		out = append(out, *node)
 	}
-
-	sort.Slice(out, func(i, j int) bool {
-		return out[i].HourlyCost > out[j].HourlyCost
-	})
+	out = store.FilterNodes(out, filter.ListQuery)
 
 	total := len(out)
 	start := clampIndex(filter.Offset, total)
@@ -165,6 +170,7 @@ func (c *Client) NodeList(ctx context.Context, filter store.NodeFilter) (store.N
 		Items:      out[start:end],
 		TotalCount: total,
 		Timestamp:  ts,
+		Allocation: mode,
 	}, nil
 }
 
@@ -175,6 +181,7 @@ func (c *Client) NodeDetail(ctx context.Context, name string) (store.NodeSummary
 	}
 	for _, node := range nodes {
 		if node.NodeName == name {
+			store.ApplyNodeAllocation(node, store.AllocationUsage)
 			return *node, nil
 		}
 	}
@@ -208,7 +215,11 @@ func (c *Client) Resources(ctx context.Context) (store.ResourcesPayload, error)
 	netRx, _, _ := c.scalarMetric(ctx, "clustercost_cluster_network_rx_bytes_total")
 	netEgress, _, _ := c.scalarMetric(ctx, "clustercost_cluster_network_egress_cost_total")
 
-	namespaces, _, nsErr := c.namespaceMetrics(ctx, "", "")
+	// Fetch Storage I/O
+	storageRead, _, _ := c.scalarMetric(ctx, "clustercost_namespace_storage_read_bytes_total")
+	storageWrite, _, _ := c.scalarMetric(ctx, "clustercost_namespace_storage_write_bytes_total")
+
+	namespaces, _, nsErr := c.namespaceMetrics(ctx, "", "", store.AllocationUsage)
 	if nsErr != nil && nsErr != ErrNoData {
 		return store.ResourcesPayload{}, nsErr
 	}
@@ -238,6 +249,18 @@ func (c *Client) Resources(ctx context.Context) (store.ResourcesPayload, error)
 	cpuWasteCost := wasteCost(nodeHourlyCost, cpuUsage, cpuRequest)
 	memWasteCost := wasteCost(nodeHourlyCost, memUsage, memRequest)
 
+	storage := store.StorageResource{
+		ReadBytesTotal:  int64(storageRead),
+		WriteBytesTotal: int64(storageWrite),
+	}
+	for _, ns := range namespaces {
+		storage.CapacityBytes += ns.StorageCapacityBytes
+		storage.UsedBytes += ns.StorageUsedBytes
+		storage.HourlyCost += ns.StorageHourlyCost
+	}
+	storage.UtilizationPercent = percent(float64(storage.UsedBytes), float64(storage.CapacityBytes))
+	storage.EstimatedHourlyWasteCost = wasteCost(storage.HourlyCost, float64(storage.UsedBytes), float64(storage.CapacityBytes))
+
 	ts := cpuUsageTS
 	if ts.IsZero() {
 		ts = time.Now().UTC()
@@ -262,17 +285,48 @@ func (c *Client) Resources(ctx context.Context) (store.ResourcesPayload, error)
 			RxBytesTotal:     int64(netRx),
 			EgressCostHourly: netEgress,
 		},
+		Storage:        storage,
 		NamespaceWaste: buildNamespaceWaste(namespaces),
 	}, nil
 }
 
-func (c *Client) NetworkTopology(ctx context.Context, opts store.NetworkTopologyOptions) ([]store.NetworkEdge, error) {
+// topologyGroupLabels identify a pod-level edge in the connection series.
+var topologyGroupLabels = []string{
+	"src_namespace",
+	"src_pod",
+	"src_node",
+	"src_ip",
+	"src_dns_name",
+	"src_availability_zone",
+	"dst_namespace",
+	"dst_pod",
+	"dst_node",
+	"dst_ip",
+	"dst_dns_name",
+	"dst_availability_zone",
+	"dst_kind",
+	"service_match",
+	"dst_services",
+	"protocol",
+}
+
+// topologyQuery is the scope of a topology request resolved to query parameters.
+type topologyQuery struct {
+	opts       store.NetworkTopologyOptions
+	labels     map[string]string
+	namespace  string
+	namespaces map[string]struct{}
+	window     string
+	start, end int64
+}
+
+func (c *Client) newTopologyQuery(ctx context.Context, opts store.NetworkTopologyOptions) (context.Context, topologyQuery, error) {
 	clusterID := opts.ClusterID
 	if clusterID == "" {
 		clusterID = c.resolveClusterID(ctx)
 	}
 	if clusterID == "" {
-		return nil, ErrNoData
+		return ctx, topologyQuery{}, ErrNoData
 	}
 	ctx = WithClusterID(ctx, clusterID)
 
@@ -287,78 +341,88 @@ func (c *Client) NetworkTopology(ctx context.Context, opts store.NetworkTopology
 	if window <= 0 {
 		window = c.lookback
 	}
-	windowStr := formatDuration(window)
 
-	labels := map[string]string{"cluster_id": clusterID}
-	queryNamespace := ""
-	if len(opts.Namespaces) == 1 {
-		queryNamespace = opts.Namespaces[0]
-	}
-	groupLabels := []string{
-		"src_namespace",
-		"src_pod",
-		"src_node",
-		"src_ip",
-		"src_dns_name",
-		"src_availability_zone",
-		"dst_namespace",
-		"dst_pod",
-		"dst_node",
-		"dst_ip",
-		"dst_dns_name",
-		"dst_availability_zone",
-		"dst_kind",
-		"service_match",
-		"dst_services",
-		"protocol",
-	}
-	groupBy := strings.Join(groupLabels, ",")
-
-	endSeconds := opts.End.UTC().Unix()
-
-	bytesSentExpr := connectionMetricExpr("clustercost_connection_bytes_sent_total", labels, queryNamespace, windowStr, endSeconds, groupBy, "increase")
-	bytesRecvExpr := connectionMetricExpr("clustercost_connection_bytes_received_total", labels, queryNamespace, windowStr, endSeconds, groupBy, "increase")
-	countExpr := connectionMetricExpr("clustercost_connection_bytes_sent_total", labels, queryNamespace, windowStr, endSeconds, groupBy, "count")
-
-	sentSamples, err := c.query(ctx, bytesSentExpr)
-	if err != nil {
-		return nil, err
+	q := topologyQuery{
+		opts:       opts,
+		labels:     map[string]string{"cluster_id": clusterID},
+		namespaces: make(map[string]struct{}, len(opts.Namespaces)),
+		window:     formatDuration(window),
+		start:      opts.Start.UTC().Unix(),
+		end:        opts.End.UTC().Unix(),
 	}
-	recvSamples, err := c.query(ctx, bytesRecvExpr)
-	if err != nil {
-		return nil, err
+	if len(opts.Namespaces) == 1 {
+		q.namespace = opts.Namespaces[0]
 	}
-
-	startSeconds := opts.Start.UTC().Unix()
-	edges := make(map[string]*store.NetworkEdge)
-
-	namespaceSet := make(map[string]struct{}, len(opts.Namespaces))
 	for _, namespace := range opts.Namespaces {
 		if namespace == "" {
 			continue
 		}
-		namespaceSet[namespace] = struct{}{}
+		q.namespaces[namespace] = struct{}{}
 	}
+	return ctx, q, nil
+}
 
-	applySample := func(sample sample, assign func(*store.NetworkEdge, float64)) {
-		edge := edgeFromLabels(sample.labels, groupLabels)
-		if edge == nil {
-			return
-		}
-		if len(namespaceSet) > 0 {
-			if _, ok := namespaceSet[edge.SrcNamespace]; !ok {
-				if _, ok := namespaceSet[edge.DstNamespace]; !ok {
-					return
-				}
+// expr sums metric per edge over the window; op is "increase" or "count".
+func (q topologyQuery) expr(metric, op string) string {
+	return connectionMetricExpr(metric, q.labels, q.namespace, q.window, q.end, strings.Join(topologyGroupLabels, ","), op)
+}
+
+// newEdge returns the edge of a series, or nil when neither end is in the requested namespaces.
+func (q topologyQuery) newEdge(labels map[string]string) *store.NetworkEdge {
+	edge := edgeFromLabels(labels, topologyGroupLabels)
+	if edge == nil {
+		return nil
+	}
+	if len(q.namespaces) > 0 {
+		if _, ok := q.namespaces[edge.SrcNamespace]; !ok {
+			if _, ok := q.namespaces[edge.DstNamespace]; !ok {
+				return nil
 			}
 		}
-		key := edgeKey(sample.labels, groupLabels)
+	}
+	edge.FirstSeen = q.start
+	edge.LastSeen = q.end
+	return edge
+}
+
+// keep applies the min* filters of the request.
+func (q topologyQuery) keep(edge *store.NetworkEdge) bool {
+	if q.opts.MinCostUSD > 0 && edge.EgressCostUSD <= q.opts.MinCostUSD {
+		return false
+	}
+	if q.opts.MinBytes > 0 && (edge.BytesSent+edge.BytesReceived) < q.opts.MinBytes {
+		return false
+	}
+	if q.opts.MinConnections > 0 && edge.ConnectionCount < q.opts.MinConnections {
+		return false
+	}
+	return true
+}
+
+func (c *Client) NetworkTopology(ctx context.Context, opts store.NetworkTopologyOptions) ([]store.NetworkEdge, error) {
+	ctx, q, err := c.newTopologyQuery(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sentSamples, err := c.query(ctx, q.expr("clustercost_connection_bytes_sent_total", "increase"))
+	if err != nil {
+		return nil, err
+	}
+	recvSamples, err := c.query(ctx, q.expr("clustercost_connection_bytes_received_total", "increase"))
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make(map[string]*store.NetworkEdge)
+	applySample := func(sample sample, assign func(*store.NetworkEdge, float64)) {
+		key := edgeKey(sample.labels, topologyGroupLabels)
 		current := edges[key]
 		if current == nil {
-			edge.FirstSeen = startSeconds
-			edge.LastSeen = endSeconds
-			edges[key] = edge
-			current = edge
+			if current = q.newEdge(sample.labels); current == nil {
+				return
+			}
+			edges[key] = current
 		}
 		assign(current, sample.value)
 	}
@@ -373,7 +437,7 @@ func (c *Client) NetworkTopology(ctx context.Context, opts store.NetworkTopology
 			edge.BytesReceived = int64(value)
 		})
 	}
-	countSamples, err := c.query(ctx, countExpr)
+	countSamples, err := c.query(ctx, q.expr("clustercost_connection_bytes_sent_total", "count"))
 	if err != nil {
 		return nil, err
 	}
@@ -389,16 +453,9 @@ func (c *Client) NetworkTopology(ctx context.Context, opts store.NetworkTopology
 
 	list := make([]store.NetworkEdge, 0, len(edges))
 	for _, edge := range edges {
-		if opts.MinCostUSD > 0 && edge.EgressCostUSD <= opts.MinCostUSD {
-			continue
+		if q.keep(edge) {
+			list = append(list, *edge)
 		}
-		if opts.MinBytes > 0 && (edge.BytesSent+edge.BytesReceived) < opts.MinBytes {
-			continue
-		}
-		if opts.MinConnections > 0 && edge.ConnectionCount < opts.MinConnections {
-			continue
-		}
-		list = append(list, *edge)
 	}
 	sort.Slice(list, func(i, j int) bool {
 		if list[i].EgressCostUSD != list[j].EgressCostUSD {
@@ -415,6 +472,70 @@ func (c *Client) NetworkTopology(ctx context.Context, opts store.NetworkTopology
 	return list, nil
 }
 
+// StreamNetworkEdges calls fn with every pod-level edge of the topology as it is decoded from
+// the VictoriaMetrics response, without holding the result: one query returns the sent,
+// received and connection series sorted by edge, so an edge is complete when the next one
+// starts. Edges come in label order rather than by cost and opts.Limit is ignored. An error
+// from fn stops the stream and is returned.
+func (c *Client) StreamNetworkEdges(ctx context.Context, opts store.NetworkTopologyOptions, fn func(store.NetworkEdge) error) error {
+	ctx, q, err := c.newTopologyQuery(ctx, opts)
+	if err != nil {
+		return err
+	}
+	sortLabels := make([]string, len(topologyGroupLabels))
+	for i, label := range topologyGroupLabels {
+		sortLabels[i] = strconv.Quote(label)
+	}
+	expr := fmt.Sprintf(`sort_by_label(label_set(%s, "series", "sent") or label_set(%s, "series", "received") or label_set(%s, "series", "connections"), %s)`,
+		q.expr("clustercost_connection_bytes_sent_total", "increase"),
+		q.expr("clustercost_connection_bytes_received_total", "increase"),
+		q.expr("clustercost_connection_bytes_sent_total", "count"),
+		strings.Join(sortLabels, ", "))
+
+	var (
+		current    *store.NetworkEdge
+		currentKey string
+		seen       bool
+	)
+	emit := func() error {
+		if current == nil || !q.keep(current) {
+			return nil
+		}
+		return fn(*current)
+	}
+	err = c.streamQuery(ctx, expr, func(sample sample) error {
+		if key := edgeKey(sample.labels, topologyGroupLabels); current == nil || key != currentKey {
+			if err := emit(); err != nil {
+				return err
+			}
+			current, currentKey = q.newEdge(sample.labels), key
+			if current == nil {
+				return nil
+			}
+			seen = true
+		}
+		switch sample.labels["series"] {
+		case "sent":
+			current.BytesSent = int64(sample.value)
+		case "received":
+			current.BytesReceived = int64(sample.value)
+		case "connections":
+			current.ConnectionCount = int64(sample.value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := emit(); err != nil {
+		return err
+	}
+	if !seen {
+		return ErrNoData
+	}
+	return nil
+}
+
 func (c *Client) AgentStatus(ctx context.Context) (store.AgentStatusPayload, error) {
 	clusterID := c.resolveClusterID(ctx)
 	ctx = WithClusterID(ctx, clusterID)
@@ -578,7 +699,7 @@ func (c *Client) ClusterMetadata(ctx context.Context) (store.ClusterMetadata, er
 	}, nil
 }
 
-func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace string) (map[string]*store.NamespaceSummary, time.Time, error) {
+func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace string, mode store.AllocationMode) (map[string]*store.NamespaceSummary, time.Time, error) {
 	clusterID := c.resolveClusterID(ctx)
 	ctx = WithClusterID(ctx, clusterID)
 	labels := map[string]string{}
@@ -589,10 +710,18 @@ func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace st
 		labels["namespace"] = namespace
 	}
 
+	// Traffic counters are reported as their increase over networkWindow
+	increase := func(metric string) func(clusterID string, labels map[string]string) string {
+		return func(clusterID string, labels map[string]string) string {
+			selector := metricSelector(metric, c.scopedLabels(labels, clusterID))
+			return fmt.Sprintf("sum by (namespace, environment) (increase(%s[%s]))", selector, formatDuration(networkWindow))
+		}
+	}
+
 	// We use pod metrics and aggregate them on the fly
 	metrics := []struct {
 		name     string
-		agg      string // "sum" or "count"
+		agg      string // "sum", "avg" or "count"
 		assign   func(entry *store.NamespaceSummary, value float64)
 		expr     func(clusterID string, labels map[string]string) string
 		fallback string
@@ -622,6 +751,15 @@ func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace st
 			"clustercost_pod_memory_request_bytes",
 		},
 		{"clustercost_namespace_memory_rss_bytes_total", "sum", func(e *store.NamespaceSummary, v float64) { e.MemoryUsageBytes = int64(v) }, nil, ""},
+		{"clustercost_namespace_gpu_request_count", "sum", func(e *store.NamespaceSummary, v float64) { e.GPURequestCount = int64(v) }, nil, "clustercost_pod_gpu_request_count"},
+		{"clustercost_namespace_gpu_allocated_count", "sum", func(e *store.NamespaceSummary, v float64) { e.GPUAllocatedCount = int64(v) }, nil, "clustercost_pod_gpu_allocated_count"},
+		{"clustercost_namespace_gpu_utilization_percent", "avg", func(e *store.NamespaceSummary, v float64) { e.GPUUtilizationPercent = v }, nil, ""},
+		{"clustercost_namespace_gpu_hourly_cost", "sum", func(e *store.NamespaceSummary, v float64) { e.GPUHourlyCost = v }, nil, "clustercost_pod_gpu_hourly_cost"},
+		{"clustercost_namespace_storage_capacity_bytes", "sum", func(e *store.NamespaceSummary, v float64) { e.StorageCapacityBytes = int64(v) }, nil, "clustercost_pod_storage_capacity_bytes"},
+		{"clustercost_namespace_storage_used_bytes", "sum", func(e *store.NamespaceSummary, v float64) { e.StorageUsedBytes = int64(v) }, nil, "clustercost_pod_storage_used_bytes"},
+		{"clustercost_namespace_storage_hourly_cost", "sum", func(e *store.NamespaceSummary, v float64) { e.StorageHourlyCost = v }, nil, "clustercost_pod_storage_hourly_cost"},
+		{"clustercost_namespace_network_egress_public_bytes_total", "sum", func(e *store.NamespaceSummary, v float64) { e.EgressBytes += int64(v) }, increase("clustercost_namespace_network_egress_public_bytes_total"), ""},
+		{"clustercost_namespace_network_egress_cross_az_bytes_total", "sum", func(e *store.NamespaceSummary, v float64) { e.EgressBytes += int64(v) }, increase("clustercost_namespace_network_egress_cross_az_bytes_total"), ""},
 	}
 
 	out := make(map[string]*store.NamespaceSummary)
@@ -689,21 +827,36 @@ func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace st
 		return samples[0].value, nil
 	}
 
-	nodeCostExpr := fmt.Sprintf("sum(max by (node) (%s))", c.lookbackExpr("clustercost_node_hourly_cost", nil, clusterID))
+	queryByNode := func(metric string) (map[string]float64, error) {
+		samples, err := c.query(ctx, fmt.Sprintf("max by (node) (%s)", c.lookbackExpr(metric, nil, clusterID)))
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[string]float64, len(samples))
+		for _, sample := range samples {
+			out[sample.labels["node"]] = sample.value
+		}
+		return out, nil
+	}
+
 	cpuAllocExpr := fmt.Sprintf("sum(max by (node) (%s))", c.lookbackExpr("clustercost_node_cpu_allocatable_milli", nil, clusterID))
 	memAllocExpr := fmt.Sprintf("sum(max by (node) (%s))", c.lookbackExpr("clustercost_node_memory_allocatable_bytes", nil, clusterID))
 
-	nodeCost, err := queryScalar(nodeCostExpr)
-	if err == nil && nodeCost > 0 {
+	// Node prices include their GPUs, which namespaces already pay for through GPUHourlyCost
+	var nodeCost float64
+	nodeCosts, err := queryByNode("clustercost_node_hourly_cost")
+	if err == nil {
+		nodeGPUs, _ := queryByNode("clustercost_node_gpu_capacity")
+		nodeCost = nodeComputeCost(nodeCosts, nodeGPUs, store.NewPricingCatalog(nil).GPUCostShare)
+	}
+	if nodeCost > 0 {
 		cpuAllocMilli, errCPU := queryScalar(cpuAllocExpr)
 		memAllocBytes, errMem := queryScalar(memAllocExpr)
 		if errCPU == nil && errMem == nil && cpuAllocMilli > 0 && memAllocBytes > 0 {
 			cpuPrice := (nodeCost * 0.5) / (cpuAllocMilli / 1000.0)
 			memPrice := (nodeCost * 0.5) / (memAllocBytes / (1024.0 * 1024.0 * 1024.0))
 			for _, entry := range out {
-				cpuUsageCores := float64(entry.CPUUsageMilli) / 1000.0
-				memUsageGB := float64(entry.MemoryUsageBytes) / (1024.0 * 1024.0 * 1024.0)
-				entry.HourlyCost = (cpuUsageCores * cpuPrice) + (memUsageGB * memPrice)
+				entry.HourlyCost = allocatedHourlyCost(entry, mode, cpuPrice, memPrice) + entry.GPUHourlyCost + entry.StorageHourlyCost
 			}
 		}
 	}
@@ -715,6 +868,7 @@ func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace st
 		region       string
 		cpuMilli     float64
 		memBytes     float64
+		gpus         float64
 	}
 	nodes := make(map[string]*nodeAlloc)
 	loadNodeAlloc := func(metric string, assign func(entry *nodeAlloc, value float64)) error {
@@ -753,6 +907,9 @@ func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace st
 		_ = loadNodeAlloc("clustercost_node_memory_allocatable_bytes", func(entry *nodeAlloc, value float64) {
 			entry.memBytes = value
 		})
+		_ = loadNodeAlloc("clustercost_node_gpu_capacity", func(entry *nodeAlloc, value float64) {
+			entry.gpus = value
+		})
 	}
 
 	pricing := store.NewPricingCatalog(nil)
@@ -770,21 +927,42 @@ func (c *Client) namespaceMetrics(ctx context.Context, environment, namespace st
 		if instanceType == "" {
 			instanceType = "default"
 		}
-		totalNodeCost += pricing.GetTotalNodePrice(context.Background(), entry.region, instanceType)
+		// The GPU share is billed per device, so only the compute pool is spread over CPU and memory.
+		computeCost, _ := pricing.SplitNodePrice(context.Background(), entry.region, instanceType, int64(entry.gpus))
+		totalNodeCost += computeCost
 	}
 
 	if totalNodeCost > 0 && totalCpuCores > 0 && totalMemGB > 0 {
 		cpuPrice := (totalNodeCost * 0.5) / totalCpuCores
 		memPrice := (totalNodeCost * 0.5) / totalMemGB
 		for _, entry := range out {
-			cpuUsageCores := float64(entry.CPUUsageMilli) / 1000.0
-			memUsageGB := float64(entry.MemoryUsageBytes) / (1024.0 * 1024.0 * 1024.0)
-			entry.HourlyCost = (cpuUsageCores * cpuPrice) + (memUsageGB * memPrice)
+			entry.HourlyCost = allocatedHourlyCost(entry, mode, cpuPrice, memPrice) + entry.GPUHourlyCost + entry.StorageHourlyCost
 		}
 	}
 	return out, latest, nil
 }
 
+// nodeComputeCost sums the node hourly prices less the GPU share of nodes that have GPUs, the
+// same split SplitNodePrice applies, leaving the pool that CPU and memory are priced from.
+func nodeComputeCost(nodeCosts, nodeGPUs map[string]float64, gpuShare float64) float64 {
+	gpuShare = math.Min(math.Max(gpuShare, 0), 1)
+	total := 0.0
+	for node, cost := range nodeCosts {
+		if nodeGPUs[node] > 0 {
+			cost -= cost * gpuShare
+		}
+		total += cost
+	}
+	return total
+}
+
+// allocatedHourlyCost prices a namespace by the CPU and memory attributed to it under the allocation mode.
+func allocatedHourlyCost(ns *store.NamespaceSummary, mode store.AllocationMode, cpuPrice, memPrice float64) float64 {
+	cpuCores := float64(store.AllocatedQuantity(mode, ns.CPUUsageMilli, ns.CPURequestMilli)) / 1000.0
+	memGB := float64(store.AllocatedQuantity(mode, ns.MemoryUsageBytes, ns.MemoryRequestBytes)) / (1024.0 * 1024.0 * 1024.0)
+	return (cpuCores * cpuPrice) + (memGB * memPrice)
+}
+
 func (c *Client) nodeMetrics(ctx context.Context, nodeName string) (map[string]*store.NodeSummary, time.Time, error) {
 	clusterID := c.resolveClusterID(ctx)
 	ctx = WithClusterID(ctx, clusterID)
@@ -807,7 +985,13 @@ func (c *Client) nodeMetrics(ctx context.Context, nodeName string) (map[string]*
 		{"clustercost_node_memory_usage_percent", func(e *store.NodeSummary, v float64, _ map[string]string) { e.MemoryUsagePercent = v }},
 		{"clustercost_node_cpu_allocatable_milli", func(e *store.NodeSummary, v float64, _ map[string]string) { e.CPUAllocatableMilli = int64(v) }},
 		{"clustercost_node_memory_allocatable_bytes", func(e *store.NodeSummary, v float64, _ map[string]string) { e.MemoryAllocatableBytes = int64(v) }},
+		{"clustercost_node_cpu_requested_milli", func(e *store.NodeSummary, v float64, _ map[string]string) { e.CPURequestMilli = int64(v) }},
+		{"clustercost_node_memory_requested_bytes", func(e *store.NodeSummary, v float64, _ map[string]string) { e.MemoryRequestBytes = int64(v) }},
 		{"clustercost_node_pod_count", func(e *store.NodeSummary, v float64, _ map[string]string) { e.PodCount = int(v) }},
+		{"clustercost_node_gpu_capacity", func(e *store.NodeSummary, v float64, _ map[string]string) { e.GPUCapacity = int64(v) }},
+		{"clustercost_node_gpu_allocatable", func(e *store.NodeSummary, v float64, _ map[string]string) { e.GPUAllocatable = int64(v) }},
+		{"clustercost_node_gpu_requested", func(e *store.NodeSummary, v float64, _ map[string]string) { e.GPURequested = int64(v) }},
+		{"clustercost_node_gpu_utilization_percent", func(e *store.NodeSummary, v float64, _ map[string]string) { e.GPUUtilizationPercent = v }},
 		{"clustercost_node_under_pressure", func(e *store.NodeSummary, v float64, _ map[string]string) { e.IsUnderPressure = v > 0.5 }},
 	}
 
@@ -830,9 +1014,11 @@ func (c *Client) nodeMetrics(ctx context.Context, nodeName string) (map[string]*
 			entry := out[node]
 			if entry == nil {
 				entry = &store.NodeSummary{
-					NodeName: node,
-					Labels:   map[string]string{},
-					Taints:   []string{},
+					NodeName:   node,
+					Status:     store.NodeStatusUnknown,
+					Labels:     map[string]string{},
+					Taints:     []string{},
+					Conditions: []store.NodeCondition{},
 				}
 				out[node] = entry
 			}
@@ -840,6 +1026,30 @@ func (c *Client) nodeMetrics(ctx context.Context, nodeName string) (map[string]*
 		}
 	}
 
+	// Traffic is the increase in bytes over networkWindow, the window the store node list uses
+	traffic := []struct {
+		name   string
+		assign func(entry *store.NodeSummary, value float64)
+	}{
+		{"clustercost_node_network_tx_bytes_total", func(e *store.NodeSummary, v float64) { e.NetTxBytes = int64(v) }},
+		{"clustercost_node_network_rx_bytes_total", func(e *store.NodeSummary, v float64) { e.NetRxBytes = int64(v) }},
+		{"clustercost_node_network_egress_public_bytes_total", func(e *store.NodeSummary, v float64) { e.EgressPublicBytes = int64(v) }},
+		{"clustercost_node_network_egress_cross_az_bytes_total", func(e *store.NodeSummary, v float64) { e.EgressCrossAZBytes = int64(v) }},
+		{"clustercost_node_network_egress_internal_bytes_total", func(e *store.NodeSummary, v float64) { e.EgressInternalBytes = int64(v) }},
+	}
+	for _, metric := range traffic {
+		selector := metricSelector(metric.name, c.scopedLabels(labels, clusterID))
+		samples, err := c.query(ctx, fmt.Sprintf("sum by (node) (increase(%s[%s]))", selector, formatDuration(networkWindow)))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		for _, sample := range samples {
+			if entry := out[sample.labels["node"]]; entry != nil {
+				metric.assign(entry, sample.value)
+			}
+		}
+	}
+
 	statusSamples, err := c.seriesTimestamp(ctx, "clustercost_node_status", labels)
 	if err != nil && err != ErrNoData {
 		return nil, time.Time{}, err
@@ -851,6 +1061,39 @@ func (c *Client) nodeMetrics(ctx context.Context, nodeName string) (map[string]*
 		}
 	}
 
+	// Info series of the latest report only, so removed labels and cleared taints drop out
+	info := []struct {
+		name   string
+		assign func(entry *store.NodeSummary, labels map[string]string)
+	}{
+		{"clustercost_node_condition", func(e *store.NodeSummary, l map[string]string) {
+			e.Conditions = append(e.Conditions, store.NodeCondition{Type: l["condition"], Status: l["status"], Reason: l["reason"]})
+		}},
+		{"clustercost_node_label", func(e *store.NodeSummary, l map[string]string) { e.Labels[l["key"]] = l["value"] }},
+		{"clustercost_node_taint", func(e *store.NodeSummary, l map[string]string) {
+			e.Taints = append(e.Taints, store.FormatTaint(l["key"], l["value"], l["effect"]))
+		}},
+	}
+	for _, metric := range info {
+		samples, err := c.seriesTimestamp(ctx, metric.name, labels)
+		if err != nil && err != ErrNoData {
+			return nil, time.Time{}, err
+		}
+		for node, series := range latestNodeSeries(samples) {
+			entry := out[node]
+			if entry == nil {
+				continue
+			}
+			for _, s := range series {
+				metric.assign(entry, s.labels)
+			}
+		}
+	}
+	for _, entry := range out {
+		sort.Slice(entry.Conditions, func(i, j int) bool { return entry.Conditions[i].Type < entry.Conditions[j].Type })
+		sort.Strings(entry.Taints)
+	}
+
 	latest := c.seriesTimestampSafe(ctx, "clustercost_node_hourly_cost")
 	return out, latest, nil
 }
@@ -1024,6 +1267,25 @@ func pickLatestStatus(samples []sample) map[string]string {
 	return out
 }
 
+// latestNodeSeries groups samples by node, keeping only those written by the node's latest report.
+func latestNodeSeries(samples []sample) map[string][]sample {
+	latest := make(map[string]time.Time)
+	for _, s := range samples {
+		node := s.labels["node"]
+		if node != "" && s.timestamp.After(latest[node]) {
+			latest[node] = s.timestamp
+		}
+	}
+	out := make(map[string][]sample, len(latest))
+	for _, s := range samples {
+		node := s.labels["node"]
+		if node != "" && s.timestamp.Equal(latest[node]) {
+			out[node] = append(out[node], s)
+		}
+	}
+	return out
+}
+
 func (c *Client) nodeNames(ctx context.Context) []string {
 	clusterID := c.resolveClusterID(ctx)
 	expr := fmt.Sprintf("max by (node) (%s)", c.lookbackExpr("clustercost_node_hourly_cost", nil, clusterID))
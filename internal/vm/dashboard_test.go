@@ -0,0 +1,20 @@
+package vm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNodeComputeCostExcludesGPUShare(t *testing.T) {
+	costs := map[string]float64{"cpu-node": 0.2, "gpu-node": 3.0}
+	gpus := map[string]float64{"gpu-node": 1}
+
+	// The GPU node keeps 20% of its price for CPU and memory; its GPUs are charged separately
+	got := nodeComputeCost(costs, gpus, 0.8)
+	if want := 0.2 + 3.0*0.2; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("expected compute pool %.2f, got %.2f", want, got)
+	}
+	if got := nodeComputeCost(costs, nil, 0.8); math.Abs(got-3.2) > 1e-9 {
+		t.Fatalf("nodes without GPUs keep their full price, got %.2f", got)
+	}
+}
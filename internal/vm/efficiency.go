@@ -0,0 +1,98 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// coverageBucket is the resolution at which data coverage over the lookback is measured.
+const coverageBucket = 5 * time.Minute
+
+// PodUsageStats summarizes the CPU and memory usage of one pod over a lookback window.
+// Percentiles are summed across the pod's containers; variation is the highest
+// container coefficient of variation (stddev / mean).
+type PodUsageStats struct {
+	ClusterID       string
+	Namespace       string
+	Pod             string
+	CPUP95Cores     float64
+	MemoryP95Bytes  float64
+	CPUVariation    float64
+	MemoryVariation float64
+	Samples         float64
+	// Coverage is the fraction of the lookback (in 5m buckets) with at least one sample
+	Coverage float64
+}
+
+// PodUsageKey identifies a pod across clusters.
+type PodUsageKey struct {
+	ClusterID string
+	Namespace string
+	Pod       string
+}
+
+// PodUsageStats returns usage statistics for every pod of clusterID (all clusters when empty)
+// over lookback. Each statistic is a single query grouped by pod rather than one per pod.
+func (c *Client) PodUsageStats(ctx context.Context, clusterID string, lookback time.Duration) (map[PodUsageKey]PodUsageStats, error) {
+	if lookback <= 0 {
+		lookback = c.lookback
+	}
+	window := formatDuration(lookback)
+	cpu := metricSelector("clustercost_pod_cpu_usage_milli", c.scopedLabels(nil, clusterID))
+	mem := metricSelector("clustercost_pod_memory_rss_bytes", c.scopedLabels(nil, clusterID))
+	buckets := math.Max(1, math.Floor(lookback.Seconds()/coverageBucket.Seconds()))
+
+	stats := make(map[PodUsageKey]*PodUsageStats)
+	queries := []struct {
+		expr   string
+		assign func(s *PodUsageStats, v float64)
+	}{
+		{fmt.Sprintf("sum by (cluster_id, namespace, pod) (quantile_over_time(0.95, %s[%s])) / 1000", cpu, window), func(s *PodUsageStats, v float64) {
+			s.CPUP95Cores = v
+		}},
+		{fmt.Sprintf("sum by (cluster_id, namespace, pod) (quantile_over_time(0.95, %s[%s]))", mem, window), func(s *PodUsageStats, v float64) {
+			s.MemoryP95Bytes = v
+		}},
+		{fmt.Sprintf("max by (cluster_id, namespace, pod) (stddev_over_time(%[1]s[%[2]s]) / avg_over_time(%[1]s[%[2]s]))", cpu, window), func(s *PodUsageStats, v float64) {
+			s.CPUVariation = v
+		}},
+		{fmt.Sprintf("max by (cluster_id, namespace, pod) (stddev_over_time(%[1]s[%[2]s]) / avg_over_time(%[1]s[%[2]s]))", mem, window), func(s *PodUsageStats, v float64) {
+			s.MemoryVariation = v
+		}},
+		{fmt.Sprintf("max by (cluster_id, namespace, pod) (count_over_time(%s[%s]))", cpu, window), func(s *PodUsageStats, v float64) {
+			s.Samples = v
+		}},
+		{fmt.Sprintf("max by (cluster_id, namespace, pod) (count_over_time(max_over_time(%s[%s])[%s:%s]))", cpu, formatDuration(coverageBucket), window, formatDuration(coverageBucket)), func(s *PodUsageStats, v float64) {
+			s.Coverage = math.Min(1, v/buckets)
+		}},
+	}
+	for _, q := range queries {
+		samples, err := c.query(ctx, q.expr)
+		if err != nil {
+			return nil, fmt.Errorf("query pod usage: %w", err)
+		}
+		for _, sample := range samples {
+			if math.IsNaN(sample.value) || math.IsInf(sample.value, 0) {
+				continue
+			}
+			key := PodUsageKey{ClusterID: sample.labels["cluster_id"], Namespace: sample.labels["namespace"], Pod: sample.labels["pod"]}
+			s := stats[key]
+			if s == nil {
+				s = &PodUsageStats{ClusterID: key.ClusterID, Namespace: key.Namespace, Pod: key.Pod}
+				stats[key] = s
+			}
+			q.assign(s, math.Max(sample.value, 0))
+		}
+	}
+	if len(stats) == 0 {
+		return nil, ErrNoData
+	}
+
+	out := make(map[PodUsageKey]PodUsageStats, len(stats))
+	for key, s := range stats {
+		out[key] = *s
+	}
+	return out, nil
+}
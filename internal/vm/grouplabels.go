@@ -0,0 +1,63 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/clustercost/clustercost-dashboard/internal/config"
+)
+
+// reservedGroupLabels are written on every namespace series by the ingestor itself, so
+// configured namespace labels cannot reuse them (cluster_id would also override scoping).
+var reservedGroupLabels = map[string]bool{
+	"namespace":      true,
+	"environment":    true,
+	"cluster_id":     true,
+	"cluster_name":   true,
+	"cluster_type":   true,
+	"cluster_region": true,
+	"agent_id":       true,
+}
+
+// namespaceLabels are the configured labels (config.NamespaceLabels) the ingestor adds to
+// the series of each namespace, sorted by key.
+type namespaceLabels map[string][]label
+
+// newNamespaceLabels validates cfg.NamespaceLabels and returns them with the label keys
+// budgets and chargeback can group by: environment plus every configured key, sorted.
+func newNamespaceLabels(cfg config.Config) (namespaceLabels, []string, error) {
+	out := make(namespaceLabels, len(cfg.NamespaceLabels))
+	keys := map[string]bool{}
+	for ns, labels := range cfg.NamespaceLabels {
+		for key, value := range labels {
+			if !labelKeyPattern.MatchString(key) || reservedGroupLabels[key] {
+				return nil, nil, fmt.Errorf("namespaceLabels: %q on namespace %q is not a usable label name", key, ns)
+			}
+			out[ns] = append(out[ns], label{key, value})
+			keys[key] = true
+		}
+		sort.Slice(out[ns], func(i, j int) bool { return out[ns][i].key < out[ns][j].key })
+	}
+
+	groupKeys := make([]string, 0, len(keys)+1)
+	for key := range keys {
+		groupKeys = append(groupKeys, key)
+	}
+	sort.Strings(groupKeys)
+	return out, append([]string{"environment"}, groupKeys...), nil
+}
+
+// ValidateGroupLabel returns ErrInvalidLabelKey unless namespace series carry key: environment
+// or one of the configured namespace labels. Any other key would match no series.
+func (c *Client) ValidateGroupLabel(key string) error {
+	if !labelKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidLabelKey, key)
+	}
+	for _, known := range c.groupLabels {
+		if key == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q is not a label of namespace series (supported: %s)", ErrInvalidLabelKey, key, strings.Join(c.groupLabels, ", "))
+}
@@ -0,0 +1,41 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clustercost/clustercost-dashboard/internal/config"
+)
+
+func TestNamespaceLabelsConfigureGroupLabels(t *testing.T) {
+	cfg := config.Config{NamespaceLabels: map[string]map[string]string{
+		"payments": {"team": "core", "cost_center": "cc-1"},
+		"web":      {"team": "frontend"},
+	}}
+	labels, keys, err := newNamespaceLabels(cfg)
+	if err != nil {
+		t.Fatalf("newNamespaceLabels returned error: %v", err)
+	}
+	if got := labels["payments"]; len(got) != 2 || got[0] != (label{"cost_center", "cc-1"}) || got[1] != (label{"team", "core"}) {
+		t.Fatalf("unexpected payments labels %+v", got)
+	}
+
+	c := &Client{groupLabels: keys}
+	for _, key := range []string{"environment", "team", "cost_center"} {
+		if err := c.ValidateGroupLabel(key); err != nil {
+			t.Errorf("expected %s to be accepted: %v", key, err)
+		}
+	}
+	for _, key := range []string{"owner", "cluster_id", "bad-key"} {
+		if err := c.ValidateGroupLabel(key); !errors.Is(err, ErrInvalidLabelKey) {
+			t.Errorf("expected %s to be rejected, got %v", key, err)
+		}
+	}
+
+	for _, bad := range []string{"cluster_id", "bad-key"} {
+		cfg := config.Config{NamespaceLabels: map[string]map[string]string{"web": {bad: "x"}}}
+		if _, _, err := newNamespaceLabels(cfg); err == nil {
+			t.Errorf("expected %s to be rejected as a namespace label", bad)
+		}
+	}
+}
@@ -59,6 +59,12 @@ type Ingestor struct {
 	wg            sync.WaitGroup
 	logLevel      string
 	gzipPool      sync.Pool
+	// cardinality is nil unless connection series reduction is configured
+	cardinality      *cardinalityPolicy
+	cardinalityStats sync.Map // agent name -> *cardinalityStats
+	reducedSeries    sync.Map // agent name + node -> *reducedState
+	// nsLabels are the configured labels added to namespace series
+	nsLabels namespaceLabels
 }
 
 type reportEnvelope struct {
@@ -112,6 +118,15 @@ func NewIngestor(cfg config.Config, logger *log.Logger) (*Ingestor, error) {
 		}
 	}
 
+	cardinality, err := newCardinalityPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	nsLabels, _, err := newNamespaceLabels(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	transport := &http.Transport{
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 100,
@@ -131,6 +146,8 @@ func NewIngestor(cfg config.Config, logger *log.Logger) (*Ingestor, error) {
 		logger:        logger,
 		agentMeta:     buildAgentMeta(cfg),
 		logLevel:      cfg.LogLevel,
+		cardinality:   cardinality,
+		nsLabels:      nsLabels,
 		gzipPool: sync.Pool{
 			New: func() interface{} {
 				return gzip.NewWriter(io.Discard)
@@ -322,6 +339,16 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 		egressPublic   int64
 		egressCrossAZ  int64
 		egressInternal int64
+		gpuRequests    int64
+		gpuAllocated   int64
+		gpuUtilWeight  float64
+		gpuDevices     int64
+		gpuHourlyCost  float64
+		storageCap     int64
+		storageUsed    int64
+		storageCost    float64
+		storageRead    int64
+		storageWrite   int64
 	}
 	// map[namespace]*nsAgg
 	nsMap := make(map[string]*nsAgg)
@@ -339,6 +366,7 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 	}
 	vcpus := safeInt64(0)
 	ramBytes := safeInt64(0)
+	gpus := safeInt64(0)
 	if req.NodeName != "" {
 		for _, node := range req.Nodes {
 			if node == nil || node.NodeName != req.NodeName {
@@ -354,10 +382,11 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 			} else if node.AllocatableMemoryBytes > 0 {
 				ramBytes = safeInt64(node.AllocatableMemoryBytes)
 			}
+			gpus = int64(node.GpuCapacity)
 			break
 		}
 	}
-	cpuPrice, memPrice := pricing.GetNodeResourcePrices(context.Background(), region, instanceType, vcpus, ramBytes)
+	cpuPrice, memPrice, gpuPrice := pricing.GetNodeResourcePricesWithGPU(context.Background(), region, instanceType, vcpus, ramBytes, gpus)
 
 	for _, pod := range req.Pods {
 		if pod == nil {
@@ -398,10 +427,12 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 		memBytes := safeInt64(0)
 		memReq := safeInt64(0)
 		memLim := safeInt64(0)
+		oomKills := safeInt64(0)
 		if pod.Memory != nil {
 			memBytes = safeInt64(pod.Memory.RssBytes)
 			memReq = safeInt64(pod.Memory.RequestBytes)
 			memLim = safeInt64(pod.Memory.LimitBytes)
+			oomKills = safeInt64(pod.Memory.OomKillsTotal)
 		}
 
 		// Network
@@ -418,11 +449,45 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 			egressInternal = safeInt64(pod.Network.EgressInternalBytes)
 		}
 
+		// GPU
+		gpuReq := safeInt64(0)
+		gpuAlloc := safeInt64(0)
+		gpuUtil := 0.0
+		gpuMemUsed := safeInt64(0)
+		if pod.Gpu != nil {
+			gpuReq = int64(pod.Gpu.RequestCount)
+			gpuAlloc = int64(pod.Gpu.AllocatedCount)
+			gpuUtil = pod.Gpu.UtilizationPercent
+			gpuMemUsed = safeInt64(pod.Gpu.MemoryUsedBytes)
+		}
+
+		// Storage (I/O counters + provisioned volumes)
+		storageRead := safeInt64(0)
+		storageWrite := safeInt64(0)
+		storageCap := safeInt64(0)
+		storageUsed := safeInt64(0)
+		storageHourlyCost := 0.0
+		if pod.Storage != nil {
+			storageRead = safeInt64(pod.Storage.ReadBytes)
+			storageWrite = safeInt64(pod.Storage.WriteBytes)
+			for _, vol := range pod.Storage.Volumes {
+				if vol == nil {
+					continue
+				}
+				storageCap += safeInt64(vol.CapacityBytes)
+				storageUsed += safeInt64(vol.UsedBytes)
+				storageHourlyCost += pricing.GetStorageHourlyCost(vol.StorageClass, safeInt64(vol.CapacityBytes))
+			}
+		}
+
 		// Prepare cached labels for this pod
 		labelBuf.Reset()
 		writeLabels(labelBuf, base,
 			label{"namespace", pod.Namespace},
 			label{"pod", pod.PodName},
+			label{"container", pod.ContainerName},
+			label{"workload_kind", pod.WorkloadKind},
+			label{"workload", pod.WorkloadName},
 			label{"node", nodeName},
 			label{"availability_zone", req.AvailabilityZone},
 			label{"region", region},
@@ -438,6 +503,7 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 		writeIntSample(buf, scratch, "clustercost_pod_memory_rss_bytes", podLabelsBlob, memBytes, tsMillis)
 		writeIntSample(buf, scratch, "clustercost_pod_memory_request_bytes", podLabelsBlob, memReq, tsMillis)
 		writeIntSample(buf, scratch, "clustercost_pod_memory_limit_bytes", podLabelsBlob, memLim, tsMillis)
+		writeIntSample(buf, scratch, "clustercost_pod_memory_oom_kills_total", podLabelsBlob, oomKills, tsMillis)
 
 		writeIntSample(buf, scratch, "clustercost_pod_network_tx_bytes_total", podLabelsBlob, netTx, tsMillis)
 		writeIntSample(buf, scratch, "clustercost_pod_network_rx_bytes_total", podLabelsBlob, netRx, tsMillis)
@@ -449,8 +515,51 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 		memReqGB := float64(memReq) / (1024 * 1024 * 1024)
 		hourlyCost := (cpuReqCores * cpuPrice) + (memReqGB * memPrice)
 
+		// Devices are exclusive, so bound devices drive cost (fallback to requests)
+		gpuDevices := gpuAlloc
+		if gpuDevices == 0 {
+			gpuDevices = gpuReq
+		}
+		gpuHourlyCost := float64(gpuDevices) * gpuPrice
+		hourlyCost += gpuHourlyCost + storageHourlyCost
+
 		writeFloatSample(buf, scratch, "clustercost_pod_hourly_cost", podLabelsBlob, hourlyCost, tsMillis)
 
+		if pod.Gpu != nil {
+			writeIntSample(buf, scratch, "clustercost_pod_gpu_request_count", podLabelsBlob, gpuReq, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_pod_gpu_allocated_count", podLabelsBlob, gpuAlloc, tsMillis)
+			writeFloatSample(buf, scratch, "clustercost_pod_gpu_utilization_percent", podLabelsBlob, gpuUtil, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_pod_gpu_memory_used_bytes", podLabelsBlob, gpuMemUsed, tsMillis)
+			writeFloatSample(buf, scratch, "clustercost_pod_gpu_hourly_cost", podLabelsBlob, gpuHourlyCost, tsMillis)
+		}
+
+		if pod.Storage != nil {
+			writeIntSample(buf, scratch, "clustercost_pod_storage_read_bytes_total", podLabelsBlob, storageRead, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_pod_storage_write_bytes_total", podLabelsBlob, storageWrite, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_pod_storage_read_ops_total", podLabelsBlob, safeInt64(pod.Storage.ReadOps), tsMillis)
+			writeIntSample(buf, scratch, "clustercost_pod_storage_write_ops_total", podLabelsBlob, safeInt64(pod.Storage.WriteOps), tsMillis)
+			writeIntSample(buf, scratch, "clustercost_pod_storage_latency_ns_total", podLabelsBlob, safeInt64(pod.Storage.TotalLatencyNs), tsMillis)
+
+			// Per-volume series extend the pod labels with pvc/storage_class
+			podLabelsLen := labelBuf.Len()
+			for _, vol := range pod.Storage.Volumes {
+				if vol == nil || vol.PvcName == "" {
+					continue
+				}
+				labelBuf.Truncate(podLabelsLen)
+				writeLabel(labelBuf, label{"pvc", vol.PvcName})
+				if vol.StorageClass != "" {
+					writeLabel(labelBuf, label{"storage_class", vol.StorageClass})
+				}
+				volLabelsBlob := labelBuf.Bytes()
+
+				capacity := safeInt64(vol.CapacityBytes)
+				writeIntSample(buf, scratch, "clustercost_pod_storage_capacity_bytes", volLabelsBlob, capacity, tsMillis)
+				writeIntSample(buf, scratch, "clustercost_pod_storage_used_bytes", volLabelsBlob, safeInt64(vol.UsedBytes), tsMillis)
+				writeFloatSample(buf, scratch, "clustercost_pod_storage_hourly_cost", volLabelsBlob, pricing.GetStorageHourlyCost(vol.StorageClass, capacity), tsMillis)
+			}
+		}
+
 		// Aggregate for Namespace
 		if nsMap[pod.Namespace] == nil {
 			nsMap[pod.Namespace] = &nsAgg{}
@@ -461,11 +570,22 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 		agg.memoryRssBytes += memBytes
 		agg.hourlyCost += hourlyCost
 		agg.cpuReqMilli += cpuReq
+		agg.memReqBytes += memReq
 		agg.netTxBytes += netTx
 		agg.netRxBytes += netRx
 		agg.egressPublic += egressPublic
 		agg.egressCrossAZ += egressCrossAZ
 		agg.egressInternal += egressInternal
+		agg.gpuRequests += gpuReq
+		agg.gpuAllocated += gpuAlloc
+		agg.gpuUtilWeight += gpuUtil * float64(gpuDevices)
+		agg.gpuDevices += gpuDevices
+		agg.gpuHourlyCost += gpuHourlyCost
+		agg.storageCap += storageCap
+		agg.storageUsed += storageUsed
+		agg.storageCost += storageHourlyCost
+		agg.storageRead += storageRead
+		agg.storageWrite += storageWrite
 	}
 
 	// 3. Emit Aggregated Namespace Metrics & Calculate Cluster Totals
@@ -482,10 +602,8 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 		clusterEgressInternal += agg.egressInternal
 
 		labelBuf.Reset()
-		writeLabels(labelBuf, base,
-			label{"namespace", ns},
-			label{"environment", "production"},
-		)
+		nsSeriesLabels := append([]label{{"namespace", ns}, {"environment", "production"}}, i.nsLabels[ns]...)
+		writeLabels(labelBuf, base, nsSeriesLabels...)
 		nsLabelsBlob := labelBuf.Bytes()
 
 		writeIntSample(buf, scratch, "clustercost_namespace_pod_count", nsLabelsBlob, agg.podCount, tsMillis)
@@ -499,6 +617,20 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 		writeIntSample(buf, scratch, "clustercost_namespace_network_egress_public_bytes_total", nsLabelsBlob, agg.egressPublic, tsMillis)
 		writeIntSample(buf, scratch, "clustercost_namespace_network_egress_cross_az_bytes_total", nsLabelsBlob, agg.egressCrossAZ, tsMillis)
 		writeIntSample(buf, scratch, "clustercost_namespace_network_egress_internal_bytes_total", nsLabelsBlob, agg.egressInternal, tsMillis)
+
+		if agg.gpuDevices > 0 {
+			writeIntSample(buf, scratch, "clustercost_namespace_gpu_request_count", nsLabelsBlob, agg.gpuRequests, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_namespace_gpu_allocated_count", nsLabelsBlob, agg.gpuAllocated, tsMillis)
+			writeFloatSample(buf, scratch, "clustercost_namespace_gpu_utilization_percent", nsLabelsBlob, agg.gpuUtilWeight/float64(agg.gpuDevices), tsMillis)
+			writeFloatSample(buf, scratch, "clustercost_namespace_gpu_hourly_cost", nsLabelsBlob, agg.gpuHourlyCost, tsMillis)
+		}
+		if agg.storageCap > 0 || agg.storageRead > 0 || agg.storageWrite > 0 {
+			writeIntSample(buf, scratch, "clustercost_namespace_storage_capacity_bytes", nsLabelsBlob, agg.storageCap, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_namespace_storage_used_bytes", nsLabelsBlob, agg.storageUsed, tsMillis)
+			writeFloatSample(buf, scratch, "clustercost_namespace_storage_hourly_cost", nsLabelsBlob, agg.storageCost, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_namespace_storage_read_bytes_total", nsLabelsBlob, agg.storageRead, tsMillis)
+			writeIntSample(buf, scratch, "clustercost_namespace_storage_write_bytes_total", nsLabelsBlob, agg.storageWrite, tsMillis)
+		}
 	}
 
 	// Cluster totals will be emitted after Node processing
@@ -533,6 +665,12 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 			memPct := (float64(node.MemoryUsageBytes) / float64(node.AllocatableMemoryBytes)) * 100
 			writeFloatSample(buf, scratch, "clustercost_node_memory_usage_percent", nodeLabelsBlob, memPct, tsMillis)
 		}
+		if node.GpuCapacity > 0 {
+			writeIntSample(buf, scratch, "clustercost_node_gpu_capacity", nodeLabelsBlob, int64(node.GpuCapacity), tsMillis)
+			writeIntSample(buf, scratch, "clustercost_node_gpu_allocatable", nodeLabelsBlob, int64(node.GpuAllocatable), tsMillis)
+			writeIntSample(buf, scratch, "clustercost_node_gpu_requested", nodeLabelsBlob, int64(node.GpuRequested), tsMillis)
+			writeFloatSample(buf, scratch, "clustercost_node_gpu_utilization_percent", nodeLabelsBlob, node.GpuUtilizationPercent, tsMillis)
+		}
 
 		// Node Network Metrics (Host Traffic)
 		if node.Network != nil {
@@ -555,6 +693,12 @@ func (i *Ingestor) appendMetricsReport(buf, labelBuf *bytes.Buffer, scratch []by
 			clusterEgressCrossAZ += nodeEgressCrossAZ
 			clusterEgressInternal += nodeEgressInternal
 		}
+
+		podCount := int64(node.PodCount)
+		if podCount == 0 && node.NodeName == req.NodeName {
+			podCount = int64(len(req.Pods))
+		}
+		appendNodeState(buf, labelBuf, scratch, node, podCount, tsMillis)
 	}
 
 	// 4. Emit Cluster Totals (Pod + Node)
@@ -581,6 +725,10 @@ func (i *Ingestor) appendNetworkReport(buf, labelBuf *bytes.Buffer, scratch []by
 	}
 	meta := i.agentMeta[agentName]
 	base := baseLabels(agentName, req.ClusterId, "", meta)
+	if i.cardinality != nil {
+		i.appendReducedConnections(buf, labelBuf, scratch, base, agentName, req, tsMillis)
+		return
+	}
 
 	// Decode Compact Connections
 	endpoints := req.Endpoints
@@ -631,6 +779,60 @@ func buildAgentMeta(cfg config.Config) map[string]agentMetadata {
 	return out
 }
 
+// appendNodeState emits the pod count and the info-style status, condition, label and taint
+// series of a node. labelBuf must hold the node labels; it is extended per series.
+func appendNodeState(buf, labelBuf *bytes.Buffer, scratch []byte, node *agentv1.NodeMetric, podCount int64, tsMillis int64) {
+	nodeLen := labelBuf.Len()
+	writeIntSample(buf, scratch, "clustercost_node_pod_count", labelBuf.Bytes(), podCount, tsMillis)
+
+	conditions := store.NodeConditionsOf(node)
+	status := store.NodeStatusOf(node)
+	if status != store.NodeStatusUnknown || len(conditions) > 0 {
+		underPressure := int64(0)
+		if store.NodeUnderPressure(conditions) {
+			underPressure = 1
+		}
+		writeFlagSample(buf, scratch, "clustercost_node_under_pressure", labelBuf.Bytes(), underPressure, tsMillis)
+	}
+
+	writeLabel(labelBuf, label{"status", status})
+	writeFlagSample(buf, scratch, "clustercost_node_status", labelBuf.Bytes(), 1, tsMillis)
+
+	for _, c := range conditions {
+		labelBuf.Truncate(nodeLen)
+		writeLabel(labelBuf, label{"condition", c.Type})
+		writeLabel(labelBuf, label{"status", c.Status})
+		if c.Reason != "" {
+			writeLabel(labelBuf, label{"reason", c.Reason})
+		}
+		writeFlagSample(buf, scratch, "clustercost_node_condition", labelBuf.Bytes(), 1, tsMillis)
+	}
+
+	keys := make([]string, 0, len(node.Labels))
+	for k := range node.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		labelBuf.Truncate(nodeLen)
+		writeLabel(labelBuf, label{"key", k})
+		writeLabel(labelBuf, label{"value", node.Labels[k]})
+		writeFlagSample(buf, scratch, "clustercost_node_label", labelBuf.Bytes(), 1, tsMillis)
+	}
+
+	for _, t := range node.Taints {
+		if t == nil || t.Key == "" {
+			continue
+		}
+		labelBuf.Truncate(nodeLen)
+		writeLabel(labelBuf, label{"key", t.Key})
+		writeLabel(labelBuf, label{"value", t.Value})
+		writeLabel(labelBuf, label{"effect", t.Effect})
+		writeFlagSample(buf, scratch, "clustercost_node_taint", labelBuf.Bytes(), 1, tsMillis)
+	}
+	labelBuf.Truncate(nodeLen)
+}
+
 type label struct {
 	key   string
 	value string
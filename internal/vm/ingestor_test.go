@@ -154,6 +154,87 @@ func TestAppendReportEmitsNodeMetrics(t *testing.T) {
 	assertLabel(t, labels, "node", "node-a")
 }
 
+func TestAppendReportEmitsNodeState(t *testing.T) {
+	req := &agentv1.MetricsReportRequest{
+		AgentId:          "agent-1",
+		ClusterId:        "cluster-1",
+		NodeName:         "node-a",
+		TimestampSeconds: 1700000000,
+		Pods:             []*agentv1.PodMetric{{Namespace: "default", PodName: "web-1"}},
+		Nodes: []*agentv1.NodeMetric{
+			{
+				NodeName: "node-a",
+				Ready:    true,
+				PodCount: 12,
+				Conditions: []*agentv1.NodeCondition{
+					{Type: "Ready", Status: "True", Reason: "KubeletReady"},
+					{Type: "MemoryPressure", Status: "True"},
+				},
+				Labels: map[string]string{"topology.kubernetes.io/zone": "us-east-1a"},
+				Taints: []*agentv1.NodeTaint{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}},
+			},
+			{NodeName: "node-b"},
+		},
+	}
+
+	ing := &Ingestor{}
+	var buf bytes.Buffer
+	ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", metricsReq: req})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	byNode := func(metric, node string) (map[string]string, string) {
+		for _, line := range lines {
+			if !strings.HasPrefix(line, metric+"{") {
+				continue
+			}
+			_, labels, value, _ := parseMetricLine(t, line)
+			if labels["node"] == node {
+				return labels, value
+			}
+		}
+		t.Fatalf("expected %s for %s in output", metric, node)
+		return nil, ""
+	}
+
+	if _, value := byNode("clustercost_node_pod_count", "node-a"); value != "12" {
+		t.Fatalf("expected reported pod count 12, got %s", value)
+	}
+	if labels, _ := byNode("clustercost_node_status", "node-a"); labels["status"] != "Ready" {
+		t.Fatalf("expected Ready status, got %q", labels["status"])
+	}
+	if _, value := byNode("clustercost_node_under_pressure", "node-a"); value != "1" {
+		t.Fatalf("expected node under pressure, got %s", value)
+	}
+	labels, _ := byNode("clustercost_node_label", "node-a")
+	assertLabel(t, labels, "key", "topology.kubernetes.io/zone")
+	assertLabel(t, labels, "value", "us-east-1a")
+	labels, _ = byNode("clustercost_node_taint", "node-a")
+	assertLabel(t, labels, "effect", "NoSchedule")
+
+	conditions := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "clustercost_node_condition{") {
+			conditions++
+		}
+	}
+	if conditions != 2 {
+		t.Fatalf("expected 2 condition series, got %d", conditions)
+	}
+
+	// node-b comes from an agent without node state
+	if labels, _ := byNode("clustercost_node_status", "node-b"); labels["status"] != "Unknown" {
+		t.Fatalf("expected Unknown status without conditions, got %q", labels["status"])
+	}
+	if _, value := byNode("clustercost_node_pod_count", "node-b"); value != "0" {
+		t.Fatalf("expected no pods on node-b, got %s", value)
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "clustercost_node_under_pressure{") && strings.Contains(line, `node="node-b"`) {
+			t.Fatalf("pressure should not be guessed without conditions: %s", line)
+		}
+	}
+}
+
 func TestAppendReportEmitsPodAndNamespaceHourlyCost(t *testing.T) {
 	req := &agentv1.MetricsReportRequest{
 		AgentId:          "agent-1",
@@ -187,7 +268,7 @@ func TestAppendReportEmitsPodAndNamespaceHourlyCost(t *testing.T) {
 		},
 	}
 
-	ing := &Ingestor{}
+	ing := &Ingestor{nsLabels: namespaceLabels{"payments": {{"team", "core"}}}}
 	var buf bytes.Buffer
 	ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", metricsReq: req})
 
@@ -213,6 +294,8 @@ func TestAppendReportEmitsPodAndNamespaceHourlyCost(t *testing.T) {
 	if labels["namespace"] != "payments" {
 		t.Fatalf("expected namespace payments, got %s", labels["namespace"])
 	}
+	// Configured namespace labels are written so budgets and chargeback can group by them
+	assertLabel(t, labels, "team", "core")
 	nsCost, err := strconv.ParseFloat(nsCostValue, 64)
 	if err != nil {
 		t.Fatalf("expected namespace hourly cost float, got %s", nsCostValue)
@@ -220,6 +303,7 @@ func TestAppendReportEmitsPodAndNamespaceHourlyCost(t *testing.T) {
 	if diff := nsCost - 0.0078; diff < -0.0001 || diff > 0.0001 {
 		t.Fatalf("expected namespace hourly cost ~0.0078, got %v", nsCost)
 	}
+	checkMetric(t, lines, "clustercost_namespace_memory_request_bytes", "1073741824")
 
 	nodeAllocLine := findMetricLine(lines, "clustercost_node_cpu_allocatable_milli")
 	if nodeAllocLine == "" {
@@ -231,6 +315,168 @@ func TestAppendReportEmitsPodAndNamespaceHourlyCost(t *testing.T) {
 	}
 }
 
+func TestAppendReportEmitsGPUMetricsAndCost(t *testing.T) {
+	req := &agentv1.MetricsReportRequest{
+		AgentId:          "agent-1",
+		ClusterId:        "cluster-1",
+		NodeName:         "gpu-node",
+		InstanceType:     "g5.xlarge",
+		TimestampSeconds: 1700000000,
+		Nodes: []*agentv1.NodeMetric{
+			{
+				NodeName:              "gpu-node",
+				CapacityCpuMillicores: 4000,
+				CapacityMemoryBytes:   16 * 1024 * 1024 * 1024,
+				GpuCapacity:           1,
+				GpuAllocatable:        1,
+				GpuRequested:          1,
+				GpuUtilizationPercent: 75,
+			},
+		},
+		Pods: []*agentv1.PodMetric{
+			{
+				Namespace: "ml",
+				PodName:   "trainer-1",
+				Cpu: &agentv1.CpuMetrics{
+					RequestMillicores: 1000,
+				},
+				Memory: &agentv1.MemoryMetrics{
+					RequestBytes: 4 * 1024 * 1024 * 1024,
+				},
+				Gpu: &agentv1.GpuMetrics{
+					RequestCount:       1,
+					AllocatedCount:     1,
+					UtilizationPercent: 75,
+					MemoryUsedBytes:    2048,
+				},
+			},
+		},
+	}
+
+	ing := &Ingestor{}
+	var buf bytes.Buffer
+	ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", metricsReq: req})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	checkMetric(t, lines, "clustercost_pod_gpu_request_count", "1")
+	checkMetric(t, lines, "clustercost_pod_gpu_allocated_count", "1")
+	checkMetric(t, lines, "clustercost_pod_gpu_utilization_percent", "75")
+	checkMetric(t, lines, "clustercost_pod_gpu_memory_used_bytes", "2048")
+	checkMetric(t, lines, "clustercost_namespace_gpu_allocated_count", "1")
+	checkMetric(t, lines, "clustercost_namespace_gpu_utilization_percent", "75")
+	checkMetric(t, lines, "clustercost_node_gpu_capacity", "1")
+	checkMetric(t, lines, "clustercost_node_gpu_requested", "1")
+
+	// 1 core * $0.02515 + 4 GB * $0.0062875 + 1 GPU * $0.8048
+	for _, metric := range []string{"clustercost_pod_hourly_cost", "clustercost_namespace_hourly_cost"} {
+		line := findMetricLine(lines, metric)
+		if line == "" {
+			t.Fatalf("expected %s in output", metric)
+		}
+		_, _, value, _ := parseMetricLine(t, line)
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("expected %s float, got %s", metric, value)
+		}
+		if diff := cost - 0.8551; diff < -0.0001 || diff > 0.0001 {
+			t.Fatalf("expected %s ~0.8551, got %v", metric, cost)
+		}
+	}
+}
+
+func TestAppendReportEmitsStorageMetricsAndCost(t *testing.T) {
+	req := &agentv1.MetricsReportRequest{
+		AgentId:          "agent-1",
+		ClusterId:        "cluster-1",
+		NodeName:         "node-a",
+		TimestampSeconds: 1700000000,
+		Pods: []*agentv1.PodMetric{
+			{
+				Namespace: "db",
+				PodName:   "postgres-0",
+				Storage: &agentv1.StorageMetrics{
+					ReadBytes:  4096,
+					WriteBytes: 8192,
+					Volumes: []*agentv1.VolumeMetric{
+						{PvcName: "data-postgres-0", StorageClass: "gp3", CapacityBytes: 100 * 1024 * 1024 * 1024, UsedBytes: 1024},
+					},
+				},
+			},
+		},
+	}
+
+	ing := &Ingestor{}
+	var buf bytes.Buffer
+	ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", metricsReq: req})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	checkMetric(t, lines, "clustercost_pod_storage_read_bytes_total", "4096")
+	checkMetric(t, lines, "clustercost_pod_storage_write_bytes_total", "8192")
+	checkMetric(t, lines, "clustercost_pod_storage_capacity_bytes", "107374182400")
+	checkMetric(t, lines, "clustercost_pod_storage_used_bytes", "1024")
+	checkMetric(t, lines, "clustercost_namespace_storage_capacity_bytes", "107374182400")
+
+	line := findMetricLine(lines, "clustercost_pod_storage_hourly_cost")
+	if line == "" {
+		t.Fatalf("expected pod storage hourly cost metric in output")
+	}
+	_, labels, _, _ := parseMetricLine(t, line)
+	assertLabel(t, labels, "pvc", "data-postgres-0")
+	assertLabel(t, labels, "storage_class", "gp3")
+	assertLabel(t, labels, "pod", "postgres-0")
+
+	// 100 GiB gp3 @ $0.08/GB-month
+	for _, metric := range []string{"clustercost_pod_hourly_cost", "clustercost_namespace_storage_hourly_cost"} {
+		line := findMetricLine(lines, metric)
+		if line == "" {
+			t.Fatalf("expected %s in output", metric)
+		}
+		_, _, value, _ := parseMetricLine(t, line)
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("expected %s float, got %s", metric, value)
+		}
+		if diff := cost - 8.0/730; diff < -0.0001 || diff > 0.0001 {
+			t.Fatalf("expected %s ~%v, got %v", metric, 8.0/730, cost)
+		}
+	}
+}
+
+func TestAppendReportEmitsContainerIdentityAndOOMKills(t *testing.T) {
+	req := &agentv1.MetricsReportRequest{
+		AgentId:          "agent-1",
+		ClusterId:        "cluster-1",
+		NodeName:         "node-a",
+		TimestampSeconds: 1700000000,
+		Pods: []*agentv1.PodMetric{
+			{
+				Namespace:     "web",
+				PodName:       "frontend-7d9f8b6c5d-x2x9k",
+				ContainerName: "app",
+				WorkloadKind:  "Deployment",
+				WorkloadName:  "frontend",
+				Memory:        &agentv1.MemoryMetrics{RssBytes: 1024, OomKillsTotal: 3},
+			},
+		},
+	}
+
+	ing := &Ingestor{}
+	var buf bytes.Buffer
+	ing.appendReport(&buf, &bytes.Buffer{}, make([]byte, 64), reportEnvelope{agentName: "agent-1", metricsReq: req})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	checkMetric(t, lines, "clustercost_pod_memory_oom_kills_total", "3")
+
+	line := findMetricLine(lines, "clustercost_pod_memory_rss_bytes")
+	if line == "" {
+		t.Fatalf("expected pod memory metric in output")
+	}
+	_, labels, _, _ := parseMetricLine(t, line)
+	assertLabel(t, labels, "container", "app")
+	assertLabel(t, labels, "workload_kind", "Deployment")
+	assertLabel(t, labels, "workload", "frontend")
+}
+
 func TestReportTimestampMillisUsesReportTimestamp(t *testing.T) {
 	got := reportTimestampMillis(1700001234)
 	if got != 1700001234000 {
@@ -0,0 +1,111 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// NodeGroupUsage is the aggregate pod requests and usage of the nodes sharing an instance type.
+// Usage is a percentile of the group total over the lookback; requests and the largest pod
+// come from the pods running now.
+type NodeGroupUsage struct {
+	ClusterID                string
+	Region                   string
+	InstanceType             string
+	Nodes                    int
+	CPURequestMilli          float64
+	MemoryRequestBytes       float64
+	CPUUsageMilli            float64
+	MemoryUsageBytes         float64
+	MaxPodCPURequestMilli    float64
+	MaxPodMemoryRequestBytes float64
+}
+
+const nodeGroupGrouping = "cluster_id, region, instance_type"
+
+// NodeGroupUsage returns one entry per (cluster, region, instance type) with running pods.
+func (c *Client) NodeGroupUsage(ctx context.Context, lookback time.Duration, percentile float64) ([]NodeGroupUsage, error) {
+	if lookback <= 0 {
+		lookback = c.lookback
+	}
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+
+	selector := func(metric string) string {
+		return metricSelector(metric, c.scopedLabels(nil, clusterID))
+	}
+	running := func(metric string) string {
+		return fmt.Sprintf("last_over_time(%s[%s])", selector(metric), formatDuration(containerRunningWindow))
+	}
+	usage := func(metric string) string {
+		return fmt.Sprintf("quantile_over_time(%g, (sum by (%s) (%s))[%s:%s])",
+			percentile, nodeGroupGrouping, selector(metric), formatDuration(lookback), formatDuration(coverageBucket))
+	}
+	largestPod := func(metric string) string {
+		return fmt.Sprintf("max by (%[1]s) (sum by (%[1]s, namespace, pod) (%[2]s))", nodeGroupGrouping, running(metric))
+	}
+
+	type groupKey struct{ cluster, region, instanceType string }
+	groups := make(map[groupKey]*NodeGroupUsage)
+	queries := []struct {
+		expr   string
+		assign func(g *NodeGroupUsage, v float64)
+	}{
+		{fmt.Sprintf("count by (%[1]s) (count by (%[1]s, node) (%[2]s))", nodeGroupGrouping, running("clustercost_pod_cpu_request_millicores")), func(g *NodeGroupUsage, v float64) {
+			g.Nodes = int(v)
+		}},
+		{fmt.Sprintf("sum by (%s) (%s)", nodeGroupGrouping, running("clustercost_pod_cpu_request_millicores")), func(g *NodeGroupUsage, v float64) { g.CPURequestMilli = v }},
+		{fmt.Sprintf("sum by (%s) (%s)", nodeGroupGrouping, running("clustercost_pod_memory_request_bytes")), func(g *NodeGroupUsage, v float64) { g.MemoryRequestBytes = v }},
+		{usage("clustercost_pod_cpu_usage_milli"), func(g *NodeGroupUsage, v float64) { g.CPUUsageMilli = v }},
+		{usage("clustercost_pod_memory_rss_bytes"), func(g *NodeGroupUsage, v float64) { g.MemoryUsageBytes = v }},
+		{largestPod("clustercost_pod_cpu_request_millicores"), func(g *NodeGroupUsage, v float64) { g.MaxPodCPURequestMilli = v }},
+		{largestPod("clustercost_pod_memory_request_bytes"), func(g *NodeGroupUsage, v float64) { g.MaxPodMemoryRequestBytes = v }},
+	}
+	for i, q := range queries {
+		samples, err := c.query(ctx, q.expr)
+		if err != nil {
+			return nil, fmt.Errorf("query node group usage: %w", err)
+		}
+		for _, s := range samples {
+			if math.IsNaN(s.value) {
+				continue
+			}
+			key := groupKey{s.labels["cluster_id"], s.labels["region"], s.labels["instance_type"]}
+			if key.instanceType == "" {
+				continue
+			}
+			g := groups[key]
+			if g == nil {
+				if i > 0 {
+					// Only groups with running nodes are considered
+					continue
+				}
+				g = &NodeGroupUsage{ClusterID: key.cluster, Region: key.region, InstanceType: key.instanceType}
+				groups[key] = g
+			}
+			q.assign(g, math.Max(s.value, 0))
+		}
+	}
+	if len(groups) == 0 {
+		return nil, ErrNoData
+	}
+
+	out := make([]NodeGroupUsage, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.ClusterID != b.ClusterID {
+			return a.ClusterID < b.ClusterID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.InstanceType < b.InstanceType
+	})
+	return out, nil
+}
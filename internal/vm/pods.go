@@ -0,0 +1,160 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
+)
+
+// podIdentity groups pod series: every container of a pod shares these labels.
+const podIdentity = "namespace, pod, node, workload_kind, workload, region, instance_type"
+
+// networkWindow is the window pod, namespace and node traffic is reported over.
+const networkWindow = store.NetworkWindow
+
+// PodList returns the pods running now, filtered and paginated, most expensive first.
+func (c *Client) PodList(ctx context.Context, filter store.PodFilter) (store.PodListResponse, error) {
+	labels := map[string]string{}
+	if filter.Namespace != "" {
+		labels["namespace"] = filter.Namespace
+	}
+	if filter.Node != "" {
+		labels["node"] = filter.Node
+	}
+	pods, err := c.podMetrics(ctx, labels, containerRunningWindow)
+	if err != nil {
+		return store.PodListResponse{}, err
+	}
+	if len(pods) == 0 {
+		return store.PodListResponse{}, ErrNoData
+	}
+
+	items, total := store.FilterPods(pods, filter)
+	ts := c.seriesTimestampSafe(ctx, "clustercost_pod_hourly_cost")
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+	return store.PodListResponse{Items: items, TotalCount: total, Timestamp: ts}, nil
+}
+
+// PodDetail returns requests vs usage, the cost breakdown and the last hour of network traffic
+// for one pod. Pods that stopped within the lookback are still found.
+func (c *Client) PodDetail(ctx context.Context, namespace, name string) (store.PodDetail, error) {
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+	labels := map[string]string{"namespace": namespace, "pod": name}
+
+	pods, err := c.podMetrics(ctx, labels, c.lookback)
+	if err != nil {
+		return store.PodDetail{}, err
+	}
+	if len(pods) == 0 {
+		return store.PodDetail{}, ErrNoData
+	}
+	pod := pods[0]
+
+	network := store.PodNetwork{Window: formatDuration(networkWindow)}
+	queries := []struct {
+		metric string
+		assign func(v float64)
+	}{
+		{"clustercost_pod_network_tx_bytes_total", func(v float64) { network.TxBytes = int64(v) }},
+		{"clustercost_pod_network_rx_bytes_total", func(v float64) { network.RxBytes = int64(v) }},
+		{"clustercost_pod_network_egress_public_bytes_total", func(v float64) { network.EgressPublicBytes = int64(v) }},
+		{"clustercost_pod_network_egress_cross_az_bytes_total", func(v float64) { network.EgressCrossAZBytes = int64(v) }},
+		{"clustercost_pod_network_egress_internal_bytes_total", func(v float64) { network.EgressInternalBytes = int64(v) }},
+	}
+	for _, q := range queries {
+		expr := fmt.Sprintf("sum(increase(%s[%s]))", metricSelector(q.metric, c.scopedLabels(labels, clusterID)), network.Window)
+		samples, err := c.query(ctx, expr)
+		if err != nil {
+			return store.PodDetail{}, err
+		}
+		if len(samples) > 0 {
+			q.assign(samples[0].value)
+		}
+	}
+	network.EgressCostHourly = store.EgressHourlyCost(network.EgressPublicBytes, network.EgressCrossAZBytes)
+
+	instanceType := pod.InstanceType
+	if instanceType == "" {
+		instanceType = "default"
+	}
+	cpuPrice, memPrice := store.NewPricingCatalog(nil).GetNodeResourcePrices(ctx, pod.Region, instanceType, 0, 0)
+	return store.NewPodDetail(pod, cpuPrice, memPrice, network), nil
+}
+
+// podMetrics sums the container series of each pod seen within window.
+func (c *Client) podMetrics(ctx context.Context, labels map[string]string, window time.Duration) ([]store.PodSummary, error) {
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+
+	series := func(agg, metric string) string {
+		selector := metricSelector(metric, c.scopedLabels(labels, clusterID))
+		return fmt.Sprintf("%s by (%s) (last_over_time(%s[%s]))", agg, podIdentity, selector, formatDuration(window))
+	}
+	egress := func(metric string) string {
+		selector := metricSelector(metric, c.scopedLabels(labels, clusterID))
+		return fmt.Sprintf("sum by (%s) (increase(%s[%s]))", podIdentity, selector, formatDuration(networkWindow))
+	}
+	queries := []struct {
+		expr   string
+		assign func(p *store.PodSummary, v float64)
+	}{
+		{series("sum", "clustercost_pod_hourly_cost"), func(p *store.PodSummary, v float64) { p.HourlyCost = v }},
+		{series("count", "clustercost_pod_cpu_request_millicores"), func(p *store.PodSummary, v float64) { p.Containers = int(v) }},
+		{series("sum", "clustercost_pod_cpu_request_millicores"), func(p *store.PodSummary, v float64) { p.CPURequestMilli = int64(v) }},
+		{series("sum", "clustercost_pod_cpu_limit_millicores"), func(p *store.PodSummary, v float64) { p.CPULimitMilli = int64(v) }},
+		{series("sum", "clustercost_pod_cpu_usage_milli"), func(p *store.PodSummary, v float64) { p.CPUUsageMilli = int64(v) }},
+		{series("sum", "clustercost_pod_memory_request_bytes"), func(p *store.PodSummary, v float64) { p.MemoryRequestBytes = int64(v) }},
+		{series("sum", "clustercost_pod_memory_limit_bytes"), func(p *store.PodSummary, v float64) { p.MemoryLimitBytes = int64(v) }},
+		{series("sum", "clustercost_pod_memory_rss_bytes"), func(p *store.PodSummary, v float64) { p.MemoryUsageBytes = int64(v) }},
+		{series("sum", "clustercost_pod_gpu_hourly_cost"), func(p *store.PodSummary, v float64) { p.GPUHourlyCost = v }},
+		{series("sum", "clustercost_pod_storage_hourly_cost"), func(p *store.PodSummary, v float64) { p.StorageHourlyCost = v }},
+		{egress("clustercost_pod_network_egress_public_bytes_total"), func(p *store.PodSummary, v float64) { p.EgressBytes += int64(v) }},
+		{egress("clustercost_pod_network_egress_cross_az_bytes_total"), func(p *store.PodSummary, v float64) { p.EgressBytes += int64(v) }},
+	}
+
+	pods := make(map[string]*store.PodSummary)
+	var order []string
+	for _, q := range queries {
+		samples, err := c.query(ctx, q.expr)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range samples {
+			ns, name := s.labels["namespace"], s.labels["pod"]
+			if ns == "" || name == "" {
+				continue
+			}
+			// A pod rescheduled within the window keeps its name; the series seen first wins
+			key := ns + "/" + name
+			p := pods[key]
+			if p == nil {
+				p = &store.PodSummary{
+					Namespace:    ns,
+					PodName:      name,
+					NodeName:     s.labels["node"],
+					WorkloadKind: s.labels["workload_kind"],
+					WorkloadName: s.labels["workload"],
+					Region:       s.labels["region"],
+					InstanceType: s.labels["instance_type"],
+				}
+				pods[key] = p
+				order = append(order, key)
+			}
+			if p.NodeName != s.labels["node"] {
+				continue
+			}
+			q.assign(p, s.value)
+		}
+	}
+
+	out := make([]store.PodSummary, 0, len(order))
+	for _, key := range order {
+		out = append(out, *pods[key])
+	}
+	return out, nil
+}
@@ -0,0 +1,194 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ContainerUsageQuery selects the containers and statistics returned by ContainerUsage.
+type ContainerUsageQuery struct {
+	Namespace        string
+	Lookback         time.Duration
+	CPUPercentile    float64
+	MemoryPercentile float64
+}
+
+// ContainerUsage is the observed usage and current requests/limits of one container.
+// Pods of the same workload are merged: percentiles and peaks take the highest pod, OOM kills
+// and samples are summed, and Replicas counts the pods still reporting. Workloads without a
+// running pod are dropped. Pod is only set when the workload is unknown.
+type ContainerUsage struct {
+	ClusterID             string
+	Namespace             string
+	WorkloadKind          string
+	Workload              string
+	Pod                   string
+	Container             string
+	Region                string
+	InstanceType          string
+	Replicas              int
+	CPURequestMilli       float64
+	CPULimitMilli         float64
+	MemoryRequestBytes    float64
+	MemoryLimitBytes      float64
+	CPUPercentileMilli    float64
+	CPUPeakMilli          float64
+	MemoryPercentileBytes float64
+	MemoryPeakBytes       float64
+	OOMKills              float64
+	Samples               float64
+
+	running bool
+}
+
+const (
+	containerUsageGrouping = "cluster_id, namespace, workload_kind, workload, pod, container"
+	// containerRunningWindow is how recently a pod must have reported to count as a replica
+	containerRunningWindow = 10 * time.Minute
+)
+
+// ContainerUsage returns per-container CPU and memory percentiles, peaks, current requests and
+// limits, and OOM kills over the lookback. Every statistic is one batched query across pods.
+func (c *Client) ContainerUsage(ctx context.Context, q ContainerUsageQuery) ([]ContainerUsage, error) {
+	if q.Lookback <= 0 {
+		q.Lookback = c.lookback
+	}
+	clusterID := c.resolveClusterID(ctx)
+	ctx = WithClusterID(ctx, clusterID)
+
+	selector := func(metric string) string {
+		var labels map[string]string
+		if q.Namespace != "" {
+			labels = map[string]string{"namespace": q.Namespace}
+		}
+		return metricSelector(metric, c.scopedLabels(labels, clusterID))
+	}
+	window := formatDuration(q.Lookback)
+	byPod := func(fn, metric string) string {
+		return fmt.Sprintf("max by (%s) (%s(%s[%s]))", containerUsageGrouping, fn, selector(metric), window)
+	}
+	quantile := func(phi float64, metric string) string {
+		return fmt.Sprintf("max by (%s) (quantile_over_time(%g, %s[%s]))", containerUsageGrouping, phi, selector(metric), window)
+	}
+
+	type podKey struct{ cluster, namespace, kind, workload, pod, container string }
+	pods := make(map[podKey]*ContainerUsage)
+	queries := []struct {
+		expr   string
+		assign func(u *ContainerUsage, v float64)
+	}{
+		{fmt.Sprintf("max by (%s, region, instance_type) (last_over_time(%s[%s]))", containerUsageGrouping, selector("clustercost_pod_cpu_request_millicores"), window), func(u *ContainerUsage, v float64) {
+			u.CPURequestMilli = v
+		}},
+		{byPod("last_over_time", "clustercost_pod_cpu_limit_millicores"), func(u *ContainerUsage, v float64) { u.CPULimitMilli = v }},
+		{byPod("last_over_time", "clustercost_pod_memory_request_bytes"), func(u *ContainerUsage, v float64) { u.MemoryRequestBytes = v }},
+		{byPod("last_over_time", "clustercost_pod_memory_limit_bytes"), func(u *ContainerUsage, v float64) { u.MemoryLimitBytes = v }},
+		{quantile(q.CPUPercentile, "clustercost_pod_cpu_usage_milli"), func(u *ContainerUsage, v float64) { u.CPUPercentileMilli = v }},
+		{byPod("max_over_time", "clustercost_pod_cpu_usage_milli"), func(u *ContainerUsage, v float64) { u.CPUPeakMilli = v }},
+		{quantile(q.MemoryPercentile, "clustercost_pod_memory_rss_bytes"), func(u *ContainerUsage, v float64) { u.MemoryPercentileBytes = v }},
+		{byPod("max_over_time", "clustercost_pod_memory_rss_bytes"), func(u *ContainerUsage, v float64) { u.MemoryPeakBytes = v }},
+		{byPod("count_over_time", "clustercost_pod_cpu_usage_milli"), func(u *ContainerUsage, v float64) { u.Samples = v }},
+		{fmt.Sprintf("max by (%s) (present_over_time(%s[%s]))", containerUsageGrouping, selector("clustercost_pod_cpu_request_millicores"), formatDuration(containerRunningWindow)), func(u *ContainerUsage, v float64) {
+			u.running = v > 0
+		}},
+		{fmt.Sprintf("sum by (%s) (increase(%s[%s]))", containerUsageGrouping, selector("clustercost_pod_memory_oom_kills_total"), window), func(u *ContainerUsage, v float64) {
+			u.OOMKills = math.Round(v)
+		}},
+	}
+	for i, query := range queries {
+		samples, err := c.query(ctx, query.expr)
+		if err != nil {
+			return nil, fmt.Errorf("query container usage: %w", err)
+		}
+		for _, s := range samples {
+			if math.IsNaN(s.value) {
+				continue
+			}
+			key := podKey{s.labels["cluster_id"], s.labels["namespace"], s.labels["workload_kind"], s.labels["workload"], s.labels["pod"], s.labels["container"]}
+			u := pods[key]
+			if u == nil {
+				if i > 0 {
+					// Only containers with requests are considered
+					continue
+				}
+				u = &ContainerUsage{
+					ClusterID:    key.cluster,
+					Namespace:    key.namespace,
+					WorkloadKind: key.kind,
+					Workload:     key.workload,
+					Pod:          key.pod,
+					Container:    key.container,
+					Region:       s.labels["region"],
+					InstanceType: s.labels["instance_type"],
+				}
+				pods[key] = u
+			}
+			query.assign(u, math.Max(s.value, 0))
+		}
+	}
+	if len(pods) == 0 {
+		return nil, ErrNoData
+	}
+
+	merged := make(map[podKey]*ContainerUsage)
+	for key, u := range pods {
+		ck := key
+		if key.workload != "" {
+			ck.pod = ""
+		}
+		m := merged[ck]
+		if m == nil {
+			m = &ContainerUsage{
+				ClusterID:    u.ClusterID,
+				Namespace:    u.Namespace,
+				WorkloadKind: u.WorkloadKind,
+				Workload:     u.Workload,
+				Pod:          ck.pod,
+				Container:    u.Container,
+			}
+			merged[ck] = m
+		}
+		m.CPUPercentileMilli = math.Max(m.CPUPercentileMilli, u.CPUPercentileMilli)
+		m.CPUPeakMilli = math.Max(m.CPUPeakMilli, u.CPUPeakMilli)
+		m.MemoryPercentileBytes = math.Max(m.MemoryPercentileBytes, u.MemoryPercentileBytes)
+		m.MemoryPeakBytes = math.Max(m.MemoryPeakBytes, u.MemoryPeakBytes)
+		m.OOMKills += u.OOMKills
+		m.Samples += u.Samples
+		if !u.running {
+			continue
+		}
+		// Current requests and limits come from the pods still running
+		m.Replicas++
+		m.Region, m.InstanceType = u.Region, u.InstanceType
+		m.CPURequestMilli = math.Max(m.CPURequestMilli, u.CPURequestMilli)
+		m.CPULimitMilli = math.Max(m.CPULimitMilli, u.CPULimitMilli)
+		m.MemoryRequestBytes = math.Max(m.MemoryRequestBytes, u.MemoryRequestBytes)
+		m.MemoryLimitBytes = math.Max(m.MemoryLimitBytes, u.MemoryLimitBytes)
+	}
+
+	out := make([]ContainerUsage, 0, len(merged))
+	for _, u := range merged {
+		if u.Replicas == 0 {
+			continue
+		}
+		out = append(out, *u)
+	}
+	if len(out) == 0 {
+		return nil, ErrNoData
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Workload+a.Pod != b.Workload+b.Pod {
+			return a.Workload+a.Pod < b.Workload+b.Pod
+		}
+		return a.Container < b.Container
+	})
+	return out, nil
+}
@@ -1,8 +1,15 @@
 package vm
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/clustercost/clustercost-dashboard/internal/store"
 )
 
 func TestConnectionMetricExpr_NoNamespace(t *testing.T) {
@@ -82,3 +89,62 @@ func TestEdgeFromLabelsParsesProtocol(t *testing.T) {
 		t.Fatalf("unexpected dns names: %s %s", edge.SrcDNSName, edge.DstDNSName)
 	}
 }
+
+func TestStreamNetworkEdgesGroupsSortedSeries(t *testing.T) {
+	series := func(src, series, value string) string {
+		return `{"metric":{"src_namespace":"` + src + `","src_pod":"p","dst_namespace":"db","dst_pod":"q","protocol":"6","series":"` + series + `"},"value":[1700000000,"` + value + `"]}`
+	}
+	body := `{"status":"success","data":{"resultType":"vector","result":[` + strings.Join([]string{
+		series("api", "connections", "2"),
+		series("api", "received", "50"),
+		series("api", "sent", "100"),
+		series("batch", "sent", "1"),
+		series("web", "sent", "300"),
+	}, ",") + `]},"stats":{"seriesFetched":"5"}}`
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	c := &Client{baseURL: server.URL, lookback: time.Hour, streamClient: server.Client()}
+
+	var edges []store.NetworkEdge
+	opts := store.NetworkTopologyOptions{ClusterID: "cluster-1", MinBytes: 10}
+	err := c.StreamNetworkEdges(context.Background(), opts, func(edge store.NetworkEdge) error {
+		edges = append(edges, edge)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if !strings.HasPrefix(query, "sort_by_label(") || !strings.Contains(query, `"src_namespace", "src_pod"`) {
+		t.Fatalf("expected a query sorted by edge labels, got %s", query)
+	}
+	if len(edges) != 2 {
+		t.Fatalf("expected the batch edge to be filtered by minBytes, got %+v", edges)
+	}
+	if api := edges[0]; api.SrcNamespace != "api" || api.BytesSent != 100 || api.BytesReceived != 50 || api.ConnectionCount != 2 {
+		t.Fatalf("expected api series merged into one edge, got %+v", api)
+	}
+	if web := edges[1]; web.SrcNamespace != "web" || web.BytesSent != 300 {
+		t.Fatalf("unexpected last edge %+v", web)
+	}
+
+	stop := errors.New("client went away")
+	calls := 0
+	err = c.StreamNetworkEdges(context.Background(), opts, func(store.NetworkEdge) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected callback error to stop the stream after one edge, got %v after %d calls", err, calls)
+	}
+
+	body = `{"status":"success","data":{"resultType":"vector","result":[]}}`
+	err = c.StreamNetworkEdges(context.Background(), opts, func(store.NetworkEdge) error { return nil })
+	if !errors.Is(err, ErrNoData) {
+		t.Fatalf("expected ErrNoData for an empty result, got %v", err)
+	}
+}